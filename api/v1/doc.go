@@ -0,0 +1,5 @@
+// Package apiv1 contains the messages and the client of the gRPC api of kuota-calc serve, generated from
+// calculator.proto.
+package apiv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative api/v1/calculator.proto
@@ -0,0 +1,59 @@
+// Package calc calculates the resource quota needs of k8s resources, for tools embedding kuota-calc instead of running
+// its binary. It is the stable api of the calculation: the types are the ones the kuota-calc command uses, so the
+// results match its reports. Resources can be added and multiplied with their Add, MulInt32 and Mul methods.
+package calc
+
+import (
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type (
+	// ResourceUsage summarizes the usage of compute resources of a k8s resource, normally and during its rollout.
+	ResourceUsage = calc.ResourceUsage
+	// Resources are the requests and limits of cpu, memory and ephemeral storage, the extended resources, the storage
+	// of the claims and the pods.
+	Resources = calc.Resources
+	// PodResources are the resources of a single pod.
+	PodResources = calc.PodResources
+	// Details describe the calculated resource.
+	Details = calc.Details
+	// Options change how the resource usage is calculated. The zero value calculates with the default behavior.
+	Options = calc.Options
+	// CalculationError is returned if a resource can't be calculated, it includes the version and kind of it.
+	CalculationError = calc.CalculationError
+)
+
+// ErrResourceNotSupported is returned if a k8s resource is not supported by kuota-calc.
+//
+//nolint:gochecknoglobals // the error has to be comparable with errors.Is
+var ErrResourceNotSupported = calc.ErrResourceNotSupported
+
+// Calculate decodes a single yaml or json document into a k8s object and calculates its resource usage with the
+// default options. Kinds kuota-calc doesn't support return ErrResourceNotSupported.
+func Calculate(data []byte) (*ResourceUsage, error) {
+	return calc.ResourceQuotaFromYaml(data, Options{})
+}
+
+// CalculateWithOptions is like Calculate, but calculates with the given options.
+func CalculateWithOptions(data []byte, opts Options) (*ResourceUsage, error) {
+	return calc.ResourceQuotaFromYaml(data, opts)
+}
+
+// CalculateAll calculates each object of a document, the items of a v1 List, as printed by kubectl get -o yaml, or
+// the document itself.
+func CalculateAll(data []byte, opts Options) ([]*ResourceUsage, error) {
+	return calc.ResourceQuotasFromYaml(data, opts)
+}
+
+// CalculateObject calculates the resource usage of an already decoded k8s object, e.g. one read with client-go.
+func CalculateObject(object runtime.Object, opts Options) (*ResourceUsage, error) {
+	return calc.ResourceQuotaFromObject(object, opts)
+}
+
+// Total calculates the sum of all usages. maxRollouts limits how many simultaneous rollouts are assumed, the largest
+// differences between the normal and the rollout resources are added to the normal ones. A negative maxRollouts
+// assumes all resources are rolled out at once.
+func Total(maxRollouts int, usage []*ResourceUsage) Resources {
+	return calc.Total(maxRollouts, usage)
+}
@@ -0,0 +1,108 @@
+package calc_test
+
+import (
+	"fmt"
+
+	"github.com/druppelt/kuota-calc/calc"
+)
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: team-a
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: api
+          image: api:1.0
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi`
+
+const autoscaler = `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: api
+  namespace: team-a
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: api
+  minReplicas: 2
+  maxReplicas: 6`
+
+func ExampleCalculate() {
+	usage, err := calc.Calculate([]byte(deployment))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s/%s: %d replicas, normal cpu %s, rollout cpu %s\n", usage.Details.Kind, usage.Details.Name,
+		usage.Details.Replicas, usage.NormalResources.CPUMin.String(), usage.RolloutResources.CPUMin.String())
+	// Output: Deployment/api: 2 replicas, normal cpu 200m, rollout cpu 300m
+}
+
+func ExampleCalculateWithOptions() {
+	hpa, err := calc.Decode([]byte(autoscaler))
+	if err != nil {
+		panic(err)
+	}
+
+	autoscalers := calc.Autoscalers{}
+	autoscalers.Add(hpa)
+
+	defaults, err := calc.BuiltinStrategyDefaults(calc.PlatformKubernetes)
+	if err != nil {
+		panic(err)
+	}
+
+	version, err := calc.ParseKubernetesVersion("1.29")
+	if err != nil {
+		panic(err)
+	}
+
+	// the normal resources use the replicas of the deployment, the rollout assumes the autoscaler scaled it up fully
+	usage, err := calc.CalculateWithOptions([]byte(deployment), calc.Options{
+		StrategyDefaults:  &defaults,
+		Autoscalers:       autoscalers,
+		HPANormalReplicas: calc.HPASpecReplicas,
+		HPAPeakReplicas:   calc.HPAMaxReplicas,
+		KubernetesVersion: version,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("autoscaler %s: %d normal replicas, %d rollout replicas\n", usage.Details.Autoscaler,
+		usage.Details.NormalReplicas, usage.Details.Replicas)
+	fmt.Printf("normal cpu %s, rollout cpu %s\n", usage.NormalResources.CPUMin.String(), usage.RolloutResources.CPUMin.String())
+	// Output:
+	// autoscaler api: 2 normal replicas, 6 rollout replicas
+	// normal cpu 200m, rollout cpu 800m
+}
+
+func ExampleTotal() {
+	var usages []*calc.ResourceUsage
+
+	for _, name := range []string{"api", "web"} {
+		usage, err := calc.Calculate([]byte(deployment))
+		if err != nil {
+			panic(err)
+		}
+
+		usage.Details.Name = name
+		usages = append(usages, usage)
+	}
+
+	// only one of the deployments is assumed to roll out at a time
+	total := calc.Total(1, usages)
+	fmt.Printf("cpu %s, memory %s, pods %s\n", total.CPUMin.String(), total.MemoryMin.String(), total.Pods.String())
+	// Output: cpu 500m, memory 640Mi, pods 5
+}
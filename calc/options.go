@@ -0,0 +1,97 @@
+package calc
+
+import (
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The types of the fields of Options and ResourceUsage. Autoscalers and VerticalAutoscalers are built by adding the
+// decoded autoscalers of the input to an empty value, e.g. Autoscalers{}.
+type (
+	// StrategyDefaults are applied to rollout strategies, which don't set all values themselves.
+	StrategyDefaults = calc.StrategyDefaults
+	// RollingUpdateDefaults are the defaults of a rolling update strategy.
+	RollingUpdateDefaults = calc.RollingUpdateDefaults
+	// HPAReplicas selects the replicas of workloads scaled by a HorizontalPodAutoscaler.
+	HPAReplicas = calc.HPAReplicas
+	// Autoscalers are the HorizontalPodAutoscalers of the input, see Autoscalers.Add.
+	Autoscalers = calc.Autoscalers
+	// VPARecommendation selects the recommendation of the VerticalPodAutoscalers.
+	VPARecommendation = calc.VPARecommendation
+	// VerticalAutoscalers are the VerticalPodAutoscalers of the input, see VerticalAutoscalers.Add.
+	VerticalAutoscalers = calc.VerticalAutoscalers
+	// KubernetesVersion is the version of the target cluster.
+	KubernetesVersion = calc.KubernetesVersion
+	// Mesh is a service mesh, which injects a proxy container into the pods.
+	Mesh = calc.Mesh
+	// InjectionRules describe the containers mutating webhooks inject into the pods.
+	InjectionRules = calc.InjectionRules
+	// InjectionRule injects a container into the pods it matches.
+	InjectionRule = calc.InjectionRule
+	// CustomKinds describe the kinds, which are calculated from their pod templates.
+	CustomKinds = calc.CustomKinds
+	// CustomKind describes a kind by the JSONPaths of its replicas and its pod templates.
+	CustomKind = calc.CustomKind
+	// RollingUpdate are the values of the rolling update of a resource.
+	RollingUpdate = calc.RollingUpdate
+	// TimelinePoint is the resource usage at a point of a rollout, recorded with Options.Timeline.
+	TimelinePoint = calc.TimelinePoint
+)
+
+const (
+	// PlatformKubernetes selects the strategy defaults of kubernetes.
+	PlatformKubernetes = calc.PlatformKubernetes
+	// PlatformOpenShift selects the strategy defaults of openshift.
+	PlatformOpenShift = calc.PlatformOpenShift
+
+	// HPASpecReplicas uses the replicas of the workload spec.
+	HPASpecReplicas = calc.HPASpecReplicas
+	// HPAMinReplicas uses the minReplicas of the autoscaler.
+	HPAMinReplicas = calc.HPAMinReplicas
+	// HPAMaxReplicas uses the maxReplicas of the autoscaler.
+	HPAMaxReplicas = calc.HPAMaxReplicas
+
+	// VPATarget uses the target recommendation.
+	VPATarget = calc.VPATarget
+	// VPAUpperBound uses the upper bound recommendation.
+	VPAUpperBound = calc.VPAUpperBound
+	// VPAOff ignores the VerticalPodAutoscalers.
+	VPAOff = calc.VPAOff
+
+	// MeshIstio selects the istio service mesh.
+	MeshIstio = calc.MeshIstio
+	// MeshLinkerd selects the linkerd service mesh.
+	MeshLinkerd = calc.MeshLinkerd
+)
+
+// BuiltinStrategyDefaults returns the strategy defaults the platform applies, PlatformKubernetes or PlatformOpenShift.
+func BuiltinStrategyDefaults(platform string) (StrategyDefaults, error) {
+	return calc.BuiltinStrategyDefaults(platform)
+}
+
+// ParseHPAReplicas parses the replicas of autoscaled workloads, min, spec or max.
+func ParseHPAReplicas(policy string) (HPAReplicas, error) {
+	return calc.ParseHPAReplicas(policy)
+}
+
+// ParseVPARecommendation parses the recommendation of the VerticalPodAutoscalers, target, upperBound or off.
+func ParseVPARecommendation(recommendation string) (VPARecommendation, error) {
+	return calc.ParseVPARecommendation(recommendation)
+}
+
+// ParseKubernetesVersion parses a version like 1.27, v1.27 or 1.27.3. The patch version is ignored.
+func ParseKubernetesVersion(value string) (KubernetesVersion, error) {
+	return calc.ParseKubernetesVersion(value)
+}
+
+// NewMesh returns the mesh of the given name, MeshIstio or MeshLinkerd, with the default resources of its proxy.
+// proxyResources overrides them in the form requests.cpu=100m,limits.memory=1Gi, if not empty.
+func NewMesh(name, proxyResources string) (*Mesh, error) {
+	return calc.NewMesh(name, proxyResources)
+}
+
+// Decode decodes a single yaml or json document into a k8s object, e.g. to add the autoscalers of the input to
+// Autoscalers and VerticalAutoscalers. Kinds kuota-calc doesn't know are returned as *runtime.Unknown.
+func Decode(data []byte) (runtime.Object, error) {
+	return calc.Decode(data)
+}
@@ -0,0 +1,135 @@
+// Package calctest helps testing custom calculators and embeddings of kuota-calc with the same machinery the golden
+// tests of the kuota-calc command use: builders for the workloads, which are serialized into the manifests kuota-calc
+// reads, and a golden file comparison of the JSON report.
+package calctest
+
+import (
+	"bytes"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// Resources returns the requests or limits of a container. Empty quantities are left out, e.g.
+// Resources("250m", "1Gi") or Resources("", "512Mi"). It panics on invalid quantities.
+func Resources(cpu, memory string) v1.ResourceList {
+	resources := v1.ResourceList{}
+
+	if cpu != "" {
+		resources[v1.ResourceCPU] = resource.MustParse(cpu)
+	}
+
+	if memory != "" {
+		resources[v1.ResourceMemory] = resource.MustParse(memory)
+	}
+
+	return resources
+}
+
+// Container returns a container with the given requests and limits.
+func Container(name string, requests, limits v1.ResourceList) v1.Container {
+	return v1.Container{
+		Name:  name,
+		Image: name,
+		Resources: v1.ResourceRequirements{
+			Requests: requests,
+			Limits:   limits,
+		},
+	}
+}
+
+// PodTemplate returns a pod template running the given containers.
+func PodTemplate(containers ...v1.Container) v1.PodTemplateSpec {
+	return v1.PodTemplateSpec{
+		Spec: v1.PodSpec{Containers: containers},
+	}
+}
+
+// Deployment returns an apps/v1 Deployment with the default RollingUpdate strategy.
+func Deployment(namespace, name string, replicas int32, template v1.PodTemplateSpec) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: template,
+		},
+	}
+}
+
+// StatefulSet returns an apps/v1 StatefulSet with the default RollingUpdate strategy.
+func StatefulSet(namespace, name string, replicas int32, template v1.PodTemplateSpec) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: template,
+		},
+	}
+}
+
+// DaemonSet returns an apps/v1 DaemonSet.
+func DaemonSet(namespace, name string, template v1.PodTemplateSpec) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       appsv1.DaemonSetSpec{Template: template},
+	}
+}
+
+// Job returns a batch/v1 Job.
+func Job(namespace, name string, template v1.PodTemplateSpec) *batchv1.Job {
+	return &batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       batchv1.JobSpec{Template: template},
+	}
+}
+
+// CronJob returns a batch/v1 CronJob running on the given schedule, e.g. "*/5 * * * *".
+func CronJob(namespace, name, schedule string, template v1.PodTemplateSpec) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: batchv1.CronJobSpec{
+			Schedule:    schedule,
+			JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: template}},
+		},
+	}
+}
+
+// Pod returns a v1 Pod running the given containers.
+func Pod(namespace, name string, containers ...v1.Container) *v1.Pod {
+	return &v1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       v1.PodSpec{Containers: containers},
+	}
+}
+
+// Manifest serializes the objects into a multi document yaml manifest, as kuota-calc reads it. Objects of custom
+// kinds can be given as *unstructured.Unstructured.
+func Manifest(t testing.TB, objects ...runtime.Object) []byte {
+	t.Helper()
+
+	var manifest bytes.Buffer
+
+	for _, object := range objects {
+		data, err := sigsyaml.Marshal(object)
+		if err != nil {
+			t.Fatalf("serializing %s: %v", object.GetObjectKind().GroupVersionKind(), err)
+		}
+
+		manifest.WriteString("---\n")
+		manifest.Write(data)
+	}
+
+	return manifest.Bytes()
+}
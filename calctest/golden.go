@@ -0,0 +1,87 @@
+package calctest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/druppelt/kuota-calc/cmd"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// UpdateGoldenEnv is the environment variable, which makes AssertGolden write the actual output to the golden files
+// instead of comparing it, e.g. KUOTA_CALC_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "KUOTA_CALC_UPDATE_GOLDEN"
+
+// Report runs kuota-calc with the given arguments on the manifest and returns its JSON report. Calculators registered
+// with extension.Register are used like in the kuota-calc command. The run is isolated from the config file and the
+// KUOTA_CALC_* environment variables of the user and the report leaves out the provenance, so reports are
+// reproducible.
+func Report(t testing.TB, manifest []byte, args ...string) []byte {
+	t.Helper()
+
+	isolateConfig(t)
+
+	var out, errOut bytes.Buffer
+
+	root := cmd.NewKuotaCalcCmd(&cmd.Version{Version: "calctest"},
+		genericclioptions.IOStreams{In: bytes.NewReader(manifest), Out: &out, ErrOut: &errOut})
+	root.SetArgs(append([]string{"--output", "json", "--provenance=false"}, args...))
+	root.SetOut(&errOut)
+	root.SetErr(&errOut)
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("running kuota-calc: %v\n%s", err, errOut.String())
+	}
+
+	return out.Bytes()
+}
+
+// AssertGolden compares the actual output with the golden file, usually in testdata. With KUOTA_CALC_UPDATE_GOLDEN
+// set, the golden file is written instead.
+func AssertGolden(t testing.TB, golden string, actual []byte) {
+	t.Helper()
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatalf("updating golden file: %v", err)
+		}
+
+		if err := os.WriteFile(golden, actual, 0o600); err != nil {
+			t.Fatalf("updating golden file: %v", err)
+		}
+
+		return
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("reading golden file, set %s=1 to create it: %v", UpdateGoldenEnv, err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("output differs from golden file %s, set %s=1 to update it\nexpected:\n%s\nactual:\n%s",
+			golden, UpdateGoldenEnv, expected, actual)
+	}
+}
+
+// isolateConfig hides the config file and the KUOTA_CALC_* environment variables of the user for the test.
+func isolateConfig(t testing.TB) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("AppData", home)
+
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, "KUOTA_CALC_") && name != UpdateGoldenEnv {
+			// Setenv restores the variable after the test
+			t.Setenv(name, "")
+			_ = os.Unsetenv(name)
+		}
+	}
+}
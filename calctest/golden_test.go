@@ -0,0 +1,121 @@
+package calctest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB records the failures of a test instead of failing it.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// Fatalf stops the goroutine like testing.T does, so AssertGolden doesn't carry on after it.
+func (r *recordingTB) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// assertGolden runs AssertGolden in its own goroutine, which Fatalf may stop, and returns its failures.
+func assertGolden(t *testing.T, golden string, actual []byte) []string {
+	tb := &recordingTB{TB: t}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		AssertGolden(tb, golden, actual)
+	}()
+
+	<-done
+
+	return tb.failures
+}
+
+func TestAssertGolden(t *testing.T) {
+	var tests = []struct {
+		name     string
+		update   bool
+		existing string
+		actual   string
+		expected string
+		failure  string
+	}{
+		{
+			name:     "equal",
+			existing: "{}\n",
+			actual:   "{}\n",
+			expected: "{}\n",
+		},
+		{
+			name:     "mismatch",
+			existing: "{}\n",
+			actual:   "[]\n",
+			expected: "{}\n",
+			failure:  "output differs from golden file",
+		},
+		{
+			name:    "missing golden file",
+			actual:  "{}\n",
+			failure: "reading golden file, set KUOTA_CALC_UPDATE_GOLDEN=1 to create it",
+		},
+		{
+			name:     "update",
+			update:   true,
+			existing: "{}\n",
+			actual:   "[]\n",
+			expected: "[]\n",
+		},
+		{
+			name:     "create",
+			update:   true,
+			actual:   "{}\n",
+			expected: "{}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			if test.update {
+				t.Setenv(UpdateGoldenEnv, "1")
+			} else {
+				t.Setenv(UpdateGoldenEnv, "")
+			}
+
+			// the golden files are created in directories, which don't exist yet
+			golden := filepath.Join(t.TempDir(), "testdata", "report.json")
+			if test.existing != "" {
+				r.NoError(os.MkdirAll(filepath.Dir(golden), 0o755))
+				r.NoError(os.WriteFile(golden, []byte(test.existing), 0o600))
+			}
+
+			failures := assertGolden(t, golden, []byte(test.actual))
+			if test.failure != "" {
+				r.Len(failures, 1)
+				r.Contains(failures[0], test.failure)
+			} else {
+				r.Empty(failures)
+			}
+
+			if test.expected != "" {
+				data, err := os.ReadFile(golden)
+				r.NoError(err)
+				r.Equal(test.expected, string(data))
+			}
+		})
+	}
+}
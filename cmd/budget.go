@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+)
+
+// printBudget prints whether the total fits into the quota budget, how many simultaneous rollouts fit and, if the
+// total doesn't fit, the rolling updates which would make it fit.
+func (opts *KuotaCalcOpts) printBudget(usage []*calc.ResourceUsage) {
+	total := calc.Total(opts.maxRollouts, usage).AtUtilization(opts.utilization)
+
+	_, _ = fmt.Fprintf(opts.Out, "\nQuota budget %s\n", opts.quotaBudget)
+
+	switch rollouts, fits := calc.MaxRolloutsWithin(usage, opts.utilization, opts.budget); {
+	case !fits:
+		_, _ = fmt.Fprintf(opts.Out, "Even without any rollout, the resources don't fit into the quota budget\n")
+	case rollouts < 0:
+		_, _ = fmt.Fprintf(opts.Out, "All rollouts fit simultaneously, no --max-rollouts needed\n")
+	case rollouts == 0:
+		_, _ = fmt.Fprintf(opts.Out, "Only the normal resources fit, not even a single rollout fits on top of them\n")
+	default:
+		_, _ = fmt.Fprintf(opts.Out, "Up to %d simultaneous rollouts fit, use --max-rollouts=%d and limit the concurrency of your CD accordingly\n",
+			rollouts, rollouts)
+	}
+
+	exceeded := opts.budget.Exceeded(total)
+	if len(exceeded) == 0 {
+		_, _ = fmt.Fprintf(opts.Out, "The total fits into the quota budget\n")
+
+		return
+	}
+
+	names := make([]string, 0, len(exceeded))
+	for _, name := range exceeded {
+		names = append(names, string(name))
+	}
+
+	_, _ = fmt.Fprintf(opts.Out, "The total exceeds the quota budget in %s\n", strings.Join(names, ", "))
+
+	suggestions, fits := calc.SuggestRollingUpdates(usage, opts.maxRollouts, opts.utilization, opts.budget)
+	if len(suggestions) == 0 {
+		_, _ = fmt.Fprintf(opts.Out, "No rolling update can be lowered to reduce it\n")
+
+		return
+	}
+
+	_, _ = fmt.Fprintf(opts.Out, "\nSuggested rolling updates\n")
+
+	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(w, "Namespace\tKind\tName\tMaxSurge\tMaxUnavailable\t\n")
+
+	for _, suggestion := range suggestions {
+		current := suggestion.Usage.Details.RollingUpdate
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d -> %d\t%d -> %d\t\n",
+			suggestion.Usage.Details.Namespace,
+			suggestion.Usage.Details.Kind,
+			suggestion.Usage.Details.Name,
+			current.MaxSurge, suggestion.MaxSurge,
+			current.MaxUnavailable, suggestion.MaxUnavailable,
+		)
+	}
+
+	if err := w.Flush(); err != nil {
+		_, _ = fmt.Fprintf(opts.Out, "printing suggested rolling updates to tabwriter failed: %v\n", err)
+	}
+
+	if fits {
+		_, _ = fmt.Fprintf(opts.Out, "With these, the total fits into the quota budget\n")
+	} else {
+		_, _ = fmt.Fprintf(opts.Out, "Even with these, the total doesn't fit into the quota budget\n")
+	}
+}
+
+// checkThreshold returns an error listing the quantities of the total, which exceed --fail-if-exceeds.
+func (opts *KuotaCalcOpts) checkThreshold(usage []*calc.ResourceUsage) error {
+	if opts.threshold == nil {
+		return nil
+	}
+
+	total := calc.Total(opts.maxRollouts, usage).AtUtilization(opts.utilization)
+
+	exceeded := opts.threshold.Exceeded(total)
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	quantities := make([]string, 0, len(exceeded))
+	for _, name := range exceeded {
+		quantity, limit := total.QuotaQuantity(name), opts.threshold[name]
+		quantities = append(quantities, fmt.Sprintf("%s %s > %s", name, quantity.String(), limit.String()))
+	}
+
+	return fmt.Errorf("the total exceeds --fail-if-exceeds in %s", strings.Join(quantities, ", "))
+}
+
+// printRolloutCost prints the workloads ranked by the resources their rollout needs in addition to their normal
+// resources, the ones to tune first to shrink the total.
+func (opts *KuotaCalcOpts) printRolloutCost(usage []*calc.ResourceUsage) {
+	_, _ = fmt.Fprintf(opts.Out, "\nRollout cost, in addition to the normal resources\n")
+
+	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(w, "Rank\tNamespace\tKind\tName\tCPURequest\tCPULimit\tMemoryRequest\tMemoryLimit\t\n")
+
+	for i, u := range calc.RankByRolloutCost(usage) {
+		cost := u.RolloutCost()
+
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			i+1,
+			u.Details.Namespace,
+			u.Details.Kind,
+			u.Details.Name,
+			cost.CPUMin.String(),
+			cost.CPUMax.String(),
+			cost.MemoryMin.String(),
+			cost.MemoryMax.String(),
+		)
+	}
+
+	if err := w.Flush(); err != nil {
+		_, _ = fmt.Fprintf(opts.Out, "printing rollout cost to tabwriter failed: %v\n", err)
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"slices"
+	"sync"
+)
+
+// reportCache caches the JSON reports of the api by the key of their manifests. Once it is full, the least recently
+// used report is evicted. A cache of size 0 doesn't cache anything.
+type reportCache struct {
+	mu      sync.Mutex
+	size    int
+	reports map[string][]byte
+	// order contains the keys of the reports, the least recently used first
+	order []string
+}
+
+func newReportCache(size int) *reportCache {
+	return &reportCache{
+		size:    size,
+		reports: make(map[string][]byte, size),
+	}
+}
+
+func (c *reportCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report, ok := c.reports[key]
+	if ok {
+		c.touch(key)
+	}
+
+	return report, ok
+}
+
+func (c *reportCache) add(key string, report []byte) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.reports[key]; ok {
+		c.touch(key)
+
+		return
+	}
+
+	if len(c.order) >= c.size {
+		delete(c.reports, c.order[0])
+		c.order = c.order[1:]
+	}
+
+	c.reports[key] = report
+	c.order = append(c.order, key)
+}
+
+// touch marks a key as the most recently used one.
+func (c *reportCache) touch(key string) {
+	c.order = append(slices.DeleteFunc(c.order, func(k string) bool { return k == key }), key)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportCacheEviction(t *testing.T) {
+	r := require.New(t)
+
+	cache := newReportCache(2)
+	cache.add("a", []byte("report a"))
+	cache.add("b", []byte("report b"))
+
+	// reading a makes b the least recently used report
+	report, ok := cache.get("a")
+	r.True(ok)
+	r.Equal([]byte("report a"), report)
+
+	cache.add("c", []byte("report c"))
+
+	_, ok = cache.get("b")
+	r.False(ok)
+
+	for _, key := range []string{"a", "c"} {
+		_, ok = cache.get(key)
+		r.True(ok, key)
+	}
+
+	r.Len(cache.reports, 2)
+	r.Len(cache.order, 2)
+}
+
+func TestReportCacheDisabled(t *testing.T) {
+	r := require.New(t)
+
+	cache := newReportCache(0)
+	cache.add("a", []byte("report a"))
+
+	_, ok := cache.get("a")
+	r.False(ok)
+	r.Empty(cache.reports)
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+)
+
+// printCarbon prints the estimated energy and emissions of running the normal requests for a month. Rollouts are
+// short, so they are left out.
+func (opts *KuotaCalcOpts) printCarbon(usage []*calc.ResourceUsage) {
+	estimate := opts.carbon.Estimate(calc.Total(0, usage))
+
+	_, _ = fmt.Fprintf(opts.Out, "\nCarbon footprint of the normal requests\n")
+	_, _ = fmt.Fprintf(opts.Out, "Energy: %.1f kWh per month\nEmissions: %.1f kgCO2e per month\n",
+		estimate.KWhPerMonth, estimate.KgCO2ePerMonth)
+	_, _ = fmt.Fprintf(opts.Out, "assuming a grid intensity of %s gCO2e/kWh, a PUE of %s and a cpu utilization of 50%%\n",
+		strconv.FormatFloat(opts.carbon.GridIntensity, 'f', -1, 64), strconv.FormatFloat(opts.carbon.PUE, 'f', -1, 64))
+}
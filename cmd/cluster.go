@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	openshiftAppsV1 "github.com/openshift/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// clusterResources are the resources listed from the cluster with --from-cluster. ReplicaSets and
+// ReplicationControllers are listed as well, so their pods are skipped as part of the Deployment or DeploymentConfig
+// owning them. The autoscalers scale the workloads like the ones of manifests, e.g. with --hpa-peak.
+func clusterResources() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{
+		autoscalingv2.SchemeGroupVersion.WithResource("horizontalpodautoscalers"),
+		{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"},
+		appsv1.SchemeGroupVersion.WithResource("deployments"),
+		appsv1.SchemeGroupVersion.WithResource("replicasets"),
+		appsv1.SchemeGroupVersion.WithResource("statefulsets"),
+		appsv1.SchemeGroupVersion.WithResource("daemonsets"),
+		batchv1.SchemeGroupVersion.WithResource("cronjobs"),
+		batchv1.SchemeGroupVersion.WithResource("jobs"),
+		openshiftAppsV1.SchemeGroupVersion.WithResource("deploymentconfigs"),
+		corev1.SchemeGroupVersion.WithResource("replicationcontrollers"),
+		corev1.SchemeGroupVersion.WithResource("pods"),
+	}
+}
+
+// clusterManifests lists the workloads of the namespace selected by the kubeconfig flags and returns them as yaml
+// documents, so they are calculated like manifests, e.g. pods owned by a workload are skipped.
+func (opts *KuotaCalcOpts) clusterManifests(ctx context.Context) ([]byte, error) {
+	namespace, _, err := opts.configFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("getting namespace: %w", err)
+	}
+
+	client, err := opts.dynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return listManifests(ctx, client, namespace)
+}
+
+// dynamicClient returns a client of the cluster selected by the kubeconfig flags.
+func (opts *KuotaCalcOpts) dynamicClient() (dynamic.Interface, error) {
+	restConfig, err := opts.configFlags.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig: %w", err)
+	}
+
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating client: %w", err)
+	}
+
+	return client, nil
+}
+
+// listManifests lists the clusterResources of the namespace in pages of releasePageSize and returns them as yaml
+// documents. Resources the cluster doesn't serve, like DeploymentConfigs outside of OpenShift or
+// VerticalPodAutoscalers without the vertical pod autoscaler, are left out.
+func listManifests(ctx context.Context, client dynamic.Interface, namespace string) ([]byte, error) {
+	var manifests bytes.Buffer
+
+	for _, gvr := range clusterResources() {
+		listOptions := metav1.ListOptions{Limit: releasePageSize}
+
+		for {
+			page, err := client.Resource(gvr).Namespace(namespace).List(ctx, listOptions)
+			if apierrors.IsNotFound(err) {
+				break
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("listing %s in namespace %s: %w", gvr.Resource, namespace, err)
+			}
+
+			for i := range page.Items {
+				// the managed fields are of no use for the calculation, but make up a good part of the objects
+				unstructured.RemoveNestedField(page.Items[i].Object, "metadata", "managedFields")
+
+				data, err := sigsyaml.Marshal(page.Items[i].Object)
+				if err != nil {
+					return nil, fmt.Errorf("encoding %s %s: %w", gvr.Resource, page.Items[i].GetName(), err)
+				}
+
+				writeDocuments(&manifests, data)
+			}
+
+			if page.GetContinue() == "" {
+				break
+			}
+
+			listOptions.Continue = page.GetContinue()
+		}
+	}
+
+	return manifests.Bytes(), nil
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+var clusterDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: team-a
+  uid: deployment-uid
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi`
+
+var clusterReplicaSet = `
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: web-abc
+  namespace: team-a
+  uid: replicaset-uid
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: Deployment
+    name: web
+    uid: deployment-uid
+    controller: true
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi`
+
+var clusterPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-abc-%d
+  namespace: team-a
+  managedFields:
+  - manager: kube-controller-manager
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: ReplicaSet
+    name: web-abc
+    uid: replicaset-uid
+    controller: true
+spec:
+  containers:
+  - name: app
+    resources:
+      requests:
+        cpu: 100m
+        memory: 128Mi
+status:
+  phase: Running`
+
+var clusterHorizontalPodAutoscaler = `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+  namespace: team-a
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  minReplicas: 2
+  maxReplicas: 6`
+
+var clusterVerticalPodAutoscaler = `
+apiVersion: autoscaling.k8s.io/v1
+kind: VerticalPodAutoscaler
+metadata:
+  name: web
+  namespace: team-a
+spec:
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+status:
+  recommendation:
+    containerRecommendations:
+    - containerName: app
+      target:
+        cpu: 200m
+        memory: 256Mi`
+
+// clusterPageSize is the size of the pages the fake cluster returns, regardless of the requested limit.
+const clusterPageSize = 2
+
+// newFakeCluster returns a dynamic client of a cluster with the objects per resource, which doesn't serve
+// DeploymentConfigs like clusters without OpenShift, and VerticalPodAutoscalers only if there are any, like clusters
+// without the vertical pod autoscaler. The lists are returned in pages of clusterPageSize, requests counts the lists
+// per resource.
+func newFakeCluster(t *testing.T, objects map[string][]string, requests map[string]int) *dynamicfake.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, gvr := range clusterResources() {
+		listKinds[gvr] = gvr.Resource + "List"
+	}
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	client.PrependReactor("list", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		list := action.(clienttesting.ListActionImpl)
+		resource := list.GetResource()
+		requests[resource.Resource]++
+
+		require.Equal(t, int64(releasePageSize), list.ListOptions.Limit)
+
+		_, hasVPAs := objects["verticalpodautoscalers"]
+		if resource.Resource == "deploymentconfigs" || (resource.Resource == "verticalpodautoscalers" && !hasVPAs) {
+			return true, nil, apierrors.NewNotFound(resource.GroupResource(), "")
+		}
+
+		start := 0
+		if list.ListOptions.Continue != "" {
+			var err error
+			start, err = strconv.Atoi(list.ListOptions.Continue)
+			require.NoError(t, err)
+		}
+
+		manifests := objects[resource.Resource]
+		end := min(start+clusterPageSize, len(manifests))
+
+		page := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+		for _, manifest := range manifests[start:end] {
+			item := unstructured.Unstructured{}
+			require.NoError(t, sigsyaml.Unmarshal([]byte(manifest), &item.Object))
+			page.Items = append(page.Items, item)
+		}
+
+		if end < len(manifests) {
+			page.SetContinue(strconv.Itoa(end))
+		}
+
+		return true, page, nil
+	})
+
+	return client
+}
+
+func TestListManifests(t *testing.T) {
+	r := require.New(t)
+
+	requests := map[string]int{}
+	client := newFakeCluster(t, map[string][]string{
+		"deployments": {clusterDeployment},
+		"replicasets": {clusterReplicaSet},
+		"pods":        {fmt.Sprintf(clusterPod, 1), fmt.Sprintf(clusterPod, 2), fmt.Sprintf(clusterPod, 3)},
+	}, requests)
+
+	manifests, err := listManifests(context.Background(), client, "team-a")
+	r.NoError(err)
+
+	// the three pods are listed in two pages, the missing DeploymentConfigs and VerticalPodAutoscalers are left out
+	r.Equal(2, requests["pods"])
+	r.Equal(1, requests["deploymentconfigs"])
+	r.Equal(1, requests["verticalpodautoscalers"])
+	r.NotContains(string(manifests), "managedFields")
+
+	opts := newTestOpts(string(manifests))
+	skipped := skippedResources{}
+
+	workloads, err := opts.readWorkloads(&calc.Options{}, skipped)
+	r.NoError(err)
+
+	// the replicaset and its pods are part of the deployment, so they aren't counted twice
+	r.Len(workloads, 1)
+	r.IsType(&appsv1.Deployment{}, workloads[0])
+	r.Equal(skippedResources{
+		{version: "apps/v1", kind: "ReplicaSet", reason: "owned by Deployment"}: 1,
+		{version: "v1", kind: "Pod", reason: "owned by Deployment"}:             3,
+	}, skipped)
+}
+
+func TestListManifestsAutoscalers(t *testing.T) {
+	r := require.New(t)
+
+	client := newFakeCluster(t, map[string][]string{
+		"deployments":              {clusterDeployment},
+		"horizontalpodautoscalers": {clusterHorizontalPodAutoscaler},
+		"verticalpodautoscalers":   {clusterVerticalPodAutoscaler},
+	}, map[string]int{})
+
+	manifests, err := listManifests(context.Background(), client, "team-a")
+	r.NoError(err)
+
+	opts := newTestOpts(string(manifests))
+	opts.hpaPeak = string(calc.HPAMaxReplicas)
+
+	usage, _, err := opts.calculateRequest(context.Background())
+	r.NoError(err)
+
+	// the autoscalers of the cluster scale the deployment like the ones of manifests
+	r.Len(usage, 1)
+	r.Equal("web", usage[0].Details.Autoscaler)
+	r.Equal("web", usage[0].Details.VerticalAutoscaler)
+	r.Equal(int32(3), usage[0].Details.NormalReplicas)
+	r.Equal(int32(6), usage[0].Details.Replicas)
+	r.Equal("600m", usage[0].NormalResources.CPUMin.String())
+}
+
+func TestListManifestsError(t *testing.T) {
+	r := require.New(t)
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		appsv1.SchemeGroupVersion.WithResource("deployments"): "DeploymentList",
+	})
+	client.PrependReactor("list", "deployments", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(appsv1.Resource("deployments"), "", fmt.Errorf("no access"))
+	})
+
+	_, err := listManifests(context.Background(), client, "team-a")
+	r.ErrorContains(err, "listing deployments in namespace team-a")
+}
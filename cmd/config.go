@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// envPrefix is the prefix of the environment variables, which set flags. E.g. KUOTA_CALC_MAX_ROLLOUTS sets --max-rollouts.
+const envPrefix = "KUOTA_CALC_"
+
+// defaultConfigPath returns the path of the config file, which is read if no --config is given.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "kuota-calc", "config.yaml")
+}
+
+// applyConfig sets the flags, which weren't given on the command line, from the environment or the config file.
+// Flags take precedence over environment variables, which take precedence over the config file.
+func (opts *KuotaCalcOpts) applyConfig(cmd *cobra.Command) error {
+	config, err := opts.loadConfig(cmd.Root())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed || flag.Name == "config" || flag.Name == "profile" || flag.Name == "help" || flag.Name == "version" {
+			return
+		}
+
+		value, ok := os.LookupEnv(envName(flag.Name))
+		source := envName(flag.Name)
+
+		if !ok {
+			value, ok = config[flag.Name]
+			source = opts.config
+		}
+
+		if !ok {
+			return
+		}
+
+		if err := cmd.Flags().Set(flag.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid --%s: %w", source, flag.Name, err))
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// loadConfig reads the flag values of the config file, overridden by the values of the selected profile. A missing
+// config file is only an error, if it was given explicitly with --config.
+func (opts *KuotaCalcOpts) loadConfig(root *cobra.Command) (map[string]string, error) {
+	explicit := opts.config != ""
+	if !explicit {
+		opts.config = defaultConfigPath()
+	}
+
+	if opts.config == "" {
+		return nil, opts.requireProfile(nil)
+	}
+
+	data, err := os.ReadFile(opts.config)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return nil, opts.requireProfile(nil)
+		}
+
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	var file struct {
+		Profiles map[string]map[string]any `json:"profiles"`
+	}
+
+	var values map[string]any
+
+	if err := sigsyaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", opts.config, err)
+	}
+
+	if err := sigsyaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing config %s: profiles: %w", opts.config, err)
+	}
+
+	delete(values, "profiles")
+
+	config, err := opts.configValues(root, "", values)
+	if err != nil {
+		return nil, err
+	}
+
+	// the profile is selected like any other flag, but before the other flags are set
+	if !root.PersistentFlags().Lookup("profile").Changed {
+		if profile, ok := os.LookupEnv(envName("profile")); ok {
+			opts.profile = profile
+		} else if profile, ok := config["profile"]; ok {
+			opts.profile = profile
+		}
+	}
+
+	if err := opts.requireProfile(file.Profiles); err != nil {
+		return nil, err
+	}
+
+	profile, err := opts.configValues(root, opts.profile, file.Profiles[opts.profile])
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range profile {
+		config[name] = value
+	}
+
+	return config, nil
+}
+
+// requireProfile returns an error, if a profile is selected which isn't part of the profiles.
+func (opts *KuotaCalcOpts) requireProfile(profiles map[string]map[string]any) error {
+	if opts.profile == "" {
+		return nil
+	}
+
+	if _, ok := profiles[opts.profile]; !ok {
+		return fmt.Errorf("profile %q not found in config %s", opts.profile, opts.config)
+	}
+
+	return nil
+}
+
+// configValues converts the values of the config file or of a profile into flag values.
+func (opts *KuotaCalcOpts) configValues(root *cobra.Command, profile string, values map[string]any) (map[string]string, error) {
+	source := opts.config
+	if profile != "" {
+		source = fmt.Sprintf("%s: profile %s", opts.config, profile)
+	}
+
+	config := make(map[string]string, len(values))
+
+	for name, value := range values {
+		// the config may contain flags of other commands, but no flags which don't exist at all
+		if !hasFlag(root, name) {
+			return nil, fmt.Errorf("config %s: unknown flag %q", source, name)
+		}
+
+		switch v := value.(type) {
+		case map[string]any, []any:
+			return nil, fmt.Errorf("config %s: flag %q must be a single value", source, name)
+		case float64:
+			// yaml numbers are decoded as floats, format them without an exponent for the int flags
+			config[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			config[name] = fmt.Sprint(value)
+		}
+	}
+
+	return config, nil
+}
+
+// hasFlag reports whether the command or any of its sub commands has the flag.
+func hasFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+
+	for _, sub := range cmd.Commands() {
+		if hasFlag(sub, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// envName returns the name of the environment variable setting a flag.
+func envName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+var profileConfig = `
+max-rollouts: 2
+assume-replicas: 3
+profile: prod
+profiles:
+  prod:
+    max-rollouts: 5
+    platform: openshift
+  dev:
+    max-rollouts: 1
+`
+
+func TestLoadConfigProfiles(t *testing.T) {
+	var tests = []struct {
+		name     string
+		profile  string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name: "profile of the config",
+			expected: map[string]string{
+				"max-rollouts":    "5",
+				"assume-replicas": "3",
+				"platform":        "openshift",
+				"profile":         "prod",
+			},
+		},
+		{
+			name:    "selected profile",
+			profile: "dev",
+			expected: map[string]string{
+				"max-rollouts":    "1",
+				"assume-replicas": "3",
+				"profile":         "prod",
+			},
+		},
+		{
+			name:    "unknown profile",
+			profile: "staging",
+			err:     `profile "staging" not found`,
+		},
+	}
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(profileConfig), 0o600))
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			t.Setenv(envName("profile"), "")
+			r.NoError(os.Unsetenv(envName("profile")))
+
+			root := NewKuotaCalcCmd(&Version{}, genericclioptions.NewTestIOStreamsDiscard())
+			if test.profile != "" {
+				r.NoError(root.PersistentFlags().Set("profile", test.profile))
+			}
+
+			opts := newTestOpts("")
+			opts.config = config
+			opts.profile = test.profile
+
+			values, err := opts.loadConfig(root)
+			if test.err != "" {
+				r.ErrorContains(err, test.err)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(test.expected, values)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/spf13/cobra"
+)
+
+const explainExample = `    # print how the resources of all supported kinds are calculated
+    %[1]s explain
+
+    # print how deployments are calculated with a termination overlap of 50%%
+    %[1]s explain deployment --termination-overlap 0.5`
+
+// newExplainCmd returns a command printing the formulas and assumptions of the calculation of each kind.
+func newExplainCmd(opts *KuotaCalcOpts) *cobra.Command {
+	return &cobra.Command{
+		Use:          "explain [kind]",
+		Short:        "Print the formulas and assumptions used to calculate a kind, with the current option values.",
+		Example:      fmt.Sprintf(explainExample, "kuota-calc"),
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return opts.runExplain(args)
+		},
+	}
+}
+
+func (opts *KuotaCalcOpts) runExplain(args []string) error {
+	calcOpts, err := opts.calcOptions()
+	if err != nil {
+		return err
+	}
+
+	methodologies := calc.Methodologies(calcOpts)
+
+	if len(args) == 1 {
+		methodology, err := calc.KindMethodology(args[0], calcOpts)
+		if err != nil {
+			return err
+		}
+
+		methodologies = []calc.Methodology{methodology}
+	}
+
+	for i, m := range methodologies {
+		if i > 0 {
+			_, _ = fmt.Fprintln(opts.Out)
+		}
+
+		_, _ = fmt.Fprintf(opts.Out, "%s\n", m.Kind)
+
+		for _, formula := range m.Formulas {
+			_, _ = fmt.Fprintf(opts.Out, "  %s\n", formula)
+		}
+
+		_, _ = fmt.Fprintf(opts.Out, "  assuming:\n")
+
+		for _, assumption := range m.Assumptions {
+			_, _ = fmt.Fprintf(opts.Out, "  - %s\n", assumption)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/druppelt/kuota-calc/calctest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReportGolden(t *testing.T) {
+	web := calctest.Deployment("team-a", "web", 2, calctest.PodTemplate(
+		calctest.Container("web", calctest.Resources("250m", "256Mi"), calctest.Resources("", "512Mi")),
+	))
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web"},
+	}
+
+	var tests = []struct {
+		name   string
+		args   []string
+		golden string
+	}{
+		{name: "rollouts", golden: "testdata/report.json"},
+		{name: "no rollouts", args: []string{"--max-rollouts", "0"}, golden: "testdata/report-no-rollouts.json"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calctest.AssertGolden(t, test.golden, calctest.Report(t, calctest.Manifest(t, web, service), test.args...))
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	apiv1 "github.com/druppelt/kuota-calc/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/dynamic"
+)
+
+// maxStreamedManifestBytes limits the size of the manifests streamed to the gRPC api. The manifests are streamed in
+// chunks, so they may exceed the limit of the web ui and the REST api.
+const maxStreamedManifestBytes = 100 << 20
+
+// grpcCalculator serves the Calculator service of the gRPC api. Like the REST api, it calculates with the flags of
+// the server.
+type grpcCalculator struct {
+	apiv1.UnimplementedCalculatorServer
+
+	opts *KuotaCalcOpts
+	// client returns the client of the cluster, whose namespaces CalculateNamespace lists.
+	client func() (dynamic.Interface, error)
+}
+
+// newGRPCServer returns a gRPC server serving the Calculator service.
+func (opts *KuotaCalcOpts) newGRPCServer() *grpc.Server {
+	server := grpc.NewServer()
+	apiv1.RegisterCalculatorServer(server, &grpcCalculator{opts: opts, client: opts.dynamicClient})
+
+	return server
+}
+
+// CalculateManifests calculates the manifests streamed by the client.
+func (c *grpcCalculator) CalculateManifests(stream grpc.ClientStreamingServer[apiv1.ManifestsChunk, apiv1.Report]) error {
+	var manifests bytes.Buffer
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if manifests.Len()+len(chunk.GetData()) > maxStreamedManifestBytes {
+			return status.Errorf(codes.ResourceExhausted, "the manifests exceed %d bytes", maxStreamedManifestBytes)
+		}
+
+		manifests.Write(chunk.GetData())
+	}
+
+	report, err := c.calculate(stream.Context(), manifests.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(report)
+}
+
+// CalculateNamespace calculates the workloads of a namespace, listed like with --from-cluster.
+func (c *grpcCalculator) CalculateNamespace(ctx context.Context, request *apiv1.CalculateNamespaceRequest) (*apiv1.Report, error) {
+	if request.GetNamespace() == "" {
+		return nil, status.Error(codes.InvalidArgument, "the namespace is required")
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	manifests, err := listManifests(ctx, client, request.GetNamespace())
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	return c.calculate(ctx, manifests)
+}
+
+// calculate calculates the manifests of a request like the REST api and returns their report.
+func (c *grpcCalculator) calculate(ctx context.Context, manifests []byte) (*apiv1.Report, error) {
+	requestOpts := c.opts.requestOpts(manifests)
+
+	usage, skipped, err := requestOpts.calculateRequest(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return newGRPCReport(requestOpts.newReport(usage, skipped)), nil
+}
+
+// newGRPCReport converts the JSON report into the report of the gRPC api.
+func newGRPCReport(r report) *apiv1.Report {
+	grpcReport := &apiv1.Report{
+		Resources:   newGRPCResources(r.Resources),
+		Total:       newGRPCQuantities(r.Total),
+		Preemptible: newGRPCResources(r.Preemptible),
+	}
+
+	for _, skipped := range r.Skipped {
+		grpcReport.Skipped = append(grpcReport.Skipped, &apiv1.Skipped{
+			Version: skipped.Version,
+			Kind:    skipped.Kind,
+			Reason:  skipped.Reason,
+			Count:   int32(skipped.Count), //nolint:gosec // the count of resources of a single request fits into an int32
+		})
+	}
+
+	if r.Carbon != nil {
+		grpcReport.Carbon = &apiv1.Carbon{
+			GridIntensity:  r.Carbon.GridIntensity,
+			Pue:            r.Carbon.PUE,
+			KwhPerMonth:    r.Carbon.KWhPerMonth,
+			KgCo2EPerMonth: r.Carbon.KgCO2ePerMonth,
+		}
+	}
+
+	if r.Provenance != nil {
+		grpcReport.Provenance = &apiv1.Provenance{
+			Tool: &apiv1.Tool{
+				Version:   r.Provenance.Tool.Version,
+				Commit:    r.Provenance.Tool.Commit,
+				Date:      r.Provenance.Tool.Date,
+				GoVersion: r.Provenance.Tool.GoVersion,
+			},
+			Flags:     r.Provenance.Flags,
+			Inputs:    r.Provenance.Inputs,
+			GitCommit: r.Provenance.GitCommit,
+			Timestamp: r.Provenance.Timestamp.Format(time.RFC3339),
+		}
+	}
+
+	return grpcReport
+}
+
+func newGRPCResources(resources []reportResource) []*apiv1.Resource {
+	grpcResources := make([]*apiv1.Resource, 0, len(resources))
+
+	for _, r := range resources {
+		grpcResources = append(grpcResources, &apiv1.Resource{
+			Version:     r.Version,
+			Kind:        r.Kind,
+			Namespace:   r.Namespace,
+			Name:        r.Name,
+			Replicas:    r.Replicas,
+			Strategy:    r.Strategy,
+			MaxReplicas: r.MaxReplicas,
+			Normal:      newGRPCQuantities(r.Normal),
+			Rollout:     newGRPCQuantities(r.Rollout),
+		})
+	}
+
+	return grpcResources
+}
+
+func newGRPCQuantities(q reportQuantities) *apiv1.Quantities {
+	quantities := &apiv1.Quantities{
+		CpuRequest:              q.CPURequest.String(),
+		CpuLimit:                q.CPULimit.String(),
+		MemoryRequest:           q.MemoryRequest.String(),
+		MemoryLimit:             q.MemoryLimit.String(),
+		EphemeralStorageRequest: q.EphemeralStorageRequest.String(),
+		EphemeralStorageLimit:   q.EphemeralStorageLimit.String(),
+		StorageRequest:          q.StorageRequest.String(),
+		PersistentVolumeClaims:  q.PersistentVolumeClaims.String(),
+		Pods:                    q.Pods.String(),
+	}
+
+	if len(q.Extended) > 0 {
+		quantities.Extended = make(map[string]string, len(q.Extended))
+
+		for name, quantity := range q.Extended {
+			quantities.Extended[string(name)] = quantity.String()
+		}
+	}
+
+	return quantities
+}
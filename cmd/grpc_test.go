@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	apiv1 "github.com/druppelt/kuota-calc/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"k8s.io/client-go/dynamic"
+)
+
+// newGRPCClient serves the Calculator service in memory and returns a client of it.
+func newGRPCClient(t *testing.T, calculator *grpcCalculator) apiv1.CalculatorClient {
+	listener := bufconn.Listen(1 << 20)
+
+	server := grpc.NewServer()
+	apiv1.RegisterCalculatorServer(server, calculator)
+
+	go func() { _ = server.Serve(listener) }()
+
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return apiv1.NewCalculatorClient(conn)
+}
+
+// calculateManifests streams the manifests in chunks of the given size and returns the report.
+func calculateManifests(client apiv1.CalculatorClient, manifests string, chunkSize int) (*apiv1.Report, error) {
+	stream, err := client.CalculateManifests(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(manifests); start += chunkSize {
+		chunk := manifests[start:min(start+chunkSize, len(manifests))]
+		if err := stream.Send(&apiv1.ManifestsChunk{Data: []byte(chunk)}); err != nil {
+			return nil, err
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+func TestGRPCCalculateManifests(t *testing.T) {
+	r := require.New(t)
+
+	client := newGRPCClient(t, &grpcCalculator{opts: newTestOpts("")})
+
+	// the chunks end in the middle of the documents
+	report, err := calculateManifests(client, apiDeployment+"\n---\n"+worker, 50)
+	r.NoError(err)
+
+	r.Len(report.GetResources(), 1)
+	r.Equal("api", report.GetResources()[0].GetName())
+	r.Equal("200m", report.GetResources()[0].GetNormal().GetCpuRequest())
+	r.Equal("300m", report.GetTotal().GetCpuRequest())
+	r.Len(report.GetSkipped(), 1)
+	r.Equal("Worker", report.GetSkipped()[0].GetKind())
+
+	_, err = calculateManifests(client, "kind: [", 50)
+	r.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCCalculateNamespace(t *testing.T) {
+	r := require.New(t)
+
+	requests := map[string]int{}
+	cluster := newFakeCluster(t, map[string][]string{
+		"deployments": {clusterDeployment},
+		"replicasets": {clusterReplicaSet},
+		"pods":        {fmt.Sprintf(clusterPod, 1), fmt.Sprintf(clusterPod, 2), fmt.Sprintf(clusterPod, 3)},
+	}, requests)
+
+	client := newGRPCClient(t, &grpcCalculator{
+		opts:   newTestOpts(""),
+		client: func() (dynamic.Interface, error) { return cluster, nil },
+	})
+
+	report, err := client.CalculateNamespace(context.Background(), &apiv1.CalculateNamespaceRequest{Namespace: "team-a"})
+	r.NoError(err)
+
+	// the replicaset and the pods are part of the deployment
+	r.Len(report.GetResources(), 1)
+	r.Equal("web", report.GetResources()[0].GetName())
+	r.Equal("300m", report.GetResources()[0].GetNormal().GetCpuRequest())
+	r.Equal(1, requests["deployments"])
+
+	_, err = client.CalculateNamespace(context.Background(), &apiv1.CalculateNamespaceRequest{})
+	r.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func TestNewGRPCReport(t *testing.T) {
+	r := require.New(t)
+
+	opts := newTestOpts(apiDeployment)
+	opts.carbonIntensity = 400
+
+	usage, skipped, err := opts.calculateRequest(context.Background())
+	r.NoError(err)
+
+	jsonReport := opts.newReport(usage, skipped)
+	grpcReport := newGRPCReport(jsonReport)
+
+	r.Equal(jsonReport.Total.MemoryRequest.String(), grpcReport.GetTotal().GetMemoryRequest())
+	r.Equal(jsonReport.Total.Pods.String(), grpcReport.GetTotal().GetPods())
+	r.Equal(int32(2), grpcReport.GetResources()[0].GetReplicas())
+	r.InDelta(jsonReport.Carbon.KgCO2ePerMonth, grpcReport.GetCarbon().GetKgCo2EPerMonth(), 0)
+	r.Nil(grpcReport.GetProvenance())
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const helmExample = `    # calculate the chart in the directory ./chart with the values of production.yaml
+    %[1]s helm ./chart -f production.yaml
+
+    # calculate a chart of a repository with a value set on the command line
+    %[1]s helm bitnami/postgresql --version 15.5.0 --set replicaCount=3
+
+    # compare the environments of the chart, each rendered with its own values file on top of values.yaml
+    %[1]s helm ./chart -f values.yaml --values-matrix dev=values-dev.yaml,prod=values-prod.yaml`
+
+// helmTemplate are the flags passed to helm template.
+type helmTemplate struct {
+	binary      string
+	releaseName string
+	version     string
+	values      []string
+	set         []string
+}
+
+// newHelmCmd returns a command calculating a helm chart, which is rendered locally by helm template.
+func newHelmCmd(opts *KuotaCalcOpts) *cobra.Command {
+	var template helmTemplate
+
+	cmd := &cobra.Command{
+		Use:          "helm <chart>",
+		Short:        "Calculate the resource quota needs of a helm chart, rendered locally before it is installed.",
+		Example:      fmt.Sprintf(helmExample, "kuota-calc"),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.valuesMatrix != "" {
+				return opts.runMatrix(cmd.Context(), func(ctx context.Context, values string) ([]byte, error) {
+					return template.withValues(values).render(ctx, args[0], opts.defaultNamespace)
+				})
+			}
+
+			manifest, err := template.render(cmd.Context(), args[0], opts.defaultNamespace)
+			if err != nil {
+				return err
+			}
+
+			opts.In = bytes.NewReader(manifest)
+
+			return opts.run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&template.binary, "helm", "helm", "helm binary rendering the chart")
+	cmd.Flags().StringVar(&template.releaseName, "release-name", "release-name", "name of the release the chart is rendered for")
+	cmd.Flags().StringVar(&template.version, "version", "", "version of the chart of a repository, the latest if empty")
+	cmd.Flags().StringArrayVarP(&template.values, "values", "f", nil, "values file of the chart, can be repeated")
+	cmd.Flags().StringArrayVar(&template.set, "set", nil, "value of the chart, e.g. replicaCount=3, can be repeated")
+	cmd.Flags().StringVar(&opts.valuesMatrix, "values-matrix", "",
+		"environments and their values files, e.g. dev=values-dev.yaml,prod=values-prod.yaml. "+
+			"Renders and calculates each of them and prints a comparison of their totals")
+
+	return cmd
+}
+
+// withValues returns the template with the values file added after the ones of --values, so it overrides them.
+func (t helmTemplate) withValues(values string) helmTemplate {
+	t.values = append(slices.Clone(t.values), values)
+
+	return t
+}
+
+// render runs helm template on the chart and returns the rendered manifests. The chart is rendered into the namespace,
+// so resources without a namespace are calculated in the namespace of the release.
+func (t helmTemplate) render(ctx context.Context, chart, namespace string) ([]byte, error) {
+	args := []string{"template", t.releaseName, chart, "--namespace", namespace}
+
+	if t.version != "" {
+		args = append(args, "--version", t.version)
+	}
+
+	for _, values := range t.values {
+		args = append(args, "--values", values)
+	}
+
+	for _, value := range t.set {
+		args = append(args, "--set", value)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	command := exec.CommandContext(ctx, t.binary, args...)
+	command.Stdout, command.Stderr = &stdout, &stderr
+
+	if err := command.Run(); err != nil {
+		return nil, fmt.Errorf("rendering chart %s: %w: %s", chart, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
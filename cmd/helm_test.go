@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// installHelm writes a stub of the helm binary running the script, which records its arguments one per line. It
+// returns the path of the stub and of the recorded arguments.
+func installHelm(t *testing.T, script string) (string, string) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "helm")
+	args := filepath.Join(dir, "args")
+
+	require.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+args+"\n"+script+"\n"), 0o755))
+
+	return binary, args
+}
+
+// recordedArgs returns the arguments the stub of helm was run with.
+func recordedArgs(t *testing.T, path string) []string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestHelmTemplateRender(t *testing.T) {
+	var tests = []struct {
+		name     string
+		template helmTemplate
+		args     []string
+	}{
+		{
+			name:     "defaults",
+			template: helmTemplate{releaseName: "release-name"},
+			args:     []string{"template", "release-name", "./chart", "--namespace", "team-a"},
+		},
+		{
+			name: "flags",
+			template: helmTemplate{
+				releaseName: "api",
+				version:     "15.5.0",
+				values:      []string{"values.yaml", "production.yaml"},
+				set:         []string{"replicaCount=3", "image.tag=v2"},
+			},
+			args: []string{
+				"template", "api", "./chart", "--namespace", "team-a", "--version", "15.5.0",
+				"--values", "values.yaml", "--values", "production.yaml", "--set", "replicaCount=3", "--set", "image.tag=v2",
+			},
+		},
+		{
+			name:     "values of the matrix",
+			template: helmTemplate{releaseName: "release-name", values: []string{"values.yaml"}}.withValues("values-dev.yaml"),
+			args: []string{
+				"template", "release-name", "./chart", "--namespace", "team-a", "--values", "values.yaml", "--values", "values-dev.yaml",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			binary, args := installHelm(t, "echo 'kind: Deployment'")
+			test.template.binary = binary
+
+			manifest, err := test.template.render(context.Background(), "./chart", "team-a")
+			r.NoError(err)
+			r.Equal("kind: Deployment\n", string(manifest))
+			r.Equal(test.args, recordedArgs(t, args))
+		})
+	}
+}
+
+func TestHelmTemplateWithValues(t *testing.T) {
+	r := require.New(t)
+
+	template := helmTemplate{values: []string{"values.yaml"}}
+
+	// the environments of the matrix don't share their values files
+	r.Equal([]string{"values.yaml", "values-dev.yaml"}, template.withValues("values-dev.yaml").values)
+	r.Equal([]string{"values.yaml", "values-prod.yaml"}, template.withValues("values-prod.yaml").values)
+	r.Equal([]string{"values.yaml"}, template.values)
+}
+
+func TestHelmTemplateRenderError(t *testing.T) {
+	r := require.New(t)
+
+	binary, _ := installHelm(t, "echo 'Error: chart \"./chart\" not found' >&2\nexit 1")
+
+	_, err := helmTemplate{binary: binary, releaseName: "release-name"}.render(context.Background(), "./chart", "team-a")
+	r.EqualError(err, `rendering chart ./chart: exit status 1: Error: chart "./chart" not found`)
+
+	_, err = helmTemplate{binary: filepath.Join(t.TempDir(), "helm")}.render(context.Background(), "./chart", "team-a")
+	r.ErrorContains(err, "rendering chart ./chart")
+}
+
+func TestHelmCmd(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.yaml")
+	r.NoError(os.WriteFile(manifest, []byte(apiDeployment), 0o600))
+
+	binary, args := installHelm(t, "cat "+manifest)
+
+	opts := newTestOpts("")
+	out := &bytes.Buffer{}
+	opts.Out = out
+
+	cmd := newHelmCmd(opts)
+	cmd.SetArgs([]string{"./chart", "--helm", binary, "-f", "production.yaml", "--set", "replicaCount=3"})
+	r.NoError(cmd.ExecuteContext(context.Background()))
+
+	// the rendered deployment is calculated
+	r.Contains(out.String(), "CPU Request: 300m")
+	r.Equal([]string{
+		"template", "release-name", "./chart", "--namespace", "default", "--values", "production.yaml", "--set", "replicaCount=3",
+	}, recordedArgs(t, args))
+
+	// the errors of helm fail the command
+	binary, _ = installHelm(t, "echo 'Error: no values file' >&2\nexit 1")
+
+	cmd = newHelmCmd(newTestOpts(""))
+	cmd.SetArgs([]string{"./chart", "--helm", binary})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	r.ErrorContains(cmd.ExecuteContext(context.Background()), "rendering chart ./chart: exit status 1: Error: no values file")
+}
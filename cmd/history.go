@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	_ "modernc.org/sqlite" // registers the sqlite driver
+)
+
+const (
+	historyExample = `    # report the trends of the last 90 days and forecast when the quotas of the cluster will be exhausted
+    kubectl get resourcequota -A -o yaml > quotas.yaml
+    %[1]s history --history-db history.sqlite --since 90d --quotas quotas.yaml`
+
+	// historyGrowthPeriod is the period the growth of the totals is reported for.
+	historyGrowthPeriod = 30 * 24 * time.Hour
+	mebibyte            = 1 << 20
+)
+
+// historySchema creates the table, in which the totals of each run are recorded per namespace. Cpu is recorded in
+// millicores, memory and ephemeral storage in bytes.
+const historySchema = `CREATE TABLE IF NOT EXISTS totals (
+	recorded_at TEXT NOT NULL,
+	git_ref TEXT NOT NULL,
+	namespace TEXT NOT NULL,
+	cpu_request INTEGER NOT NULL,
+	cpu_limit INTEGER NOT NULL,
+	memory_request INTEGER NOT NULL,
+	memory_limit INTEGER NOT NULL,
+	ephemeral_storage_request INTEGER NOT NULL,
+	ephemeral_storage_limit INTEGER NOT NULL
+)`
+
+// openHistory opens the history database and creates its table, if it doesn't exist yet.
+func openHistory(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening history %s: %w", path, err)
+	}
+
+	if _, err := db.ExecContext(ctx, historySchema); err != nil {
+		_ = db.Close()
+
+		return nil, fmt.Errorf("creating history %s: %w", path, err)
+	}
+
+	return db, nil
+}
+
+// recordHistory records the totals of each namespace in the history database.
+func (opts *KuotaCalcOpts) recordHistory(usage []*calc.ResourceUsage) error {
+	ctx := context.Background()
+
+	db, err := openHistory(ctx, opts.historyDB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	namespaceKey, err := calc.GroupKey(calc.GroupByNamespace)
+	if err != nil {
+		return err
+	}
+
+	recordedAt := time.Now().UTC().Format(time.RFC3339)
+	gitRef := currentGitRef(ctx)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("recording history: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, group := range calc.GroupBy(usage, namespaceKey) {
+		total := calc.Total(opts.maxRollouts, group.Usage).AtUtilization(opts.utilization)
+
+		_, err := tx.ExecContext(ctx, `INSERT INTO totals VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			recordedAt,
+			gitRef,
+			group.Key,
+			total.CPUMin.MilliValue(),
+			total.CPUMax.MilliValue(),
+			total.MemoryMin.Value(),
+			total.MemoryMax.Value(),
+			total.EphemeralStorageMin.Value(),
+			total.EphemeralStorageMax.Value(),
+		)
+		if err != nil {
+			return fmt.Errorf("recording history of namespace %s: %w", group.Key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("recording history: %w", err)
+	}
+
+	return nil
+}
+
+// currentGitRef returns the commit checked out in the working directory, or an empty string outside of a git repository.
+func currentGitRef(ctx context.Context) string {
+	out, err := exec.CommandContext(ctx, "git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// historyResource is a resource, whose totals are recorded in a column of the history.
+type historyResource struct {
+	name   corev1.ResourceName
+	column string
+	format resource.Format
+	// milli is true, if the column is recorded in milli units
+	milli bool
+}
+
+func historyResources() []historyResource {
+	return []historyResource{
+		{name: corev1.ResourceRequestsCPU, column: "cpu_request", format: resource.DecimalSI, milli: true},
+		{name: corev1.ResourceLimitsCPU, column: "cpu_limit", format: resource.DecimalSI, milli: true},
+		{name: corev1.ResourceRequestsMemory, column: "memory_request", format: resource.BinarySI},
+		{name: corev1.ResourceLimitsMemory, column: "memory_limit", format: resource.BinarySI},
+		{name: corev1.ResourceRequestsEphemeralStorage, column: "ephemeral_storage_request", format: resource.BinarySI},
+		{name: corev1.ResourceLimitsEphemeralStorage, column: "ephemeral_storage_limit", format: resource.BinarySI},
+	}
+}
+
+// quantity converts a recorded value into a quantity.
+func (r historyResource) quantity(value int64) *resource.Quantity {
+	if r.milli {
+		return resource.NewMilliQuantity(value, r.format)
+	}
+
+	return resource.NewQuantity(value, r.format)
+}
+
+// round rounds a value to whole mebibytes, unless it is recorded in milli units already.
+func (r historyResource) round(value int64) int64 {
+	if r.milli {
+		return value
+	}
+
+	return value / mebibyte * mebibyte
+}
+
+// value converts a quantity into a recorded value.
+func (r historyResource) value(quantity resource.Quantity) int64 {
+	if r.milli {
+		return quantity.MilliValue()
+	}
+
+	return quantity.Value()
+}
+
+// namespaceHistory contains the recorded totals of a namespace per resource.
+type namespaceHistory map[corev1.ResourceName][]calc.TrendPoint
+
+// newHistoryCmd returns a command reporting the trends of the recorded totals.
+func newHistoryCmd(opts *KuotaCalcOpts) *cobra.Command {
+	var since, quotas string
+
+	cmd := &cobra.Command{
+		Use:          "history",
+		Short:        "Report how the recorded totals evolved and forecast when they will exhaust the quotas.",
+		Example:      fmt.Sprintf(historyExample, "kuota-calc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return opts.runHistory(since, quotas)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "90d", "only consider the totals recorded within this duration, e.g. 90d or 12h")
+	cmd.Flags().StringVar(&quotas, "quotas", "", "yaml file with the current ResourceQuotas, to forecast when they will be exhausted")
+
+	return cmd
+}
+
+func (opts *KuotaCalcOpts) runHistory(since, quotasFile string) error {
+	if opts.historyDB == "" {
+		return errors.New("the history needs a --history-db")
+	}
+
+	duration, err := parseSince(since)
+	if err != nil {
+		return err
+	}
+
+	quotas := map[string]corev1.ResourceList{}
+
+	if quotasFile != "" {
+		quotas, err = opts.loadQuotas(quotasFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	history, err := opts.loadHistory(time.Now().Add(-duration))
+	if err != nil {
+		return err
+	}
+
+	opts.printHistory(history, quotas)
+
+	return nil
+}
+
+// parseSince parses a duration, which in addition to time.ParseDuration supports days like 90d.
+func parseSince(since string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(since, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", since)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(since)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", since, err)
+	}
+
+	return duration, nil
+}
+
+// loadHistory returns the totals recorded since the given time per namespace.
+func (opts *KuotaCalcOpts) loadHistory(since time.Time) (map[string]namespaceHistory, error) {
+	ctx := context.Background()
+
+	db, err := openHistory(ctx, opts.historyDB)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	resources := historyResources()
+
+	columns := make([]string, 0, len(resources))
+	for _, r := range resources {
+		columns = append(columns, r.column)
+	}
+
+	//nolint:gosec // the columns are constants
+	rows, err := db.QueryContext(ctx, "SELECT recorded_at, namespace, "+strings.Join(columns, ", ")+
+		" FROM totals WHERE recorded_at >= ? ORDER BY recorded_at", since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	defer rows.Close()
+
+	history := map[string]namespaceHistory{}
+
+	for rows.Next() {
+		var (
+			recordedAt, namespace string
+			values                = make([]int64, len(resources))
+		)
+
+		dest := []any{&recordedAt, &namespace}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("reading history: %w", err)
+		}
+
+		at, err := time.Parse(time.RFC3339, recordedAt)
+		if err != nil {
+			return nil, fmt.Errorf("reading history: %w", err)
+		}
+
+		if history[namespace] == nil {
+			history[namespace] = namespaceHistory{}
+		}
+
+		for i, r := range resources {
+			history[namespace][r.name] = append(history[namespace][r.name], calc.TrendPoint{Time: at, Value: values[i]})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+
+	return history, nil
+}
+
+// loadQuotas reads the ResourceQuotas of a yaml file and returns the hard limits per namespace. If a namespace has
+// several quotas, the lowest limit of each resource applies.
+func (opts *KuotaCalcOpts) loadQuotas(path string) (map[string]corev1.ResourceList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading quotas: %w", err)
+	}
+	defer file.Close()
+
+	quotas := map[string]corev1.ResourceList{}
+	yamlReader := yaml.NewYAMLReader(bufio.NewReader(file))
+
+	for {
+		data, err := yamlReader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return quotas, nil
+			}
+
+			return nil, fmt.Errorf("reading quotas: %w", err)
+		}
+
+		object, err := calc.Decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := resourceQuotas(object)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, quota := range items {
+			calc.DefaultNamespace(&quota, opts.defaultNamespace)
+
+			if quotas[quota.Namespace] == nil {
+				quotas[quota.Namespace] = corev1.ResourceList{}
+			}
+
+			for name, hard := range quota.Spec.Hard {
+				if current, ok := quotas[quota.Namespace][name]; !ok || hard.Cmp(current) < 0 {
+					quotas[quota.Namespace][name] = hard
+				}
+			}
+		}
+	}
+}
+
+// resourceQuotas returns the ResourceQuotas of an object, which is either a ResourceQuota or a list of them, as
+// returned by kubectl get -o yaml.
+func resourceQuotas(object runtime.Object) ([]corev1.ResourceQuota, error) {
+	switch o := object.(type) {
+	case *corev1.ResourceQuota:
+		return []corev1.ResourceQuota{*o}, nil
+	case *corev1.ResourceQuotaList:
+		return o.Items, nil
+	case *corev1.List:
+		var quotas []corev1.ResourceQuota
+
+		for _, item := range o.Items {
+			itemObject, err := calc.Decode(item.Raw)
+			if err != nil {
+				return nil, err
+			}
+
+			itemQuotas, err := resourceQuotas(itemObject)
+			if err != nil {
+				return nil, err
+			}
+
+			quotas = append(quotas, itemQuotas...)
+		}
+
+		return quotas, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (opts *KuotaCalcOpts) printHistory(history map[string]namespaceHistory, quotas map[string]corev1.ResourceList) {
+	namespaces := make([]string, 0, len(history))
+	for namespace := range history {
+		namespaces = append(namespaces, namespace)
+	}
+
+	slices.Sort(namespaces)
+
+	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(w, "Namespace\tResource\tFirst\tLatest\tGrowthPer30d\tQuota\tExhausted\t\n")
+
+	for _, namespace := range namespaces {
+		for _, r := range historyResources() {
+			points := history[namespace][r.name]
+
+			// resources which were never requested or limited, like ephemeral storage usually, aren't of interest
+			if !slices.ContainsFunc(points, func(p calc.TrendPoint) bool { return p.Value != 0 }) {
+				continue
+			}
+
+			first, latest := points[0], points[len(points)-1]
+
+			growth, quota, exhausted := "-", "-", "-"
+
+			trend, hasTrend := calc.FitTrend(points)
+			if hasTrend {
+				growth = r.quantity(r.round(trend.Per(historyGrowthPeriod))).String()
+			}
+
+			if hard, ok := quotas[namespace][r.name]; ok {
+				quota = hard.String()
+				exhausted = forecastExhaustion(trend, hasTrend, latest.Value, r.value(hard))
+			}
+
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+				namespace,
+				r.name,
+				r.quantity(first.Value).String(),
+				r.quantity(latest.Value).String(),
+				growth,
+				quota,
+				exhausted,
+			)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		_, _ = fmt.Fprintf(opts.Out, "printing history to tabwriter failed: %v\n", err)
+	}
+}
+
+// forecastExhaustion describes when the trend reaches the quota.
+func forecastExhaustion(trend calc.Trend, hasTrend bool, latest, quota int64) string {
+	if latest >= quota {
+		return "exhausted"
+	}
+
+	if !hasTrend {
+		return "-"
+	}
+
+	at, ok := trend.Reaches(quota)
+	if !ok {
+		return "never"
+	}
+
+	return at.Format(time.DateOnly)
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseSince(t *testing.T) {
+	var tests = []struct {
+		since    string
+		duration time.Duration
+		err      bool
+	}{
+		{since: "30d", duration: 30 * 24 * time.Hour},
+		{since: "0d", duration: 0},
+		{since: "12h", duration: 12 * time.Hour},
+		{since: "-1d", err: true},
+		{since: "d", err: true},
+		{since: "1w", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.since, func(t *testing.T) {
+			r := require.New(t)
+
+			duration, err := parseSince(test.since)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(test.duration, duration)
+		})
+	}
+}
+
+func TestForecastExhaustion(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	growing, _ := calc.FitTrend([]calc.TrendPoint{{Time: start, Value: 100}, {Time: start.Add(24 * time.Hour), Value: 200}})
+	shrinking, _ := calc.FitTrend([]calc.TrendPoint{{Time: start, Value: 200}, {Time: start.Add(24 * time.Hour), Value: 100}})
+
+	var tests = []struct {
+		name      string
+		trend     calc.Trend
+		hasTrend  bool
+		latest    int64
+		exhausted string
+	}{
+		{name: "exhausted", trend: growing, hasTrend: true, latest: 500, exhausted: "exhausted"},
+		{name: "growing", trend: growing, hasTrend: true, latest: 200, exhausted: "2024-01-04"},
+		{name: "shrinking", trend: shrinking, hasTrend: true, latest: 100, exhausted: "never"},
+		{name: "no trend", latest: 100, exhausted: "-"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.exhausted, forecastExhaustion(test.trend, test.hasTrend, test.latest, 450))
+		})
+	}
+}
+
+func TestPrintHistorySinglePoint(t *testing.T) {
+	r := require.New(t)
+
+	opts := newTestOpts("")
+	out := &bytes.Buffer{}
+	opts.Out = out
+
+	history := map[string]namespaceHistory{
+		"team-a": {
+			corev1.ResourceRequestsCPU: {{Time: time.Now(), Value: 500}},
+		},
+	}
+	quotas := map[string]corev1.ResourceList{
+		"team-a": {corev1.ResourceRequestsCPU: resource.MustParse("2")},
+	}
+
+	opts.printHistory(history, quotas)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	r.Len(lines, 2)
+
+	// a single recorded total has no trend, so neither the growth nor the exhaustion can be forecast
+	r.Equal([]string{"team-a", "requests.cpu", "500m", "500m", "-", "2", "-"}, strings.Fields(lines[1]))
+}
+
+var quotaList = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ResourceQuota
+  metadata:
+    name: compute
+    namespace: team-a
+  spec:
+    hard:
+      requests.cpu: "4"
+      requests.memory: 8Gi
+- apiVersion: v1
+  kind: ResourceQuota
+  metadata:
+    name: cpu
+    namespace: team-a
+  spec:
+    hard:
+      requests.cpu: "2"
+      limits.cpu: "6"
+---
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: compute
+spec:
+  hard:
+    requests.memory: 1Gi
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored`
+
+func TestLoadQuotas(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "quotas.yaml")
+	r.NoError(os.WriteFile(path, []byte(quotaList), 0o600))
+
+	opts := newTestOpts("")
+
+	quotas, err := opts.loadQuotas(path)
+	r.NoError(err)
+	r.Len(quotas, 2)
+
+	// the lowest limit of the quotas of a namespace applies
+	assertEqualQuotas(r, corev1.ResourceList{
+		corev1.ResourceRequestsCPU:    resource.MustParse("2"),
+		corev1.ResourceLimitsCPU:      resource.MustParse("6"),
+		corev1.ResourceRequestsMemory: resource.MustParse("8Gi"),
+	}, quotas["team-a"])
+
+	// quotas without a namespace are in the default namespace
+	assertEqualQuotas(r, corev1.ResourceList{
+		corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+	}, quotas["default"])
+}
+
+func TestResourceQuotasOfList(t *testing.T) {
+	r := require.New(t)
+
+	list, err := calc.Decode([]byte(strings.Split(quotaList, "---")[0]))
+	r.NoError(err)
+
+	quotas, err := resourceQuotas(list)
+	r.NoError(err)
+	r.Len(quotas, 2)
+	r.Equal("compute", quotas[0].Name)
+	r.Equal("cpu", quotas[1].Name)
+
+	configMap, err := calc.Decode([]byte(strings.Split(quotaList, "---")[2]))
+	r.NoError(err)
+
+	quotas, err = resourceQuotas(configMap)
+	r.NoError(err)
+	r.Empty(quotas)
+}
+
+// assertEqualQuotas asserts that the quotas have the same resources with equal quantities, regardless of their format.
+func assertEqualQuotas(r *require.Assertions, expected, actual corev1.ResourceList) {
+	r.Len(actual, len(expected))
+
+	for name, quantity := range expected {
+		value, ok := actual[name]
+		r.True(ok, "missing %s", name)
+		r.Zero(quantity.Cmp(value), "%s: expected %s, got %s", name, quantity.String(), value.String())
+	}
+}
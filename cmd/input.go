@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// stdinFilename is the filename, which reads the manifests from stdin, like in kubectl.
+const stdinFilename = "-"
+
+// manifestExtensions are the extensions of the files, which are read from the directories of --filename.
+func manifestExtensions() []string {
+	return []string{".yaml", ".yml", ".json"}
+}
+
+// openInput replaces the input by the files of --filename, in their order, followed by the manifests rendered from
+// --kustomize and the workloads listed with --from-cluster. Without any, the manifests are read from stdin.
+func (opts *KuotaCalcOpts) openInput(ctx context.Context) error {
+	if len(opts.files) == 0 && opts.kustomize == "" && !opts.fromCluster {
+		return nil
+	}
+
+	var input bytes.Buffer
+
+	for _, file := range opts.files {
+		files, err := expandInputFile(file, opts.recursive)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range files {
+			data, err := readInputFile(opts.In, path)
+			if err != nil {
+				return err
+			}
+
+			writeDocuments(&input, data)
+		}
+	}
+
+	if opts.kustomize != "" {
+		data, err := renderKustomization(opts.kustomize)
+		if err != nil {
+			return err
+		}
+
+		writeDocuments(&input, data)
+	}
+
+	if opts.fromCluster {
+		data, err := opts.clusterManifests(ctx)
+		if err != nil {
+			return err
+		}
+
+		input.Write(data)
+	}
+
+	opts.In = &input
+
+	return nil
+}
+
+// inputName is the name of the input in the provenance of the report.
+func (opts *KuotaCalcOpts) inputName() string {
+	switch {
+	case len(opts.files) > 0:
+		return "filename"
+	case opts.kustomize != "":
+		return "kustomize"
+	case opts.fromCluster:
+		return "cluster"
+	default:
+		return "stdin"
+	}
+}
+
+// writeDocuments writes the yaml documents to the input. The separator ends the last document, even if the data
+// doesn't end with a newline.
+func writeDocuments(input *bytes.Buffer, data []byte) {
+	input.Write(data)
+
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		input.WriteString("\n")
+	}
+
+	input.WriteString("---\n")
+}
+
+// jsonDocuments splits a document of json input, like kubectl get -o json prints it, into its objects. The document
+// may contain several objects one after another and arrays of objects, nested arrays are flattened. null values are
+// left out. Documents which aren't json, like any yaml, are returned as they are.
+func jsonDocuments(data []byte) [][]byte {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return [][]byte{data}
+	}
+
+	var documents [][]byte
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+
+	for {
+		var value json.RawMessage
+
+		if err := decoder.Decode(&value); err != nil {
+			if errors.Is(err, io.EOF) {
+				return documents
+			}
+
+			// yaml flow style starts like json as well
+			return [][]byte{data}
+		}
+
+		var err error
+		if documents, err = appendJSONDocuments(documents, value); err != nil {
+			return [][]byte{data}
+		}
+	}
+}
+
+// appendJSONDocuments appends the value to the documents, or the items of it and of its nested arrays, if it is an
+// array. null values are left out.
+func appendJSONDocuments(documents [][]byte, value json.RawMessage) ([][]byte, error) {
+	value = bytes.TrimSpace(value)
+
+	switch {
+	case len(value) == 0 || bytes.Equal(value, []byte("null")):
+		return documents, nil
+	case value[0] != '[':
+		return append(documents, value), nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(value, &items); err != nil {
+		return nil, fmt.Errorf("decoding json array: %w", err)
+	}
+
+	for _, item := range items {
+		var err error
+		if documents, err = appendJSONDocuments(documents, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return documents, nil
+}
+
+// expandInputFile returns the manifests of a directory of --filename in lexical order, including the ones of its
+// subdirectories with --recursive. Other files are returned as they are.
+func expandInputFile(file string, recursive bool) ([]string, error) {
+	if file == stdinFilename {
+		return []string{file}, nil
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+
+	if !info.IsDir() {
+		return []string{file}, nil
+	}
+
+	var files []string
+
+	err = filepath.WalkDir(file, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if path != file && !recursive {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if slices.Contains(manifestExtensions(), filepath.Ext(path)) {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+
+	return files, nil
+}
+
+// readInputFile reads a file of --filename, - reads stdin.
+func readInputFile(stdin io.Reader, file string) ([]byte, error) {
+	if file == stdinFilename {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading stdin: %w", err)
+		}
+
+		return data, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+
+	return data, nil
+}
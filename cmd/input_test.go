@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONDocuments(t *testing.T) {
+	var tests = []struct {
+		name      string
+		data      string
+		documents []string
+	}{
+		{
+			name:      "single object",
+			data:      `{"kind": "Deployment"}`,
+			documents: []string{`{"kind": "Deployment"}`},
+		},
+		{
+			name:      "concatenated objects",
+			data:      "{\"kind\": \"Deployment\"}\n{\"kind\": \"StatefulSet\"}\n",
+			documents: []string{`{"kind": "Deployment"}`, `{"kind": "StatefulSet"}`},
+		},
+		{
+			name:      "array",
+			data:      `[{"kind": "Deployment"}, {"kind": "StatefulSet"}]`,
+			documents: []string{`{"kind": "Deployment"}`, `{"kind": "StatefulSet"}`},
+		},
+		{
+			name:      "nested arrays",
+			data:      `[[{"kind": "Deployment"}], [[{"kind": "StatefulSet"}]], []]`,
+			documents: []string{`{"kind": "Deployment"}`, `{"kind": "StatefulSet"}`},
+		},
+		{
+			name:      "null values",
+			data:      `{"kind": "Deployment"} null [null, {"kind": "StatefulSet"}]`,
+			documents: []string{`{"kind": "Deployment"}`, `{"kind": "StatefulSet"}`},
+		},
+		{
+			// the items of the list are calculated each after decoding it
+			name:      "v1 list",
+			data:      `{"apiVersion": "v1", "kind": "List", "items": [{"kind": "Deployment"}]}`,
+			documents: []string{`{"apiVersion": "v1", "kind": "List", "items": [{"kind": "Deployment"}]}`},
+		},
+		{
+			name:      "yaml flow style",
+			data:      "{kind: Deployment, metadata: {name: api}}\n",
+			documents: []string{"{kind: Deployment, metadata: {name: api}}\n"},
+		},
+		{
+			name:      "yaml",
+			data:      "kind: Deployment\n",
+			documents: []string{"kind: Deployment\n"},
+		},
+		{
+			name:      "empty input",
+			data:      "",
+			documents: []string{""},
+		},
+		{
+			name: "empty array",
+			data: "[]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var documents []string
+			for _, document := range jsonDocuments([]byte(test.data)) {
+				documents = append(documents, string(document))
+			}
+
+			require.Equal(t, test.documents, documents)
+		})
+	}
+}
+
+func TestExpandInputFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"b.yaml", "a.yml", "c.json", "notes.txt", "sub/d.yaml", "sub/deeper/e.yml", "sub/f.md"} {
+		path := filepath.Join(dir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("kind: Deployment\n"), 0o600))
+	}
+
+	var tests = []struct {
+		name      string
+		file      string
+		recursive bool
+		files     []string
+		err       string
+	}{
+		{
+			name:  "directory",
+			file:  dir,
+			files: []string{"a.yml", "b.yaml", "c.json"},
+		},
+		{
+			name:      "recursive directory",
+			file:      dir,
+			recursive: true,
+			files:     []string{"a.yml", "b.yaml", "c.json", "sub/d.yaml", "sub/deeper/e.yml"},
+		},
+		{
+			name:      "subdirectory",
+			file:      filepath.Join(dir, "sub"),
+			recursive: true,
+			files:     []string{"sub/d.yaml", "sub/deeper/e.yml"},
+		},
+		{
+			name:  "file of any extension",
+			file:  filepath.Join(dir, "notes.txt"),
+			files: []string{"notes.txt"},
+		},
+		{
+			name: "missing file",
+			file: filepath.Join(dir, "missing.yaml"),
+			err:  "reading input",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			files, err := expandInputFile(test.file, test.recursive)
+			if test.err != "" {
+				r.ErrorContains(err, test.err)
+				return
+			}
+
+			r.NoError(err)
+
+			var expected []string
+			for _, file := range test.files {
+				expected = append(expected, filepath.Join(dir, file))
+			}
+
+			r.Equal(expected, files)
+		})
+	}
+
+	files, err := expandInputFile(stdinFilename, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{stdinFilename}, files)
+}
+
+func TestReadInputFile(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "deployment.yaml")
+	r.NoError(os.WriteFile(file, []byte("kind: Deployment\n"), 0o600))
+
+	data, err := readInputFile(strings.NewReader("kind: StatefulSet\n"), file)
+	r.NoError(err)
+	r.Equal("kind: Deployment\n", string(data))
+
+	data, err = readInputFile(strings.NewReader("kind: StatefulSet\n"), stdinFilename)
+	r.NoError(err)
+	r.Equal("kind: StatefulSet\n", string(data))
+
+	_, err = readInputFile(strings.NewReader(""), filepath.Join(dir, "missing.yaml"))
+	r.ErrorContains(err, "reading input")
+	r.ErrorIs(err, fs.ErrNotExist)
+}
+
+func TestOpenInputFiles(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	deployment := filepath.Join(dir, "deployment.yaml")
+	r.NoError(os.WriteFile(deployment, []byte("kind: Deployment"), 0o600))
+	daemonSet := filepath.Join(dir, "daemonset.yaml")
+	r.NoError(os.WriteFile(daemonSet, []byte("kind: DaemonSet\n"), 0o600))
+
+	// the files of repeated --filename are read in their order, - reads stdin in between
+	opts := newTestOpts("kind: StatefulSet\n")
+	opts.files = []string{deployment, stdinFilename, daemonSet}
+	r.NoError(opts.openInput(context.Background()))
+
+	input, err := io.ReadAll(opts.In)
+	r.NoError(err)
+	r.Equal("kind: Deployment\n---\nkind: StatefulSet\n---\nkind: DaemonSet\n---\n", string(input))
+
+	opts = newTestOpts("")
+	opts.files = []string{deployment, filepath.Join(dir, "missing.yaml")}
+	r.ErrorContains(opts.openInput(context.Background()), "reading input")
+}
@@ -3,16 +3,32 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
-	"runtime"
+	"os"
+	goruntime "runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/druppelt/kuota-calc/internal/calc"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 const (
@@ -20,7 +36,19 @@ const (
     cat deployment.yaml | kubectl %[1]s
 
     # do the same, calling the binary directly with detailed output
-    cat deployment.yaml | %[1]s --detailed`
+    cat deployment.yaml | %[1]s --detailed
+
+    # read the manifests from files instead of stdin
+    %[1]s -f deployment.yaml -f statefulset.yaml
+
+    # render a kustomize overlay
+    %[1]s -k overlays/production
+
+    # right-size an existing namespace
+    %[1]s --from-cluster -n my-namespace
+
+    # compare the overlays of the environments
+    %[1]s --values-matrix dev=overlays/dev,prod=overlays/prod`
 )
 
 // KuotaCalcOpts holds all command options.
@@ -28,13 +56,96 @@ type KuotaCalcOpts struct {
 	genericclioptions.IOStreams
 
 	// flags
-	debug       bool
-	detailed    bool
-	version     bool
-	maxRollouts int
-	// files    []string
+	debug              bool
+	detailed           bool
+	version            bool
+	maxRollouts        int
+	terminationOverlap string
+	timeline           bool
+	platform           string
+	strategyDefaults   string
+	kubernetesVersion  string
+	mesh               string
+	meshProxy          string
+	injectionRules     string
+	customKinds        string
+	heuristic          bool
+	execCalculators    bool
+	showZero           bool
+	assumeReplicas     int32
+	hpaMode            string
+	hpaNormal          string
+	hpaPeak            string
+	vpaMode            string
+	jobRetries         bool
+	defaultNamespace   string
+	groupBy            string
+	groupByLabel       string
+	explain            bool
+	emptyDirStorage    bool
+	targetUtilization  string
+	output             string
+	quotaName          string
+	limitRangeName     string
+	quotaScopes        string
+	provenance         bool
+	tee                bool
+	carbonRegion       string
+	carbonIntensity    float64
+	ignorePriority     string
+	trace              bool
+	roundUp            string
+	quotaHeadroom      float64
+	outputDir          string
+	outputFile         string
+	ci                 bool
+	podPhases          string
+	quotaBudget        string
+	failIfExceeds      string
+	quota              string
+	rolloutCost        bool
+	fieldSelector      string
+	outputFormats      string
+	historyDB          string
+	config             string
+	profile            string
+	otlp               bool
+	simulateFailure    string
+	systemOverhead     string
+	systemReserved     string
+	kubeReserved       string
+	evictionHard       string
+	files              []string
+	recursive          bool
+	kustomize          string
+	fromCluster        bool
+	valuesMatrix       string
 
 	versionInfo *Version
+	utilization calc.TargetUtilization
+	groupKeys   []func(*calc.ResourceUsage) string
+	failure     calc.FailureSimulation
+	overhead    calc.SystemOverhead
+	budget      calc.Budget
+	threshold   calc.Budget
+	carbon      *calc.CarbonFootprint
+	traces      *documentTraces
+	rounding    calc.QuotaRounding
+	telemetry   *telemetry
+	// existingQuotas are the hard limits of the quotas of --quota per namespace
+	existingQuotas map[string]corev1.ResourceList
+	// configFlags are the kubeconfig flags, selecting the cluster and namespace of --from-cluster
+	configFlags *genericclioptions.ConfigFlags
+	// nodes are the nodes of the input, read by the last calculation
+	nodes []corev1.Node
+	// inputHash is the sha256 hash of the input, read by the last calculation
+	inputHash string
+	// effectiveFlags are the flags given on the command line, in the environment or in the config file
+	effectiveFlags map[string]string
+	// priorityClasses are the priority classes of the input, read by the last calculation with --ignore-priority-below
+	priorityClasses calc.PriorityClasses
+	// preemptible are the workloads excluded from the total by --ignore-priority-below in the last calculation
+	preemptible []*calc.ResourceUsage
 }
 
 // NewKuotaCalcCmd returns a coba command wrapping KuotaCalcOps
@@ -42,6 +153,7 @@ func NewKuotaCalcCmd(version *Version, streams genericclioptions.IOStreams) *cob
 	opts := KuotaCalcOpts{
 		IOStreams:   streams,
 		versionInfo: version,
+		configFlags: genericclioptions.NewConfigFlags(true),
 	}
 
 	cmd := &cobra.Command{
@@ -49,19 +161,186 @@ func NewKuotaCalcCmd(version *Version, streams genericclioptions.IOStreams) *cob
 		Short:        "Calculate the resource quota needs of your deployment(s).",
 		Example:      fmt.Sprintf(kuotaCalcExample, "kuota-calc"),
 		SilenceUsage: true,
-		RunE: func(_ *cobra.Command, _ []string) error {
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := opts.applyConfig(cmd); err != nil {
+				return err
+			}
+
+			if err := opts.applyHPAMode(cmd); err != nil {
+				return err
+			}
+
+			opts.recordFlags(cmd)
+
+			if !opts.otlp {
+				return nil
+			}
+
+			var err error
+
+			opts.telemetry, err = startTelemetry(cmd.Context())
+
+			return err
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
 			if opts.version {
 				return opts.printVersion()
 			}
 
-			return opts.run()
+			if opts.valuesMatrix != "" {
+				if len(opts.files) > 0 || opts.kustomize != "" || opts.fromCluster {
+					return errors.New("--values-matrix renders the kustomization of each environment, " +
+						"it can't be combined with --filename, --kustomize or --from-cluster")
+				}
+
+				return opts.runMatrix(cmd.Context(), func(_ context.Context, dir string) ([]byte, error) {
+					return renderKustomization(dir)
+				})
+			}
+
+			return opts.run(cmd.Context())
 		},
 	}
 
-	cmd.Flags().BoolVar(&opts.debug, "debug", false, "enable debug logging")
-	cmd.Flags().BoolVar(&opts.detailed, "detailed", false, "enable detailed output")
+	// finalizers also run if the command failed, so the traces of failed calculations are exported too
+	cobra.OnFinalize(opts.stopTelemetry)
+
+	cmd.PersistentFlags().StringVar(&opts.config, "config", "",
+		fmt.Sprintf("config file setting default values of the flags (default %s)", defaultConfigPath()))
+	cmd.PersistentFlags().StringVar(&opts.profile, "profile", "", "profile of the config file, whose values override the other values of the config file")
+	cmd.PersistentFlags().BoolVar(&opts.debug, "debug", false, "enable debug logging")
+	cmd.PersistentFlags().BoolVar(&opts.detailed, "detailed", false, "enable detailed output")
 	cmd.Flags().BoolVar(&opts.version, "version", false, "print version and exit")
-	cmd.Flags().IntVar(&opts.maxRollouts, "max-rollouts", -1, "limit the simultaneous rollout to the n most expensive rollouts per resource")
+	cmd.Flags().StringArrayVarP(&opts.files, "filename", "f", nil,
+		"file or directory containing the manifests, can be repeated, - reads stdin. Without any, the manifests are read from stdin")
+	cmd.Flags().BoolVarP(&opts.recursive, "recursive", "R", false,
+		"read the directories of --filename recursively, e.g. a whole gitops repository")
+	cmd.Flags().StringVarP(&opts.kustomize, "kustomize", "k", "",
+		"directory of a kustomization, whose rendered manifests are calculated like kustomize build would render them")
+	cmd.Flags().BoolVar(&opts.fromCluster, "from-cluster", false,
+		"calculate the deployments, statefulsets, daemonsets, cronjobs, jobs and pods of the namespace in the cluster, "+
+			"scaled by its autoscalers and selected by the kubeconfig flags, e.g. -n")
+	cmd.Flags().StringVar(&opts.valuesMatrix, "values-matrix", "",
+		"environments and the directories of their kustomizations, e.g. dev=overlays/dev,prod=overlays/prod. "+
+			"Renders and calculates each of them and prints a comparison of their totals")
+	opts.configFlags.AddFlags(cmd.Flags())
+	cmd.PersistentFlags().IntVar(&opts.maxRollouts, "max-rollouts", -1, "limit the simultaneous rollout to the n most expensive rollouts per resource")
+	cmd.PersistentFlags().Int32Var(&opts.assumeReplicas, "assume-replicas", 1, "replicas assumed for workloads, which don't set spec.replicas")
+	cmd.PersistentFlags().BoolVar(&opts.showZero, "show-zero", false,
+		"list workloads scaled to zero replicas in the detailed output and the reports, the quotas and limit ranges leave them out")
+	cmd.PersistentFlags().StringVar(&opts.terminationOverlap, "termination-overlap", "",
+		"fraction (0-1) of scaled down pods assumed to still be terminating during a rollout, "+
+			"or 'grace' to derive it from the terminationGracePeriodSeconds")
+	cmd.PersistentFlags().StringVar(&opts.platform, "platform", calc.PlatformKubernetes,
+		fmt.Sprintf("platform whose strategy defaults are applied, one of %s, %s", calc.PlatformKubernetes, calc.PlatformOpenShift))
+	cmd.PersistentFlags().StringVar(&opts.kubernetesVersion, "kubernetes-version", "",
+		"version of the target cluster, e.g. 1.27. Fields it doesn't support are ignored with a warning, defaults to the latest version")
+	cmd.PersistentFlags().StringVar(&opts.strategyDefaults, "strategy-defaults", "", "yaml file overriding the strategy defaults of the platform")
+	cmd.PersistentFlags().StringVar(&opts.hpaMode, "hpa-mode", "",
+		fmt.Sprintf("replicas of workloads scaled by a HorizontalPodAutoscaler used for the normal and the rollout resources, "+
+			"one of %s, %s, %s. --hpa-normal and --hpa-peak override it", calc.HPAMinReplicas, calc.HPASpecReplicas, calc.HPAMaxReplicas))
+	cmd.PersistentFlags().StringVar(&opts.hpaNormal, "hpa-normal", string(calc.HPASpecReplicas),
+		fmt.Sprintf("replicas of workloads scaled by a HorizontalPodAutoscaler used for the normal resources, one of %s, %s, %s",
+			calc.HPAMinReplicas, calc.HPASpecReplicas, calc.HPAMaxReplicas))
+	cmd.PersistentFlags().StringVar(&opts.hpaPeak, "hpa-peak", string(calc.HPASpecReplicas),
+		fmt.Sprintf("replicas of workloads scaled by a HorizontalPodAutoscaler used for the rollout resources, one of %s, %s, %s",
+			calc.HPAMinReplicas, calc.HPASpecReplicas, calc.HPAMaxReplicas))
+	cmd.PersistentFlags().StringVar(&opts.vpaMode, "vpa-mode", string(calc.VPATarget),
+		fmt.Sprintf("recommendation of the VerticalPodAutoscalers of the input replacing the requests of the pods they scale, one of %s, %s, %s",
+			calc.VPATarget, calc.VPAUpperBound, calc.VPAOff))
+	cmd.PersistentFlags().BoolVar(&opts.jobRetries, "job-retries", false,
+		"assume failing job pods are still terminating while their retry pods are starting")
+	cmd.PersistentFlags().StringVar(&opts.podPhases, "pod-phases", calc.DefaultPodPhases,
+		"phases of the pods of the input, which are calculated. Pods without a phase, e.g. of manifests, are always calculated")
+	cmd.PersistentFlags().StringVar(&opts.fieldSelector, "field-selector", "",
+		"calculate only the pods of the input matching the field selector, e.g. spec.nodeName=worker-1")
+	cmd.PersistentFlags().StringVar(&opts.defaultNamespace, "default-namespace", "default", "namespace of resources, which don't specify one")
+	cmd.PersistentFlags().StringVar(&opts.groupBy, "group-by", "",
+		fmt.Sprintf("additionally print the totals grouped by %s, %s, %s, %s<key> or %s<key>, comma separated for nested groups, e.g. %s,%s",
+			calc.GroupByNamespace, calc.GroupByPriorityClass, calc.GroupByKind, calc.GroupByLabelPrefix, calc.GroupByAnnotationPrefix,
+			calc.GroupByNamespace, calc.GroupByKind))
+	cmd.PersistentFlags().StringVar(&opts.groupByLabel, "group-by-label", "",
+		"additionally print the totals per value of the given label of the resources, e.g. team")
+	cmd.PersistentFlags().StringVarP(&opts.output, "output", "o", "",
+		fmt.Sprintf("output format, empty for the report, %s for the JSON report, %s for a markdown report, %s for a csv report, "+
+			"%s for a ResourceQuota manifest per namespace or %s for a LimitRange manifest per namespace",
+			outputJSON, outputMarkdown, outputCSV, outputQuota, outputLimitRange))
+	cmd.PersistentFlags().BoolVar(&opts.ci, "ci", false,
+		"print the human readable report to stderr and the JSON report to stdout, to log the report and pass it on in one step")
+	cmd.PersistentFlags().StringVar(&opts.outputFile, "output-file", "", "file, to which the output is written instead of stdout")
+	cmd.PersistentFlags().StringVar(&opts.outputDir, "output-dir", "", "directory, to which each of the --output-formats is written in addition")
+	cmd.PersistentFlags().StringVar(&opts.outputFormats, "output-formats", outputText,
+		fmt.Sprintf("comma separated formats written to --output-dir, any of %s", strings.Join(outputFormats(), ",")))
+	cmd.PersistentFlags().StringVar(&opts.quotaName, "quota-name", "compute-resources", "name of the ResourceQuotas generated with -o quota")
+	cmd.PersistentFlags().StringVar(&opts.limitRangeName, "limit-range-name", "container-limits",
+		"name of the LimitRanges generated with -o limitrange")
+	cmd.PersistentFlags().StringVar(&opts.quotaScopes, "quota-scopes", "",
+		fmt.Sprintf("split the ResourceQuotas generated with -o quota by scopes, any of %s and %s", calc.QuotaScopeTerminating, calc.QuotaScopePriorityClass))
+	cmd.PersistentFlags().Float64Var(&opts.quotaHeadroom, "quota-headroom", 0,
+		"percentage added on top of the values of the ResourceQuotas generated with -o quota, e.g. 20")
+	cmd.PersistentFlags().StringVar(&opts.roundUp, "round-up", "",
+		"round the values of the ResourceQuotas generated with -o quota up to the given increments, e.g. cpu=500m,memory=1Gi")
+	cmd.PersistentFlags().BoolVar(&opts.provenance, "provenance", true,
+		"include the version, the effective flags, the input hashes, the git commit and a timestamp in the JSON report")
+	cmd.PersistentFlags().BoolVar(&opts.tee, "tee", false,
+		"copy the input unchanged to stdout after a successful calculation and print the report to stderr or --output-file")
+	cmd.PersistentFlags().StringVar(&opts.carbonRegion, "carbon-region", "",
+		"estimate the carbon footprint of the normal requests in the cloud region, e.g. aws/eu-central-1")
+	cmd.PersistentFlags().Float64Var(&opts.carbonIntensity, "carbon-grid-intensity", 0,
+		"estimate the carbon footprint of the normal requests with the carbon intensity of the grid in gCO2e per kWh")
+	cmd.PersistentFlags().StringVar(&opts.ignorePriority, "ignore-priority-below", "",
+		"exclude the workloads with a priority below the given priority class or value from the total and list them separately")
+	cmd.PersistentFlags().BoolVar(&opts.trace, "trace", false,
+		"print the size, decode and calculation time of each document and the slowest documents to stderr")
+	cmd.PersistentFlags().BoolVar(&opts.heuristic, "heuristic", false,
+		"estimate kinds without a calculator from the pod spec found in them, e.g. spec.template.spec, instead of skipping them")
+	cmd.PersistentFlags().BoolVar(&opts.execCalculators, "external-calculators", false,
+		"calculate kinds unknown to kuota-calc with the executable kuota-calc-<kind> on the PATH, e.g. kuota-calc-worker")
+	cmd.PersistentFlags().StringVar(&opts.mesh, "mesh", "",
+		fmt.Sprintf("service mesh injecting a proxy into the pods, one of %s, %s. Namespaces of the input select the injected ones",
+			calc.MeshIstio, calc.MeshLinkerd))
+	cmd.PersistentFlags().StringVar(&opts.meshProxy, "mesh-proxy", "",
+		"resources of the injected proxy overriding the defaults of the --mesh, e.g. requests.cpu=50m,limits.memory=512Mi")
+	cmd.PersistentFlags().StringVar(&opts.injectionRules, "injection-rules", "",
+		"yaml file describing the containers mutating webhooks inject into the pods, e.g. vault agents or log shippers")
+	cmd.PersistentFlags().StringVar(&opts.customKinds, "custom-kinds", "",
+		"yaml file describing the JSONPaths of the replicas and pod templates of custom resources, which are calculated from them")
+	cmd.PersistentFlags().BoolVar(&opts.emptyDirStorage, "empty-dir-storage", false,
+		"count the sizeLimit of emptyDir volumes towards the ephemeral storage")
+	cmd.PersistentFlags().StringVar(&opts.targetUtilization, "target-utilization", "",
+		"inflate the total requests, so the calculated requests use the given share of them, e.g. cpu=0.6,memory=0.8")
+	cmd.PersistentFlags().StringVar(&opts.historyDB, "history-db", "", "sqlite database, in which the totals of each namespace are recorded")
+	cmd.PersistentFlags().BoolVar(&opts.otlp, "otlp", false,
+		"export traces and metrics over OTLP/HTTP, configured by the OTEL_EXPORTER_OTLP_* environment variables")
+	cmd.PersistentFlags().StringVar(&opts.simulateFailure, "simulate-failure", "",
+		"estimate the headroom needed to reschedule the pods of a failed zone or of failed nodes, e.g. zone or nodes=2")
+	cmd.PersistentFlags().BoolVar(&opts.rolloutCost, "rollout-cost", false,
+		"rank the workloads by the resources their rollout needs in addition to their normal resources")
+	cmd.PersistentFlags().StringVar(&opts.quotaBudget, "quota-budget", "",
+		"quota the total has to fit into, e.g. requests.cpu=4,limits.memory=16Gi. Suggests rolling updates which fit, if it doesn't")
+	cmd.PersistentFlags().StringVar(&opts.quota, "quota", "",
+		"compare the total with existing ResourceQuotas, of a yaml file or of the cluster given as <namespace>/<name>")
+	cmd.PersistentFlags().StringVar(&opts.failIfExceeds, "fail-if-exceeds", "",
+		"exit with 1 after the report, if the total exceeds any of the quantities, e.g. cpu.request=10,memory.limit=64Gi")
+	cmd.PersistentFlags().StringVar(&opts.systemOverhead, "system-overhead", "",
+		"resources of each node reserved for the kubelet and system daemons, e.g. cpu=500m,memory=1Gi. Prints the capacity of the nodes of the input")
+	cmd.PersistentFlags().StringVar(&opts.systemReserved, "system-reserved", "",
+		"resources the kubelet reserves for system daemons, e.g. cpu=500m,memory=1Gi. Derives the allocatable resources from the node capacity")
+	cmd.PersistentFlags().StringVar(&opts.kubeReserved, "kube-reserved", "",
+		"resources the kubelet reserves for kubernetes daemons, e.g. cpu=100m,memory=512Mi")
+	cmd.PersistentFlags().StringVar(&opts.evictionHard, "eviction-hard", "",
+		"hard eviction thresholds of the kubelet, of which memory.available is reserved, e.g. memory.available<100Mi")
+	cmd.PersistentFlags().BoolVar(&opts.explain, "explain", false, "print how the resources of each workload are calculated")
+	cmd.PersistentFlags().BoolVar(&opts.timeline, "timeline", false, "simulate the rollouts over time and report the peak of the simultaneous rollout of all resources")
+
+	cmd.AddCommand(newReleaseCmd(&opts))
+	cmd.AddCommand(newHelmCmd(&opts))
+	cmd.AddCommand(newTUICmd(&opts))
+	cmd.AddCommand(newServeCmd(&opts))
+	cmd.AddCommand(newHistoryCmd(&opts))
+	cmd.AddCommand(newExplainCmd(&opts))
+	cmd.AddCommand(newSupportedCmd(&opts))
+	cmd.AddCommand(newRecalcCmd(&opts))
 
 	return cmd
 }
@@ -71,71 +350,717 @@ func (opts *KuotaCalcOpts) printVersion() error {
 		opts.versionInfo.Version,
 		opts.versionInfo.Commit,
 		opts.versionInfo.Date,
-		runtime.Version(),
+		goruntime.Version(),
 	)
 
 	return nil
 }
 
-func (opts *KuotaCalcOpts) run() error {
+func (opts *KuotaCalcOpts) run(ctx context.Context) error {
+	if err := validateOutput(opts.output); err != nil {
+		return err
+	}
+
+	if opts.ci && opts.output != "" {
+		return fmt.Errorf("--ci prints the report and the JSON report, it can't be combined with --output %s", opts.output)
+	}
+
+	if opts.ci && opts.tee {
+		return errors.New("--ci prints the JSON report to stdout, it can't be combined with --tee")
+	}
+
+	formats, err := opts.outputFormatList()
+	if err != nil {
+		return err
+	}
+
+	if err := opts.parseReportFlags(); err != nil {
+		return err
+	}
+
+	if opts.quota != "" {
+		opts.existingQuotas, err = opts.loadExistingQuotas(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := opts.openInput(ctx); err != nil {
+		return err
+	}
+
+	if opts.trace {
+		opts.traces = newDocumentTraces()
+	}
+
+	// the input is only passed on after a successful calculation, so a failing check stops the pipeline
+	var input []byte
+
+	if opts.tee {
+		input, err = io.ReadAll(opts.In)
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+
+		opts.In = bytes.NewReader(input)
+	}
+
+	summary, skipped, err := opts.calculate(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts.printTraces()
+
+	if opts.historyDB != "" {
+		if err := opts.recordHistory(summary); err != nil {
+			return err
+		}
+	}
+
+	if opts.outputDir != "" {
+		if err := opts.writeOutputs(formats, summary, skipped); err != nil {
+			return err
+		}
+	}
+
+	format := opts.output
+
+	if opts.ci {
+		logOpts := *opts
+		logOpts.Out = opts.ErrOut
+
+		if err := logOpts.printReport(summary, skipped); err != nil {
+			return err
+		}
+
+		format = outputJSON
+	}
+
+	// the report is printed even if the threshold is exceeded, but the input isn't passed on
+	thresholdErr := opts.checkThreshold(summary)
+	if thresholdErr != nil {
+		input = nil
+	}
+
+	switch {
+	case opts.tee:
+		err = opts.printTee(input, format, summary, skipped)
+	case opts.outputFile != "":
+		err = opts.writeOutputFile(format, summary, skipped)
+	default:
+		err = opts.printOutput(format, summary, skipped)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return thresholdErr
+}
+
+// printTee prints the report to stderr or --output-file and copies the input unchanged to stdout, so kuota-calc can
+// check the manifests in the middle of a pipeline.
+func (opts *KuotaCalcOpts) printTee(input []byte, format string, summary []*calc.ResourceUsage, skipped skippedResources) error {
+	reportOpts := *opts
+	reportOpts.Out = opts.ErrOut
+
+	if opts.outputFile != "" {
+		if err := reportOpts.writeOutputFile(format, summary, skipped); err != nil {
+			return err
+		}
+	} else if err := reportOpts.printOutput(format, summary, skipped); err != nil {
+		return err
+	}
+
+	if _, err := opts.Out.Write(input); err != nil {
+		return fmt.Errorf("copying input: %w", err)
+	}
+
+	return nil
+}
+
+// parseReportFlags parses the flags, which only affect the report and not the calculation itself.
+func (opts *KuotaCalcOpts) parseReportFlags() error {
+	var err error
+
+	if opts.simulateFailure != "" {
+		opts.failure, err = calc.ParseFailureSimulation(opts.simulateFailure)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.groupBy != "" {
+		opts.groupKeys, err = calc.GroupKeys(opts.groupBy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.systemOverhead != "" {
+		opts.overhead, err = calc.ParseSystemOverhead(opts.systemOverhead)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.reservesNodeResources() {
+		opts.overhead.Reservation, err = calc.ParseNodeReservation(opts.systemReserved, opts.kubeReserved, opts.evictionHard)
+		if err != nil {
+			return fmt.Errorf("invalid reserved node resources: %w", err)
+		}
+	}
+
+	if opts.quotaBudget != "" {
+		opts.budget, err = calc.ParseBudget(opts.quotaBudget)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.failIfExceeds != "" {
+		opts.threshold, err = calc.ParseThreshold(opts.failIfExceeds)
+		if err != nil {
+			return fmt.Errorf("invalid --fail-if-exceeds: %w", err)
+		}
+	}
+
+	if opts.carbonRegion != "" || opts.carbonIntensity != 0 {
+		carbon, err := calc.NewCarbonFootprint(opts.carbonRegion, opts.carbonIntensity)
+		if err != nil {
+			return err
+		}
+
+		opts.carbon = &carbon
+	}
+
+	if opts.quotaHeadroom < 0 {
+		return fmt.Errorf("invalid --quota-headroom %v, the percentage must not be negative", opts.quotaHeadroom)
+	}
+
+	if opts.roundUp != "" {
+		opts.rounding, err = calc.ParseQuotaRounding(opts.roundUp)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printReport prints the human readable report, the default output.
+func (opts *KuotaCalcOpts) printReport(summary []*calc.ResourceUsage, skipped skippedResources) error {
+	if opts.detailed {
+		opts.printDetailed(summary)
+	} else {
+		opts.printSummary(summary)
+	}
+
+	opts.printNamespaceTotals(summary)
+
+	if opts.rolloutCost {
+		opts.printRolloutCost(summary)
+	}
+
+	if opts.budget != nil {
+		opts.printBudget(summary)
+	}
+
+	if opts.existingQuotas != nil {
+		opts.printQuotaComparison(summary)
+	}
+
+	if opts.carbon != nil {
+		opts.printCarbon(summary)
+	}
+
+	if opts.systemOverhead != "" || opts.reservesNodeResources() {
+		if err := opts.printCapacity(summary, opts.overhead); err != nil {
+			return err
+		}
+	}
+
+	if opts.simulateFailure != "" {
+		if err := opts.printFailureHeadroom(summary, opts.failure, opts.overhead); err != nil {
+			return err
+		}
+	}
+
+	if opts.explain {
+		opts.printExplanations(summary)
+	}
+
+	if opts.groupKeys != nil {
+		opts.printGroups(opts.groupBy, calc.GroupByNested(summary, opts.groupKeys))
+	}
+
+	if opts.groupByLabel != "" {
+		opts.printGroups("label "+opts.groupByLabel, calc.GroupBy(summary, calc.LabelGroupKey(opts.groupByLabel)))
+	}
+
+	if opts.timeline {
+		opts.printTimeline(summary)
+	}
+
+	opts.printPreemptible()
+	opts.printSkipped(skipped)
+
+	return nil
+}
+
+// calculate calculates the resource usage of all workloads of the input. Unsupported resources and workloads
+// scaled to zero are skipped. The calculation is traced and its totals are recorded as metrics.
+func (opts *KuotaCalcOpts) calculate(ctx context.Context) ([]*calc.ResourceUsage, skippedResources, error) {
 	var (
 		summary []*calc.ResourceUsage
+		skipped = skippedResources{}
 	)
 
-	yamlReader := yaml.NewYAMLReader(bufio.NewReader(opts.In))
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, "calculate")
+	defer span.End()
 
-	for {
-		data, err := yamlReader.Read()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
+	calcOpts, err := opts.calcOptions()
+	if err != nil {
+		return nil, nil, err
+	}
 
-			return fmt.Errorf("reading input: %w", err)
+	if opts.targetUtilization != "" {
+		opts.utilization, err = calc.ParseTargetUtilization(opts.targetUtilization)
+		if err != nil {
+			return nil, nil, err
 		}
+	}
+
+	workloads, err := opts.readWorkloads(&calcOpts, skipped)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts.nodes = calcOpts.Nodes
+
+	for _, object := range workloads {
+		start := time.Now()
+		usage, err := opts.calculateTraced(ctx, object, calcOpts)
+		opts.traces.addCalculation(object, time.Since(start))
 
-		usage, err := calc.ResourceQuotaFromYaml(data)
 		if err != nil {
-			if errors.Is(err, calc.ErrResourceNotSupported) {
+			var calcErr calc.CalculationError
+			if errors.Is(err, calc.ErrResourceNotSupported) && errors.As(err, &calcErr) {
 				if opts.debug {
-					_, _ = fmt.Fprintf(opts.Out, "DEBUG: %s\n", err)
+					// stdout is the report or, with --tee, the input passed on, which must stay parseable
+					_, _ = fmt.Fprintf(opts.ErrOut, "DEBUG: %s\n", err)
 				}
 
+				skipped.add(calcErr.Version, calcErr.Kind, skipUnsupported)
+
 				continue
 			}
 
-			return err
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, nil, err
+		}
+
+		if usage.ScaledToZero() && !opts.showZero {
+			reason := skipZeroReplicas
+			if usage.Details.Suspended {
+				reason = skipSuspended
+			}
+
+			skipped.add(usage.Details.Version, usage.Details.Kind, reason)
+
+			continue
 		}
 
 		summary = append(summary, usage)
 	}
 
-	if opts.detailed {
-		opts.printDetailed(summary)
-	} else {
-		opts.printSummary(summary)
+	if opts.ignorePriority != "" {
+		threshold, err := opts.priorityClasses.Threshold(opts.ignorePriority)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		summary, opts.preemptible = opts.priorityClasses.SplitByPriority(summary, threshold)
+	}
+
+	span.SetAttributes(attribute.Int("kuota_calc.workloads", len(summary)))
+
+	if err := opts.recordTotals(ctx, summary); err != nil {
+		return nil, nil, err
+	}
+
+	return summary, skipped, nil
+}
+
+// calculateTraced calculates the resource usage of a single workload in its own span.
+func (opts *KuotaCalcOpts) calculateTraced(ctx context.Context, object runtime.Object, calcOpts calc.Options) (*calc.ResourceUsage, error) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, "calculate "+object.GetObjectKind().GroupVersionKind().Kind)
+	defer span.End()
+
+	if accessor, err := meta.Accessor(object); err == nil {
+		span.SetAttributes(
+			attribute.String("k8s.namespace.name", accessor.GetNamespace()),
+			attribute.String("kuota_calc.workload.name", accessor.GetName()),
+		)
+	}
+
+	usage, err := opts.calculateObject(ctx, object, calcOpts)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return usage, err
+}
+
+// readWorkloads decodes all yaml documents of the input and returns the workloads. Autoscalers and nodes aren't
+// calculated themselves, they are added to the options of the calculation of the workloads instead. Workloads whose
+// controller is part of the input are already calculated with it, they are skipped. So are the pods excluded by
+// --pod-phases and --field-selector. With --ignore-priority-below, the priority classes are read as well.
+func (opts *KuotaCalcOpts) readWorkloads(calcOpts *calc.Options, skipped skippedResources) ([]runtime.Object, error) {
+	podFilter, err := calc.ParsePodFilter(opts.podPhases, opts.fieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := opts.readObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	var workloads []runtime.Object
+
+	calcOpts.Autoscalers = calc.Autoscalers{}
+	calcOpts.VerticalAutoscalers = calc.VerticalAutoscalers{}
+	opts.priorityClasses = calc.PriorityClasses{}
+	owners := calc.NewOwners(objects)
+
+	for _, object := range objects {
+		if kind, ok := owners.Controller(object); ok {
+			gvk := object.GetObjectKind().GroupVersionKind()
+			skipped.add(gvk.GroupVersion().String(), gvk.Kind, skipReason("owned by "+kind))
+
+			continue
+		}
+
+		if pod, ok := object.(*corev1.Pod); ok {
+			if reason := podFilter.Excludes(pod); reason != "" {
+				skipped.add(pod.APIVersion, pod.Kind, skipReason("excluded by "+reason))
+
+				continue
+			}
+		}
+
+		if node, ok := object.(*corev1.Node); ok {
+			calcOpts.Nodes = append(calcOpts.Nodes, *node)
+
+			continue
+		}
+
+		// the namespaces of the input tell, which of them enable the injection of the mesh and the injection rules
+		if namespace, ok := object.(*corev1.Namespace); ok && (calcOpts.Mesh != nil || calcOpts.Injections != nil) {
+			if calcOpts.Mesh != nil {
+				calcOpts.Mesh.AddNamespace(namespace)
+			}
+
+			if calcOpts.Injections != nil {
+				calcOpts.Injections.AddNamespace(namespace)
+			}
+
+			continue
+		}
+
+		if opts.ignorePriority != "" && opts.priorityClasses.Add(object) {
+			continue
+		}
+
+		if !calcOpts.Autoscalers.Add(object) && !calcOpts.VerticalAutoscalers.Add(object) {
+			workloads = append(workloads, object)
+		}
+	}
+
+	return workloads, nil
+}
+
+// readObjects decodes all yaml and json documents of the input. The items of v1 Lists and json arrays are returned
+// instead of the lists.
+func (opts *KuotaCalcOpts) readObjects() ([]runtime.Object, error) {
+	var objects []runtime.Object
+
+	hash := sha256.New()
+	yamlReader := yaml.NewYAMLReader(bufio.NewReader(io.TeeReader(opts.In, hash)))
+
+	for {
+		data, err := yamlReader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				opts.inputHash = hex.EncodeToString(hash.Sum(nil))
+
+				return objects, nil
+			}
+
+			return nil, fmt.Errorf("reading input: %w", err)
+		}
+
+		for _, document := range jsonDocuments(data) {
+			start := time.Now()
+
+			object, err := calc.Decode(document)
+			if err != nil {
+				return nil, err
+			}
+
+			items, err := calc.ListItems(object)
+			if err != nil {
+				return nil, err
+			}
+
+			decode := time.Since(start)
+
+			// the items of a list share the size and decode time of their document
+			for _, item := range items {
+				calc.DefaultNamespace(item, opts.defaultNamespace)
+				opts.traces.addDocument(item, len(document), decode)
+
+				objects = append(objects, item)
+			}
+		}
+	}
+}
+
+// applyHPAMode applies --hpa-mode to --hpa-normal and --hpa-peak, unless they are given themselves.
+func (opts *KuotaCalcOpts) applyHPAMode(cmd *cobra.Command) error {
+	if opts.hpaMode == "" {
+		return nil
+	}
+
+	if _, err := calc.ParseHPAReplicas(opts.hpaMode); err != nil {
+		return fmt.Errorf("invalid --hpa-mode: %w", err)
+	}
+
+	if !cmd.Flags().Changed("hpa-normal") {
+		opts.hpaNormal = opts.hpaMode
+	}
+
+	if !cmd.Flags().Changed("hpa-peak") {
+		opts.hpaPeak = opts.hpaMode
 	}
 
 	return nil
 }
 
+// calcOptions converts the flags into options for the calculation.
+func (opts *KuotaCalcOpts) calcOptions() (calc.Options, error) {
+	strategyDefaults, err := opts.loadStrategyDefaults()
+	if err != nil {
+		return calc.Options{}, err
+	}
+
+	if opts.assumeReplicas < 0 {
+		return calc.Options{}, fmt.Errorf("invalid assumed replicas %d: must not be negative", opts.assumeReplicas)
+	}
+
+	hpaNormal, err := calc.ParseHPAReplicas(opts.hpaNormal)
+	if err != nil {
+		return calc.Options{}, fmt.Errorf("invalid --hpa-normal: %w", err)
+	}
+
+	hpaPeak, err := calc.ParseHPAReplicas(opts.hpaPeak)
+	if err != nil {
+		return calc.Options{}, fmt.Errorf("invalid --hpa-peak: %w", err)
+	}
+
+	vpaRecommendation, err := calc.ParseVPARecommendation(opts.vpaMode)
+	if err != nil {
+		return calc.Options{}, fmt.Errorf("invalid --vpa-mode: %w", err)
+	}
+
+	calcOpts := calc.Options{
+		AssumedReplicas:          &opts.assumeReplicas,
+		StrategyDefaults:         &strategyDefaults,
+		Timeline:                 opts.timeline,
+		HPANormalReplicas:        hpaNormal,
+		HPAPeakReplicas:          hpaPeak,
+		VPARecommendation:        vpaRecommendation,
+		JobRetryOverlap:          opts.jobRetries,
+		Explain:                  opts.explain,
+		EmptyDirEphemeralStorage: opts.emptyDirStorage,
+	}
+
+	switch opts.terminationOverlap {
+	case "":
+	case "grace":
+		calcOpts.TerminationOverlapByGracePeriod = true
+	default:
+		overlap, err := strconv.ParseFloat(opts.terminationOverlap, 64)
+		if err != nil || overlap < 0 || overlap > 1 {
+			return calcOpts, fmt.Errorf("invalid termination overlap %q: must be a fraction between 0 and 1 or 'grace'", opts.terminationOverlap)
+		}
+
+		calcOpts.TerminationOverlap = overlap
+	}
+
+	if opts.mesh != "" {
+		calcOpts.Mesh, err = calc.NewMesh(opts.mesh, opts.meshProxy)
+		if err != nil {
+			return calcOpts, fmt.Errorf("invalid --mesh: %w", err)
+		}
+	}
+
+	if opts.injectionRules != "" {
+		calcOpts.Injections, err = loadInjectionRules(opts.injectionRules)
+		if err != nil {
+			return calcOpts, err
+		}
+	}
+
+	if opts.customKinds != "" {
+		calcOpts.CustomKinds, err = loadCustomKinds(opts.customKinds)
+		if err != nil {
+			return calcOpts, err
+		}
+	}
+
+	if opts.kubernetesVersion != "" {
+		calcOpts.KubernetesVersion, err = calc.ParseKubernetesVersion(opts.kubernetesVersion)
+		if err != nil {
+			return calcOpts, fmt.Errorf("invalid --kubernetes-version: %w", err)
+		}
+	}
+
+	return calcOpts, nil
+}
+
+// loadInjectionRules reads the rules of the containers injected by mutating webhooks.
+func loadInjectionRules(path string) (*calc.InjectionRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading injection rules: %w", err)
+	}
+
+	var rules calc.InjectionRules
+	if err := sigsyaml.UnmarshalStrict(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing injection rules %s: %w", path, err)
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("parsing injection rules %s: %w", path, err)
+	}
+
+	return &rules, nil
+}
+
+// loadCustomKinds reads the descriptions of the custom kinds calculated from their pod templates.
+func loadCustomKinds(path string) (*calc.CustomKinds, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading custom kinds: %w", err)
+	}
+
+	var kinds calc.CustomKinds
+	if err := sigsyaml.UnmarshalStrict(data, &kinds); err != nil {
+		return nil, fmt.Errorf("parsing custom kinds %s: %w", path, err)
+	}
+
+	if err := kinds.Validate(); err != nil {
+		return nil, fmt.Errorf("parsing custom kinds %s: %w", path, err)
+	}
+
+	return &kinds, nil
+}
+
+// loadStrategyDefaults returns the strategy defaults of the platform, overridden by the strategy defaults file if given.
+func (opts *KuotaCalcOpts) loadStrategyDefaults() (calc.StrategyDefaults, error) {
+	defaults, err := calc.BuiltinStrategyDefaults(opts.platform)
+	if err != nil {
+		return defaults, err
+	}
+
+	if opts.strategyDefaults == "" {
+		return defaults, nil
+	}
+
+	data, err := os.ReadFile(opts.strategyDefaults)
+	if err != nil {
+		return defaults, fmt.Errorf("reading strategy defaults: %w", err)
+	}
+
+	// values missing in the file keep the defaults of the platform
+	if err := sigsyaml.UnmarshalStrict(data, &defaults); err != nil {
+		return defaults, fmt.Errorf("parsing strategy defaults %s: %w", opts.strategyDefaults, err)
+	}
+
+	return defaults, nil
+}
+
 func (opts *KuotaCalcOpts) printDetailed(usage []*calc.ResourceUsage) {
 	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+	storage := hasEphemeralStorage(usage)
+	extended := calc.ExtendedResourceNames(usage)
 
-	_, _ = fmt.Fprintf(w, "Version\tKind\tName\tReplicas\tStrategy\tMaxReplicas\tCPURequest\tCPULimit\tMemoryRequest\tMemoryLimit\t\n")
+	_, _ = fmt.Fprintf(w, "Version\tKind\tNamespace\tName\tReplicas\tStrategy\tMaxReplicas\tCPURequest\tCPULimit\tMemoryRequest\tMemoryLimit\t%s%s\n",
+		storageHeader(storage, ""), tabbed(extendedHeader(extended)))
 
 	for _, u := range usage {
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\t%s\t%s\t\n",
+		if u.ScaledToZero() {
+			replicas := "replicas=0"
+			if u.Details.Suspended {
+				replicas = "suspended"
+			}
+
+			// scaled to zero workloads don't need any resources, mark them clearly instead of printing zeros
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t-\t-\t-\t-\t-\t%s%s\n",
+				u.Details.Version,
+				u.Details.Kind,
+				u.Details.Namespace,
+				u.Details.Name,
+				replicas,
+				u.Details.Strategy,
+				tabbed(storagePlaceholders(storage)),
+				strings.Repeat("-\t", len(extended)),
+			)
+
+			continue
+		}
+
+		kind := u.Details.Kind
+		if u.Details.Heuristic {
+			kind += " (heuristic)"
+		}
+
+		if u.Details.VerticalAutoscaler != "" {
+			kind += fmt.Sprintf(" (vpa %s)", u.Details.VerticalAutoscaler)
+		}
+
+		replicas := strconv.Itoa(int(u.Details.Replicas))
+		if u.Details.ReplicasAssumed {
+			replicas += " (assumed)"
+		}
+
+		if u.Details.ReplicasAnnotated {
+			replicas += " (annotated)"
+		}
+
+		if u.Details.Autoscaler != "" {
+			replicas = fmt.Sprintf("%d/%d (hpa %s)", u.Details.NormalReplicas, u.Details.Replicas, u.Details.Autoscaler)
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s%s\n",
 			u.Details.Version,
-			u.Details.Kind,
+			kind,
+			u.Details.Namespace,
 			u.Details.Name,
-			u.Details.Replicas,
+			replicas,
 			u.Details.Strategy,
 			u.Details.MaxReplicas,
 			u.RolloutResources.CPUMin.String(),
 			u.RolloutResources.CPUMax.String(),
 			u.RolloutResources.MemoryMin.String(),
 			u.RolloutResources.MemoryMax.String(),
+			tabbed(storageColumns(storage, u.RolloutResources)),
+			tabbed(extendedColumns(extended, u.RolloutResources)),
 		)
 	}
 
@@ -156,12 +1081,381 @@ func (opts *KuotaCalcOpts) printDetailed(usage []*calc.ResourceUsage) {
 }
 
 func (opts *KuotaCalcOpts) printSummary(usage []*calc.ResourceUsage) {
-	totalResources := calc.Total(opts.maxRollouts, usage)
+	totalResources := calc.Total(opts.maxRollouts, usage).AtUtilization(opts.utilization)
 
-	_, _ = fmt.Fprintf(opts.Out, "CPU Request: %s\nCPU Limit: %s\nMemory Request: %s\nMemory Limit: %s\n",
+	if opts.targetUtilization != "" {
+		_, _ = fmt.Fprintf(opts.Out, "Requests at a target utilization of %s\n", opts.targetUtilization)
+	}
+
+	_, _ = fmt.Fprintf(opts.Out, "CPU Request: %s\nCPU Limit: %s\nMemory Request: %s\nMemory Limit: %s\nPods: %s\n",
 		totalResources.CPUMin.String(),
 		totalResources.CPUMax.String(),
 		totalResources.MemoryMin.String(),
 		totalResources.MemoryMax.String(),
+		totalResources.Pods.String(),
 	)
+
+	// ephemeral storage is only of interest, if any resource requests or limits it
+	if !totalResources.EphemeralStorageMin.IsZero() || !totalResources.EphemeralStorageMax.IsZero() {
+		_, _ = fmt.Fprintf(opts.Out, "Ephemeral Storage Request: %s\nEphemeral Storage Limit: %s\n",
+			totalResources.EphemeralStorageMin.String(),
+			totalResources.EphemeralStorageMax.String(),
+		)
+	}
+
+	// extended resources can't be overcommitted, their requests equal their limits
+	for _, name := range calc.ExtendedResourceNames(usage) {
+		quantity := totalResources.Extended[name]
+		_, _ = fmt.Fprintf(opts.Out, "%s: %s\n", name, quantity.String())
+	}
+
+	// persistent volume claims are only of interest, if any resource claims storage
+	if !totalResources.PersistentVolumeClaims.IsZero() {
+		_, _ = fmt.Fprintf(opts.Out, "Storage Request: %s\nPersistent Volume Claims: %s\n",
+			totalResources.Storage.String(),
+			totalResources.PersistentVolumeClaims.String(),
+		)
+	}
+}
+
+// hasEphemeralStorage reports whether any resource requests or limits ephemeral storage. Only then the tables show it.
+func hasEphemeralStorage(usage []*calc.ResourceUsage) bool {
+	for _, u := range usage {
+		r := u.RolloutResources.Add(u.NormalResources)
+		if !r.EphemeralStorageMin.IsZero() || !r.EphemeralStorageMax.IsZero() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// storageHeader returns the tab separated headers of the ephemeral storage columns with the prefix, if they are shown.
+func storageHeader(show bool, prefix string) string {
+	if !show {
+		return ""
+	}
+
+	return prefix + "EphemeralStorageRequest\t" + prefix + "EphemeralStorageLimit\t"
+}
+
+// storageColumns returns the ephemeral storage request and limit of the resources, if they are shown.
+func storageColumns(show bool, r calc.Resources) []string {
+	if !show {
+		return nil
+	}
+
+	return []string{r.EphemeralStorageMin.String(), r.EphemeralStorageMax.String()}
+}
+
+// storagePlaceholders returns the placeholders of the ephemeral storage columns, if they are shown.
+func storagePlaceholders(show bool) []string {
+	if !show {
+		return nil
+	}
+
+	return []string{"-", "-"}
+}
+
+// extendedHeader returns the headers of the columns of the extended resources, e.g. nvidia.com/gpu.
+func extendedHeader(names []corev1.ResourceName) []string {
+	header := make([]string, 0, len(names))
+
+	for _, name := range names {
+		header = append(header, string(name))
+	}
+
+	return header
+}
+
+// extendedColumns returns the quantities of the extended resources with the names.
+func extendedColumns(names []corev1.ResourceName, r calc.Resources) []string {
+	columns := make([]string, 0, len(names))
+
+	for _, name := range names {
+		quantity := r.Extended[name]
+		columns = append(columns, quantity.String())
+	}
+
+	return columns
+}
+
+// tabbed joins the columns of a tabwriter row, each one terminated by a tab.
+func tabbed(columns []string) string {
+	return strings.Join(append(columns, ""), "\t")
+}
+
+// printCapacity prints the allocatable resources of the nodes of the input, minus the system overhead of each node,
+// and how many of these nodes the total requests need.
+func (opts *KuotaCalcOpts) printCapacity(usage []*calc.ResourceUsage, overhead calc.SystemOverhead) error {
+	capacity, err := calc.NodeCapacity(calc.Total(opts.maxRollouts, usage).AtUtilization(opts.utilization), opts.nodes, overhead)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(opts.Out, "\nCapacity of %d nodes%s\n", capacity.Nodes, opts.capacityAssumptions())
+	_, _ = fmt.Fprintf(opts.Out, "Allocatable CPU: %s\nAllocatable Memory: %s\nNodes Needed: %d\n",
+		capacity.CPU.String(),
+		capacity.Memory.String(),
+		capacity.NodesNeeded,
+	)
+
+	return nil
+}
+
+// reservesNodeResources returns whether the allocatable resources of the nodes are derived from their capacity.
+func (opts *KuotaCalcOpts) reservesNodeResources() bool {
+	return opts.systemReserved != "" || opts.kubeReserved != "" || opts.evictionHard != ""
+}
+
+// capacityAssumptions describes how the allocatable resources of the nodes are derived, for the capacity header.
+func (opts *KuotaCalcOpts) capacityAssumptions() string {
+	var assumptions []string
+
+	if opts.reservesNodeResources() {
+		for _, reserved := range []struct{ flag, value string }{
+			{"system-reserved", opts.systemReserved},
+			{"kube-reserved", opts.kubeReserved},
+			{"eviction-hard", opts.evictionHard},
+		} {
+			if reserved.value != "" {
+				assumptions = append(assumptions, fmt.Sprintf("%s %s", reserved.flag, reserved.value))
+			}
+		}
+	}
+
+	if opts.systemOverhead != "" {
+		assumptions = append(assumptions, fmt.Sprintf("a system overhead of %s", opts.systemOverhead))
+	}
+
+	if len(assumptions) == 0 {
+		return ""
+	}
+
+	return ", minus " + strings.Join(assumptions, ", ") + " per node"
+}
+
+// printFailureHeadroom prints the headroom needed to reschedule the pods of a failed zone or of failed nodes, on top
+// of the total, and the allocatable resources left after the failure.
+func (opts *KuotaCalcOpts) printFailureHeadroom(usage []*calc.ResourceUsage, failure calc.FailureSimulation, overhead calc.SystemOverhead) error {
+	headroom, err := calc.SimulateFailure(usage, opts.nodes, failure, overhead)
+	if err != nil {
+		return err
+	}
+
+	total := calc.Total(opts.maxRollouts, usage).AtUtilization(opts.utilization).Add(headroom.Resources)
+
+	_, _ = fmt.Fprintf(opts.Out, "\nFailure of %s\n", failure)
+	_, _ = fmt.Fprintf(opts.Out, "Headroom CPU Request: %s\nHeadroom CPU Limit: %s\nHeadroom Memory Request: %s\nHeadroom Memory Limit: %s\n",
+		headroom.Resources.CPUMin.String(),
+		headroom.Resources.CPUMax.String(),
+		headroom.Resources.MemoryMin.String(),
+		headroom.Resources.MemoryMax.String(),
+	)
+	_, _ = fmt.Fprintf(opts.Out, "CPU Request incl. Headroom: %s\nMemory Request incl. Headroom: %s\n",
+		total.CPUMin.String(),
+		total.MemoryMin.String(),
+	)
+	_, _ = fmt.Fprintf(opts.Out, "Remaining Allocatable CPU: %s\nRemaining Allocatable Memory: %s\n",
+		headroom.RemainingCPU.String(),
+		headroom.RemainingMemory.String(),
+	)
+
+	return nil
+}
+
+// runningWorkloads returns the usage without the workloads scaled to zero, which --show-zero lists in the reports. The
+// ResourceQuotas and LimitRanges leave them out, so a namespace of only such workloads doesn't get a quota of zero,
+// which would block its pods once they are scaled up again.
+func runningWorkloads(usage []*calc.ResourceUsage) []*calc.ResourceUsage {
+	return slices.DeleteFunc(slices.Clone(usage), (*calc.ResourceUsage).ScaledToZero)
+}
+
+// printQuotas prints a ResourceQuota manifest for each namespace, which allows the total of the namespace. With
+// --quota-scopes, the quota of a namespace is split into scoped quotas, each allowing the total of its scope. With
+// --quota-headroom, the percentage is added on top of the values, before they are rounded up to the increments of
+// --round-up.
+func (opts *KuotaCalcOpts) printQuotas(usage []*calc.ResourceUsage) error {
+	namespaceKey, err := calc.GroupKey(calc.GroupByNamespace)
+	if err != nil {
+		return err
+	}
+
+	var scopes calc.QuotaScopes
+
+	if opts.quotaScopes != "" {
+		scopes, err = calc.ParseQuotaScopes(opts.quotaScopes)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, group := range calc.GroupBy(runningWorkloads(usage), namespaceKey) {
+		for _, bucket := range scopes.Buckets(group.Usage) {
+			total := calc.Total(opts.maxRollouts, bucket.Usage).AtUtilization(opts.utilization)
+
+			name := opts.quotaName
+			if bucket.Suffix != "" {
+				name += "-" + bucket.Suffix
+			}
+
+			quota := calc.ResourceQuota(group.Key, name, opts.rounding.RoundUp(total.WithHeadroom(opts.quotaHeadroom)))
+			quota.Spec.ScopeSelector = bucket.ScopeSelector
+
+			data, err := sigsyaml.Marshal(quota)
+			if err != nil {
+				return fmt.Errorf("printing resource quota %s of namespace %s: %w", name, group.Key, err)
+			}
+
+			_, _ = fmt.Fprintf(opts.Out, "---\n%s", data)
+		}
+	}
+
+	return nil
+}
+
+// printLimitRanges prints a LimitRange for each namespace of the input, suggested from the smallest and largest
+// requests and limits of its containers.
+func (opts *KuotaCalcOpts) printLimitRanges(usage []*calc.ResourceUsage) error {
+	namespaceKey, err := calc.GroupKey(calc.GroupByNamespace)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range calc.GroupBy(runningWorkloads(usage), namespaceKey) {
+		data, err := sigsyaml.Marshal(calc.LimitRange(group.Key, opts.limitRangeName, group.Usage))
+		if err != nil {
+			return fmt.Errorf("printing limit range of namespace %s: %w", group.Key, err)
+		}
+
+		_, _ = fmt.Fprintf(opts.Out, "---\n%s", data)
+	}
+
+	return nil
+}
+
+func (opts *KuotaCalcOpts) printExplanations(usage []*calc.ResourceUsage) {
+	_, _ = fmt.Fprintf(opts.Out, "\nCalculation of each resource\n")
+
+	for _, u := range usage {
+		_, _ = fmt.Fprintf(opts.Out, "\n%s %s %s/%s\n", u.Details.Version, u.Details.Kind, u.Details.Namespace, u.Details.Name)
+
+		for _, line := range u.Explanation {
+			_, _ = fmt.Fprintf(opts.Out, "  %s\n", line)
+		}
+	}
+}
+
+func (opts *KuotaCalcOpts) printGroups(groupedBy string, groups []calc.Group) {
+	_, _ = fmt.Fprintf(opts.Out, "\nTotal by %s\n", groupedBy)
+
+	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	var usage []*calc.ResourceUsage
+	for _, group := range groups {
+		usage = append(usage, group.Usage...)
+	}
+
+	storage := hasEphemeralStorage(usage)
+
+	_, _ = fmt.Fprintf(w, "Group\tCPURequest\tCPULimit\tMemoryRequest\tMemoryLimit\t%s\n", storageHeader(storage, ""))
+
+	opts.printGroupRows(w, groups, "", storage)
+
+	if err := w.Flush(); err != nil {
+		_, _ = fmt.Fprintf(opts.Out, "printing groups to tabwriter failed: %v\n", err)
+	}
+}
+
+// printNamespaceTotals prints the total of each namespace, if the resources span several namespaces, as quotas are
+// per namespace. It is left out if --group-by starts with the namespace anyway.
+func (opts *KuotaCalcOpts) printNamespaceTotals(usage []*calc.ResourceUsage) {
+	if grouping, _, _ := strings.Cut(opts.groupBy, ","); strings.TrimSpace(grouping) == calc.GroupByNamespace {
+		return
+	}
+
+	groups := calc.GroupBy(usage, func(u *calc.ResourceUsage) string {
+		return u.Details.Namespace
+	})
+	if len(groups) < 2 {
+		return
+	}
+
+	opts.printGroups(calc.GroupByNamespace, groups)
+}
+
+// printGroupRows prints a row per group, followed by the rows of its nested groups indented below it.
+func (opts *KuotaCalcOpts) printGroupRows(w io.Writer, groups []calc.Group, indent string, storage bool) {
+	for _, group := range groups {
+		key := group.Key
+		if key == "" {
+			key = "<none>"
+		}
+
+		total := calc.Total(opts.maxRollouts, group.Usage).AtUtilization(opts.utilization)
+
+		_, _ = fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\n",
+			indent,
+			key,
+			total.CPUMin.String(),
+			total.CPUMax.String(),
+			total.MemoryMin.String(),
+			total.MemoryMax.String(),
+			tabbed(storageColumns(storage, total)),
+		)
+
+		opts.printGroupRows(w, group.Groups, indent+"  ", storage)
+	}
+}
+
+func (opts *KuotaCalcOpts) printTimeline(usage []*calc.ResourceUsage) {
+	_, _ = fmt.Fprintf(opts.Out, "\nTimeline of the simultaneous rollout of all resources\n")
+
+	storage := hasEphemeralStorage(usage)
+
+	if opts.detailed {
+		w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+		_, _ = fmt.Fprintf(w, "Version\tKind\tNamespace\tName\tRolloutSeconds\tPeakCPURequest\tPeakCPULimit\tPeakMemoryRequest\tPeakMemoryLimit\t%s\n",
+			storageHeader(storage, "Peak"))
+
+		for _, u := range usage {
+			peak := calc.TimelinePeak([]*calc.ResourceUsage{u})
+
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+				u.Details.Version,
+				u.Details.Kind,
+				u.Details.Namespace,
+				u.Details.Name,
+				u.RolloutSeconds(),
+				peak.CPUMin.String(),
+				peak.CPUMax.String(),
+				peak.MemoryMin.String(),
+				peak.MemoryMax.String(),
+				tabbed(storageColumns(storage, peak)),
+			)
+		}
+
+		if err := w.Flush(); err != nil {
+			_, _ = fmt.Fprintf(opts.Out, "printing timeline to tabwriter failed: %v\n", err)
+		}
+
+		_, _ = fmt.Fprintf(opts.Out, "\n")
+	}
+
+	peak := calc.TimelinePeak(usage)
+
+	_, _ = fmt.Fprintf(opts.Out, "Peak CPU Request: %s\nPeak CPU Limit: %s\nPeak Memory Request: %s\nPeak Memory Limit: %s\n",
+		peak.CPUMin.String(),
+		peak.CPUMax.String(),
+		peak.MemoryMin.String(),
+		peak.MemoryMax.String(),
+	)
+
+	if storage {
+		_, _ = fmt.Fprintf(opts.Out, "Peak Ephemeral Storage Request: %s\nPeak Ephemeral Storage Limit: %s\n",
+			peak.EphemeralStorageMin.String(),
+			peak.EphemeralStorageMax.String(),
+		)
+	}
 }
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// runOutputs runs the calculation of the input and returns what it printed to stdout and stderr.
+func runOutputs(t *testing.T, input string, modify func(*KuotaCalcOpts)) (string, string) {
+	opts := newTestOpts(input)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	opts.Out, opts.ErrOut = stdout, stderr
+	modify(opts)
+
+	require.NoError(t, opts.run(context.Background()))
+
+	return stdout.String(), stderr.String()
+}
+
+func TestTeeDebug(t *testing.T) {
+	r := require.New(t)
+
+	input := apiDeployment + "\n---\n" + worker + "\n"
+
+	stdout, stderr := runOutputs(t, input, func(opts *KuotaCalcOpts) {
+		opts.tee = true
+		opts.debug = true
+	})
+
+	// the input is passed on unchanged, the report and the debug output of the unsupported kind go to stderr
+	r.Equal(input, stdout)
+	r.Contains(stderr, "DEBUG: ")
+	r.Contains(stderr, "CPU Request: ")
+}
+
+func TestCIDebug(t *testing.T) {
+	r := require.New(t)
+
+	stdout, stderr := runOutputs(t, apiDeployment+"\n---\n"+worker, func(opts *KuotaCalcOpts) {
+		opts.ci = true
+		opts.debug = true
+	})
+
+	// the next stage of the pipeline gets the JSON report only, the text report and the debug output are logged
+	var ci report
+	r.NoError(json.Unmarshal([]byte(stdout), &ci))
+	r.Len(ci.Resources, 1)
+	r.Contains(stderr, "DEBUG: ")
+	r.Contains(stderr, "CPU Request: ")
+}
+
+var idleDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: idle
+  namespace: team-b
+spec:
+  replicas: 0
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi`
+
+var suspendedCronJob = `
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly
+  namespace: team-a
+spec:
+  schedule: "0 2 * * *"
+  suspend: true
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: report
+            resources:
+              requests:
+                cpu: 500m
+                memory: 1Gi`
+
+func TestShowZero(t *testing.T) {
+	r := require.New(t)
+
+	input := apiDeployment + "\n---\n" + idleDeployment + "\n---\n" + suspendedCronJob
+
+	// the workloads scaled to zero are marked in the detailed output instead of printing zeros
+	stdout, _ := runOutputs(t, input, func(opts *KuotaCalcOpts) {
+		opts.showZero = true
+		opts.detailed = true
+	})
+
+	rows := map[string][]string{}
+	for _, line := range strings.Split(stdout, "\n") {
+		if fields := strings.Fields(line); len(fields) > 4 {
+			rows[fields[3]] = fields
+		}
+	}
+
+	r.Equal("2", rows["api"][4])
+	r.Equal("replicas=0", rows["idle"][4])
+	r.Equal("suspended", rows["nightly"][4])
+	r.Contains(rows["idle"], "-")
+	r.NotContains(stdout, "Skipped resources")
+
+	// without --show-zero they are counted as skipped
+	stdout, _ = runOutputs(t, input, func(opts *KuotaCalcOpts) { opts.detailed = true })
+	r.NotContains(stdout, "idle")
+	r.Contains(stdout, "zero replicas")
+	r.Contains(stdout, "suspended")
+
+	// the JSON and CSV reports list them with zero quantities
+	stdout, _ = runOutputs(t, input, func(opts *KuotaCalcOpts) {
+		opts.showZero = true
+		opts.output = outputJSON
+	})
+
+	var jsonReport report
+	r.NoError(json.Unmarshal([]byte(stdout), &jsonReport))
+	r.Len(jsonReport.Resources, 3)
+	r.Empty(jsonReport.Skipped)
+	r.Equal("300m", jsonReport.Total.CPURequest.String())
+
+	stdout, _ = runOutputs(t, input, func(opts *KuotaCalcOpts) {
+		opts.showZero = true
+		opts.output = outputCSV
+	})
+	r.Contains(stdout, "apps/v1,Deployment,team-b,idle,0,")
+
+	// the quotas and limit ranges leave them out, team-b would get a quota of zero otherwise
+	for _, output := range []string{outputQuota, outputLimitRange} {
+		stdout, _ = runOutputs(t, input, func(opts *KuotaCalcOpts) {
+			opts.showZero = true
+			opts.output = output
+		})
+		r.Contains(stdout, "namespace: team-a", output)
+		r.NotContains(stdout, "team-b", output)
+	}
+}
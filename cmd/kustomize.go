@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// renderKustomization renders the kustomization in the directory like kustomize build, so overlays don't have to be
+// rendered in a separate step.
+func renderKustomization(dir string) ([]byte, error) {
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+
+	resources, err := kustomizer.Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("rendering kustomization %s: %w", dir, err)
+	}
+
+	data, err := resources.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("rendering kustomization %s: %w", dir, err)
+	}
+
+	return data, nil
+}
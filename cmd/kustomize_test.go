@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKustomize(t *testing.T) {
+	var tests = []struct {
+		name      string
+		dir       string
+		workload  string
+		namespace string
+		replicas  int32
+		cpu       string
+	}{
+		{
+			name:      "base",
+			dir:       "testdata/kustomize/base",
+			workload:  "api",
+			namespace: "default",
+			replicas:  2,
+			cpu:       "200m",
+		},
+		{
+			name:      "overlay",
+			dir:       "testdata/kustomize/overlays/prod",
+			workload:  "prod-api",
+			namespace: "team-prod",
+			replicas:  4,
+			cpu:       "1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			opts := newTestOpts("")
+			opts.kustomize = test.dir
+			r.NoError(opts.openInput(context.Background()))
+
+			usage, skipped, err := opts.calculateRequest(context.Background())
+			r.NoError(err)
+
+			// the service of the base is rendered as well, but it isn't a workload
+			r.Len(usage, 1)
+			r.Equal("Deployment", usage[0].Details.Kind)
+			r.Equal(test.workload, usage[0].Details.Name)
+			r.Equal(test.namespace, usage[0].Details.Namespace)
+			r.Equal(test.replicas, usage[0].Details.Replicas)
+			r.Equal(test.cpu, usage[0].NormalResources.CPUMin.String())
+			r.Equal(skippedResources{{version: "v1", kind: "Service", reason: skipUnsupported}: 1}, skipped)
+		})
+	}
+}
+
+func TestKustomizeError(t *testing.T) {
+	r := require.New(t)
+
+	_, err := renderKustomization("testdata/kustomize/missing")
+	r.ErrorContains(err, "rendering kustomization testdata/kustomize/missing")
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+)
+
+// matrixEnvironment is an environment of --values-matrix and the values it is rendered with: a values file of the
+// chart for the helm command, the directory of its kustomization otherwise.
+type matrixEnvironment struct {
+	name   string
+	values string
+}
+
+// renderFunc renders the manifests of an environment of --values-matrix.
+type renderFunc func(ctx context.Context, values string) ([]byte, error)
+
+// parseValuesMatrix parses the environments of --values-matrix, e.g. dev=values-dev.yaml,prod=values-prod.yaml. The
+// environments keep their order, so the comparison lists them as given.
+func parseValuesMatrix(matrix string) ([]matrixEnvironment, error) {
+	var (
+		environments []matrixEnvironment
+		names        = map[string]bool{}
+	)
+
+	for _, entry := range strings.Split(matrix, ",") {
+		name, values, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || name == "" || values == "" {
+			return nil, fmt.Errorf("invalid --values-matrix entry %q, expected <environment>=<values>", entry)
+		}
+
+		if names[name] {
+			return nil, fmt.Errorf("invalid --values-matrix: environment %s is given twice", name)
+		}
+
+		names[name] = true
+		environments = append(environments, matrixEnvironment{name: name, values: values})
+	}
+
+	return environments, nil
+}
+
+// validateMatrix returns an error, if a flag is given which writes a single report. The matrix only prints the
+// comparison of the environments.
+func (opts *KuotaCalcOpts) validateMatrix() error {
+	flags := []struct {
+		name string
+		set  bool
+	}{
+		{name: "output", set: opts.output != ""},
+		{name: "output-file", set: opts.outputFile != ""},
+		{name: "output-dir", set: opts.outputDir != ""},
+		{name: "tee", set: opts.tee},
+		{name: "ci", set: opts.ci},
+		{name: "history-db", set: opts.historyDB != ""},
+	}
+
+	for _, flag := range flags {
+		if flag.set {
+			return fmt.Errorf("--values-matrix prints a comparison of the environments, it can't be combined with --%s", flag.name)
+		}
+	}
+
+	return nil
+}
+
+// runMatrix renders and calculates every environment of --values-matrix and prints a comparison of their totals.
+// --fail-if-exceeds applies to each environment, it fails after the comparison if any of them exceeds it.
+func (opts *KuotaCalcOpts) runMatrix(ctx context.Context, render renderFunc) error {
+	environments, err := parseValuesMatrix(opts.valuesMatrix)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.validateMatrix(); err != nil {
+		return err
+	}
+
+	if err := opts.parseReportFlags(); err != nil {
+		return err
+	}
+
+	totals := make([]calc.Resources, 0, len(environments))
+
+	var thresholdErrs []error
+
+	for _, environment := range environments {
+		manifests, err := render(ctx, environment.values)
+		if err != nil {
+			return fmt.Errorf("environment %s: %w", environment.name, err)
+		}
+
+		envOpts := *opts
+		envOpts.In = bytes.NewReader(manifests)
+
+		usage, _, err := envOpts.calculate(ctx)
+		if err != nil {
+			return fmt.Errorf("environment %s: %w", environment.name, err)
+		}
+
+		totals = append(totals, calc.Total(opts.maxRollouts, usage).AtUtilization(envOpts.utilization))
+
+		if err := envOpts.checkThreshold(usage); err != nil {
+			thresholdErrs = append(thresholdErrs, fmt.Errorf("environment %s: %w", environment.name, err))
+		}
+	}
+
+	opts.printMatrix(environments, totals)
+
+	return errors.Join(thresholdErrs...)
+}
+
+// printMatrix prints the totals of the environments side by side. Storage is only printed, if any environment
+// claims it.
+func (opts *KuotaCalcOpts) printMatrix(environments []matrixEnvironment, totals []calc.Resources) {
+	storage := false
+
+	for _, total := range totals {
+		if !total.PersistentVolumeClaims.IsZero() {
+			storage = true
+		}
+	}
+
+	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(w, "Environment\tCPURequest\tCPULimit\tMemoryRequest\tMemoryLimit\tPods\t")
+	if storage {
+		_, _ = fmt.Fprintf(w, "StorageRequest\tPersistentVolumeClaims\t")
+	}
+
+	_, _ = fmt.Fprintln(w)
+
+	for i, total := range totals {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t",
+			environments[i].name,
+			total.CPUMin.String(),
+			total.CPUMax.String(),
+			total.MemoryMin.String(),
+			total.MemoryMax.String(),
+			total.Pods.String(),
+		)
+
+		if storage {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t", total.Storage.String(), total.PersistentVolumeClaims.String())
+		}
+
+		_, _ = fmt.Fprintln(w)
+	}
+
+	if err := w.Flush(); err != nil {
+		_, _ = fmt.Fprintf(opts.Out, "printing values matrix to tabwriter failed: %v\n", err)
+	}
+}
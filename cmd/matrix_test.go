@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValuesMatrix(t *testing.T) {
+	var tests = []struct {
+		matrix       string
+		environments []matrixEnvironment
+		err          bool
+	}{
+		{
+			matrix: "dev=values-dev.yaml, prod=values-prod.yaml",
+			environments: []matrixEnvironment{
+				{name: "dev", values: "values-dev.yaml"},
+				{name: "prod", values: "values-prod.yaml"},
+			},
+		},
+		{matrix: "prod=overlays/prod", environments: []matrixEnvironment{{name: "prod", values: "overlays/prod"}}},
+		{matrix: "values-dev.yaml", err: true},
+		{matrix: "dev=", err: true},
+		{matrix: "dev=a.yaml,dev=b.yaml", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.matrix, func(t *testing.T) {
+			r := require.New(t)
+
+			environments, err := parseValuesMatrix(test.matrix)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(test.environments, environments)
+		})
+	}
+}
+
+// renderReplicas renders the api deployment with the replicas given as values.
+func renderReplicas(_ context.Context, replicas string) ([]byte, error) {
+	return []byte(strings.Replace(apiDeployment, "replicas: 2", "replicas: "+replicas, 1)), nil
+}
+
+func TestRunMatrix(t *testing.T) {
+	r := require.New(t)
+
+	opts := newTestOpts("")
+	out := &bytes.Buffer{}
+	opts.Out = out
+	opts.valuesMatrix = "dev=1,prod=4"
+	opts.failIfExceeds = "cpu.request=300m"
+
+	err := opts.runMatrix(context.Background(), renderReplicas)
+	r.ErrorContains(err, "environment prod: the total exceeds --fail-if-exceeds")
+	r.NotContains(err.Error(), "environment dev")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	r.Len(lines, 3)
+	r.Equal([]string{"Environment", "CPURequest", "CPULimit", "MemoryRequest", "MemoryLimit", "Pods"}, strings.Fields(lines[0]))
+	r.Equal("dev", strings.Fields(lines[1])[0])
+	r.Equal("prod", strings.Fields(lines[2])[0])
+}
+
+func TestRunMatrixErrors(t *testing.T) {
+	var tests = []struct {
+		name   string
+		modify func(*KuotaCalcOpts)
+		render renderFunc
+		err    string
+	}{
+		{
+			name:   "single report flag",
+			modify: func(opts *KuotaCalcOpts) { opts.output = outputJSON },
+			render: renderReplicas,
+			err:    "can't be combined with --output",
+		},
+		{
+			name:   "rendering fails",
+			modify: func(*KuotaCalcOpts) {},
+			render: func(context.Context, string) ([]byte, error) { return nil, errors.New("no chart") },
+			err:    "environment dev: no chart",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			opts := newTestOpts("")
+			opts.valuesMatrix = "dev=1"
+			test.modify(opts)
+
+			r.ErrorContains(opts.runMatrix(context.Background(), test.render), test.err)
+		})
+	}
+}
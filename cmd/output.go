@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+)
+
+const (
+	// outputText prints the human readable report, it is the default output.
+	outputText = "text"
+	// outputJSON prints the JSON report, as returned by the REST api.
+	outputJSON = "json"
+	// outputMarkdown prints the report as markdown tables, e.g. for pull request comments.
+	outputMarkdown = "markdown"
+	// outputQuota prints a ResourceQuota manifest per namespace instead of the report.
+	outputQuota = "quota"
+	// outputCSV prints a row per resource and a total row, e.g. for spreadsheets.
+	outputCSV = "csv"
+	// outputLimitRange prints a LimitRange per namespace, suggested from the containers, instead of the report.
+	outputLimitRange = "limitrange"
+)
+
+// outputFileNames are the names of the files written to --output-dir for each format.
+func outputFileNames() map[string]string {
+	return map[string]string{
+		outputText:       "report.txt",
+		outputJSON:       "report.json",
+		outputMarkdown:   "report.md",
+		outputQuota:      "quota.yaml",
+		outputCSV:        "report.csv",
+		outputLimitRange: "limitrange.yaml",
+	}
+}
+
+// outputFormats returns the supported output formats, sorted.
+func outputFormats() []string {
+	formats := make([]string, 0, len(outputFileNames()))
+	for format := range outputFileNames() {
+		formats = append(formats, format)
+	}
+
+	slices.Sort(formats)
+
+	return formats
+}
+
+func validateOutput(format string) error {
+	if _, ok := outputFileNames()[format]; !ok && format != "" {
+		return fmt.Errorf("unknown output format %q, supported are %s", format, strings.Join(outputFormats(), ", "))
+	}
+
+	return nil
+}
+
+// outputFormatList parses --output-formats.
+func (opts *KuotaCalcOpts) outputFormatList() ([]string, error) {
+	var formats []string
+
+	for _, format := range strings.Split(opts.outputFormats, ",") {
+		format = strings.TrimSpace(format)
+
+		if err := validateOutput(format); err != nil || format == "" {
+			return nil, fmt.Errorf("invalid --output-formats %q: unknown format %q, supported are %s",
+				opts.outputFormats, format, strings.Join(outputFormats(), ", "))
+		}
+
+		if !slices.Contains(formats, format) {
+			formats = append(formats, format)
+		}
+	}
+
+	return formats, nil
+}
+
+// printOutput prints the calculated resources in the given format.
+func (opts *KuotaCalcOpts) printOutput(format string, summary []*calc.ResourceUsage, skipped skippedResources) error {
+	switch format {
+	case outputQuota:
+		return opts.printQuotas(summary)
+	case outputJSON:
+		return opts.printJSON(summary, skipped)
+	case outputMarkdown:
+		opts.printMarkdown(summary, skipped)
+
+		return nil
+	case outputCSV:
+		return opts.printCSV(summary)
+	case outputLimitRange:
+		return opts.printLimitRanges(summary)
+	default:
+		return opts.printReport(summary, skipped)
+	}
+}
+
+// writeOutputs writes each format to its own file in --output-dir, so a single calculation serves all of them.
+func (opts *KuotaCalcOpts) writeOutputs(formats []string, summary []*calc.ResourceUsage, skipped skippedResources) error {
+	if err := os.MkdirAll(opts.outputDir, 0o750); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	for _, format := range formats {
+		var out bytes.Buffer
+
+		formatOpts := *opts
+		formatOpts.Out = &out
+
+		if err := formatOpts.printOutput(format, summary, skipped); err != nil {
+			return err
+		}
+
+		if err := writeFileAtomic(filepath.Join(opts.outputDir, outputFileNames()[format]), out.Bytes()); err != nil {
+			return fmt.Errorf("writing %s output: %w", format, err)
+		}
+	}
+
+	return nil
+}
+
+// writeOutputFile writes the output to --output-file instead of printing it.
+func (opts *KuotaCalcOpts) writeOutputFile(format string, summary []*calc.ResourceUsage, skipped skippedResources) error {
+	var out bytes.Buffer
+
+	fileOpts := *opts
+	fileOpts.Out = &out
+
+	if err := fileOpts.printOutput(format, summary, skipped); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(opts.outputFile, out.Bytes()); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes the data to a temporary file next to the path and renames it to the path, so readers never
+// see a half written file, even if kuota-calc is interrupted.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	// removing fails once the file is renamed, which is fine
+	defer os.Remove(tmp.Name()) //nolint:errcheck // best effort cleanup
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	// the temporary file is only readable by its owner, but reports are meant to be shared like any other file
+	if err := tmp.Chmod(0o644); err != nil { //nolint:gosec // reports aren't secret
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (opts *KuotaCalcOpts) printJSON(summary []*calc.ResourceUsage, skipped skippedResources) error {
+	data, err := json.MarshalIndent(opts.newReport(summary, skipped), "", "  ")
+	if err != nil {
+		return fmt.Errorf("printing json report: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(opts.Out, "%s\n", data)
+
+	return nil
+}
+
+// printCSV prints a row with the rollout resources of each resource and a last row with the total. Unlike the other
+// reports all columns are always printed, so spreadsheets can rely on them.
+func (opts *KuotaCalcOpts) printCSV(summary []*calc.ResourceUsage) error {
+	w := csv.NewWriter(opts.Out)
+	extended := calc.ExtendedResourceNames(summary)
+
+	header := []string{"Version", "Kind", "Namespace", "Name", "Replicas", "Strategy", "MaxReplicas",
+		"CPURequest", "CPULimit", "MemoryRequest", "MemoryLimit", "EphemeralStorageRequest", "EphemeralStorageLimit",
+		"StorageRequest", "PersistentVolumeClaims", "Pods"}
+	_ = w.Write(append(header, extendedHeader(extended)...))
+
+	for _, u := range summary {
+		row := []string{
+			u.Details.Version,
+			u.Details.Kind,
+			u.Details.Namespace,
+			u.Details.Name,
+			strconv.Itoa(int(u.Details.Replicas)),
+			u.Details.Strategy,
+			strconv.Itoa(int(u.Details.MaxReplicas)),
+		}
+		_ = w.Write(append(append(row, csvQuantities(u.RolloutResources)...), extendedColumns(extended, u.RolloutResources)...))
+	}
+
+	total := calc.Total(opts.maxRollouts, summary).AtUtilization(opts.utilization)
+	row := []string{"", "Total", "", "", "", "", ""}
+	_ = w.Write(append(append(row, csvQuantities(total)...), extendedColumns(extended, total)...))
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("printing csv report: %w", err)
+	}
+
+	return nil
+}
+
+// csvQuantities returns the columns of the quantities of the resources in a csv row.
+func csvQuantities(r calc.Resources) []string {
+	return []string{
+		r.CPUMin.String(),
+		r.CPUMax.String(),
+		r.MemoryMin.String(),
+		r.MemoryMax.String(),
+		r.EphemeralStorageMin.String(),
+		r.EphemeralStorageMax.String(),
+		r.Storage.String(),
+		r.PersistentVolumeClaims.String(),
+		r.Pods.String(),
+	}
+}
+
+// printMarkdown prints the resources, the total and the skipped resources as markdown tables.
+func (opts *KuotaCalcOpts) printMarkdown(summary []*calc.ResourceUsage, skipped skippedResources) {
+	storageHeader, storageAlignment := "", ""
+	storage := hasEphemeralStorage(summary)
+
+	if storage {
+		storageHeader, storageAlignment = " EphemeralStorageRequest | EphemeralStorageLimit |", "---:|---:|"
+	}
+
+	_, _ = fmt.Fprintf(opts.Out, "## Resources\n\n")
+	_, _ = fmt.Fprintf(opts.Out, "| Version | Kind | Namespace | Name | Replicas | Strategy | MaxReplicas | CPURequest | CPULimit | MemoryRequest | MemoryLimit |%s\n",
+		storageHeader)
+	_, _ = fmt.Fprintf(opts.Out, "|---|---|---|---|---:|---|---:|---:|---:|---:|---:|%s\n", storageAlignment)
+
+	for _, u := range summary {
+		var storageCells string
+		for _, column := range storageColumns(storage, u.RolloutResources) {
+			storageCells += " " + column + " |"
+		}
+
+		_, _ = fmt.Fprintf(opts.Out, "| %s | %s | %s | %s | %d | %s | %d | %s | %s | %s | %s |%s\n",
+			u.Details.Version,
+			u.Details.Kind,
+			u.Details.Namespace,
+			u.Details.Name,
+			u.Details.Replicas,
+			u.Details.Strategy,
+			u.Details.MaxReplicas,
+			u.RolloutResources.CPUMin.String(),
+			u.RolloutResources.CPUMax.String(),
+			u.RolloutResources.MemoryMin.String(),
+			u.RolloutResources.MemoryMax.String(),
+			storageCells,
+		)
+	}
+
+	total := calc.Total(opts.maxRollouts, summary).AtUtilization(opts.utilization)
+
+	_, _ = fmt.Fprintf(opts.Out, "\n## Total\n\n")
+	_, _ = fmt.Fprintf(opts.Out, "| CPURequest | CPULimit | MemoryRequest | MemoryLimit | EphemeralStorageRequest | EphemeralStorageLimit | Pods |\n")
+	_, _ = fmt.Fprintf(opts.Out, "|---:|---:|---:|---:|---:|---:|---:|\n")
+	_, _ = fmt.Fprintf(opts.Out, "| %s | %s | %s | %s | %s | %s | %s |\n",
+		total.CPUMin.String(),
+		total.CPUMax.String(),
+		total.MemoryMin.String(),
+		total.MemoryMax.String(),
+		total.EphemeralStorageMin.String(),
+		total.EphemeralStorageMax.String(),
+		total.Pods.String(),
+	)
+
+	opts.printMarkdownPreemptible()
+
+	if len(skipped) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(opts.Out, "\n## Skipped\n\n")
+	_, _ = fmt.Fprintf(opts.Out, "| Version | Kind | Reason | Count |\n")
+	_, _ = fmt.Fprintf(opts.Out, "|---|---|---|---:|\n")
+
+	for _, resource := range skipped.sorted() {
+		_, _ = fmt.Fprintf(opts.Out, "| %s | %s | %s | %d |\n", resource.version, resource.kind, resource.reason, skipped[resource])
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	var tests = []struct {
+		name  string
+		setup func(t *testing.T, dir string) string
+		err   bool
+	}{
+		{
+			name: "new file",
+			setup: func(_ *testing.T, dir string) string {
+				return filepath.Join(dir, "report.json")
+			},
+		},
+		{
+			name: "existing file",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "report.json")
+				require.NoError(t, os.WriteFile(path, []byte("a previous report, which is longer than the new one"), 0o600))
+
+				return path
+			},
+		},
+		{
+			name: "missing directory",
+			setup: func(_ *testing.T, dir string) string {
+				return filepath.Join(dir, "missing", "report.json")
+			},
+			err: true,
+		},
+		{
+			name: "directory in the way",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "report.json")
+				require.NoError(t, os.MkdirAll(filepath.Join(path, "nested"), 0o755))
+
+				return path
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			dir := t.TempDir()
+			path := test.setup(t, dir)
+
+			err := writeFileAtomic(path, []byte(`{"total": {}}`))
+
+			// the temporary file is removed, whether it was renamed or not
+			tmpFiles, globErr := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+			r.NoError(globErr)
+			r.Empty(tmpFiles)
+
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+
+			data, err := os.ReadFile(path)
+			r.NoError(err)
+			r.Equal(`{"total": {}}`, string(data))
+
+			info, err := os.Stat(path)
+			r.NoError(err)
+			r.Equal(os.FileMode(0o644), info.Mode().Perm())
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// externalCalculatorPrefix is the prefix of the executables calculating kinds unknown to kuota-calc, like the
+// plugins of kubectl. E.g. kuota-calc-worker calculates the kind Worker.
+const externalCalculatorPrefix = "kuota-calc-"
+
+// errExternalCalculatorsDisabled is the reason no external calculator is looked up without --external-calculators.
+var errExternalCalculatorsDisabled = errors.New("external calculators are disabled")
+
+// calculateObject calculates the resource usage of an object. Kinds unknown to kuota-calc are calculated by an
+// external calculator, if they are enabled with --external-calculators and one is found on the PATH, or estimated from
+// their pod spec with --heuristic. External calculators are never enabled by default, as the kind of the input selects
+// the executable which is run.
+func (opts *KuotaCalcOpts) calculateObject(ctx context.Context, object runtime.Object, calcOpts calc.Options) (*calc.ResourceUsage, error) {
+	usage, err := calc.ResourceQuotaFromObject(object, calcOpts)
+
+	unknown, ok := object.(*runtime.Unknown)
+	if ok && err == nil {
+		// unlike registered calculators, custom kinds don't get the default namespace when they are decoded
+		usage.Details.Namespace = cmp.Or(usage.Details.Namespace, opts.defaultNamespace)
+	}
+
+	if !ok || !errors.Is(err, calc.ErrResourceNotSupported) {
+		return usage, err
+	}
+
+	path, lookErr := "", errExternalCalculatorsDisabled
+	if opts.execCalculators {
+		path, lookErr = exec.LookPath(externalCalculatorPrefix + strings.ToLower(unknown.Kind))
+	}
+
+	if lookErr != nil {
+		// objects without a pod spec stay unsupported
+		if opts.heuristic {
+			heuristic, heuristicErr := opts.heuristicUsage(unknown, calcOpts)
+			if !errors.Is(heuristicErr, calc.ErrResourceNotSupported) {
+				return heuristic, heuristicErr
+			}
+		}
+
+		return usage, err
+	}
+
+	usage, err = opts.execCalculator(ctx, path, unknown)
+	if err != nil {
+		return nil, fmt.Errorf("calculating %s %s with %s: %w", unknown.APIVersion, unknown.Kind, path, err)
+	}
+
+	return usage, nil
+}
+
+// heuristicUsage estimates the resource usage of an unknown kind from the pod spec found in it.
+func (opts *KuotaCalcOpts) heuristicUsage(object *runtime.Unknown, calcOpts calc.Options) (*calc.ResourceUsage, error) {
+	usage, err := calc.HeuristicUsage(object, calcOpts)
+	if err != nil {
+		return nil, fmt.Errorf("estimating %s %s: %w", object.APIVersion, object.Kind, err)
+	}
+
+	usage.Details.Namespace = cmp.Or(usage.Details.Namespace, opts.defaultNamespace)
+
+	return usage, nil
+}
+
+// execCalculator runs an external calculator. It receives the object as json on stdin and writes its resource usage
+// as json to stdout, in the format of a resource of the json report.
+func (opts *KuotaCalcOpts) execCalculator(ctx context.Context, path string, object *runtime.Unknown) (*calc.ResourceUsage, error) {
+	content := unstructured.Unstructured{}
+	if err := sigsyaml.Unmarshal(object.Raw, &content.Object); err != nil {
+		return nil, fmt.Errorf("decoding object: %w", err)
+	}
+
+	if content.GetNamespace() == "" {
+		content.SetNamespace(opts.defaultNamespace)
+	}
+
+	input, err := content.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("encoding object: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	command := exec.CommandContext(ctx, path)
+	command.Stdin, command.Stdout, command.Stderr = bytes.NewReader(input), &stdout, &stderr
+
+	if err := command.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var result reportResource
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("decoding result: %w", err)
+	}
+
+	// the calculator may leave out the details of the object
+	details := calc.Details{
+		Version:     cmp.Or(result.Version, content.GetAPIVersion()),
+		Kind:        cmp.Or(result.Kind, content.GetKind()),
+		Namespace:   cmp.Or(result.Namespace, content.GetNamespace()),
+		Name:        cmp.Or(result.Name, content.GetName()),
+		Strategy:    result.Strategy,
+		Replicas:    result.Replicas,
+		MaxReplicas: result.MaxReplicas,
+		Labels:      content.GetLabels(),
+		Annotations: content.GetAnnotations(),
+	}
+
+	return &calc.ResourceUsage{
+		NormalResources:  result.Normal.resources(),
+		RolloutResources: result.Rollout.resources(),
+		Details:          details,
+	}, nil
+}
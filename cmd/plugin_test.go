@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var worker = `
+apiVersion: example.com/v1
+kind: Worker
+metadata:
+  name: queue
+spec:
+  replicas: 3`
+
+// installCalculator installs an external calculator running the script into a directory, which is the only one on
+// the PATH.
+func installCalculator(t *testing.T, kind, script string) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, externalCalculatorPrefix+kind), []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+
+	t.Setenv("PATH", dir)
+}
+
+func TestExternalCalculators(t *testing.T) {
+	var tests = []struct {
+		name     string
+		kind     string
+		script   string
+		disabled bool
+		cpu      resource.Quantity
+		err      string
+	}{
+		{
+			name: "success",
+			kind: "worker",
+			script: `echo '{"replicas": 3, "maxReplicas": 3, "strategy": "Recreate",` +
+				` "normal": {"cpuRequest": "1500m"}, "rollout": {"cpuRequest": "1500m"}}'`,
+			cpu: resource.MustParse("1500m"),
+		},
+		{
+			name:   "failure",
+			kind:   "worker",
+			script: "echo 'no capacity model' >&2\nexit 3",
+			err:    "exit status 3: no capacity model",
+		},
+		{
+			name:   "invalid result",
+			kind:   "worker",
+			script: "echo 'not json'",
+			err:    "decoding result",
+		},
+		{
+			name:   "calculator of another kind",
+			kind:   "pool",
+			script: "exit 1",
+			err:    calc.ErrResourceNotSupported.Error(),
+		},
+		{
+			name:     "disabled",
+			kind:     "worker",
+			script:   "exit 1",
+			disabled: true,
+			err:      calc.ErrResourceNotSupported.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			installCalculator(t, test.kind, test.script)
+
+			object, err := calc.Decode([]byte(worker))
+			r.NoError(err)
+
+			opts := newTestOpts("")
+			opts.execCalculators = !test.disabled
+
+			usage, err := opts.calculateObject(context.Background(), object, calc.Options{})
+			if test.err != "" {
+				r.ErrorContains(err, test.err)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal("Worker", usage.Details.Kind)
+			r.Equal("queue", usage.Details.Name)
+			r.Equal("default", usage.Details.Namespace)
+			r.Equal(int32(3), usage.Details.Replicas)
+			r.Zero(test.cpu.Cmp(usage.NormalResources.CPUMin), "cpu request value %s", usage.NormalResources.CPUMin.String())
+		})
+	}
+}
+
+func TestRequestOptsDisableExternalCalculators(t *testing.T) {
+	opts := newTestOpts("")
+	opts.execCalculators = true
+
+	require.False(t, opts.requestOpts([]byte(worker)).execCalculators)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+)
+
+// printPreemptible prints the workloads excluded from the total by --ignore-priority-below.
+func (opts *KuotaCalcOpts) printPreemptible() {
+	if len(opts.preemptible) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(opts.Out, "\nPreemptible workloads below priority %s, which are not included in the total\n", opts.ignorePriority)
+
+	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(w, "Version\tKind\tNamespace\tName\tPriorityClass\tCPURequest\tCPULimit\tMemoryRequest\tMemoryLimit\t\n")
+
+	for _, u := range opts.preemptible {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			u.Details.Version,
+			u.Details.Kind,
+			u.Details.Namespace,
+			u.Details.Name,
+			u.Details.PriorityClassName,
+			u.RolloutResources.CPUMin.String(),
+			u.RolloutResources.CPUMax.String(),
+			u.RolloutResources.MemoryMin.String(),
+			u.RolloutResources.MemoryMax.String(),
+		)
+	}
+
+	if err := w.Flush(); err != nil {
+		_, _ = fmt.Fprintf(opts.Out, "printing preemptible workloads to tabwriter failed: %v\n", err)
+	}
+}
+
+// printMarkdownPreemptible prints the workloads excluded from the total by --ignore-priority-below as markdown table.
+func (opts *KuotaCalcOpts) printMarkdownPreemptible() {
+	if len(opts.preemptible) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(opts.Out, "\n## Preemptible below priority %s\n\n", opts.ignorePriority)
+	_, _ = fmt.Fprintf(opts.Out, "| Version | Kind | Namespace | Name | PriorityClass | CPURequest | CPULimit | MemoryRequest | MemoryLimit |\n")
+	_, _ = fmt.Fprintf(opts.Out, "|---|---|---|---|---|---:|---:|---:|---:|\n")
+
+	for _, u := range opts.preemptible {
+		_, _ = fmt.Fprintf(opts.Out, "| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			u.Details.Version,
+			u.Details.Kind,
+			u.Details.Namespace,
+			u.Details.Name,
+			u.Details.PriorityClassName,
+			u.RolloutResources.CPUMin.String(),
+			u.RolloutResources.CPUMax.String(),
+			u.RolloutResources.MemoryMin.String(),
+			u.RolloutResources.MemoryMax.String(),
+		)
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	goruntime "runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// reportProvenance tells, which kuota-calc calculated a report from which input with which flags, so archived reports
+// are auditable and reproducible.
+type reportProvenance struct {
+	Tool reportTool `json:"tool"`
+	// Flags are the effective flags, given on the command line, in the environment or in the config file.
+	Flags map[string]string `json:"flags"`
+	// Inputs are the sha256 hashes of the input and the files given by flags, by stdin or the name of the flag. The
+	// input read from the files of --filename and rendered from --kustomize is hashed as a whole.
+	Inputs map[string]string `json:"inputs"`
+	// GitCommit is the commit of the manifests, if the CI or the git repository of the working directory tells it.
+	GitCommit string    `json:"gitCommit,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type reportTool struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"goVersion"`
+}
+
+// inputFileFlags are the flags naming files, which are part of the input of the calculation.
+func inputFileFlags() []string {
+	return []string{"config", "strategy-defaults", "injection-rules", "custom-kinds"}
+}
+
+// secretFlags are the flags passing credentials, whose values are left out of the provenance.
+func secretFlags() []string {
+	return []string{"token", "password"}
+}
+
+// gitCommitEnvs are the environment variables, in which common CI systems pass the commit of the pipeline.
+func gitCommitEnvs() []string {
+	return []string{"GITHUB_SHA", "CI_COMMIT_SHA", "BUILD_SOURCEVERSION", "GIT_COMMIT"}
+}
+
+// recordFlags records the effective flags of the command for the provenance of the report.
+func (opts *KuotaCalcOpts) recordFlags(cmd *cobra.Command) {
+	opts.effectiveFlags = map[string]string{}
+
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		// the credentials of the kubeconfig flags must not end up in archived reports
+		if slices.Contains(secretFlags(), flag.Name) {
+			opts.effectiveFlags[flag.Name] = "<redacted>"
+
+			return
+		}
+
+		opts.effectiveFlags[flag.Name] = flag.Value.String()
+	})
+}
+
+// newProvenance returns the provenance of a report calculated by the last calculation.
+func (opts *KuotaCalcOpts) newProvenance() *reportProvenance {
+	p := &reportProvenance{
+		Tool: reportTool{
+			Version:   opts.versionInfo.Version,
+			Commit:    opts.versionInfo.Commit,
+			Date:      opts.versionInfo.Date,
+			GoVersion: goruntime.Version(),
+		},
+		Flags:     opts.effectiveFlags,
+		Inputs:    map[string]string{},
+		GitCommit: gitCommit(),
+		Timestamp: reportTimestamp(),
+	}
+
+	if opts.inputHash != "" {
+		p.Inputs[opts.inputName()] = opts.inputHash
+	}
+
+	for _, flag := range inputFileFlags() {
+		path := opts.effectiveFlags[flag]
+		if flag == "config" {
+			// the config file is read from the default path without the flag
+			path = opts.config
+		}
+
+		if path == "" {
+			continue
+		}
+
+		if data, err := os.ReadFile(path); err == nil {
+			hash := sha256.Sum256(data)
+			p.Inputs[flag] = hex.EncodeToString(hash[:])
+		}
+	}
+
+	return p
+}
+
+// gitCommit returns the commit the CI passes, or the HEAD of the git repository of the working directory, or an empty
+// string if neither is known.
+func gitCommit() string {
+	for _, env := range gitCommitEnvs() {
+		if commit := os.Getenv(env); commit != "" {
+			return commit
+		}
+	}
+
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// reportTimestamp returns the current time, or the time of SOURCE_DATE_EPOCH for reproducible reports.
+// https://reproducible-builds.org/docs/source-date-epoch/
+func reportTimestamp() time.Time {
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+
+	return time.Now().UTC().Truncate(time.Second)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// loadExistingQuotas reads the ResourceQuotas of --quota, either of a yaml file or a single one from the cluster
+// selected by the kubeconfig flags, given as namespace/name. It returns the hard limits per namespace.
+func (opts *KuotaCalcOpts) loadExistingQuotas(ctx context.Context) (map[string]corev1.ResourceList, error) {
+	if _, err := os.Stat(opts.quota); err == nil {
+		return opts.loadQuotas(opts.quota)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading quotas: %w", err)
+	}
+
+	namespace, name, found := strings.Cut(opts.quota, "/")
+	if !found || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid --quota %q, expected a file or <namespace>/<name> of a ResourceQuota in the cluster", opts.quota)
+	}
+
+	restConfig, err := opts.configFlags.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating client: %w", err)
+	}
+
+	quota, err := client.CoreV1().ResourceQuotas(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting resource quota %s: %w", opts.quota, err)
+	}
+
+	return map[string]corev1.ResourceList{namespace: quota.Spec.Hard}, nil
+}
+
+// printQuotaComparison prints the total of each namespace of --quota next to the hard limits of its quota, with the
+// remaining headroom and whether the total fits.
+func (opts *KuotaCalcOpts) printQuotaComparison(usage []*calc.ResourceUsage) {
+	namespaces := make([]string, 0, len(opts.existingQuotas))
+	for namespace := range opts.existingQuotas {
+		namespaces = append(namespaces, namespace)
+	}
+
+	slices.Sort(namespaces)
+
+	for _, namespace := range namespaces {
+		var namespaceUsage []*calc.ResourceUsage
+
+		for _, u := range usage {
+			if u.Details.Namespace == namespace {
+				namespaceUsage = append(namespaceUsage, u)
+			}
+		}
+
+		total := calc.Total(opts.maxRollouts, namespaceUsage).AtUtilization(opts.utilization)
+		comparisons := calc.CompareQuota(opts.existingQuotas[namespace], total)
+
+		verdict := "PASS"
+		if slices.ContainsFunc(comparisons, calc.QuotaComparison.Exceeded) {
+			verdict = "FAIL"
+		}
+
+		_, _ = fmt.Fprintf(opts.Out, "\nQuota of namespace %s: %s\n", namespace, verdict)
+
+		w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+		_, _ = fmt.Fprintf(w, "Resource\tRequested\tAllowed\tRemaining\t\n")
+
+		for _, c := range comparisons {
+			remaining := c.Remaining()
+
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", c.Name, c.Requested.String(), c.Allowed.String(), remaining.String())
+		}
+
+		if err := w.Flush(); err != nil {
+			_, _ = fmt.Fprintf(opts.Out, "printing quota comparison to tabwriter failed: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/spf13/cobra"
+)
+
+const (
+	recalcExample = `    # save a report and recalculate its total with at most 2 simultaneous rollouts
+    %[1]s -o json < manifests.yaml > report.json
+    %[1]s recalc report.json --max-rollouts 2
+
+    # recalculate the total of the namespace team-a at 70%% cpu utilization
+    %[1]s recalc report.json --namespaces team-a --target-utilization cpu=0.7`
+
+	// skipRecalcFilter is the reason of the resources of a saved report, which the filters of recalc exclude.
+	skipRecalcFilter skipReason = "excluded by recalc filter"
+)
+
+// recalcFilter selects the resources of a saved report, which are recalculated. Empty lists select all of them.
+type recalcFilter struct {
+	namespaces []string
+	kinds      []string
+}
+
+// recalcIgnoredFlags returns the flags changing how the resources themselves are calculated. A saved report only
+// contains the calculated resources, so recalc can't apply them. They are ignored with a warning instead of an error,
+// as the config file might set them for the calculations.
+func recalcIgnoredFlags() []string {
+	return []string{
+		"assume-replicas", "termination-overlap", "platform", "kubernetes-version", "strategy-defaults",
+		"hpa-mode", "hpa-normal", "hpa-peak", "vpa-mode", "job-retries", "pod-phases", "field-selector",
+		"default-namespace", "ignore-priority-below", "heuristic", "external-calculators", "mesh", "mesh-proxy",
+		"injection-rules", "custom-kinds", "empty-dir-storage",
+	}
+}
+
+func (f recalcFilter) excludes(r reportResource) bool {
+	return (len(f.namespaces) > 0 && !slices.Contains(f.namespaces, r.Namespace)) ||
+		(len(f.kinds) > 0 && !slices.Contains(f.kinds, r.Kind))
+}
+
+// newRecalcCmd returns a command recalculating the totals of a saved JSON report under different assumptions,
+// without reading the manifests again.
+func newRecalcCmd(opts *KuotaCalcOpts) *cobra.Command {
+	var filter recalcFilter
+
+	cmd := &cobra.Command{
+		Use:          "recalc [report.json]",
+		Short:        "Recalculate the totals of a saved JSON report, e.g. with other --max-rollouts or --target-utilization.",
+		Example:      fmt.Sprintf(recalcExample, "kuota-calc"),
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "-"
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			var ignored []string
+
+			for _, name := range recalcIgnoredFlags() {
+				if cmd.Flags().Changed(name) {
+					ignored = append(ignored, name)
+				}
+			}
+
+			return opts.runRecalc(path, filter, ignored)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&filter.namespaces, "namespaces", nil, "only recalculate the resources of these namespaces")
+	cmd.Flags().StringSliceVar(&filter.kinds, "kinds", nil, "only recalculate the resources of these kinds, e.g. Deployment,StatefulSet")
+
+	return cmd
+}
+
+// runRecalc recalculates the saved report at the path, or read from stdin for -, and prints it in the --output format.
+// The saved resources keep their normal and rollout resources, only the flags applied to the totals take effect. The
+// ignored flags are the ones given, which change the calculation of the resources, they are warned about.
+func (opts *KuotaCalcOpts) runRecalc(path string, filter recalcFilter, ignored []string) error {
+	if err := validateOutput(opts.output); err != nil {
+		return err
+	}
+
+	if err := opts.validateRecalc(); err != nil {
+		return err
+	}
+
+	if err := opts.parseReportFlags(); err != nil {
+		return err
+	}
+
+	for _, name := range ignored {
+		_, _ = fmt.Fprintf(opts.ErrOut, "WARNING: --%s is ignored, it changes how the resources are calculated and recalc "+
+			"keeps the resources of the saved report\n", name)
+	}
+
+	data, err := readRecalcInput(opts.In, path)
+	if err != nil {
+		return err
+	}
+
+	var saved report
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("decoding report %s: %w", path, err)
+	}
+
+	hash := sha256.Sum256(data)
+	opts.inputHash = hex.EncodeToString(hash[:])
+
+	var usage []*calc.ResourceUsage
+
+	skipped := skippedResources{}
+
+	for _, r := range saved.Resources {
+		if filter.excludes(r) {
+			skipped.add(r.Version, r.Kind, skipRecalcFilter)
+
+			continue
+		}
+
+		usage = append(usage, r.usage())
+	}
+
+	for _, s := range saved.Skipped {
+		skipped[skippedResource{version: s.Version, kind: s.Kind, reason: skipReason(s.Reason)}] += s.Count
+	}
+
+	return opts.printOutput(opts.output, usage, skipped)
+}
+
+// validateRecalc returns an error, if an output needs details of the resources, which a saved report doesn't contain:
+// their labels, annotations and priority classes or the resources of their containers.
+func (opts *KuotaCalcOpts) validateRecalc() error {
+	if opts.groupByLabel != "" {
+		return errors.New("recalc can't --group-by-label, the saved report doesn't contain the labels of the resources")
+	}
+
+	for _, key := range strings.Split(opts.groupBy, ",") {
+		key = strings.TrimSpace(key)
+		if key == calc.GroupByPriorityClass || strings.HasPrefix(key, calc.GroupByLabelPrefix) ||
+			strings.HasPrefix(key, calc.GroupByAnnotationPrefix) {
+			return fmt.Errorf("recalc can't --group-by %s, the saved report doesn't contain the labels, annotations and "+
+				"priority classes of the resources", key)
+		}
+	}
+
+	if opts.quotaScopes != "" {
+		scopes, err := calc.ParseQuotaScopes(opts.quotaScopes)
+		if err != nil {
+			return err
+		}
+
+		if scopes.PriorityClass {
+			return fmt.Errorf("recalc can't split the quotas by --quota-scopes %s, the saved report doesn't contain the "+
+				"priority classes of the resources", calc.QuotaScopePriorityClass)
+		}
+	}
+
+	if opts.output == outputLimitRange {
+		return fmt.Errorf("recalc can't write the %s output, the saved report doesn't contain the resources of the containers",
+			outputLimitRange)
+	}
+
+	return nil
+}
+
+func readRecalcInput(stdin io.Reader, path string) ([]byte, error) {
+	if path == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading report: %w", err)
+		}
+
+		return data, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading report: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var databaseStatefulSet = `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: database
+  namespace: team-b
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: postgres
+        resources:
+          requests:
+            cpu: 200m
+            memory: 256Mi`
+
+// savedReport calculates the api deployment and the database statefulset and returns their JSON report.
+func savedReport(t *testing.T, modify func(*KuotaCalcOpts)) report {
+	stdout, _ := runOutputs(t, apiDeployment+"\n---\n"+databaseStatefulSet, func(opts *KuotaCalcOpts) {
+		opts.output = outputJSON
+		modify(opts)
+	})
+
+	var saved report
+	require.NoError(t, json.Unmarshal([]byte(stdout), &saved))
+
+	return saved
+}
+
+// recalcOutputs recalculates the saved report and returns the recalculated report and what was printed to stderr.
+func recalcOutputs(t *testing.T, saved report, filter recalcFilter, ignored []string, modify func(*KuotaCalcOpts)) (report, string) {
+	data, err := json.Marshal(saved)
+	require.NoError(t, err)
+
+	opts := newTestOpts(string(data))
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	opts.Out, opts.ErrOut = stdout, stderr
+	opts.output = outputJSON
+	modify(opts)
+
+	require.NoError(t, opts.runRecalc("-", filter, ignored))
+
+	var recalculated report
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &recalculated))
+
+	return recalculated, stderr.String()
+}
+
+func TestRecalcMaxRollouts(t *testing.T) {
+	r := require.New(t)
+
+	saved := savedReport(t, func(*KuotaCalcOpts) {})
+	direct := savedReport(t, func(opts *KuotaCalcOpts) { opts.maxRollouts = 0 })
+
+	recalculated, _ := recalcOutputs(t, saved, recalcFilter{}, nil, func(opts *KuotaCalcOpts) { opts.maxRollouts = 0 })
+
+	// the saved resources are recalculated like the manifests themselves with the other max rollouts
+	total, err := json.Marshal(recalculated.Total)
+	r.NoError(err)
+	directTotal, err := json.Marshal(direct.Total)
+	r.NoError(err)
+	savedTotal, err := json.Marshal(saved.Total)
+	r.NoError(err)
+
+	r.JSONEq(string(directTotal), string(total))
+	r.NotEqual(string(savedTotal), string(total))
+	r.Len(recalculated.Resources, 2)
+}
+
+func TestRecalcFilter(t *testing.T) {
+	var tests = []struct {
+		name      string
+		filter    recalcFilter
+		resources []string
+		excluded  int
+	}{
+		{name: "all", resources: []string{"api", "database"}},
+		{name: "namespace", filter: recalcFilter{namespaces: []string{"team-a"}}, resources: []string{"api"}, excluded: 1},
+		{name: "kind", filter: recalcFilter{kinds: []string{"StatefulSet"}}, resources: []string{"database"}, excluded: 1},
+		{
+			name:     "namespace and kind",
+			filter:   recalcFilter{namespaces: []string{"team-a"}, kinds: []string{"StatefulSet"}},
+			excluded: 2,
+		},
+	}
+
+	saved := savedReport(t, func(*KuotaCalcOpts) {})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			recalculated, _ := recalcOutputs(t, saved, test.filter, nil, func(*KuotaCalcOpts) {})
+
+			var names []string
+			for _, resource := range recalculated.Resources {
+				names = append(names, resource.Name)
+			}
+
+			r.Equal(test.resources, names)
+
+			excluded := 0
+
+			for _, skipped := range recalculated.Skipped {
+				if skipped.Reason == string(skipRecalcFilter) {
+					excluded += skipped.Count
+				}
+			}
+
+			r.Equal(test.excluded, excluded)
+		})
+	}
+}
+
+func TestRecalcIgnoredFlags(t *testing.T) {
+	r := require.New(t)
+
+	saved := savedReport(t, func(*KuotaCalcOpts) {})
+
+	recalculated, stderr := recalcOutputs(t, saved, recalcFilter{}, []string{"hpa-peak", "assume-replicas"}, func(*KuotaCalcOpts) {})
+
+	r.Contains(stderr, "WARNING: --hpa-peak is ignored")
+	r.Contains(stderr, "WARNING: --assume-replicas is ignored")
+	r.Len(recalculated.Resources, 2)
+}
+
+func TestRecalcUnsupportedOutputs(t *testing.T) {
+	var tests = []struct {
+		name   string
+		modify func(*KuotaCalcOpts)
+		err    string
+	}{
+		{name: "group by label", modify: func(opts *KuotaCalcOpts) { opts.groupBy = "namespace,label:team" }, err: "--group-by label:team"},
+		{name: "group by priority class", modify: func(opts *KuotaCalcOpts) { opts.groupBy = "priorityClass" }, err: "--group-by priorityClass"},
+		{name: "group by label flag", modify: func(opts *KuotaCalcOpts) { opts.groupByLabel = "team" }, err: "--group-by-label"},
+		{
+			name: "priority class quota scope",
+			modify: func(opts *KuotaCalcOpts) {
+				opts.output = outputQuota
+				opts.quotaScopes = "terminating,priority-class"
+			},
+			err: "--quota-scopes priority-class",
+		},
+		{name: "limit range", modify: func(opts *KuotaCalcOpts) { opts.output = outputLimitRange }, err: "limitrange output"},
+	}
+
+	saved, err := json.Marshal(savedReport(t, func(*KuotaCalcOpts) {}))
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := newTestOpts(string(saved))
+			test.modify(opts)
+
+			require.ErrorContains(t, opts.runRecalc("-", recalcFilter{}, nil), test.err)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	releaseExample = `    # calculate the deployed release my-app in the namespace my-namespace
+    %[1]s release my-app -n my-namespace`
+
+	// helmReleaseSecretType is the type of the secrets, in which helm stores its releases.
+	helmReleaseSecretType = "helm.sh/release.v1"
+	// releasePageSize is the number of release secrets listed per request.
+	releasePageSize = 500
+)
+
+// newReleaseCmd returns a command calculating a helm release deployed in the cluster.
+func newReleaseCmd(opts *KuotaCalcOpts) *cobra.Command {
+	configFlags := genericclioptions.NewConfigFlags(true)
+
+	cmd := &cobra.Command{
+		Use:          "release <release-name>",
+		Short:        "Calculate the resource quota needs of a helm release deployed in the cluster.",
+		Example:      fmt.Sprintf(releaseExample, "kuota-calc"),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, namespace, err := releaseManifest(cmd.Context(), configFlags, args[0])
+			if err != nil {
+				return err
+			}
+
+			// helm installs resources without a namespace into the namespace of the release
+			if !cmd.Flags().Changed("default-namespace") {
+				opts.defaultNamespace = namespace
+			}
+
+			opts.In = strings.NewReader(manifest)
+
+			return opts.run(cmd.Context())
+		},
+	}
+
+	configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// releaseManifest returns the manifest and the namespace of the latest deployed revision of a helm release.
+// Only the default secret storage of helm is supported. The release secrets are listed in pages by their metadata, so
+// namespaces with many releases don't have to be loaded into memory at once, only the latest revision is fetched.
+func releaseManifest(ctx context.Context, configFlags *genericclioptions.ConfigFlags, name string) (manifest, namespace string, err error) {
+	namespace, _, err = configFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return "", "", fmt.Errorf("getting namespace: %w", err)
+	}
+
+	restConfig, err := configFlags.ToRESTConfig()
+	if err != nil {
+		return "", "", fmt.Errorf("loading kubeconfig: %w", err)
+	}
+
+	latest, latestVersion, err := latestRelease(ctx, restConfig, namespace, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	if latest == "" {
+		return "", "", fmt.Errorf("no deployed release %s found in namespace %s", name, namespace)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return "", "", fmt.Errorf("creating client: %w", err)
+	}
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, latest, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("getting release %s revision %d: %w", name, latestVersion, err)
+	}
+
+	manifest, err = decodeRelease(secret.Data["release"])
+	if err != nil {
+		return "", "", fmt.Errorf("decoding release %s revision %d: %w", name, latestVersion, err)
+	}
+
+	return manifest, namespace, nil
+}
+
+// latestRelease returns the name and the version of the secret of the latest deployed revision of a helm release, or
+// an empty name if there is none. The secrets are listed by their metadata in pages of releasePageSize.
+func latestRelease(ctx context.Context, restConfig *rest.Config, namespace, name string) (string, int, error) {
+	client, err := metadata.NewForConfig(restConfig)
+	if err != nil {
+		return "", 0, fmt.Errorf("creating metadata client: %w", err)
+	}
+
+	secrets := client.Resource(corev1.SchemeGroupVersion.WithResource("secrets")).Namespace(namespace)
+	listOptions := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("owner=helm,name=%s,status=deployed", name),
+		FieldSelector: "type=" + helmReleaseSecretType,
+		Limit:         releasePageSize,
+	}
+
+	var (
+		latest        string
+		latestVersion int
+	)
+
+	for {
+		page, err := secrets.List(ctx, listOptions)
+		if err != nil {
+			return "", 0, fmt.Errorf("listing releases: %w", err)
+		}
+
+		for _, secret := range page.Items {
+			version, err := strconv.Atoi(secret.Labels["version"])
+			if err != nil {
+				continue
+			}
+
+			if latest == "" || version > latestVersion {
+				latest, latestVersion = secret.Name, version
+			}
+		}
+
+		if page.Continue == "" {
+			return latest, latestVersion, nil
+		}
+
+		listOptions.Continue = page.Continue
+	}
+}
+
+// decodeRelease returns the manifest of a release stored by helm, which is base64 encoded, gzipped json.
+func decodeRelease(data []byte) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return "", err
+	}
+
+	// helm only compresses releases since v3, but reads uncompressed ones as well
+	if bytes.HasPrefix(decoded, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+
+		decoded, err = io.ReadAll(reader)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var release struct {
+		Manifest string `json:"manifest"`
+	}
+
+	if err := json.Unmarshal(decoded, &release); err != nil {
+		return "", err
+	}
+
+	return release.Manifest, nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// encodeRelease encodes a release like helm stores it in its release secrets.
+func encodeRelease(t *testing.T, release string, compress bool) []byte {
+	data := []byte(release)
+
+	if compress {
+		var buf bytes.Buffer
+
+		writer := gzip.NewWriter(&buf)
+		_, err := writer.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		data = buf.Bytes()
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(data))
+}
+
+func TestDecodeRelease(t *testing.T) {
+	release := `{"name":"my-app","version":3,"manifest":"---\nkind: Deployment\n"}`
+
+	var tests = []struct {
+		name     string
+		data     []byte
+		manifest string
+		err      bool
+	}{
+		{name: "compressed", data: encodeRelease(t, release, true), manifest: "---\nkind: Deployment\n"},
+		{name: "uncompressed", data: encodeRelease(t, release, false), manifest: "---\nkind: Deployment\n"},
+		{name: "not base64", data: []byte("not base64!"), err: true},
+		{name: "not json", data: encodeRelease(t, "manifest: yaml", true), err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			manifest, err := decodeRelease(test.data)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(test.manifest, manifest)
+		})
+	}
+}
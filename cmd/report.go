@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"github.com/druppelt/kuota-calc/internal/calc"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// report is the JSON report of a calculation, as returned by the REST api.
+type report struct {
+	Resources []reportResource `json:"resources"`
+	// Total is the total of all resources, limited to the max rollouts and inflated to the target utilization.
+	Total   reportQuantities `json:"total"`
+	Skipped []reportSkipped  `json:"skipped"`
+	// Preemptible are the resources excluded from the total by --ignore-priority-below.
+	Preemptible []reportResource `json:"preemptible,omitempty"`
+	// Carbon is the estimated carbon footprint of the normal requests with --carbon-region or --carbon-grid-intensity.
+	Carbon *reportCarbon `json:"carbon,omitempty"`
+	// Provenance is left out with --provenance=false.
+	Provenance *reportProvenance `json:"provenance,omitempty"`
+}
+
+// reportCarbon is the estimated energy and emissions of running the normal requests for a month.
+type reportCarbon struct {
+	GridIntensity  float64 `json:"gridIntensity"`
+	PUE            float64 `json:"pue"`
+	KWhPerMonth    float64 `json:"kWhPerMonth"`
+	KgCO2ePerMonth float64 `json:"kgCO2ePerMonth"`
+}
+
+// reportResource is the calculated usage of a single resource.
+type reportResource struct {
+	Version     string           `json:"version"`
+	Kind        string           `json:"kind"`
+	Namespace   string           `json:"namespace"`
+	Name        string           `json:"name"`
+	Replicas    int32            `json:"replicas"`
+	Strategy    string           `json:"strategy"`
+	MaxReplicas int32            `json:"maxReplicas"`
+	Normal      reportQuantities `json:"normal"`
+	Rollout     reportQuantities `json:"rollout"`
+}
+
+type reportQuantities struct {
+	CPURequest              resource.Quantity   `json:"cpuRequest"`
+	CPULimit                resource.Quantity   `json:"cpuLimit"`
+	MemoryRequest           resource.Quantity   `json:"memoryRequest"`
+	MemoryLimit             resource.Quantity   `json:"memoryLimit"`
+	EphemeralStorageRequest resource.Quantity   `json:"ephemeralStorageRequest"`
+	EphemeralStorageLimit   resource.Quantity   `json:"ephemeralStorageLimit"`
+	Extended                corev1.ResourceList `json:"extended,omitempty"`
+	StorageRequest          resource.Quantity   `json:"storageRequest"`
+	PersistentVolumeClaims  resource.Quantity   `json:"persistentVolumeClaims"`
+	Pods                    resource.Quantity   `json:"pods"`
+}
+
+// reportSkipped counts the resources of a version and kind, which were skipped for the same reason.
+type reportSkipped struct {
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	Reason  string `json:"reason"`
+	Count   int    `json:"count"`
+}
+
+func newReportQuantities(r calc.Resources) reportQuantities {
+	return reportQuantities{
+		CPURequest:              r.CPUMin,
+		CPULimit:                r.CPUMax,
+		MemoryRequest:           r.MemoryMin,
+		MemoryLimit:             r.MemoryMax,
+		EphemeralStorageRequest: r.EphemeralStorageMin,
+		EphemeralStorageLimit:   r.EphemeralStorageMax,
+		Extended:                r.Extended,
+		StorageRequest:          r.Storage,
+		PersistentVolumeClaims:  r.PersistentVolumeClaims,
+		Pods:                    r.Pods,
+	}
+}
+
+func (q reportQuantities) resources() calc.Resources {
+	return calc.Resources{
+		CPUMin:                 q.CPURequest,
+		CPUMax:                 q.CPULimit,
+		MemoryMin:              q.MemoryRequest,
+		MemoryMax:              q.MemoryLimit,
+		EphemeralStorageMin:    q.EphemeralStorageRequest,
+		EphemeralStorageMax:    q.EphemeralStorageLimit,
+		Extended:               q.Extended,
+		Storage:                q.StorageRequest,
+		PersistentVolumeClaims: q.PersistentVolumeClaims,
+		Pods:                   q.Pods,
+	}
+}
+
+// newReport returns the JSON report of the calculated and skipped resources.
+func (opts *KuotaCalcOpts) newReport(usage []*calc.ResourceUsage, skipped skippedResources) report {
+	r := report{
+		Resources: make([]reportResource, 0, len(usage)),
+		Total:     newReportQuantities(calc.Total(opts.maxRollouts, usage).AtUtilization(opts.utilization)),
+		Skipped:   make([]reportSkipped, 0, len(skipped)),
+	}
+
+	for _, u := range usage {
+		r.Resources = append(r.Resources, newReportResource(u))
+	}
+
+	for _, u := range opts.preemptible {
+		r.Preemptible = append(r.Preemptible, newReportResource(u))
+	}
+
+	if opts.provenance {
+		r.Provenance = opts.newProvenance()
+	}
+
+	if opts.carbon != nil {
+		estimate := opts.carbon.Estimate(calc.Total(0, usage))
+		r.Carbon = &reportCarbon{
+			GridIntensity:  opts.carbon.GridIntensity,
+			PUE:            opts.carbon.PUE,
+			KWhPerMonth:    estimate.KWhPerMonth,
+			KgCO2ePerMonth: estimate.KgCO2ePerMonth,
+		}
+	}
+
+	for _, resource := range skipped.sorted() {
+		r.Skipped = append(r.Skipped, reportSkipped{
+			Version: resource.version,
+			Kind:    resource.kind,
+			Reason:  string(resource.reason),
+			Count:   skipped[resource],
+		})
+	}
+
+	return r
+}
+
+// usage returns the resource usage of a resource of a saved report.
+func (r reportResource) usage() *calc.ResourceUsage {
+	return &calc.ResourceUsage{
+		NormalResources:  r.Normal.resources(),
+		RolloutResources: r.Rollout.resources(),
+		Details: calc.Details{
+			Version:     r.Version,
+			Kind:        r.Kind,
+			Namespace:   r.Namespace,
+			Name:        r.Name,
+			Strategy:    r.Strategy,
+			Replicas:    r.Replicas,
+			MaxReplicas: r.MaxReplicas,
+		},
+	}
+}
+
+func newReportResource(u *calc.ResourceUsage) reportResource {
+	return reportResource{
+		Version:     u.Details.Version,
+		Kind:        u.Details.Kind,
+		Namespace:   u.Details.Namespace,
+		Name:        u.Details.Name,
+		Replicas:    u.Details.Replicas,
+		Strategy:    u.Details.Strategy,
+		MaxReplicas: u.Details.MaxReplicas,
+		Normal:      newReportQuantities(u.NormalResources),
+		Rollout:     newReportQuantities(u.RolloutResources),
+	}
+}
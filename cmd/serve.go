@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/spf13/cobra"
+)
+
+const (
+	serveExample = `    # serve the web ui on port 8080
+    %[1]s serve --listen :8080
+
+    # serve the grpc api on port 9090 in addition
+    %[1]s serve --listen :8080 --grpc-listen :9090`
+
+	// maxManifestBytes limits the size of the manifests pasted or uploaded to the web ui.
+	maxManifestBytes = 10 << 20
+	// readHeaderTimeout protects the server against clients, which never finish their request headers.
+	readHeaderTimeout = 10 * time.Second
+)
+
+//go:embed web
+var webFS embed.FS //nolint:gochecknoglobals // embedded files can only be assigned to globals
+
+// servePage is the data the web ui is rendered with.
+type servePage struct {
+	Manifests string
+	Report    string
+	Quota     string
+	Error     string
+}
+
+// newServeCmd returns a command serving a web ui, which calculates pasted or uploaded manifests.
+func newServeCmd(opts *KuotaCalcOpts) *cobra.Command {
+	var (
+		listen     string
+		grpcListen string
+		cacheSize  int
+	)
+
+	cmd := &cobra.Command{
+		Use:          "serve",
+		Short:        "Serve a web ui calculating the resource quota needs of pasted or uploaded manifests.",
+		Example:      fmt.Sprintf(serveExample, "kuota-calc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			page, err := template.ParseFS(webFS, "web/index.html")
+			if err != nil {
+				return fmt.Errorf("parsing web ui: %w", err)
+			}
+
+			server := &http.Server{
+				Addr:              listen,
+				Handler:           opts.serveHandler(page, newReportCache(cacheSize)),
+				ReadHeaderTimeout: readHeaderTimeout,
+			}
+
+			if grpcListen == "" {
+				_, _ = fmt.Fprintf(opts.Out, "serving the web ui on %s\n", listen)
+
+				return server.ListenAndServe()
+			}
+
+			listener, err := net.Listen("tcp", grpcListen)
+			if err != nil {
+				return fmt.Errorf("listening for the grpc api: %w", err)
+			}
+
+			grpcServer := opts.newGRPCServer()
+
+			// both servers run until either of them fails
+			errs := make(chan error, 2)
+
+			go func() { errs <- grpcServer.Serve(listener) }()
+			go func() { errs <- server.ListenAndServe() }()
+
+			_, _ = fmt.Fprintf(opts.Out, "serving the web ui on %s and the grpc api on %s\n", listen, grpcListen)
+
+			return <-errs
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", ":8080", "address the web ui listens on")
+	cmd.Flags().StringVar(&grpcListen, "grpc-listen", "",
+		"address the grpc api listens on, e.g. :9090. Without it, only the web ui and the REST api are served")
+	cmd.Flags().IntVar(&cacheSize, "cache-size", 100, "number of api reports cached by the hash of their manifests, 0 disables the cache")
+
+	return cmd
+}
+
+// serveHandler serves the web ui and the REST api. The web ui renders the detailed report and ResourceQuotas of
+// posted manifests, the api returns their JSON report. The flags of the command apply to every calculation.
+func (opts *KuotaCalcOpts) serveHandler(page *template.Template, cache *reportCache) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		var data servePage
+
+		if r.Method == http.MethodPost {
+			data = opts.calculatePage(w, r)
+		}
+
+		if err := page.Execute(w, data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("POST /v1/calculate", opts.calculateAPI(cache))
+
+	mux.HandleFunc("GET /v1/openapi.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		spec, err := webFS.ReadFile("web/openapi.yaml")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(spec)
+	})
+
+	return mux
+}
+
+// calculateAPI answers the manifests in the request body, yaml or json, with their JSON report. Reports are cached
+// by the key of their manifests, which is also their ETag.
+func (opts *KuotaCalcOpts) calculateAPI(cache *reportCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		manifests, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxManifestBytes))
+		if err != nil {
+			writeAPIError(w, fmt.Errorf("reading request: %w", err))
+
+			return
+		}
+
+		etag := fmt.Sprintf("%q", opts.reportKey(manifests))
+
+		w.Header().Set("ETag", etag)
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		if report, ok := cache.get(etag); ok {
+			_, _ = w.Write(report)
+
+			return
+		}
+
+		apiOpts := opts.requestOpts(manifests)
+
+		usage, skipped, err := apiOpts.calculateRequest(r.Context())
+		if err != nil {
+			writeAPIError(w, err)
+
+			return
+		}
+
+		report, err := json.Marshal(apiOpts.newReport(usage, skipped))
+		if err != nil {
+			w.Header().Del("ETag")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		report = append(report, '\n')
+		cache.add(etag, report)
+
+		_, _ = w.Write(report)
+	}
+}
+
+// reportKey returns the hash of the manifests, the flags of the server and its version. The flags and the version
+// change the report of the same manifests, e.g. --assume-replicas, so a restarted server must not answer an ETag of
+// a report, which was calculated with other flags, with 304 Not Modified.
+func (opts *KuotaCalcOpts) reportKey(manifests []byte) string {
+	flags := make([]string, 0, len(opts.effectiveFlags))
+	for name, value := range opts.effectiveFlags {
+		flags = append(flags, name+"="+value)
+	}
+
+	slices.Sort(flags)
+
+	if opts.versionInfo != nil {
+		flags = append(flags, "version="+opts.versionInfo.Version+"+"+opts.versionInfo.Commit)
+	}
+
+	hash := sha256.New()
+
+	for _, flag := range flags {
+		fmt.Fprintln(hash, flag)
+	}
+
+	// the manifests come last, after one line per flag
+	hash.Write(manifests)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeAPIError answers a failed api request. Failed requests have no ETag, they are never cached.
+func writeAPIError(w http.ResponseWriter, err error) {
+	w.Header().Del("ETag")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(apiError{Error: err.Error()})
+}
+
+// apiError is the body of a failed api request.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// calculatePage calculates the manifests posted to the web ui.
+func (opts *KuotaCalcOpts) calculatePage(w http.ResponseWriter, r *http.Request) servePage {
+	r.Body = http.MaxBytesReader(w, r.Body, maxManifestBytes)
+
+	manifests, err := postedManifests(r)
+	if err != nil {
+		return servePage{Error: err.Error()}
+	}
+
+	data := servePage{Manifests: manifests}
+
+	pageOpts := opts.requestOpts([]byte(manifests))
+
+	usage, skipped, err := pageOpts.calculateRequest(r.Context())
+	if err != nil {
+		data.Error = err.Error()
+
+		return data
+	}
+
+	var report, quota bytes.Buffer
+
+	pageOpts.Out, pageOpts.detailed = &report, true
+
+	if err := pageOpts.printOutput("", usage, skipped); err != nil {
+		data.Error = err.Error()
+
+		return data
+	}
+
+	pageOpts.Out = &quota
+
+	if err := pageOpts.printOutput(outputQuota, usage, skipped); err != nil {
+		data.Error = err.Error()
+
+		return data
+	}
+
+	data.Report, data.Quota = report.String(), quota.String()
+
+	return data
+}
+
+// requestOpts returns the options calculating the manifests of a single request. Every request works on its own
+// copy of the options, so concurrent requests don't share their input, output and traces. The manifests of a request
+// are no runs of the own deployments: they aren't read from files or the cluster, recorded in the history, written
+// to files or passed through, and they don't fail on --fail-if-exceeds. The kinds of posted manifests must not select
+// an executable run on the server, so external calculators are always disabled.
+func (opts *KuotaCalcOpts) requestOpts(manifests []byte) *KuotaCalcOpts {
+	requestOpts := *opts
+	requestOpts.In, requestOpts.Out = bytes.NewReader(manifests), io.Discard
+	requestOpts.historyDB, requestOpts.outputDir, requestOpts.outputFile, requestOpts.ci = "", "", "", false
+	requestOpts.tee, requestOpts.failIfExceeds = false, ""
+	requestOpts.execCalculators = false
+	requestOpts.traces = nil
+
+	if opts.trace {
+		requestOpts.traces = newDocumentTraces()
+	}
+
+	return &requestOpts
+}
+
+// calculateRequest calculates the manifests of a request. The web ui and the api share it, so both apply the same
+// flags to the calculation and the report.
+func (opts *KuotaCalcOpts) calculateRequest(ctx context.Context) ([]*calc.ResourceUsage, skippedResources, error) {
+	if err := opts.parseReportFlags(); err != nil {
+		return nil, nil, err
+	}
+
+	if opts.quota != "" {
+		var err error
+
+		opts.existingQuotas, err = opts.loadExistingQuotas(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return opts.calculate(ctx)
+}
+
+// postedManifests returns the uploaded file, or the pasted manifests if no file was uploaded.
+func postedManifests(r *http.Request) (string, error) {
+	if err := r.ParseMultipartForm(maxManifestBytes); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return "", fmt.Errorf("reading form: %w", err)
+	}
+
+	file, _, err := r.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return r.FormValue("manifests"), nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("reading uploaded file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("reading uploaded file: %w", err)
+	}
+
+	return string(data), nil
+}
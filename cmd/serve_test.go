@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+var apiDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: team-a
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi`
+
+// newTestOpts returns the options of the command with the defaults of its flags, reading the input.
+func newTestOpts(input string) *KuotaCalcOpts {
+	return &KuotaCalcOpts{
+		IOStreams: genericclioptions.IOStreams{
+			In:     strings.NewReader(input),
+			Out:    &bytes.Buffer{},
+			ErrOut: io.Discard,
+		},
+		configFlags:      genericclioptions.NewConfigFlags(true),
+		maxRollouts:      -1,
+		assumeReplicas:   1,
+		hpaNormal:        string(calc.HPASpecReplicas),
+		hpaPeak:          string(calc.HPASpecReplicas),
+		vpaMode:          string(calc.VPATarget),
+		platform:         "kubernetes",
+		defaultNamespace: "default",
+		podPhases:        calc.DefaultPodPhases,
+		outputFormats:    outputText,
+	}
+}
+
+func postManifests(handler http.Handler, manifests, etag string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/calculate", strings.NewReader(manifests))
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	return response
+}
+
+func TestCalculateAPI(t *testing.T) {
+	r := require.New(t)
+
+	cache := newReportCache(10)
+	handler := newTestOpts("").calculateAPI(cache)
+
+	response := postManifests(handler, apiDeployment, "")
+	r.Equal(http.StatusOK, response.Code)
+	r.Contains(response.Body.String(), `"name":"api"`)
+
+	etag := response.Header().Get("ETag")
+	r.NotEmpty(etag)
+
+	cached, ok := cache.get(etag)
+	r.True(ok)
+	r.Equal(response.Body.Bytes(), cached)
+
+	t.Run("not modified", func(t *testing.T) {
+		r := require.New(t)
+
+		response := postManifests(handler, apiDeployment, etag)
+		r.Equal(http.StatusNotModified, response.Code)
+		r.Empty(response.Body.String())
+	})
+
+	t.Run("cache hit", func(t *testing.T) {
+		r := require.New(t)
+
+		// a report served from the cache isn't calculated again
+		cache.reports[etag] = []byte("cached\n")
+
+		response := postManifests(handler, apiDeployment, "")
+		r.Equal(http.StatusOK, response.Code)
+		r.Equal("cached\n", response.Body.String())
+		r.Equal(etag, response.Header().Get("ETag"))
+	})
+
+	t.Run("errors aren't cached", func(t *testing.T) {
+		r := require.New(t)
+
+		response := postManifests(handler, "kind: [", "")
+		r.Equal(http.StatusBadRequest, response.Code)
+		r.Empty(response.Header().Get("ETag"))
+		r.Contains(response.Body.String(), `"error"`)
+		r.Len(cache.reports, 1)
+	})
+}
+
+func TestReportKey(t *testing.T) {
+	r := require.New(t)
+
+	opts := newTestOpts("")
+	opts.effectiveFlags = map[string]string{"assume-replicas": "1"}
+	key := opts.reportKey([]byte(apiDeployment))
+
+	r.Equal(key, opts.reportKey([]byte(apiDeployment)))
+	r.NotEqual(key, opts.reportKey([]byte(apiDeployment+"\n")))
+
+	opts.effectiveFlags["assume-replicas"] = "3"
+	r.NotEqual(key, opts.reportKey([]byte(apiDeployment)))
+
+	opts.effectiveFlags["assume-replicas"] = "1"
+	opts.versionInfo = &Version{Version: "v1.2.3"}
+	r.NotEqual(key, opts.reportKey([]byte(apiDeployment)))
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+)
+
+// skipReason describes why a resource isn't included in the total.
+type skipReason string
+
+const (
+	skipUnsupported  skipReason = "unsupported"
+	skipZeroReplicas skipReason = "zero replicas"
+	skipSuspended    skipReason = "suspended"
+)
+
+type skippedResource struct {
+	version string
+	kind    string
+	reason  skipReason
+}
+
+// skippedResources counts the skipped resources per version, kind and reason.
+type skippedResources map[skippedResource]int
+
+func (s skippedResources) add(version, kind string, reason skipReason) {
+	s[skippedResource{version: version, kind: kind, reason: reason}]++
+}
+
+// sorted returns the skipped resources sorted by version, kind and reason.
+func (s skippedResources) sorted() []skippedResource {
+	resources := make([]skippedResource, 0, len(s))
+	for resource := range s {
+		resources = append(resources, resource)
+	}
+
+	slices.SortFunc(resources, func(a, b skippedResource) int {
+		return cmp.Or(cmp.Compare(a.version, b.version), cmp.Compare(a.kind, b.kind), cmp.Compare(a.reason, b.reason))
+	})
+
+	return resources
+}
+
+func (opts *KuotaCalcOpts) printSkipped(skipped skippedResources) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	resources := skipped.sorted()
+
+	_, _ = fmt.Fprintf(opts.Out, "\nSkipped resources, which are not included in the total\n")
+
+	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(w, "Version\tKind\tReason\tCount\t\n")
+
+	for _, resource := range resources {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t\n", resource.version, resource.kind, resource.reason, skipped[resource])
+	}
+
+	if err := w.Flush(); err != nil {
+		_, _ = fmt.Fprintf(opts.Out, "printing skipped resources to tabwriter failed: %v\n", err)
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/spf13/cobra"
+)
+
+const (
+	supportedExample = `    # list the kinds kuota-calc calculates
+    %[1]s supported
+
+    # fail a script, if deployments aren't supported
+    %[1]s supported -o json | jq -e '.[] | select(.kind == "Deployment")'`
+
+	// supportedAnyVersion is the version of the kinds of external calculators, which calculate every version.
+	supportedAnyVersion = "*"
+)
+
+// supportedKind is a kind kuota-calc calculates, as printed by the supported command.
+type supportedKind struct {
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Source is builtin, registered for a calculator registered at compile time or plugin for an external calculator.
+	Source string `json:"source"`
+	// Calculator is the path of the external calculator.
+	Calculator string `json:"calculator,omitempty"`
+}
+
+// newSupportedCmd returns a command listing the kinds kuota-calc calculates.
+func newSupportedCmd(opts *KuotaCalcOpts) *cobra.Command {
+	return &cobra.Command{
+		Use:          "supported",
+		Short:        "List the kinds kuota-calc calculates, including registered calculators and, with --external-calculators, plugins on the PATH.",
+		Example:      fmt.Sprintf(supportedExample, "kuota-calc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return opts.printSupported()
+		},
+	}
+}
+
+func (opts *KuotaCalcOpts) printSupported() error {
+	kinds := supportedKinds(opts.execCalculators)
+
+	switch opts.output {
+	case "", outputText:
+	case outputJSON:
+		data, err := json.MarshalIndent(kinds, "", "  ")
+		if err != nil {
+			return fmt.Errorf("printing supported kinds: %w", err)
+		}
+
+		_, _ = fmt.Fprintf(opts.Out, "%s\n", data)
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q for the supported kinds, use %s or %s", opts.output, outputText, outputJSON)
+	}
+
+	w := tabwriter.NewWriter(opts.Out, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(w, "Version\tKind\tSource\tCalculator\n")
+
+	for _, k := range kinds {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.Version, k.Kind, k.Source, k.Calculator)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("printing supported kinds to tabwriter failed: %w", err)
+	}
+
+	return nil
+}
+
+// supportedKinds returns the built-in kinds, the kinds of the registered calculators and, if they are enabled, the
+// kinds of the external calculators on the PATH, which aren't already calculated by kuota-calc itself.
+func supportedKinds(external bool) []supportedKind {
+	var kinds []supportedKind
+
+	for _, k := range calc.SupportedKinds() {
+		source := "builtin"
+		if !k.Builtin {
+			source = "registered"
+		}
+
+		gvk := k.GroupVersionKind
+		kinds = append(kinds, supportedKind{Version: gvk.GroupVersion().String(), Kind: gvk.Kind, Source: source})
+	}
+
+	if !external {
+		return kinds
+	}
+
+	for _, plugin := range externalCalculators() {
+		kind := strings.TrimPrefix(filepath.Base(plugin), externalCalculatorPrefix)
+
+		// external calculators are matched by the lowercase kind and only used for kinds kuota-calc doesn't calculate
+		if slices.ContainsFunc(kinds, func(k supportedKind) bool { return strings.ToLower(k.Kind) == kind }) {
+			continue
+		}
+
+		kinds = append(kinds, supportedKind{Version: supportedAnyVersion, Kind: kind, Source: "plugin", Calculator: plugin})
+	}
+
+	return kinds
+}
+
+// externalCalculators returns the paths of the external calculators on the PATH. Like exec.LookPath, the first
+// executable of a name wins.
+func externalCalculators() []string {
+	var (
+		paths []string
+		seen  = map[string]bool{}
+	)
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, externalCalculatorPrefix) || seen[name] {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+				continue
+			}
+
+			seen[name] = true
+
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+
+	return paths
+}
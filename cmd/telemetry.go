@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// instrumentationName is the name of the tracer and the meter of kuota-calc.
+const instrumentationName = "github.com/druppelt/kuota-calc"
+
+// telemetry exports the traces and metrics of kuota-calc over OTLP. Without it, the global providers of
+// OpenTelemetry don't record anything.
+type telemetry struct {
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+}
+
+// startTelemetry exports traces and metrics over OTLP/HTTP. The exporters are configured by the standard
+// OTEL_EXPORTER_OTLP_* environment variables.
+func startTelemetry(ctx context.Context) (*telemetry, error) {
+	traceExporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating otlp trace exporter: %w", err)
+	}
+
+	metricExporter, err := otlpmetrichttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating otlp metric exporter: %w", err)
+	}
+
+	t := &telemetry{
+		tracerProvider: sdktrace.NewTracerProvider(sdktrace.WithBatcher(traceExporter)),
+		meterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter))),
+	}
+
+	otel.SetTracerProvider(t.tracerProvider)
+	otel.SetMeterProvider(t.meterProvider)
+
+	return t, nil
+}
+
+// shutdown flushes the pending traces and metrics.
+func (t *telemetry) shutdown(ctx context.Context) error {
+	return errors.Join(t.tracerProvider.Shutdown(ctx), t.meterProvider.Shutdown(ctx))
+}
+
+// stopTelemetry flushes the telemetry, if it was started.
+func (opts *KuotaCalcOpts) stopTelemetry() {
+	if opts.telemetry == nil {
+		return
+	}
+
+	if err := opts.telemetry.shutdown(context.Background()); err != nil {
+		_, _ = fmt.Fprintf(opts.ErrOut, "exporting telemetry failed: %v\n", err)
+	}
+
+	opts.telemetry = nil
+}
+
+// recordTotals records the totals of each namespace as gauges.
+func (opts *KuotaCalcOpts) recordTotals(ctx context.Context, usage []*calc.ResourceUsage) error {
+	meter := otel.Meter(instrumentationName)
+
+	cpu, err := meter.Float64Gauge("kuota_calc.total.cpu", metric.WithUnit("{cpu}"),
+		metric.WithDescription("calculated cpu of the namespace"))
+	if err != nil {
+		return fmt.Errorf("creating cpu gauge: %w", err)
+	}
+
+	memory, err := meter.Int64Gauge("kuota_calc.total.memory", metric.WithUnit("By"),
+		metric.WithDescription("calculated memory of the namespace"))
+	if err != nil {
+		return fmt.Errorf("creating memory gauge: %w", err)
+	}
+
+	ephemeralStorage, err := meter.Int64Gauge("kuota_calc.total.ephemeral_storage", metric.WithUnit("By"),
+		metric.WithDescription("calculated ephemeral storage of the namespace"))
+	if err != nil {
+		return fmt.Errorf("creating ephemeral storage gauge: %w", err)
+	}
+
+	namespaceKey, err := calc.GroupKey(calc.GroupByNamespace)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range calc.GroupBy(usage, namespaceKey) {
+		total := calc.Total(opts.maxRollouts, group.Usage).AtUtilization(opts.utilization)
+
+		requests := metric.WithAttributes(attribute.String("k8s.namespace.name", group.Key), attribute.String("type", "request"))
+		limits := metric.WithAttributes(attribute.String("k8s.namespace.name", group.Key), attribute.String("type", "limit"))
+
+		cpu.Record(ctx, total.CPUMin.AsApproximateFloat64(), requests)
+		cpu.Record(ctx, total.CPUMax.AsApproximateFloat64(), limits)
+		memory.Record(ctx, total.MemoryMin.Value(), requests)
+		memory.Record(ctx, total.MemoryMax.Value(), limits)
+		ephemeralStorage.Record(ctx, total.EphemeralStorageMin.Value(), requests)
+		ephemeralStorage.Record(ctx, total.EphemeralStorageMax.Value(), limits)
+	}
+
+	return nil
+}
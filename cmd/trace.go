@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// slowestDocuments is the number of documents listed in the summary of --trace.
+const slowestDocuments = 5
+
+// documentTrace records the size of a yaml document of the input and the time spent on it.
+type documentTrace struct {
+	// index is the position of the document in the input, starting at 1.
+	index     int
+	bytes     int
+	kind      string
+	namespace string
+	name      string
+	decode    time.Duration
+	calculate time.Duration
+}
+
+func (d *documentTrace) total() time.Duration {
+	return d.decode + d.calculate
+}
+
+// documentTraces records the documents of the input with --trace. A nil documentTraces records nothing.
+type documentTraces struct {
+	documents []*documentTrace
+	byObject  map[runtime.Object]*documentTrace
+}
+
+func newDocumentTraces() *documentTraces {
+	return &documentTraces{byObject: map[runtime.Object]*documentTrace{}}
+}
+
+// addDocument records a decoded document.
+func (t *documentTraces) addDocument(object runtime.Object, bytes int, decode time.Duration) {
+	if t == nil {
+		return
+	}
+
+	d := &documentTrace{
+		index:  len(t.documents) + 1,
+		bytes:  bytes,
+		kind:   object.GetObjectKind().GroupVersionKind().Kind,
+		decode: decode,
+	}
+
+	if accessor, err := meta.Accessor(object); err == nil {
+		d.namespace, d.name = accessor.GetNamespace(), accessor.GetName()
+	}
+
+	t.documents = append(t.documents, d)
+	t.byObject[object] = d
+}
+
+// addCalculation records the time the calculation of the object of a document took.
+func (t *documentTraces) addCalculation(object runtime.Object, calculate time.Duration) {
+	if t == nil {
+		return
+	}
+
+	if d, ok := t.byObject[object]; ok {
+		d.calculate += calculate
+	}
+}
+
+// printTraces prints the size, decode and calculation time of each document of the input and the slowest documents.
+// The traces are printed to stderr, so they don't mix with the JSON or quota output.
+func (opts *KuotaCalcOpts) printTraces() {
+	if opts.traces == nil {
+		return
+	}
+
+	_, _ = fmt.Fprintf(opts.ErrOut, "\nTime spent on each document\n")
+	opts.printDocumentTraces(opts.traces.documents)
+
+	slowest := slices.Clone(opts.traces.documents)
+	slices.SortStableFunc(slowest, func(a, b *documentTrace) int {
+		return cmp.Compare(b.total(), a.total())
+	})
+
+	_, _ = fmt.Fprintf(opts.ErrOut, "\nSlowest documents\n")
+	opts.printDocumentTraces(slowest[:min(len(slowest), slowestDocuments)])
+}
+
+func (opts *KuotaCalcOpts) printDocumentTraces(documents []*documentTrace) {
+	w := tabwriter.NewWriter(opts.ErrOut, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(w, "Document\tKind\tNamespace\tName\tBytes\tDecode\tCalculate\tTotal\t\n")
+
+	for _, d := range documents {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t\n",
+			d.index, d.kind, d.namespace, d.name, d.bytes,
+			d.decode.Round(time.Microsecond), d.calculate.Round(time.Microsecond), d.total().Round(time.Microsecond))
+	}
+
+	if err := w.Flush(); err != nil {
+		_, _ = fmt.Fprintf(opts.ErrOut, "printing document traces to tabwriter failed: %v\n", err)
+	}
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/druppelt/kuota-calc/internal/calc"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	tuiExample = `    # browse the resources of the manifests interactively
+    cat deployment.yaml | %[1]s tui`
+
+	tuiHelp = "up/down: select  s: sort  v: normal/rollout  +/-: max rollouts  ]/[: replicas  q: quit"
+)
+
+// tuiColumns are the columns the workloads can be sorted by.
+func tuiColumns() []string {
+	return []string{"Name", "CPURequest", "CPULimit", "MemoryRequest", "MemoryLimit"}
+}
+
+// newTUICmd returns a command to browse the calculation interactively.
+func newTUICmd(opts *KuotaCalcOpts) *cobra.Command {
+	return &cobra.Command{
+		Use:          "tui",
+		Short:        "Browse the resource quota needs of your deployment(s) interactively.",
+		Example:      fmt.Sprintf(tuiExample, "kuota-calc"),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return opts.runTUI()
+		},
+	}
+}
+
+func (opts *KuotaCalcOpts) runTUI() error {
+	calcOpts, err := opts.calcOptions()
+	if err != nil {
+		return err
+	}
+
+	if opts.targetUtilization != "" {
+		opts.utilization, err = calc.ParseTargetUtilization(opts.targetUtilization)
+		if err != nil {
+			return err
+		}
+	}
+
+	objects, err := opts.readWorkloads(&calcOpts, skippedResources{})
+	if err != nil {
+		return err
+	}
+
+	model := tuiModel{
+		opts:        opts,
+		calcOpts:    calcOpts,
+		maxRollouts: opts.maxRollouts,
+	}
+
+	for _, object := range objects {
+		usage, err := opts.calculateObject(context.Background(), object, calcOpts)
+		if err != nil {
+			if errors.Is(err, calc.ErrResourceNotSupported) {
+				continue
+			}
+
+			return err
+		}
+
+		model.workloads = append(model.workloads, &tuiWorkload{object: object, usage: usage})
+	}
+
+	model.sort()
+
+	// the manifests are usually piped in, so the keys are read from the terminal
+	_, err = tea.NewProgram(model, tea.WithInputTTY(), tea.WithOutput(opts.Out), tea.WithAltScreen()).Run()
+
+	return err
+}
+
+// tuiWorkload is a workload shown in the tui, usage is recalculated if its replicas are overridden.
+type tuiWorkload struct {
+	object runtime.Object
+	usage  *calc.ResourceUsage
+}
+
+type tuiModel struct {
+	opts        *KuotaCalcOpts
+	calcOpts    calc.Options
+	workloads   []*tuiWorkload
+	cursor      int
+	sortColumn  int
+	rollout     bool
+	maxRollouts int
+	err         error
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	m.err = nil
+
+	switch key.String() {
+	case "q", "ctrl+c", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(m.workloads)-1)
+	case "s":
+		m.sortColumn = (m.sortColumn + 1) % len(tuiColumns())
+		m.sort()
+	case "v":
+		m.rollout = !m.rollout
+	case "+":
+		m.maxRollouts++
+	case "-":
+		// -1 is a simultaneous rollout of all resources
+		m.maxRollouts = max(m.maxRollouts-1, -1)
+	case "]":
+		m.scale(1)
+	case "[":
+		m.scale(-1)
+	}
+
+	return m, nil
+}
+
+// scale changes the replicas of the selected workload and recalculates it.
+func (m *tuiModel) scale(delta int32) {
+	if len(m.workloads) == 0 {
+		return
+	}
+
+	workload := m.workloads[m.cursor]
+
+	replicas := max(workload.usage.Details.Replicas+delta, 0)
+	if workload.usage.Details.Autoscaler != "" {
+		replicas = max(workload.usage.Details.NormalReplicas+delta, 0)
+	}
+
+	scaled, ok := calc.Scale(workload.object, replicas)
+	if !ok {
+		m.err = fmt.Errorf("%s %s has no replicas", workload.usage.Details.Kind, workload.usage.Details.Name)
+
+		return
+	}
+
+	usage, err := calc.ResourceQuotaFromObject(scaled, m.calcOpts)
+	if err != nil {
+		m.err = err
+
+		return
+	}
+
+	workload.object, workload.usage = scaled, usage
+}
+
+// sort sorts the workloads by the sort column, names ascending and quantities descending.
+func (m *tuiModel) sort() {
+	slices.SortStableFunc(m.workloads, func(a, b *tuiWorkload) int {
+		if m.sortColumn == 0 {
+			return strings.Compare(a.usage.Details.Name, b.usage.Details.Name)
+		}
+
+		quantityA, quantityB := m.quantity(a, m.sortColumn), m.quantity(b, m.sortColumn)
+
+		return quantityB.Cmp(quantityA)
+	})
+}
+
+// resources returns the resources of the workload in the current view.
+func (m tuiModel) resources(workload *tuiWorkload) calc.Resources {
+	if m.rollout {
+		return workload.usage.RolloutResources
+	}
+
+	return workload.usage.NormalResources
+}
+
+// quantity returns the quantity of a quantity column.
+func (m tuiModel) quantity(workload *tuiWorkload, column int) resource.Quantity {
+	resources := m.resources(workload)
+
+	return []resource.Quantity{{}, resources.CPUMin, resources.CPUMax, resources.MemoryMin, resources.MemoryMax}[column]
+}
+
+func (m tuiModel) View() string {
+	var b strings.Builder
+
+	view := "normal"
+	if m.rollout {
+		view = "rollout"
+	}
+
+	rollouts := "all"
+	if m.maxRollouts > -1 {
+		rollouts = strconv.Itoa(m.maxRollouts)
+	}
+
+	_, _ = fmt.Fprintf(&b, "%s\nview: %s  sorted by: %s  max rollouts: %s\n\n", tuiHelp, view, tuiColumns()[m.sortColumn], rollouts)
+
+	w := tabwriter.NewWriter(&b, 0, 0, 4, ' ', tabwriter.TabIndent)
+
+	_, _ = fmt.Fprintf(w, " \tKind\tNamespace\tName\tReplicas\tCPURequest\tCPULimit\tMemoryRequest\tMemoryLimit\t\n")
+
+	usage := make([]*calc.ResourceUsage, 0, len(m.workloads))
+
+	for i, workload := range m.workloads {
+		usage = append(usage, workload.usage)
+
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+
+		resources := m.resources(workload)
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t\n",
+			cursor,
+			workload.usage.Details.Kind,
+			workload.usage.Details.Namespace,
+			workload.usage.Details.Name,
+			workload.usage.Details.Replicas,
+			resources.CPUMin.String(),
+			resources.CPUMax.String(),
+			resources.MemoryMin.String(),
+			resources.MemoryMax.String(),
+		)
+	}
+
+	_ = w.Flush()
+
+	// the normal view totals the normal resources, which is a rollout of no resources
+	maxRollouts := m.maxRollouts
+	if !m.rollout {
+		maxRollouts = 0
+	}
+
+	total := calc.Total(maxRollouts, usage).AtUtilization(m.opts.utilization)
+
+	_, _ = fmt.Fprintf(&b, "\nTotal\nCPU Request: %s\nCPU Limit: %s\nMemory Request: %s\nMemory Limit: %s\n",
+		total.CPUMin.String(),
+		total.CPUMax.String(),
+		total.MemoryMin.String(),
+		total.MemoryMax.String(),
+	)
+
+	if m.err != nil {
+		_, _ = fmt.Fprintf(&b, "\nError: %s\n", m.err)
+	}
+
+	return b.String()
+}
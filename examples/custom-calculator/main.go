@@ -0,0 +1,63 @@
+// Package main is an example of kuota-calc with a calculator for a custom resource. The Worker kind of example.com
+// runs spec.replicas pods of spec.template and replaces all of them at once on a rollout.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/druppelt/kuota-calc/cmd"
+	"github.com/druppelt/kuota-calc/extension"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type worker struct {
+	Spec struct {
+		Replicas *int32             `json:"replicas"`
+		Template v1.PodTemplateSpec `json:"template"`
+	} `json:"spec"`
+}
+
+func init() {
+	extension.Register(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Worker"}, calculateWorker)
+}
+
+func calculateWorker(object *unstructured.Unstructured, opts extension.Options) (*extension.ResourceUsage, error) {
+	var w worker
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &w); err != nil {
+		return nil, fmt.Errorf("worker: %s: %w", object.GetName(), err)
+	}
+
+	replicas := int32(1)
+	if w.Spec.Replicas != nil {
+		replicas = *w.Spec.Replicas
+	}
+
+	pod := extension.PodResourcesOf(&w.Spec.Template.Spec, opts)
+
+	return &extension.ResourceUsage{
+		NormalResources:  pod.Containers.MulInt32(replicas),
+		RolloutResources: pod.MaxResources.MulInt32(replicas),
+		Details: extension.Details{
+			Version:     object.GetAPIVersion(),
+			Kind:        object.GetKind(),
+			Namespace:   object.GetNamespace(),
+			Name:        object.GetName(),
+			Strategy:    "Recreate",
+			Replicas:    replicas,
+			MaxReplicas: replicas,
+		},
+	}, nil
+}
+
+func main() {
+	root := cmd.NewKuotaCalcCmd(&cmd.Version{Version: "custom-calculator"},
+		genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err := root.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/druppelt/kuota-calc/calctest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+func TestWorkerReport(t *testing.T) {
+	data, err := os.ReadFile("worker.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worker := &unstructured.Unstructured{}
+	if err := sigsyaml.Unmarshal(data, &worker.Object); err != nil {
+		t.Fatal(err)
+	}
+
+	web := calctest.Deployment("default", "web", 2, calctest.PodTemplate(
+		calctest.Container("web", calctest.Resources("250m", "256Mi"), calctest.Resources("", "512Mi")),
+	))
+
+	calctest.AssertGolden(t, "testdata/report.json", calctest.Report(t, calctest.Manifest(t, web, worker)))
+}
@@ -0,0 +1,45 @@
+// Package extension allows adding calculators for kinds kuota-calc doesn't support itself, e.g. the custom resources
+// of an organization. Calculators are registered at compile time: a custom main package registers them in an init
+// function and runs the kuota-calc command, see examples/custom-calculator.
+package extension
+
+import (
+	"github.com/druppelt/kuota-calc/calc"
+	internal "github.com/druppelt/kuota-calc/internal/calc"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type (
+	// Calculator calculates the resource usage of a kind from its unstructured object.
+	Calculator = internal.Calculator
+	// Handler calculates the resource usage of a kind from its decoded object. Kinds known to the bundled
+	// kubernetes and openshift api are passed typed, others as *runtime.Unknown.
+	Handler = internal.Handler
+	// Options are the options of the calculation, see the calc package for the types of their fields.
+	Options = calc.Options
+	// ResourceUsage is the calculated resource usage of an object.
+	ResourceUsage = calc.ResourceUsage
+	// Resources are the requests and limits of cpu, memory and ephemeral storage.
+	Resources = calc.Resources
+	// PodResources are the resources of a single pod.
+	PodResources = calc.PodResources
+	// Details describe the calculated object in the detailed output.
+	Details = calc.Details
+)
+
+// Register registers the calculator of a kind. It panics, if a calculator of the kind is already registered.
+func Register(gvk schema.GroupVersionKind, calculator Calculator) {
+	internal.RegisterCalculator(gvk, calculator)
+}
+
+// RegisterHandler registers the handler of a kind, e.g. for a kind of the kubernetes api kuota-calc doesn't calculate
+// itself. It panics, if the kind is calculated by kuota-calc itself or a handler of the kind is already registered.
+func RegisterHandler(gvk schema.GroupVersionKind, handler Handler) {
+	internal.RegisterHandler(gvk, handler)
+}
+
+// PodResourcesOf returns the resources of a single pod, for calculators of kinds with a pod template.
+func PodResourcesOf(podSpec *v1.PodSpec, opts Options) *PodResources {
+	return internal.CalcPodResources(podSpec, opts)
+}
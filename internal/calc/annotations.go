@@ -0,0 +1,81 @@
+package calc
+
+import (
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotations on workloads, which let manifest authors encode their sizing intent inline.
+const (
+	// AnnotationReplicas overrides the replicas of a Deployment, DeploymentConfig or StatefulSet, e.g. with the peak
+	// replicas of an autoscaler.
+	AnnotationReplicas = "kuota-calc.io/replicas"
+	// AnnotationExtraCPU adds cpu to the requests and limits of each pod, e.g. for sidecars injected at admission.
+	AnnotationExtraCPU = "kuota-calc.io/extra-cpu"
+	// AnnotationExtraMemory adds memory to the requests and limits of each pod.
+	AnnotationExtraMemory = "kuota-calc.io/extra-memory"
+)
+
+// workloadReplicas returns the replicas of a workload: the replicas of its replicas annotation, its spec replicas or
+// the assumed replicas, in this order. The second return value reports whether the replicas were assumed, the third
+// one whether they were annotated. Invalid annotations are ignored with a warning.
+func (o Options) workloadReplicas(meta metav1.ObjectMeta, specReplicas *int32) (int32, bool, bool) {
+	if value, ok := meta.Annotations[AnnotationReplicas]; ok {
+		replicas, err := strconv.ParseInt(value, 10, 32)
+		if err == nil && replicas >= 0 {
+			return int32(replicas), false, true
+		}
+
+		log.Warn().Msgf("%s: ignoring invalid annotation %s: %q", meta.Name, AnnotationReplicas, value)
+	}
+
+	replicas, assumed := o.replicas(specReplicas)
+
+	return replicas, assumed, false
+}
+
+// extraResources returns the resources the annotations add to each pod. Invalid annotations are ignored with a warning.
+func extraResources(meta metav1.ObjectMeta) Resources {
+	var r Resources
+
+	for annotation, quantities := range map[string][]*resource.Quantity{
+		AnnotationExtraCPU:    {&r.CPUMin, &r.CPUMax},
+		AnnotationExtraMemory: {&r.MemoryMin, &r.MemoryMax},
+	} {
+		value, ok := meta.Annotations[annotation]
+		if !ok {
+			continue
+		}
+
+		extra, err := resource.ParseQuantity(value)
+		if err != nil || extra.Sign() < 0 {
+			log.Warn().Msgf("%s: ignoring invalid annotation %s: %q", meta.Name, annotation, value)
+
+			continue
+		}
+
+		for _, quantity := range quantities {
+			*quantity = extra.DeepCopy()
+		}
+	}
+
+	return r
+}
+
+// annotatedPodResources returns the resources of a single pod of a workload, including the extra resources of the
+// annotations of the workload and the containers a mesh or the injection rules inject into the pod, whose metadata is
+// given by podMeta.
+func annotatedPodResources(meta, podMeta metav1.ObjectMeta, podSpec *v1.PodSpec, opts Options) *PodResources {
+	r := calcPodResources(opts.Injections.inject(meta.Namespace, podMeta, podSpec), opts)
+
+	// the extra resources and the proxy are needed as long as the pod exists, no matter which of its containers runs
+	extra := extraResources(meta).Add(opts.Mesh.proxyResources(meta.Namespace, podMeta))
+	r.Containers = r.Containers.Add(extra)
+	r.MaxResources = r.MaxResources.Add(extra)
+
+	return r
+}
@@ -0,0 +1,107 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var annotatedDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+  annotations:
+    kuota-calc.io/replicas: "4"
+    kuota-calc.io/extra-cpu: 100m
+    kuota-calc.io/extra-memory: 64Mi
+spec:
+  replicas: 2
+  strategy:
+    type: Recreate
+  template:
+    spec:
+      containers:
+      - name: myapp
+        image: myapp
+        resources:
+          requests:
+            cpu: 250m
+            memory: 64Mi
+          limits:
+            cpu: 500m
+            memory: 128Mi`
+
+var invalidAnnotationsDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+  annotations:
+    kuota-calc.io/replicas: "many"
+    kuota-calc.io/extra-memory: -1Gi
+spec:
+  replicas: 2
+  strategy:
+    type: Recreate
+  template:
+    spec:
+      containers:
+      - name: myapp
+        image: myapp
+        resources:
+          requests:
+            cpu: 250m
+            memory: 64Mi
+          limits:
+            cpu: 500m
+            memory: 128Mi`
+
+func TestAnnotations(t *testing.T) {
+	var tests = []struct {
+		name              string
+		deployment        string
+		replicas          int32
+		replicasAnnotated bool
+		cpuMin            resource.Quantity
+		cpuMax            resource.Quantity
+		memoryMin         resource.Quantity
+		memoryMax         resource.Quantity
+	}{
+		{
+			name:              "annotated",
+			deployment:        annotatedDeployment,
+			replicas:          4,
+			replicasAnnotated: true,
+			cpuMin:            resource.MustParse("1400m"),
+			cpuMax:            resource.MustParse("2400m"),
+			memoryMin:         resource.MustParse("512Mi"),
+			memoryMax:         resource.MustParse("768Mi"),
+		},
+		{
+			name:       "invalid annotations are ignored",
+			deployment: invalidAnnotationsDeployment,
+			replicas:   2,
+			cpuMin:     resource.MustParse("500m"),
+			cpuMax:     resource.MustParse("1"),
+			memoryMin:  resource.MustParse("128Mi"),
+			memoryMax:  resource.MustParse("256Mi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.deployment), Options{})
+			r.NoError(err)
+			r.Equal(test.replicas, usage.Details.Replicas)
+			r.Equal(test.replicasAnnotated, usage.Details.ReplicasAnnotated)
+			AssertEqualQuantities(r, test.cpuMin, usage.RolloutResources.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.cpuMax, usage.RolloutResources.CPUMax, "cpu limit value")
+			AssertEqualQuantities(r, test.memoryMin, usage.RolloutResources.MemoryMin, "memory request value")
+			AssertEqualQuantities(r, test.memoryMax, usage.RolloutResources.MemoryMax, "memory limit value")
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// argoRolloutKind is the Rollout of Argo Rollouts, a Deployment with canary and blue-green strategies.
+// https://argo-rollouts.readthedocs.io/en/stable/features/specification/
+func argoRolloutKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+}
+
+// argoRollout is the part of a Rollout, which kuota-calc calculates. The Argo Rollouts api isn't a dependency of
+// kuota-calc, so the Rollout is decoded from its unstructured content.
+type argoRollout struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              argoRolloutSpec `json:"spec"`
+}
+
+type argoRolloutSpec struct {
+	Replicas        *int32             `json:"replicas,omitempty"`
+	Template        v1.PodTemplateSpec `json:"template"`
+	WorkloadRef     map[string]any     `json:"workloadRef,omitempty"`
+	MinReadySeconds int32              `json:"minReadySeconds,omitempty"`
+	Strategy        struct {
+		Canary    *argoCanaryStrategy    `json:"canary,omitempty"`
+		BlueGreen *argoBlueGreenStrategy `json:"blueGreen,omitempty"`
+	} `json:"strategy"`
+}
+
+type argoCanaryStrategy struct {
+	MaxSurge           *intstr.IntOrString `json:"maxSurge,omitempty"`
+	MaxUnavailable     *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	TrafficRouting     map[string]any      `json:"trafficRouting,omitempty"`
+	DynamicStableScale bool                `json:"dynamicStableScale,omitempty"`
+	Steps              []argoCanaryStep    `json:"steps,omitempty"`
+}
+
+type argoCanaryStep struct {
+	SetWeight      *int32 `json:"setWeight,omitempty"`
+	SetCanaryScale *struct {
+		Weight             *int32 `json:"weight,omitempty"`
+		Replicas           *int32 `json:"replicas,omitempty"`
+		MatchTrafficWeight bool   `json:"matchTrafficWeight,omitempty"`
+	} `json:"setCanaryScale,omitempty"`
+}
+
+type argoBlueGreenStrategy struct {
+	PreviewReplicaCount *int32 `json:"previewReplicaCount,omitempty"`
+}
+
+// errArgoWorkloadRef is returned for Rollouts referencing the pod template of a Deployment, which isn't known to
+// kuota-calc.
+var errArgoWorkloadRef = errors.New("rollouts with a workloadRef aren't supported, the referenced pod template is unknown")
+
+// calculates the cpu/memory resources a single argo rollout needs. Replicas and the canary steps or the blue-green
+// preview are taken into account.
+//
+// A canary without traffic routing is scaled like a deployment with a rolling update. With traffic routing, the
+// stable pods keep running until the canary is promoted, so the canary pods come on top of them, up to all replicas
+// at the promotion. A blue-green rollout starts the preview pods next to the active ones and scales them up to all
+// replicas before the active pods are scaled down. Both double the resources at their peak.
+func argoRolloutResource(object *unstructured.Unstructured, opts Options) (*ResourceUsage, error) {
+	var rollout argoRollout
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &rollout); err != nil {
+		return nil, fmt.Errorf("rollout: %s: decoding: %w", object.GetName(), err)
+	}
+
+	if rollout.Spec.WorkloadRef != nil {
+		return nil, fmt.Errorf("rollout: %s: %w: %w", rollout.Name, errArgoWorkloadRef, ErrResourceNotSupported)
+	}
+
+	replicas, replicasAssumed, replicasAnnotated := opts.workloadReplicas(rollout.ObjectMeta, rollout.Spec.Replicas)
+	normalReplicas, replicas, autoscaler := opts.autoscaledReplicas("Rollout", rollout.ObjectMeta, replicas)
+
+	podResources := annotatedPodResources(rollout.ObjectMeta, rollout.Spec.Template.ObjectMeta, &rollout.Spec.Template.Spec, opts)
+
+	resourceUsage := ResourceUsage{
+		NormalResources: podResources.Containers.MulInt32(normalReplicas),
+		Pod:             podResources,
+		Details: Details{
+			Version:           rollout.APIVersion,
+			Kind:              rollout.Kind,
+			Namespace:         rollout.Namespace,
+			Name:              rollout.Name,
+			PriorityClassName: rollout.Spec.Template.Spec.PriorityClassName,
+			Replicas:          replicas,
+			ReplicasAssumed:   replicasAssumed,
+			ReplicasAnnotated: replicasAnnotated,
+			MaxReplicas:       replicas,
+			Autoscaler:        autoscaler,
+			NormalReplicas:    normalReplicas,
+		},
+	}
+
+	if replicas == 0 {
+		resourceUsage.explainf(opts, "replicas: 0, no pods are running")
+
+		return &resourceUsage, nil
+	}
+
+	resourceUsage.explainReplicas(opts)
+
+	// stable pods keep running during the rollout, new pods might run their init containers
+	var stable, started int32
+
+	switch canary, blueGreen := rollout.Spec.Strategy.Canary, rollout.Spec.Strategy.BlueGreen; {
+	case blueGreen != nil:
+		resourceUsage.Details.Strategy = "BlueGreen"
+		stable, started = replicas, replicas
+
+		resourceUsage.explainf(opts, "BlueGreen: the preview is scaled up to %d replicas before the %d active pods are scaled down",
+			replicas, replicas)
+	case canary != nil && canary.TrafficRouting != nil:
+		resourceUsage.Details.Strategy = "Canary"
+		stable, started = argoCanarySteps(canary, replicas)
+
+		resourceUsage.explainf(opts, "Canary with traffic routing: at most %d stable and %d canary pods at once, "+
+			"including the promotion", stable, started)
+	case canary != nil:
+		resourceUsage.Details.Strategy = "Canary"
+
+		maxSurge, maxUnavailable, err := rollingUpdateBounds(rollout.Name, withDefault(canary.MaxSurge, intstr.FromString("25%")),
+			withDefault(canary.MaxUnavailable, intstr.FromString("25%")), replicas)
+		if err != nil {
+			return nil, fmt.Errorf("rollout: %s: %w", rollout.Name, err)
+		}
+
+		stable, started = replicas-maxUnavailable, maxSurge+maxUnavailable
+		resourceUsage.Details.RollingUpdate = &RollingUpdate{MaxSurge: maxSurge, MaxUnavailable: maxUnavailable}
+
+		resourceUsage.explainf(opts, "Canary: maxSurge -> %d, maxUnavailable -> %d, the steps are scaled within these bounds",
+			maxSurge, maxUnavailable)
+	default:
+		return nil, fmt.Errorf("rollout: %s: %w: neither canary nor blueGreen is set", rollout.Name, ErrInvalidStrategy)
+	}
+
+	resourceUsage.RolloutResources = podResources.Containers.MulInt32(stable).Add(podResources.MaxResources.MulInt32(started))
+	resourceUsage.Details.MaxReplicas = stable + started
+
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "normal = containers * %d", normalReplicas)
+	resourceUsage.explainf(opts, "rollout = containers * %d stable + max * %d new", stable, started)
+
+	if opts.Timeline {
+		resourceUsage.Timeline = staticTimeline(&resourceUsage, newPodTimings(&rollout.Spec.Template.Spec, rollout.Spec.MinReadySeconds, opts))
+	}
+
+	return &resourceUsage, nil
+}
+
+// argoCanarySteps returns the stable and the canary pods at the peak of a canary with traffic routing. The canary is
+// scaled to the weight of the steps, or to the replicas or weight of setCanaryScale, and finally to all replicas at
+// the promotion. The stable pods stay at all replicas, unless dynamicStableScale scales them down to the remaining
+// weight.
+func argoCanarySteps(canary *argoCanaryStrategy, replicas int32) (stable, started int32) {
+	var (
+		weight int32
+		scale  *int32 // the canary pods set by setCanaryScale, until matchTrafficWeight resets it
+	)
+
+	peak := func(canaryPods int32) {
+		stablePods := replicas
+		if canary.DynamicStableScale {
+			stablePods = weightedReplicas(100-weight, replicas)
+		}
+
+		if stablePods+canaryPods > stable+started {
+			stable, started = stablePods, canaryPods
+		}
+	}
+
+	for _, step := range canary.Steps {
+		if step.SetWeight != nil {
+			weight = min(max(*step.SetWeight, 0), 100)
+		}
+
+		if s := step.SetCanaryScale; s != nil {
+			switch {
+			case s.Replicas != nil:
+				scale = s.Replicas
+			case s.Weight != nil:
+				scaled := weightedReplicas(*s.Weight, replicas)
+				scale = &scaled
+			case s.MatchTrafficWeight:
+				scale = nil
+			}
+		}
+
+		if scale != nil {
+			peak(*scale)
+		} else {
+			peak(weightedReplicas(weight, replicas))
+		}
+	}
+
+	// the promotion scales the canary to all replicas, the stable pods are scaled down after the scaleDownDelaySeconds
+	weight = 100
+	peak(replicas)
+
+	return stable, started
+}
+
+// weightedReplicas returns the pods of a weight in percent of the replicas, rounded up like Argo Rollouts does.
+func weightedReplicas(weight, replicas int32) int32 {
+	return int32(math.Ceil(float64(weight) * float64(replicas) / 100))
+}
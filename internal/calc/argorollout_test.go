@@ -0,0 +1,124 @@
+package calc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// argoRolloutManifest is an argo rollout of 4 replicas with the strategy inserted.
+const argoRolloutManifest = `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: myrollout
+  namespace: web
+spec:
+  replicas: 4
+  selector:
+    matchLabels:
+      app: myrollout
+  template:
+    metadata:
+      labels:
+        app: myrollout
+    spec:
+      containers:
+      - name: myapp
+        image: myimage
+        resources:
+          limits:
+            cpu: "1"
+            memory: 1Gi
+          requests:
+            cpu: 500m
+            memory: 512Mi
+  strategy:
+%s`
+
+func TestArgoRollout(t *testing.T) {
+	var tests = []struct {
+		name        string
+		strategy    string
+		cpuMin      resource.Quantity
+		memoryMin   resource.Quantity
+		maxReplicas int32
+		kind        string
+	}{
+		{
+			name:        "blue-green doubles the replicas",
+			strategy:    "    blueGreen:\n      activeService: active\n      previewService: preview",
+			cpuMin:      resource.MustParse("4"),
+			memoryMin:   resource.MustParse("4Gi"),
+			maxReplicas: 8,
+			kind:        "BlueGreen",
+		},
+		{
+			name:        "canary without traffic routing surges like a deployment",
+			strategy:    "    canary:\n      steps:\n      - setWeight: 50\n      - pause: {}",
+			cpuMin:      resource.MustParse("2500m"),
+			memoryMin:   resource.MustParse("2560Mi"),
+			maxReplicas: 5,
+			kind:        "Canary",
+		},
+		{
+			name: "canary with traffic routing is promoted next to the stable pods",
+			strategy: "    canary:\n      trafficRouting:\n        nginx:\n          stableIngress: web\n" +
+				"      steps:\n      - setWeight: 20\n      - pause: {}",
+			cpuMin:      resource.MustParse("4"),
+			memoryMin:   resource.MustParse("4Gi"),
+			maxReplicas: 8,
+			kind:        "Canary",
+		},
+		{
+			name: "canary scale above the replicas",
+			strategy: "    canary:\n      trafficRouting:\n        nginx:\n          stableIngress: web\n" +
+				"      steps:\n      - setCanaryScale:\n          replicas: 6\n      - setWeight: 10\n" +
+				"      - setCanaryScale:\n          matchTrafficWeight: true",
+			cpuMin:      resource.MustParse("5"),
+			memoryMin:   resource.MustParse("5Gi"),
+			maxReplicas: 10,
+			kind:        "Canary",
+		},
+		{
+			name: "dynamic stable scale",
+			strategy: "    canary:\n      dynamicStableScale: true\n      trafficRouting:\n        nginx:\n          stableIngress: web\n" +
+				"      steps:\n      - setWeight: 30",
+			cpuMin:      resource.MustParse("2500m"),
+			memoryMin:   resource.MustParse("2560Mi"),
+			maxReplicas: 5,
+			kind:        "Canary",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(fmt.Sprintf(argoRolloutManifest, test.strategy)), Options{})
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			AssertEqualQuantities(r, resource.MustParse("2"), usage.NormalResources.CPUMin, "normal cpu request value")
+			AssertEqualQuantities(r, test.cpuMin, usage.RolloutResources.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.memoryMin, usage.RolloutResources.MemoryMin, "memory request value")
+			r.Equal(int32(4), usage.Details.Replicas)
+			r.Equal(test.maxReplicas, usage.Details.MaxReplicas)
+			r.Equal(test.kind, usage.Details.Strategy)
+			r.Equal("web", usage.Details.Namespace)
+		})
+	}
+}
+
+func TestArgoRolloutUnsupported(t *testing.T) {
+	r := require.New(t)
+
+	_, err := ResourceQuotaFromYaml([]byte(fmt.Sprintf(argoRolloutManifest,
+		"    canary: {}\n  workloadRef:\n    apiVersion: apps/v1\n    kind: Deployment\n    name: web")), Options{})
+	r.ErrorIs(err, ErrResourceNotSupported)
+
+	_, err = ResourceQuotaFromYaml([]byte(fmt.Sprintf(argoRolloutManifest, "    {}")), Options{})
+	r.ErrorIs(err, ErrInvalidStrategy)
+}
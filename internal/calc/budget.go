@@ -0,0 +1,188 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Budget are the hard limits of a quota, which the total has to fit into, like the ones of a ResourceQuota.
+// Resources missing in the budget aren't limited.
+type Budget v1.ResourceList
+
+// ParseBudget parses a budget in the form requests.cpu=4,limits.memory=16Gi. Like in a ResourceQuota, cpu, memory and
+// ephemeral-storage are short for their requests and extended resources and hugepages are budgeted by their
+// requests, e.g. requests.nvidia.com/gpu=4 or requests.hugepages-2Mi=1Gi.
+func ParseBudget(value string) (Budget, error) {
+	b, err := parseQuotaResources(value, "quota budget")
+
+	return Budget(b), err
+}
+
+// ParseThreshold parses a threshold in the form cpu.request=10,memory.limit=64Gi, the totals must not exceed. Besides
+// <resource>.request and <resource>.limit, the names of ParseBudget are accepted, e.g. requests.cpu=10 or pods=50.
+func ParseThreshold(value string) (Budget, error) {
+	pairs := strings.Split(value, ",")
+
+	for i, pair := range pairs {
+		name, quantity, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+
+		for _, suffix := range []struct{ name, prefix string }{
+			{".request", "requests."},
+			{".requests", "requests."},
+			{".limit", "limits."},
+			{".limits", "limits."},
+		} {
+			if resourceName, found := strings.CutSuffix(name, suffix.name); found {
+				pairs[i] = suffix.prefix + resourceName + "=" + quantity
+
+				break
+			}
+		}
+	}
+
+	b, err := parseQuotaResources(strings.Join(pairs, ","), "threshold")
+
+	return Budget(b), err
+}
+
+// parseQuotaResources parses resources by their name in a ResourceQuota, e.g. requests.cpu=4,limits.memory=16Gi.
+// cpu, memory and ephemeral-storage are short for their requests. what names the parsed value in the errors.
+func parseQuotaResources(value, what string) (v1.ResourceList, error) {
+	b := v1.ResourceList{}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, quantityValue, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid %s %q, expected <resource>=<quantity>", what, pair)
+		}
+
+		resourceName := v1.ResourceName(name)
+
+		switch resourceName {
+		case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage:
+			resourceName = v1.ResourceName("requests." + name)
+		}
+
+		if _, ok := (Resources{}).quotaResources()[resourceName]; !ok && !isExtendedQuotaResource(resourceName) {
+			return nil, fmt.Errorf("unknown resource %q in %s, supported are cpu, memory and ephemeral-storage "+
+				"and their requests.* and limits.*, the requests.* of extended resources and hugepages, "+
+				"requests.storage, persistentvolumeclaims and pods", name, what)
+		}
+
+		quantity, err := resource.ParseQuantity(quantityValue)
+		if err != nil || quantity.Sign() < 0 {
+			return nil, fmt.Errorf("invalid %s %q of %s, must be a positive quantity", what, quantityValue, name)
+		}
+
+		b[resourceName] = quantity
+	}
+
+	return b, nil
+}
+
+// Exceeded returns the resources of the budget, which the given resources exceed, sorted by name.
+func (b Budget) Exceeded(r Resources) []v1.ResourceName {
+	var exceeded []v1.ResourceName
+
+	quantities := r.quotaResources()
+
+	for name, limit := range b {
+		if quantity := quantities[name]; quantity.Cmp(limit) > 0 {
+			exceeded = append(exceeded, name)
+		}
+	}
+
+	slices.Sort(exceeded)
+
+	return exceeded
+}
+
+// QuotaQuantity returns a resource by its name in a ResourceQuota, like the names of a Budget.
+func (r Resources) QuotaQuantity(name v1.ResourceName) resource.Quantity {
+	return r.quotaResources()[name]
+}
+
+// setQuotaResource sets a resource by its name in a ResourceQuota, other names are ignored.
+func (r *Resources) setQuotaResource(name v1.ResourceName, quantity resource.Quantity) {
+	quantities := map[v1.ResourceName]*resource.Quantity{
+		v1.ResourceRequestsCPU:              &r.CPUMin,
+		v1.ResourceLimitsCPU:                &r.CPUMax,
+		v1.ResourceRequestsMemory:           &r.MemoryMin,
+		v1.ResourceLimitsMemory:             &r.MemoryMax,
+		v1.ResourceRequestsEphemeralStorage: &r.EphemeralStorageMin,
+		v1.ResourceLimitsEphemeralStorage:   &r.EphemeralStorageMax,
+		v1.ResourceRequestsStorage:          &r.Storage,
+		v1.ResourcePersistentVolumeClaims:   &r.PersistentVolumeClaims,
+		v1.ResourcePods:                     &r.Pods,
+	}
+
+	if q, ok := quantities[name]; ok {
+		*q = quantity
+
+		return
+	}
+
+	if isExtendedQuotaResource(name) {
+		// the list might be shared with other copies of the resources
+		extended := r.Extended.DeepCopy()
+		if extended == nil {
+			extended = v1.ResourceList{}
+		}
+
+		extended[v1.ResourceName(strings.TrimPrefix(string(name), "requests."))] = quantity
+		r.Extended = extended
+	}
+}
+
+// isExtendedQuotaResource reports whether the resource is the name of an extended resource or hugepages in a
+// ResourceQuota, e.g. requests.nvidia.com/gpu. They can't be overcommitted, so a quota only limits their requests.
+func isExtendedQuotaResource(name v1.ResourceName) bool {
+	resourceName, found := strings.CutPrefix(string(name), "requests.")
+
+	return found && isExtendedOrHugePages(v1.ResourceName(resourceName))
+}
+
+// quotaResources returns the resources by their name in a ResourceQuota.
+func (r Resources) quotaResources() map[v1.ResourceName]resource.Quantity {
+	quantities := map[v1.ResourceName]resource.Quantity{
+		v1.ResourceRequestsCPU:              r.CPUMin,
+		v1.ResourceLimitsCPU:                r.CPUMax,
+		v1.ResourceRequestsMemory:           r.MemoryMin,
+		v1.ResourceLimitsMemory:             r.MemoryMax,
+		v1.ResourceRequestsEphemeralStorage: r.EphemeralStorageMin,
+		v1.ResourceLimitsEphemeralStorage:   r.EphemeralStorageMax,
+		v1.ResourceRequestsStorage:          r.Storage,
+		v1.ResourcePersistentVolumeClaims:   r.PersistentVolumeClaims,
+		v1.ResourcePods:                     r.Pods,
+	}
+
+	for name, quantity := range r.Extended {
+		quantities[v1.ResourceName("requests."+string(name))] = quantity
+	}
+
+	return quantities
+}
+
+// MaxRolloutsWithin returns the largest number of simultaneous rollouts, whose total at the target utilization fits
+// into the budget. It returns -1 if all rollouts fit at once and false, if even running without rollouts doesn't fit.
+func MaxRolloutsWithin(usage []*ResourceUsage, utilization TargetUtilization, budget Budget) (int, bool) {
+	if len(budget.Exceeded(Total(-1, usage).AtUtilization(utilization))) == 0 {
+		return -1, true
+	}
+
+	// the total grows with the number of rollouts, so the first one fitting from the top is the largest
+	for rollouts := len(usage) - 1; rollouts >= 0; rollouts-- {
+		if len(budget.Exceeded(Total(rollouts, usage).AtUtilization(utilization))) == 0 {
+			return rollouts, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,102 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseBudget(t *testing.T) {
+	r := require.New(t)
+
+	budget, err := ParseBudget("cpu=4,limits.memory=16Gi")
+	r.NoError(err)
+	r.Equal(Budget{
+		v1.ResourceRequestsCPU:  resource.MustParse("4"),
+		v1.ResourceLimitsMemory: resource.MustParse("16Gi"),
+	}, budget)
+
+	for _, invalid := range []string{"cpu", "gpu=1", "requests.cpu=-1", "memory=lots"} {
+		_, err := ParseBudget(invalid)
+		r.Error(err, invalid)
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	r := require.New(t)
+
+	threshold, err := ParseThreshold("cpu.request=10,memory.limit=64Gi,nvidia.com/gpu.request=2,pods=50")
+	r.NoError(err)
+	r.Equal(Budget{
+		v1.ResourceRequestsCPU:                     resource.MustParse("10"),
+		v1.ResourceLimitsMemory:                    resource.MustParse("64Gi"),
+		v1.ResourceName("requests.nvidia.com/gpu"): resource.MustParse("2"),
+		v1.ResourcePods:                            resource.MustParse("50"),
+	}, threshold)
+
+	for _, invalid := range []string{"cpu.request", "gpu.limit=1", "cpu.request=-1", "nvidia.com/gpu.limit=1"} {
+		_, err := ParseThreshold(invalid)
+		r.Error(err, invalid)
+	}
+}
+
+func TestBudgetExceeded(t *testing.T) {
+	r := require.New(t)
+
+	budget := Budget{
+		v1.ResourceRequestsCPU:    resource.MustParse("4"),
+		v1.ResourceLimitsCPU:      resource.MustParse("8"),
+		v1.ResourceRequestsMemory: resource.MustParse("8Gi"),
+	}
+
+	r.Empty(budget.Exceeded(Resources{CPUMin: resource.MustParse("4"), MemoryMax: resource.MustParse("64Gi")}))
+	r.Equal([]v1.ResourceName{v1.ResourceLimitsCPU, v1.ResourceRequestsMemory}, budget.Exceeded(Resources{
+		CPUMin:    resource.MustParse("3"),
+		CPUMax:    resource.MustParse("9"),
+		MemoryMin: resource.MustParse("9Gi"),
+	}))
+}
+
+func TestMaxRolloutsWithin(t *testing.T) {
+	usage := []*ResourceUsage{
+		{
+			NormalResources:  Resources{CPUMin: resource.MustParse("1")},
+			RolloutResources: Resources{CPUMin: resource.MustParse("2")},
+		},
+		{
+			NormalResources:  Resources{CPUMin: resource.MustParse("1")},
+			RolloutResources: Resources{CPUMin: resource.MustParse("1500m")},
+		},
+		{
+			NormalResources:  Resources{CPUMin: resource.MustParse("1")},
+			RolloutResources: Resources{CPUMin: resource.MustParse("1200m")},
+		},
+	}
+
+	var tests = []struct {
+		name     string
+		cpu      string
+		utilized TargetUtilization
+		rollouts int
+		fits     bool
+	}{
+		{name: "all", cpu: "5", rollouts: -1, fits: true},
+		{name: "two", cpu: "4600m", rollouts: 2, fits: true},
+		{name: "one", cpu: "4", rollouts: 1, fits: true},
+		{name: "none", cpu: "3", rollouts: 0, fits: true},
+		{name: "too small", cpu: "2"},
+		{name: "at utilization", cpu: "5", utilized: TargetUtilization{CPU: 0.75}, rollouts: 0, fits: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			rollouts, fits := MaxRolloutsWithin(usage, test.utilized, Budget{v1.ResourceRequestsCPU: resource.MustParse(test.cpu)})
+			r.Equal(test.fits, fits)
+			r.Equal(test.rollouts, rollouts)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package calc
+
+import (
+	buildv1 "github.com/openshift/api/build/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// calculates the cpu/memory resources of the build pod of a buildconfig. The builds of a buildconfig run one at a
+// time with the runPolicy Serial or SerialLatestOnly. With Parallel, how many builds run at once depends on how often
+// they are triggered, so a single build is assumed as well.
+func buildConfig(buildConfig buildv1.BuildConfig, opts Options) *ResourceUsage {
+	podSpec := buildPodSpec(&buildConfig.Spec.CommonSpec)
+	podResources := annotatedPodResources(buildConfig.ObjectMeta, metav1.ObjectMeta{}, podSpec, opts)
+
+	resourceUsage := ResourceUsage{
+		NormalResources:  podResources.Containers,
+		RolloutResources: podResources.MaxResources,
+		Pod:              podResources,
+		Details: Details{
+			Version:   buildConfig.APIVersion,
+			Kind:      buildConfig.Kind,
+			Namespace: buildConfig.Namespace,
+			Name:      buildConfig.Name,
+			Strategy:  string(buildConfig.Spec.RunPolicy),
+		},
+	}
+
+	resourceUsage.explainPod(opts, podResources)
+
+	if buildConfig.Spec.RunPolicy == buildv1.BuildRunPolicyParallel {
+		resourceUsage.explainf(opts, "runPolicy Parallel: a single build is assumed, builds triggered at once run in parallel")
+	}
+
+	resourceUsage.explainf(opts, "normal = containers")
+	resourceUsage.explainf(opts, "rollout = max")
+
+	if opts.Timeline {
+		resourceUsage.Timeline = staticTimeline(&resourceUsage, newPodTimings(podSpec, 0, opts))
+	}
+
+	return &resourceUsage
+}
+
+// calculates the cpu/memory resources of the pod of a build. Finished builds don't run a pod anymore, so they don't
+// need any resources.
+func build(build buildv1.Build, opts Options) *ResourceUsage {
+	podSpec := buildPodSpec(&build.Spec.CommonSpec)
+	podResources := annotatedPodResources(build.ObjectMeta, metav1.ObjectMeta{}, podSpec, opts)
+
+	resourceUsage := ResourceUsage{
+		NormalResources:  podResources.Containers,
+		RolloutResources: podResources.MaxResources,
+		Pod:              podResources,
+		Details: Details{
+			Version:   build.APIVersion,
+			Kind:      build.Kind,
+			Namespace: build.Namespace,
+			Name:      build.Name,
+		},
+	}
+
+	if buildFinished(build.Status.Phase) {
+		resourceUsage.NormalResources = Resources{}
+		resourceUsage.RolloutResources = Resources{}
+		resourceUsage.explainf(opts, "phase %s: the build finished, its pod doesn't run anymore", build.Status.Phase)
+
+		return &resourceUsage
+	}
+
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "normal = containers")
+	resourceUsage.explainf(opts, "rollout = max")
+
+	if opts.Timeline {
+		resourceUsage.Timeline = staticTimeline(&resourceUsage, newPodTimings(podSpec, 0, opts))
+	}
+
+	return &resourceUsage
+}
+
+// buildPodSpec returns the pod spec of a build pod. The build container and the init containers, which e.g. clone the
+// source, all get the resources of the build and run one after another, so the pod is calculated as a single container.
+func buildPodSpec(spec *buildv1.CommonSpec) *v1.PodSpec {
+	return &v1.PodSpec{
+		Containers: []v1.Container{{Name: "build", Resources: spec.Resources}},
+	}
+}
+
+// buildFinished returns whether a build in the phase has finished.
+func buildFinished(phase buildv1.BuildPhase) bool {
+	switch phase {
+	case buildv1.BuildPhaseComplete, buildv1.BuildPhaseFailed, buildv1.BuildPhaseError, buildv1.BuildPhaseCancelled:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,121 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var normalBuildConfig = `
+apiVersion: build.openshift.io/v1
+kind: BuildConfig
+metadata:
+  name: app
+  namespace: builds
+spec:
+  runPolicy: Serial
+  resources:
+    requests:
+      cpu: 500m
+      memory: 1Gi
+    limits:
+      cpu: "2"
+      memory: 2Gi
+  source:
+    git:
+      uri: https://example.com/app.git
+  strategy:
+    type: Docker
+    dockerStrategy: {}`
+
+var runningBuild = `
+apiVersion: build.openshift.io/v1
+kind: Build
+metadata:
+  name: app-1
+  namespace: builds
+spec:
+  resources:
+    requests:
+      cpu: 500m
+      memory: 1Gi
+  strategy:
+    type: Docker
+    dockerStrategy: {}
+status:
+  phase: Running`
+
+var completeBuild = `
+apiVersion: build.openshift.io/v1
+kind: Build
+metadata:
+  name: app-2
+  namespace: builds
+spec:
+  resources:
+    requests:
+      cpu: 500m
+      memory: 1Gi
+  strategy:
+    type: Docker
+    dockerStrategy: {}
+status:
+  phase: Complete`
+
+func TestBuild(t *testing.T) {
+	var tests = []struct {
+		name      string
+		build     string
+		kind      string
+		cpuMin    resource.Quantity
+		cpuMax    resource.Quantity
+		memoryMin resource.Quantity
+		memoryMax resource.Quantity
+		pods      resource.Quantity
+	}{
+		{
+			name:      "buildconfig",
+			build:     normalBuildConfig,
+			kind:      "BuildConfig",
+			cpuMin:    resource.MustParse("500m"),
+			cpuMax:    resource.MustParse("2"),
+			memoryMin: resource.MustParse("1Gi"),
+			memoryMax: resource.MustParse("2Gi"),
+			pods:      resource.MustParse("1"),
+		},
+		{
+			name:      "running build",
+			build:     runningBuild,
+			kind:      "Build",
+			cpuMin:    resource.MustParse("500m"),
+			memoryMin: resource.MustParse("1Gi"),
+			pods:      resource.MustParse("1"),
+		},
+		{
+			name:  "finished build",
+			build: completeBuild,
+			kind:  "Build",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.build), Options{})
+			r.NoError(err)
+			r.NotEmpty(usage)
+			r.Equal(test.kind, usage.Details.Kind)
+			r.Equal("builds", usage.Details.Namespace)
+
+			for _, resources := range []Resources{usage.NormalResources, usage.RolloutResources} {
+				AssertEqualQuantities(r, test.cpuMin, resources.CPUMin, "cpu request value")
+				AssertEqualQuantities(r, test.cpuMax, resources.CPUMax, "cpu limit value")
+				AssertEqualQuantities(r, test.memoryMin, resources.MemoryMin, "memory request value")
+				AssertEqualQuantities(r, test.memoryMax, resources.MemoryMax, "memory limit value")
+				AssertEqualQuantities(r, test.pods, resources.Pods, "pods")
+			}
+		})
+	}
+}
@@ -2,18 +2,20 @@
 package calc
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"math"
 	"slices"
 
-	openshiftAppsV1 "github.com/openshift/api/apps/v1"
 	openshiftScheme "github.com/openshift/client-go/apps/clientset/versioned/scheme"
+	openshiftBuildScheme "github.com/openshift/client-go/build/clientset/versioned/scheme"
 	"github.com/rs/zerolog/log"
-	appsv1 "k8s.io/api/apps/v1"
-	batchV1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes/scheme"
 )
@@ -21,6 +23,8 @@ import (
 var (
 	// ErrResourceNotSupported is returned if a k8s resource is not supported by kuota-calc.
 	ErrResourceNotSupported = errors.New("resource not supported")
+	// ErrInvalidStrategy is returned if the rollout strategy of a k8s resource can never make progress.
+	ErrInvalidStrategy = errors.New("invalid rollout strategy")
 )
 
 // CalculationError is an error implementation that includes a k8s Kind/Version.
@@ -49,6 +53,12 @@ type ResourceUsage struct {
 	NormalResources  Resources
 	RolloutResources Resources
 	Details          Details
+	// Pod are the resources of a single pod of the k8s resource. It is nil for workloads scaled to zero.
+	Pod *PodResources
+	// Timeline is only set, if the timeline simulation is enabled in the Options.
+	Timeline []TimelinePoint
+	// Explanation is only set, if explanations are enabled in the Options. It describes the calculation step by step.
+	Explanation []string
 }
 
 // Details contains a few details of a k8s resource, which are needed to generate a detailed resource
@@ -56,19 +66,159 @@ type ResourceUsage struct {
 type Details struct {
 	Version     string
 	Kind        string
+	Namespace   string
 	Name        string
 	Strategy    string
 	Replicas    int32
 	MaxReplicas int32
+	// PriorityClassName is the priority class of the pods of the resource.
+	PriorityClassName string
+	// ReplicasAssumed is true, if the resource doesn't set its replicas and the assumed replicas were used.
+	ReplicasAssumed bool
+	// ReplicasAnnotated is true, if the replicas were taken from the kuota-calc.io/replicas annotation.
+	ReplicasAnnotated bool
+	// Autoscaler is the name of the HorizontalPodAutoscaler scaling the resource, if any. Replicas are the ones used
+	// for the rollout resources then, NormalReplicas the ones used for the normal resources.
+	Autoscaler     string
+	NormalReplicas int32
+	// VerticalAutoscaler is the name of the VerticalPodAutoscaler, whose recommendation replaced the requests of the
+	// pods, if any.
+	VerticalAutoscaler string
+	// Labels and Annotations are the ones of the resource itself, not the ones of its pods.
+	Labels      map[string]string
+	Annotations map[string]string
+	// RollingUpdate is set for Deployments and DeploymentConfigs, which are rolled out by a rolling update.
+	RollingUpdate *RollingUpdate
+	// Heuristic is true, if the resource of an unknown kind was estimated from the pod spec found in it.
+	Heuristic bool
+	// Suspended is true for suspended CronJobs, which don't start any jobs.
+	Suspended bool
+}
+
+// RollingUpdate are the resolved values of a rolling update.
+type RollingUpdate struct {
+	MaxSurge       int32
+	MaxUnavailable int32
+	// TerminatingPods are the scaled down pods, which are assumed to be still terminating.
+	TerminatingPods int32
+}
+
+// ScaledToZero reports whether the resource is a workload, which is scaled down to zero replicas, or a suspended
+// CronJob, which doesn't start any jobs.
+func (u *ResourceUsage) ScaledToZero() bool {
+	if u.Details.Suspended {
+		return true
+	}
+
+	switch u.Details.Kind {
+	case "Deployment", "DeploymentConfig", "StatefulSet", "ReplicaSet", "Rollout":
+		return u.Details.Replicas == 0
+	default:
+		return false
+	}
+}
+
+// Options contains settings that change how the resource usage of k8s resources is calculated.
+// The zero value calculates with the default behavior.
+type Options struct {
+	// TerminationOverlap is the fraction (0 to 1) of the pods scaled down during a rollout, which are assumed to
+	// still be terminating while their replacements are already starting. Terminating pods still count against the quota.
+	TerminationOverlap float64
+	// TerminationOverlapByGracePeriod derives the overlap from the terminationGracePeriodSeconds of the pod template
+	// instead of using TerminationOverlap: pods with a grace period overlap completely, pods without one don't overlap.
+	TerminationOverlapByGracePeriod bool
+	// AssumedReplicas are used for resources, which don't set their replicas. If nil, the api default of 1 is used.
+	AssumedReplicas *int32
+	// StrategyDefaults are applied to rollout strategies, which don't set all values themselves.
+	// The defaults of kubernetes are used if they are nil.
+	StrategyDefaults *StrategyDefaults
+	// Timeline simulates the rollouts over time and records the resource usage of each phase in ResourceUsage.Timeline.
+	// Old pods keep terminating for the share of their grace period given by the termination overlap.
+	Timeline bool
+	// JobRetryOverlap assumes that a failing pod of a job or cronjob is still terminating, while its retry pod is
+	// already starting. This only applies to jobs, which retry failed pods and create the retry pods early.
+	JobRetryOverlap bool
+	// Explain records how the resources are calculated in ResourceUsage.Explanation.
+	Explain bool
+	// EmptyDirEphemeralStorage counts the sizeLimit of emptyDir volumes towards the ephemeral storage requests and
+	// limits of a pod, as they consume ephemeral storage of the node.
+	EmptyDirEphemeralStorage bool
+	// Nodes are the nodes of the cluster. If set, daemonsets are calculated with a pod on each node they can be
+	// scheduled on, instead of a single pod.
+	Nodes []v1.Node
+	// Autoscalers are the HorizontalPodAutoscalers of the input. The replicas of workloads scaled by one of them
+	// are selected by HPANormalReplicas and HPAPeakReplicas.
+	Autoscalers Autoscalers
+	// HPANormalReplicas selects the replicas of autoscaled workloads for the normal resources, defaults to the spec replicas.
+	HPANormalReplicas HPAReplicas
+	// HPAPeakReplicas selects the replicas of autoscaled workloads for the rollout resources, defaults to the spec replicas.
+	HPAPeakReplicas HPAReplicas
+	// VerticalAutoscalers are the VerticalPodAutoscalers of the input. The requests of the pods of workloads scaled
+	// by one of them are replaced by the recommendation selected by VPARecommendation, unless the autoscaler only
+	// recommends (updateMode Off).
+	VerticalAutoscalers VerticalAutoscalers
+	// VPARecommendation selects the recommendation of the VerticalPodAutoscalers, defaults to the target.
+	VPARecommendation VPARecommendation
+	// KubernetesVersion is the version of the target cluster. Fields it doesn't support are ignored with a warning,
+	// defaults to the latest version.
+	KubernetesVersion KubernetesVersion
+	// Mesh adds the proxy a service mesh injects to the pods. If nil, no proxy is injected.
+	Mesh *Mesh
+	// Injections add the containers mutating webhooks inject to the pods. If nil, no containers are injected.
+	Injections *InjectionRules
+	// CustomKinds describe the kinds without a handler, which are calculated from their pod templates. If nil, they
+	// aren't supported.
+	CustomKinds *CustomKinds
+}
+
+// replicas returns the replicas of a resource, or the assumed replicas if the resource doesn't set them.
+// The second return value reports whether the replicas were assumed.
+func (o Options) replicas(replicas *int32) (int32, bool) {
+	if replicas != nil {
+		return *replicas, false
+	}
+
+	// https://pkg.go.dev/k8s.io/api/apps/v1#DeploymentSpec
+	if o.AssumedReplicas == nil {
+		return 1, true
+	}
+
+	return *o.AssumedReplicas, true
+}
+
+// terminatingPods returns the number of scaled down pods, which are assumed to still be terminating during a rollout.
+func (o Options) terminatingPods(scaledDown int32, gracePeriodSeconds *int64) int32 {
+	overlap := o.TerminationOverlap
+
+	if o.TerminationOverlapByGracePeriod {
+		// the api server defaults a missing grace period to 30 seconds
+		if gracePeriodSeconds == nil || *gracePeriodSeconds > 0 {
+			overlap = 1
+		} else {
+			overlap = 0
+		}
+	}
+
+	return int32(math.Ceil(float64(scaledDown) * overlap))
 }
 
 // Resources contains the limits and requests for cpu and memory that are typically used in kubernetes and openshift.
 // Can be used to apply arithmetic operations equally on all quantities.
 type Resources struct {
-	CPUMin    resource.Quantity
-	CPUMax    resource.Quantity
-	MemoryMin resource.Quantity
-	MemoryMax resource.Quantity
+	CPUMin              resource.Quantity
+	CPUMax              resource.Quantity
+	MemoryMin           resource.Quantity
+	MemoryMax           resource.Quantity
+	EphemeralStorageMin resource.Quantity
+	EphemeralStorageMax resource.Quantity
+	// Extended are the extended resources like nvidia.com/gpu and the hugepages like hugepages-2Mi by name. Their
+	// requests always equal their limits. The list is never modified in place, copies of Resources share it.
+	Extended v1.ResourceList
+	// Storage is the storage requested by the persistent volume claims, PersistentVolumeClaims their number.
+	Storage                resource.Quantity
+	PersistentVolumeClaims resource.Quantity
+	// Pods is the number of pods, which exist at the same time.
+	Pods resource.Quantity
 }
 
 // PodResources contain the sum of the resources required by the initContainer, the normal containers
@@ -78,15 +228,20 @@ type PodResources struct {
 	Containers     Resources
 	InitContainers Resources
 	MaxResources   Resources
+	// ContainerResources are the resources of each container and init container of the pod, as set in its spec.
+	ContainerResources []Resources
 }
 
 // ConvertToResources converts a kubernetes/openshift ResourceRequirements struct to a Resources struct
 func ConvertToResources(req *v1.ResourceRequirements) Resources {
 	return Resources{
-		CPUMin:    *req.Requests.Cpu(),
-		CPUMax:    *req.Limits.Cpu(),
-		MemoryMin: *req.Requests.Memory(),
-		MemoryMax: *req.Limits.Memory(),
+		CPUMin:              *req.Requests.Cpu(),
+		CPUMax:              *req.Limits.Cpu(),
+		MemoryMin:           *req.Requests.Memory(),
+		MemoryMax:           *req.Limits.Memory(),
+		EphemeralStorageMin: *req.Requests.StorageEphemeral(),
+		EphemeralStorageMax: *req.Limits.StorageEphemeral(),
+		Extended:            extendedResources(req),
 	}
 }
 
@@ -96,6 +251,12 @@ func (r Resources) Add(y Resources) Resources {
 	r.CPUMax.Add(y.CPUMax)
 	r.MemoryMin.Add(y.MemoryMin)
 	r.MemoryMax.Add(y.MemoryMax)
+	r.EphemeralStorageMin.Add(y.EphemeralStorageMin)
+	r.EphemeralStorageMax.Add(y.EphemeralStorageMax)
+	r.Extended = combineExtended(r.Extended, y.Extended, addQuantities)
+	r.Storage.Add(y.Storage)
+	r.PersistentVolumeClaims.Add(y.PersistentVolumeClaims)
+	r.Pods.Add(y.Pods)
 
 	return r
 }
@@ -112,39 +273,73 @@ func (r Resources) Mul(y float64) Resources {
 	r.CPUMax.SetMilli(int64(float64(r.CPUMax.MilliValue()) * y))
 	r.MemoryMin.SetMilli(int64(float64(r.MemoryMin.MilliValue()) * y))
 	r.MemoryMax.SetMilli(int64(float64(r.MemoryMax.MilliValue()) * y))
+	r.EphemeralStorageMin.SetMilli(int64(float64(r.EphemeralStorageMin.MilliValue()) * y))
+	r.EphemeralStorageMax.SetMilli(int64(float64(r.EphemeralStorageMax.MilliValue()) * y))
+	r.Extended = mulExtended(r.Extended, y)
+	r.Storage.SetMilli(int64(float64(r.Storage.MilliValue()) * y))
+	r.PersistentVolumeClaims.SetMilli(int64(float64(r.PersistentVolumeClaims.MilliValue()) * y))
+	r.Pods.SetMilli(int64(float64(r.Pods.MilliValue()) * y))
 
 	return r
 }
 
-func calcPodResources(podSpec *v1.PodSpec) (r *PodResources) {
+func calcPodResources(podSpec *v1.PodSpec, opts Options) (r *PodResources) {
 	r = new(PodResources)
 
 	for i := range podSpec.Containers {
-		container := podSpec.Containers[i]
-
-		r.Containers.CPUMin.Add(*container.Resources.Requests.Cpu())
-		r.Containers.CPUMax.Add(*container.Resources.Limits.Cpu())
-		r.Containers.MemoryMin.Add(*container.Resources.Requests.Memory())
-		r.Containers.MemoryMax.Add(*container.Resources.Limits.Memory())
+		resources := ConvertToResources(&podSpec.Containers[i].Resources)
+		r.Containers = r.Containers.Add(resources)
+		r.ContainerResources = append(r.ContainerResources, resources)
 	}
 
+	var sidecars Resources
+
 	for i := range podSpec.InitContainers {
-		container := podSpec.InitContainers[i]
+		resources := ConvertToResources(&podSpec.InitContainers[i].Resources)
+		r.ContainerResources = append(r.ContainerResources, resources)
 
-		r.InitContainers.CPUMin.Add(*container.Resources.Requests.Cpu())
-		r.InitContainers.CPUMax.Add(*container.Resources.Limits.Cpu())
-		r.InitContainers.MemoryMin.Add(*container.Resources.Requests.Memory())
-		r.InitContainers.MemoryMax.Add(*container.Resources.Limits.Memory())
+		if opts.isSidecar(&podSpec.InitContainers[i]) {
+			sidecars = sidecars.Add(resources)
+		} else {
+			r.InitContainers = r.InitContainers.Add(resources)
+		}
 	}
 
-	r.MaxResources.CPUMin = maxQuantity(r.Containers.CPUMin, r.InitContainers.CPUMin)
-	r.MaxResources.CPUMax = maxQuantity(r.Containers.CPUMax, r.InitContainers.CPUMax)
-	r.MaxResources.MemoryMin = maxQuantity(r.Containers.MemoryMin, r.InitContainers.MemoryMin)
-	r.MaxResources.MemoryMax = maxQuantity(r.Containers.MemoryMax, r.InitContainers.MemoryMax)
+	// native sidecars keep running next to the containers and, as they are started first, next to the init containers
+	r.Containers = r.Containers.Add(sidecars)
+	r.InitContainers = r.InitContainers.Add(sidecars)
+	// the containers are the ones of a single pod, so multiplying them by the replicas counts the pods too
+	r.Containers.Pods = *resource.NewQuantity(1, resource.DecimalSI)
+	r.MaxResources = maxResources(r.Containers, r.InitContainers)
+
+	// emptyDirs exist as long as the pod, no matter which of its containers runs
+	if opts.EmptyDirEphemeralStorage {
+		emptyDirs := emptyDirResources(podSpec)
+		r.Containers = r.Containers.Add(emptyDirs)
+		r.MaxResources = r.MaxResources.Add(emptyDirs)
+	}
 
 	return
 }
 
+// emptyDirResources returns the ephemeral storage of the emptyDir volumes of a pod, which are limited in size.
+// emptyDirs backed by memory count towards the memory of the containers instead.
+func emptyDirResources(podSpec *v1.PodSpec) Resources {
+	var r Resources
+
+	for _, volume := range podSpec.Volumes {
+		emptyDir := volume.EmptyDir
+		if emptyDir == nil || emptyDir.SizeLimit == nil || emptyDir.Medium == v1.StorageMediumMemory {
+			continue
+		}
+
+		r.EphemeralStorageMin.Add(*emptyDir.SizeLimit)
+		r.EphemeralStorageMax.Add(*emptyDir.SizeLimit)
+	}
+
+	return r
+}
+
 func maxQuantity(q1, q2 resource.Quantity) resource.Quantity {
 	if q1.MilliValue() > q2.MilliValue() {
 		return q1
@@ -169,6 +364,8 @@ func Total(maxRollout int, usage []*ResourceUsage) Resources {
 		cpuMaxUsage    resource.Quantity
 		memoryMinUsage resource.Quantity
 		memoryMaxUsage resource.Quantity
+		ephemeralMin   resource.Quantity
+		ephemeralMax   resource.Quantity
 	)
 
 	if maxRollout <= -1 {
@@ -178,6 +375,8 @@ func Total(maxRollout int, usage []*ResourceUsage) Resources {
 			cpuMaxUsage.Add(u.RolloutResources.CPUMax)
 			memoryMinUsage.Add(u.RolloutResources.MemoryMin)
 			memoryMaxUsage.Add(u.RolloutResources.MemoryMax)
+			ephemeralMin.Add(u.RolloutResources.EphemeralStorageMin)
+			ephemeralMax.Add(u.RolloutResources.EphemeralStorageMax)
 		}
 	} else {
 		// limited simultaneous rollout
@@ -188,9 +387,11 @@ func Total(maxRollout int, usage []*ResourceUsage) Resources {
 			cpuMaxUsage.Add(u.NormalResources.CPUMax)
 			memoryMinUsage.Add(u.NormalResources.MemoryMin)
 			memoryMaxUsage.Add(u.NormalResources.MemoryMax)
+			ephemeralMin.Add(u.NormalResources.EphemeralStorageMin)
+			ephemeralMax.Add(u.NormalResources.EphemeralStorageMax)
 		}
 
-		var cpuMinDiffs, cpuMaxDiffs, memoryMinDiffs, memoryMaxDiffs []resource.Quantity
+		var cpuMinDiffs, cpuMaxDiffs, memoryMinDiffs, memoryMaxDiffs, ephemeralMinDiffs, ephemeralMaxDiffs []resource.Quantity
 
 		for _, u := range usage {
 			cpuMinDiffs = append(cpuMinDiffs, diffQuantities(&u.RolloutResources.CPUMin, &u.NormalResources.CPUMin))
@@ -200,6 +401,10 @@ func Total(maxRollout int, usage []*ResourceUsage) Resources {
 			memoryMinDiffs = append(memoryMinDiffs, diffQuantities(&u.RolloutResources.MemoryMin, &u.NormalResources.MemoryMin))
 
 			memoryMaxDiffs = append(memoryMaxDiffs, diffQuantities(&u.RolloutResources.MemoryMax, &u.NormalResources.MemoryMax))
+
+			ephemeralMinDiffs = append(ephemeralMinDiffs, diffQuantities(&u.RolloutResources.EphemeralStorageMin, &u.NormalResources.EphemeralStorageMin))
+
+			ephemeralMaxDiffs = append(ephemeralMaxDiffs, diffQuantities(&u.RolloutResources.EphemeralStorageMax, &u.NormalResources.EphemeralStorageMax))
 		}
 
 		compareQuantityDescending := func(a, b resource.Quantity) int {
@@ -210,6 +415,8 @@ func Total(maxRollout int, usage []*ResourceUsage) Resources {
 		slices.SortFunc(cpuMaxDiffs, compareQuantityDescending)
 		slices.SortFunc(memoryMinDiffs, compareQuantityDescending)
 		slices.SortFunc(memoryMaxDiffs, compareQuantityDescending)
+		slices.SortFunc(ephemeralMinDiffs, compareQuantityDescending)
+		slices.SortFunc(ephemeralMaxDiffs, compareQuantityDescending)
 
 		for i := 0; i < len(cpuMinDiffs) && i < maxRollout; i++ {
 			cpuMinUsage.Add(cpuMinDiffs[i])
@@ -226,105 +433,251 @@ func Total(maxRollout int, usage []*ResourceUsage) Resources {
 		for i := 0; i < len(memoryMaxDiffs) && i < maxRollout; i++ {
 			memoryMaxUsage.Add(memoryMaxDiffs[i])
 		}
+
+		for i := 0; i < len(ephemeralMinDiffs) && i < maxRollout; i++ {
+			ephemeralMin.Add(ephemeralMinDiffs[i])
+		}
+
+		for i := 0; i < len(ephemeralMaxDiffs) && i < maxRollout; i++ {
+			ephemeralMax.Add(ephemeralMaxDiffs[i])
+		}
 	}
 
 	return Resources{
-		CPUMin:    cpuMinUsage,
-		CPUMax:    cpuMaxUsage,
-		MemoryMin: memoryMinUsage,
-		MemoryMax: memoryMaxUsage,
+		CPUMin:              cpuMinUsage,
+		CPUMax:              cpuMaxUsage,
+		MemoryMin:           memoryMinUsage,
+		MemoryMax:           memoryMaxUsage,
+		EphemeralStorageMin: ephemeralMin,
+		EphemeralStorageMax: ephemeralMax,
+		Extended:            totalExtended(maxRollout, usage),
+		Storage: totalQuantity(maxRollout, usage, func(r Resources) resource.Quantity {
+			return r.Storage
+		}),
+		PersistentVolumeClaims: totalQuantity(maxRollout, usage, func(r Resources) resource.Quantity {
+			return r.PersistentVolumeClaims
+		}),
+		Pods: totalQuantity(maxRollout, usage, func(r Resources) resource.Quantity {
+			return r.Pods
+		}),
 	}
 }
 
-// ResourceQuotaFromYaml decodes a single yaml document into a k8s object. Then performs a type assertion
-// on the object and calculates the resource needs of it.
-// Currently supported:
-// * apps.openshift.io/v1 - DeploymentConfig
-// * apps/v1 - Deployment
-// * apps/v1 - StatefulSet
-// * apps/v1 - DaemonSet
-// * batch/v1 - CronJob
-// * batch/v1 - Job
-// * v1 - Pod
-func ResourceQuotaFromYaml(yamlData []byte) (*ResourceUsage, error) {
-	var version string
+// Decode decodes a single yaml document into a k8s object. Kinds which aren't registered are returned as
+// *runtime.Unknown with their apiVersion and kind set.
+func Decode(yamlData []byte) (runtime.Object, error) {
+	codecs := serializer.NewCodecFactory(newScheme())
+	decoder := codecs.UniversalDeserializer()
+
+	object, _, err := decoder.Decode(yamlData, nil, nil)
+	if err == nil {
+		return object, nil
+	}
+
+	if !runtime.IsNotRegisteredError(err) {
+		return nil, fmt.Errorf("decoding yaml data: %w", err)
+	}
+
+	unknown := runtime.Unknown{Raw: yamlData}
+
+	if _, gvk, err := decoder.Decode(yamlData, nil, &unknown); err == nil {
+		unknown.SetGroupVersionKind(*gvk)
+	}
+
+	// when the kind is not found and no handler is registered for it, I just warn and skip. VerticalPodAutoscalers
+	// aren't calculated, they are added to the options instead.
+	if _, ok := kindHandlerOf(unknown.GroupVersionKind()); !ok && unknown.GroupVersionKind() != verticalPodAutoscalerKind() {
+		log.Warn().Msg(err.Error())
+	}
 
-	var kind string
+	return &unknown, nil
+}
 
+// newScheme returns the scheme of the bundled api, the kubernetes and the openshift kinds.
+func newScheme() *runtime.Scheme {
 	combinedScheme := runtime.NewScheme()
 	_ = scheme.AddToScheme(combinedScheme)
 	_ = openshiftScheme.AddToScheme(combinedScheme)
-	codecs := serializer.NewCodecFactory(combinedScheme)
-	decoder := codecs.UniversalDeserializer()
+	_ = openshiftBuildScheme.AddToScheme(combinedScheme)
 
-	object, gvk, err := decoder.Decode(yamlData, nil, nil)
+	return combinedScheme
+}
 
-	if err != nil {
-		// when the kind is not found, I just warn and skip
-		if runtime.IsNotRegisteredError(err) {
-			log.Warn().Msg(err.Error())
+// objectKind returns the kind of an object. Typed objects, which don't set their kind, e.g. the ones returned by
+// client-go, are looked up in the scheme of the bundled api.
+func objectKind(object runtime.Object) schema.GroupVersionKind {
+	gvk := object.GetObjectKind().GroupVersionKind()
+	if !gvk.Empty() {
+		return gvk
+	}
 
-			unknown := runtime.Unknown{Raw: yamlData}
+	if kinds, _, err := newScheme().ObjectKinds(object); err == nil && len(kinds) > 0 {
+		return kinds[0]
+	}
 
-			if _, gvk1, err := decoder.Decode(yamlData, nil, &unknown); err == nil {
-				kind = gvk1.Kind
-				version = gvk1.Version
-			}
-		} else {
-			return nil, fmt.Errorf("decoding yaml data: %w", err)
-		}
-	} else {
-		kind = gvk.Kind
-		version = gvk.Version
+	return gvk
+}
+
+// ListItems returns the decoded items of a v1 List, as printed by kubectl get -o yaml. Lists within the items are
+// unwrapped as well. Any other object is returned as its only item.
+func ListItems(object runtime.Object) ([]runtime.Object, error) {
+	list, ok := object.(*v1.List)
+	if !ok {
+		return []runtime.Object{object}, nil
 	}
 
-	switch obj := object.(type) {
-	case *openshiftAppsV1.DeploymentConfig:
-		usage, err := deploymentConfig(*obj)
-		if err != nil {
-			return nil, CalculationError{
-				Version: gvk.Version,
-				Kind:    gvk.Kind,
-				err:     err,
+	var items []runtime.Object
+
+	for i, item := range list.Items {
+		itemObject := item.Object
+		if itemObject == nil {
+			var err error
+
+			itemObject, err = Decode(item.Raw)
+			if err != nil {
+				return nil, fmt.Errorf("decoding list item %d: %w", i, err)
 			}
 		}
 
-		return usage, nil
-	case *appsv1.Deployment:
-		usage, err := deployment(*obj)
+		itemObjects, err := ListItems(itemObject)
 		if err != nil {
-			return nil, CalculationError{
-				Version: gvk.Version,
-				Kind:    gvk.Kind,
-				err:     err,
-			}
+			return nil, err
 		}
 
-		return usage, nil
-	case *appsv1.StatefulSet:
-		usage, err := statefulSet(*obj)
+		items = append(items, itemObjects...)
+	}
+
+	return items, nil
+}
+
+// DefaultNamespace sets the namespace of an object, which doesn't specify one, like the api server does when it is
+// applied. Objects without metadata are left unchanged.
+func DefaultNamespace(object runtime.Object, namespace string) {
+	if unknown, ok := object.(*runtime.Unknown); ok {
+		defaultUnknownNamespace(unknown, namespace)
+
+		return
+	}
+
+	accessor, err := meta.Accessor(object)
+	if err != nil || accessor.GetNamespace() != "" {
+		return
+	}
+
+	accessor.SetNamespace(namespace)
+}
+
+// ResourceQuotaFromYaml decodes a single yaml document into a k8s object and calculates the resource needs of it
+// with the given options. See ResourceQuotaFromObject for the supported kinds. A v1 List is calculated like its only
+// item, use ResourceQuotasFromYaml for lists with several items.
+func ResourceQuotaFromYaml(yamlData []byte, opts Options) (*ResourceUsage, error) {
+	object, err := Decode(yamlData)
+	if err != nil {
+		return nil, err
+	}
+
+	if items, err := ListItems(object); err == nil && len(items) == 1 {
+		object = items[0]
+	}
+
+	return ResourceQuotaFromObject(object, opts)
+}
+
+// ResourceQuotasFromYaml decodes a single yaml document into k8s objects and calculates the resource needs of each
+// with the given options. The items of a v1 List, as printed by kubectl get -o yaml, are calculated each, any other
+// document is a single object.
+func ResourceQuotasFromYaml(yamlData []byte, opts Options) ([]*ResourceUsage, error) {
+	object, err := Decode(yamlData)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := ListItems(object)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]*ResourceUsage, 0, len(items))
+
+	for _, item := range items {
+		usage, err := ResourceQuotaFromObject(item, opts)
 		if err != nil {
-			return nil, CalculationError{
-				Version: gvk.Version,
-				Kind:    gvk.Kind,
-				err:     err,
-			}
+			return nil, err
 		}
 
-		return usage, nil
-	case *appsv1.DaemonSet:
-		return daemonSet(*obj), nil
-	case *batchV1.Job:
-		return job(*obj), nil
-	case *batchV1.CronJob:
-		return cronjob(*obj), nil
-	case *v1.Pod:
-		return pod(*obj), nil
+		usages = append(usages, usage)
+	}
+
+	return usages, nil
+}
+
+// ResourceQuotaFromObject calculates the resource needs of a decoded k8s object with the handler of its kind and the
+// given options. The built-in kinds are:
+// * apps.openshift.io/v1 - DeploymentConfig
+// * apps/v1 - Deployment
+// * apps/v1 - StatefulSet
+// * apps/v1 - DaemonSet
+// * batch/v1 - CronJob
+// * batch/v1 - Job
+// * v1 - Pod
+// * apps/v1 - ReplicaSet
+// * argoproj.io/v1alpha1 - Rollout
+// * v1 - PersistentVolumeClaim
+// * build.openshift.io/v1 - BuildConfig
+// * build.openshift.io/v1 - Build
+// * monitoring.coreos.com/v1 - Prometheus
+// * monitoring.coreos.com/v1 - Alertmanager
+// * monitoring.coreos.com/v1 - ThanosRuler
+// * elasticsearch.k8s.elastic.co/v1 - Elasticsearch
+// * kubevirt.io/v1 - VirtualMachine
+// * kubevirt.io/v1 - VirtualMachineInstance
+// The deprecated extensions/v1beta1, apps/v1beta1 and apps/v1beta2 Deployments and batch/v1beta1 CronJobs are
+// calculated like their current version. Other kinds are calculated by the handlers registered with RegisterHandler
+// or RegisterCalculator, or from their pod templates, if the CustomKinds of the options describe them.
+func ResourceQuotaFromObject(object runtime.Object, opts Options) (*ResourceUsage, error) {
+	var (
+		usage *ResourceUsage
+		err   error
+	)
+
+	gvk := objectKind(object)
+	object, verticalAutoscaler := opts.verticallyScaled(object)
+
+	unknown, isUnknown := object.(*runtime.Unknown)
+	customKind, isCustom := opts.CustomKinds.kind(gvk)
+
+	switch handler, ok := kindHandlerOf(gvk); {
+	case ok:
+		usage, err = handler(object, opts)
+	case isUnknown && isCustom:
+		usage, err = customKindResource(unknown, customKind, opts)
 	default:
+		err = ErrResourceNotSupported
+	}
+
+	if err != nil {
 		return nil, CalculationError{
-			Version: version,
-			Kind:    kind,
-			err:     ErrResourceNotSupported,
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+			err:     err,
 		}
 	}
+
+	// calculators of custom kinds may set the labels and annotations themselves
+	if accessor, err := meta.Accessor(object); err == nil {
+		if usage.Details.Labels == nil {
+			usage.Details.Labels = accessor.GetLabels()
+		}
+
+		if usage.Details.Annotations == nil {
+			usage.Details.Annotations = accessor.GetAnnotations()
+		}
+	}
+
+	if verticalAutoscaler != "" {
+		usage.Details.VerticalAutoscaler = verticalAutoscaler
+		usage.explainf(opts, "requests: the %s recommendation of vpa %s", cmp.Or(opts.VPARecommendation, VPATarget), verticalAutoscaler)
+	}
+
+	return usage, nil
 }
@@ -2,6 +2,7 @@ package calc
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -396,6 +397,81 @@ spec:
       securityContext: {}
       terminationGracePeriodSeconds: 30`
 
+var noProgressDeployment = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: stuck
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: stuck
+  strategy:
+    rollingUpdate:
+      maxSurge: 0
+      maxUnavailable: 0%
+    type: RollingUpdate
+  template:
+    metadata:
+      labels:
+        app: stuck
+    spec:
+      containers:
+        - image: myapp:v1.0.7
+          name: stuck
+          resources:
+            requests:
+              cpu: '250m'
+              memory: 2Gi`
+
+var deploymentWithoutReplicas = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: hpa
+  name: hpa
+spec:
+  selector:
+    matchLabels:
+      app: hpa
+  template:
+    metadata:
+      labels:
+        app: hpa
+    spec:
+      containers:
+        - image: myapp:v1.0.7
+          name: hpa
+          resources:
+            limits:
+              cpu: '1'
+              memory: 4Gi
+            requests:
+              cpu: '250m'
+              memory: 2Gi`
+
+var normalDeploymentHPA = `---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: normal-hpa
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: normal
+  minReplicas: 2
+  maxReplicas: 20
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 80`
+
 var normalStatefulSet = `
 ---
 apiVersion: apps/v1
@@ -504,6 +580,29 @@ spec:
       restartPolicy: Never
   backoffLimit: 4`
 
+var failedReplacementJob = `
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pi
+spec:
+  podReplacementPolicy: Failed
+  template:
+    spec:
+      containers:
+        - name: pi
+          image: alpine
+          resources:
+            limits:
+              cpu: "1"
+              memory: 4Gi
+            requests:
+              cpu: 250m
+              memory: 2Gi
+      restartPolicy: Never
+  backoffLimit: 4`
+
 var normalCronJob = `---
 apiVersion: batch/v1
 kind: CronJob
@@ -528,6 +627,73 @@ spec:
               imagePullPolicy: IfNotPresent
           restartPolicy: OnFailure`
 
+var frequentCronJob = `---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: frequent
+spec:
+  schedule: "*/5 * * * *"
+  startingDeadlineSeconds: 300
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 900
+      template:
+        spec:
+          containers:
+            - name: frequent
+              image: busybox
+              resources:
+                limits:
+                  cpu: "1"
+                  memory: 4Gi
+                requests:
+                  cpu: 250m
+                  memory: 2Gi
+          restartPolicy: OnFailure`
+
+var forbidConcurrentCronJob = `---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: forbid
+spec:
+  schedule: "*/5 * * * *"
+  concurrencyPolicy: Forbid
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 900
+      template:
+        spec:
+          containers:
+            - name: forbid
+              image: busybox
+              resources:
+                limits:
+                  cpu: "1"
+                  memory: 4Gi
+                requests:
+                  cpu: 250m
+                  memory: 2Gi
+          restartPolicy: OnFailure`
+
+var invalidScheduleCronJob = `---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: invalid
+spec:
+  schedule: "every five minutes"
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 900
+      template:
+        spec:
+          containers:
+            - name: invalid
+              image: busybox
+          restartPolicy: OnFailure`
+
 var normalPod = `
 ---
 apiVersion: v1
@@ -550,6 +716,42 @@ spec:
         memory: 2Gi
   terminationGracePeriodSeconds: 30`
 
+var emptyDirPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: cache
+spec:
+  containers:
+    - name: cache
+      image: myapp:v1.0.7
+      resources:
+        requests:
+          cpu: 250m
+          memory: 2Gi
+          ephemeral-storage: 1Gi
+        limits:
+          cpu: "1"
+          memory: 4Gi
+          ephemeral-storage: 2Gi
+      volumeMounts:
+        - name: cache
+          mountPath: /cache
+        - name: scratch
+          mountPath: /scratch
+        - name: shm
+          mountPath: /dev/shm
+  volumes:
+    - name: cache
+      emptyDir:
+        sizeLimit: 5Gi
+    - name: scratch
+      emptyDir: {}
+    - name: shm
+      emptyDir:
+        medium: Memory
+        sizeLimit: 1Gi`
+
 var multiContainerPod = `
 ---
 apiVersion: v1
@@ -710,10 +912,79 @@ spec:
             memory: 200Mi
       terminationGracePeriodSeconds: 30`
 
+var normalReplicaSet = `
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: myreplicaset
+  namespace: operator
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: myreplicaset
+  template:
+    metadata:
+      labels:
+        app: myreplicaset
+    spec:
+      initContainers:
+      - name: migrate
+        image: myimage
+        resources:
+          limits:
+            cpu: "4"
+            memory: 4Gi
+          requests:
+            cpu: "2"
+            memory: 1Gi
+      containers:
+      - name: myapp
+        image: myimage
+        resources:
+          limits:
+            cpu: "1"
+            memory: 1Gi
+          requests:
+            cpu: 500m
+            memory: 512Mi`
+
+var selectiveDaemonSet = `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: logging
+spec:
+  selector:
+    matchLabels:
+      name: logging
+  template:
+    metadata:
+      labels:
+        name: logging
+    spec:
+      nodeSelector:
+        pool: workers
+      tolerations:
+        - key: dedicated
+          operator: Equal
+          value: logging
+          effect: NoSchedule
+      containers:
+      - name: logging
+        image: quay.io/fluentd_elasticsearch/fluentd:v2.5.2
+        resources:
+          limits:
+            memory: 2Gi
+            cpu: "2"
+          requests:
+            cpu: 500m
+            memory: 200Mi`
+
 func TestResourceQuotaFromYaml(t *testing.T) {
 	r := require.New(t)
 
-	usage, err := ResourceQuotaFromYaml([]byte(service))
+	usage, err := ResourceQuotaFromYaml([]byte(service), Options{})
 	r.Error(err)
 	r.True(errors.Is(err, ErrResourceNotSupported))
 	r.Nil(usage)
@@ -723,7 +994,7 @@ func TestResourceQuotaFromYaml(t *testing.T) {
 	r.True(errors.As(err, &calcErr))
 	r.Equal("calculating v1/Service resource usage: resource not supported", calcErr.Error())
 
-	usage, err = ResourceQuotaFromYaml([]byte(unsupportedOpenshiftRoute))
+	usage, err = ResourceQuotaFromYaml([]byte(unsupportedOpenshiftRoute), Options{})
 	t.Log(err)
 	r.Error(err)
 	r.True(errors.Is(err, ErrResourceNotSupported))
@@ -731,6 +1002,125 @@ func TestResourceQuotaFromYaml(t *testing.T) {
 	r.True(errors.As(err, &calcErr))
 }
 
+var podList = `
+apiVersion: v1
+kind: List
+metadata:
+  resourceVersion: ""
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: web
+    namespace: shop
+  spec:
+    containers:
+    - name: web
+      image: web
+      resources:
+        requests:
+          cpu: 100m
+          memory: 128Mi
+- apiVersion: v1
+  kind: List
+  items:
+  - apiVersion: v1
+    kind: Pod
+    metadata:
+      name: worker
+      namespace: shop
+    spec:
+      containers:
+      - name: worker
+        image: worker
+        resources:
+          requests:
+            cpu: 200m
+            memory: 256Mi`
+
+func TestResourceQuotasFromYaml(t *testing.T) {
+	r := require.New(t)
+
+	// the items of a list are calculated each, nested lists are unwrapped
+	usages, err := ResourceQuotasFromYaml([]byte(podList), Options{})
+	r.NoError(err)
+	r.Len(usages, 2)
+	r.Equal("web", usages[0].Details.Name)
+	r.Equal("worker", usages[1].Details.Name)
+	AssertEqualQuantities(r, resource.MustParse("200m"), usages[1].NormalResources.CPUMin, "cpu request")
+
+	// any other document is a single object
+	usages, err = ResourceQuotasFromYaml([]byte(normalDeployment), Options{})
+	r.NoError(err)
+	r.Len(usages, 1)
+	r.Equal("Deployment", usages[0].Details.Kind)
+}
+
+func TestScaledToZero(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(zeroReplicaDeployment), Options{})
+	r.NoError(err)
+	r.True(usage.ScaledToZero())
+
+	usage, err = ResourceQuotaFromYaml([]byte(normalDeployment), Options{})
+	r.NoError(err)
+	r.False(usage.ScaledToZero())
+
+	// old replicasets of a deployment are kept scaled to zero
+	usage, err = ResourceQuotaFromYaml([]byte(strings.Replace(normalReplicaSet, "replicas: 3", "replicas: 0", 1)), Options{})
+	r.NoError(err)
+	r.True(usage.ScaledToZero())
+
+	// pods don't have replicas
+	usage, err = ResourceQuotaFromYaml([]byte(normalPod), Options{})
+	r.NoError(err)
+	r.False(usage.ScaledToZero())
+}
+
+func TestPods(t *testing.T) {
+	r := require.New(t)
+
+	deployment, err := ResourceQuotaFromYaml([]byte(normalDeployment), Options{})
+	r.NoError(err)
+	AssertEqualQuantities(r, resource.MustParse("10"), deployment.NormalResources.Pods, "normal pods")
+	AssertEqualQuantities(r, resource.MustParse("13"), deployment.RolloutResources.Pods, "rollout pods")
+
+	// the init containers run in the same pod
+	pod, err := ResourceQuotaFromYaml([]byte(initContainerPod), Options{})
+	r.NoError(err)
+	AssertEqualQuantities(r, resource.MustParse("1"), pod.RolloutResources.Pods, "pod")
+
+	AssertEqualQuantities(r, resource.MustParse("14"), Total(1, []*ResourceUsage{deployment, pod}).Pods, "total pods")
+	AssertEqualQuantities(r, resource.MustParse("11"), Total(0, []*ResourceUsage{deployment, pod}).Pods, "total pods without rollouts")
+}
+
+func TestDefaultNamespace(t *testing.T) {
+	r := require.New(t)
+
+	object, err := Decode([]byte(normalDeployment))
+	r.NoError(err)
+
+	DefaultNamespace(object, "team-a")
+	usage, err := ResourceQuotaFromObject(object, Options{})
+	r.NoError(err)
+	r.Equal("team-a", usage.Details.Namespace)
+
+	// a namespace set in the manifest is kept
+	DefaultNamespace(object, "team-b")
+	usage, err = ResourceQuotaFromObject(object, Options{})
+	r.NoError(err)
+	r.Equal("team-a", usage.Details.Namespace)
+}
+
+func TestDetailsLabels(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(normalDeployment), Options{})
+	r.NoError(err)
+	r.Equal(map[string]string{"app": "normal"}, usage.Details.Labels)
+}
+
 func AssertEqualQuantities(r *require.Assertions, expected resource.Quantity, actual resource.Quantity, name string) {
 	r.Conditionf(func() bool { return expected.Equal(actual) }, name+" expected: "+expected.String()+" but was: "+actual.String())
 }
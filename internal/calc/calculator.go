@@ -0,0 +1,221 @@
+package calc
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// Calculator calculates the resource usage of a kind, which kuota-calc doesn't support itself, e.g. a custom resource.
+type Calculator func(object *unstructured.Unstructured, opts Options) (*ResourceUsage, error)
+
+// Handler calculates the resource usage of a decoded object of a kind. Objects of kinds known to the bundled api are
+// passed typed, e.g. as *appsv1.Deployment, the ones of other kinds as *runtime.Unknown.
+type Handler func(object runtime.Object, opts Options) (*ResourceUsage, error)
+
+// handlers are registered at compile time, like the drivers of database/sql.
+//
+//nolint:gochecknoglobals // the registry has to be reachable from the init functions of other packages
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[schema.GroupVersionKind]Handler{}
+)
+
+// RegisterHandler registers the handler of a kind. It is meant to be called from an init function and panics, if the
+// kind is calculated by kuota-calc itself or a handler of the kind is already registered.
+func RegisterHandler(gvk schema.GroupVersionKind, handler Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+
+	if _, ok := builtinHandler(gvk); ok {
+		panic(fmt.Sprintf("calc: %s is calculated by kuota-calc itself", gvk))
+	}
+
+	if _, ok := handlers[gvk]; ok {
+		panic(fmt.Sprintf("calc: handler of %s registered twice", gvk))
+	}
+
+	handlers[gvk] = handler
+}
+
+// RegisterCalculator registers the calculator of a kind, which is passed the object unstructured. It is meant to be
+// called from an init function and panics like RegisterHandler.
+func RegisterCalculator(gvk schema.GroupVersionKind, calculator Calculator) {
+	RegisterHandler(gvk, unstructuredHandler(calculator))
+}
+
+// kindHandler is the handler of a built-in kind.
+type kindHandler struct {
+	gvk     schema.GroupVersionKind
+	handler Handler
+}
+
+// builtinHandlers returns the handlers of the built-in kinds followed by the ones of their deprecated versions.
+func builtinHandlers() []kindHandler {
+	builtin := []kindHandler{
+		{schema.GroupVersionKind{Group: "apps.openshift.io", Version: "v1", Kind: "DeploymentConfig"}, typed(deploymentConfig)},
+		{schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, typed(deployment)},
+		{schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}, typed(statefulSet)},
+		{schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}, typed(infallible(daemonSet))},
+		{schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"}, typed(cronjob)},
+		{schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, typed(infallible(job))},
+		{schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}, typed(infallible(pod))},
+		{schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}, typed(infallible(replicaSet))},
+		{argoRolloutKind(), unstructuredHandler(argoRolloutResource)},
+		{schema.GroupVersionKind{Group: "", Version: "v1", Kind: "PersistentVolumeClaim"}, typed(infallible(persistentVolumeClaim))},
+		{schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: "BuildConfig"}, typed(infallible(buildConfig))},
+		{schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: "Build"}, typed(infallible(build))},
+		{prometheusOperatorKind("Prometheus"), unstructuredHandler(prometheusResource)},
+		{prometheusOperatorKind("Alertmanager"), unstructuredHandler(alertmanagerResource)},
+		{prometheusOperatorKind("ThanosRuler"), unstructuredHandler(thanosRulerResource)},
+		{elasticsearchKind(), unstructuredHandler(elasticsearchResource)},
+		{kubevirtKind("VirtualMachine"), unstructuredHandler(virtualMachineResource)},
+		{kubevirtKind("VirtualMachineInstance"), unstructuredHandler(virtualMachineInstanceResource)},
+	}
+
+	for _, gvk := range legacyKinds() {
+		builtin = append(builtin, kindHandler{gvk, legacyResource})
+	}
+
+	return builtin
+}
+
+// builtinHandler returns the handler of a built-in kind.
+func builtinHandler(gvk schema.GroupVersionKind) (Handler, bool) {
+	for _, h := range builtinHandlers() {
+		if h.gvk == gvk {
+			return h.handler, true
+		}
+	}
+
+	return nil, false
+}
+
+// kindHandlerOf returns the handler of a kind, built-in or registered.
+func kindHandlerOf(gvk schema.GroupVersionKind) (Handler, bool) {
+	if handler, ok := builtinHandler(gvk); ok {
+		return handler, true
+	}
+
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+
+	handler, ok := handlers[gvk]
+
+	return handler, ok
+}
+
+// typed returns the handler of a kind of the bundled api, which is passed the object by its type.
+func typed[T any](calculate func(T, Options) (*ResourceUsage, error)) Handler {
+	return func(object runtime.Object, opts Options) (*ResourceUsage, error) {
+		obj, ok := object.(*T)
+		if !ok {
+			return nil, ErrResourceNotSupported
+		}
+
+		return calculate(*obj, opts)
+	}
+}
+
+// infallible adapts the calculation of a kind, which can't fail, to typed.
+func infallible[T any](calculate func(T, Options) *ResourceUsage) func(T, Options) (*ResourceUsage, error) {
+	return func(obj T, opts Options) (*ResourceUsage, error) {
+		return calculate(obj, opts), nil
+	}
+}
+
+// unstructuredHandler returns the handler of a calculator. Kinds unknown to the bundled api are decoded from their
+// raw manifest, the others are converted from their type.
+func unstructuredHandler(calculator Calculator) Handler {
+	return func(object runtime.Object, opts Options) (*ResourceUsage, error) {
+		gvk := object.GetObjectKind().GroupVersionKind()
+
+		var (
+			content map[string]any
+			err     error
+		)
+
+		if unknown, ok := object.(*runtime.Unknown); ok {
+			err = sigsyaml.Unmarshal(unknown.Raw, &content)
+		} else {
+			content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", gvk, err)
+		}
+
+		return calculator(&unstructured.Unstructured{Object: content}, opts)
+	}
+}
+
+// SupportedKind is a kind, which kuota-calc calculates.
+type SupportedKind struct {
+	GroupVersionKind schema.GroupVersionKind
+	// Builtin is false for the kinds of registered calculators.
+	Builtin bool
+}
+
+// SupportedKinds returns the built-in kinds and their deprecated versions, followed by the kinds of the registered
+// handlers and calculators sorted by group, version and kind.
+func SupportedKinds() []SupportedKind {
+	builtin := builtinHandlers()
+	kinds := make([]SupportedKind, 0, len(builtin))
+
+	for _, h := range builtin {
+		kinds = append(kinds, SupportedKind{GroupVersionKind: h.gvk, Builtin: true})
+	}
+
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+
+	registered := make([]SupportedKind, 0, len(handlers))
+
+	for gvk := range handlers {
+		registered = append(registered, SupportedKind{GroupVersionKind: gvk})
+	}
+
+	slices.SortFunc(registered, func(a, b SupportedKind) int {
+		return cmp.Or(
+			cmp.Compare(a.GroupVersionKind.Group, b.GroupVersionKind.Group),
+			cmp.Compare(a.GroupVersionKind.Version, b.GroupVersionKind.Version),
+			cmp.Compare(a.GroupVersionKind.Kind, b.GroupVersionKind.Kind),
+		)
+	})
+
+	return append(kinds, registered...)
+}
+
+// CalcPodResources returns the resources of a single pod, for calculators of kinds with a pod template.
+func CalcPodResources(podSpec *v1.PodSpec, opts Options) *PodResources {
+	return calcPodResources(podSpec, opts)
+}
+
+// defaultUnknownNamespace sets the namespace of a kind unknown to the bundled api, if a handler is registered for it.
+func defaultUnknownNamespace(object *runtime.Unknown, namespace string) {
+	if _, ok := kindHandlerOf(object.GroupVersionKind()); !ok {
+		return
+	}
+
+	content := unstructured.Unstructured{}
+	if err := sigsyaml.Unmarshal(object.Raw, &content.Object); err != nil || content.GetNamespace() != "" {
+		return
+	}
+
+	content.SetNamespace(namespace)
+
+	// json is valid yaml, so the calculator decodes the changed object just like the original one
+	raw, err := content.MarshalJSON()
+	if err != nil {
+		return
+	}
+
+	object.Raw = raw
+}
@@ -0,0 +1,136 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var widget = `
+apiVersion: test.kuota-calc/v1
+kind: Widget
+metadata:
+  name: my-widget
+spec:
+  cpu: 250m`
+
+var gadget = `
+apiVersion: test.kuota-calc/v1
+kind: Gadget
+metadata:
+  name: my-gadget`
+
+func TestRegisterCalculator(t *testing.T) {
+	r := require.New(t)
+
+	gvk := schema.GroupVersionKind{Group: "test.kuota-calc", Version: "v1", Kind: "Widget"}
+
+	RegisterCalculator(gvk, func(object *unstructured.Unstructured, _ Options) (*ResourceUsage, error) {
+		cpu, _, err := unstructured.NestedString(object.Object, "spec", "cpu")
+		if err != nil {
+			return nil, err
+		}
+
+		return &ResourceUsage{
+			NormalResources: Resources{CPUMin: resource.MustParse(cpu)},
+			Details: Details{
+				Kind:      object.GetKind(),
+				Namespace: object.GetNamespace(),
+				Name:      object.GetName(),
+			},
+		}, nil
+	})
+
+	r.Panics(func() { RegisterCalculator(gvk, nil) })
+
+	object, err := Decode([]byte(widget))
+	r.NoError(err)
+
+	DefaultNamespace(object, "my-namespace")
+
+	usage, err := ResourceQuotaFromObject(object, Options{})
+	r.NoError(err)
+	r.Equal("Widget", usage.Details.Kind)
+	r.Equal("my-widget", usage.Details.Name)
+	r.Equal("my-namespace", usage.Details.Namespace)
+	AssertEqualQuantities(r, resource.MustParse("250m"), usage.NormalResources.CPUMin, "cpu request value")
+
+	object, err = Decode([]byte(gadget))
+	r.NoError(err)
+
+	_, err = ResourceQuotaFromObject(object, Options{})
+	r.ErrorIs(err, ErrResourceNotSupported)
+}
+
+func TestRegisterHandler(t *testing.T) {
+	r := require.New(t)
+
+	// ReplicationControllers are part of the bundled api, but not calculated by kuota-calc itself
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "ReplicationController"}
+
+	RegisterHandler(gvk, func(object runtime.Object, opts Options) (*ResourceUsage, error) {
+		rc, ok := object.(*v1.ReplicationController)
+		if !ok {
+			return nil, ErrResourceNotSupported
+		}
+
+		pod := CalcPodResources(&rc.Spec.Template.Spec, opts)
+
+		return &ResourceUsage{
+			NormalResources: pod.Containers.MulInt32(*rc.Spec.Replicas),
+			Details:         Details{Kind: "ReplicationController", Name: rc.Name},
+		}, nil
+	})
+
+	r.Panics(func() { RegisterHandler(gvk, nil) })
+	r.Panics(func() {
+		RegisterHandler(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, nil)
+	})
+
+	replicas := int32(2)
+	controller := &v1.ReplicationController{
+		Spec: v1.ReplicationControllerSpec{
+			Replicas: &replicas,
+			Template: &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{
+				Name: "app",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m")},
+				},
+			}}}},
+		},
+	}
+	controller.Name = "legacy"
+
+	// typed objects without a kind, like the ones of client-go, are looked up in the scheme
+	usage, err := ResourceQuotaFromObject(controller, Options{})
+	r.NoError(err)
+	r.Equal("legacy", usage.Details.Name)
+	AssertEqualQuantities(r, resource.MustParse("500m"), usage.NormalResources.CPUMin, "cpu request")
+}
+
+func TestSupportedKinds(t *testing.T) {
+	r := require.New(t)
+
+	gvk := schema.GroupVersionKind{Group: "test.kuota-calc", Version: "v1", Kind: "Sprocket"}
+	RegisterCalculator(gvk, func(_ *unstructured.Unstructured, _ Options) (*ResourceUsage, error) {
+		return &ResourceUsage{}, nil
+	})
+
+	kinds := SupportedKinds()
+	deploymentConfig := schema.GroupVersionKind{Group: "apps.openshift.io", Version: "v1", Kind: "DeploymentConfig"}
+	r.Equal(SupportedKind{GroupVersionKind: deploymentConfig, Builtin: true}, kinds[0])
+	r.Equal(SupportedKind{GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, Builtin: true}, kinds[6])
+	r.Contains(kinds, SupportedKind{GroupVersionKind: gvk})
+
+	legacyDeployment := schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}
+	r.Equal(SupportedKind{GroupVersionKind: legacyDeployment, Builtin: true}, kinds[18])
+
+	for _, kind := range kinds[18+len(legacyKinds()):] {
+		r.False(kind.Builtin)
+	}
+}
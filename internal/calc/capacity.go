@@ -0,0 +1,196 @@
+package calc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// SystemOverhead are the resources of each node reserved for the kubelet and system daemons, which aren't
+// reflected in the allocatable resources of the nodes.
+type SystemOverhead struct {
+	CPU    resource.Quantity
+	Memory resource.Quantity
+	// Reservation derives the allocatable resources of the nodes from their capacity. If nil, the allocatable
+	// resources of the nodes are used, as reported by their kubelet.
+	Reservation *NodeReservation
+}
+
+// NodeReservation are the resources the kubelet of each node withholds from the pods: the system-reserved and the
+// kube-reserved resources and the hard eviction threshold of the memory.
+type NodeReservation struct {
+	CPU    resource.Quantity
+	Memory resource.Quantity
+	// EvictionMemory is the hard eviction threshold of memory.available, either as quantity or as percentage of the
+	// memory capacity of the node in EvictionMemoryPercent.
+	EvictionMemory        resource.Quantity
+	EvictionMemoryPercent float64
+}
+
+// ParseNodeReservation parses the reserved resources of the kubelet, in the format of its flags: --system-reserved
+// and --kube-reserved like cpu=500m,memory=1Gi and --eviction-hard like memory.available<100Mi or
+// memory.available<5%. Empty values reserve nothing, other eviction signals than memory.available are ignored.
+func ParseNodeReservation(systemReserved, kubeReserved, evictionHard string) (*NodeReservation, error) {
+	var r NodeReservation
+
+	for _, reserved := range []string{systemReserved, kubeReserved} {
+		if reserved == "" {
+			continue
+		}
+
+		o, err := ParseSystemOverhead(reserved)
+		if err != nil {
+			return nil, err
+		}
+
+		r.CPU.Add(o.CPU)
+		r.Memory.Add(o.Memory)
+	}
+
+	for _, threshold := range strings.Split(evictionHard, ",") {
+		signal, value, found := strings.Cut(strings.TrimSpace(threshold), "<")
+		if !found || signal != "memory.available" {
+			continue
+		}
+
+		if percent, ok := strings.CutSuffix(value, "%"); ok {
+			p, err := strconv.ParseFloat(percent, 64)
+			if err != nil || p < 0 || p > 100 {
+				return nil, fmt.Errorf("invalid eviction threshold %q, must be a percentage between 0%% and 100%%", value)
+			}
+
+			r.EvictionMemoryPercent = p
+
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil || quantity.Sign() < 0 {
+			return nil, fmt.Errorf("invalid eviction threshold %q, must be a positive quantity", value)
+		}
+
+		r.EvictionMemory = quantity
+	}
+
+	return &r, nil
+}
+
+// allocatable returns the capacity of the node minus the reserved resources, like the kubelet calculates the
+// allocatable resources.
+func (r *NodeReservation) allocatable(node v1.Node) (resource.Quantity, resource.Quantity) {
+	cpu := node.Status.Capacity.Cpu().DeepCopy()
+	cpu.Sub(r.CPU)
+
+	memory := node.Status.Capacity.Memory().DeepCopy()
+	memory.Sub(r.Memory)
+	memory.Sub(r.EvictionMemory)
+
+	if r.EvictionMemoryPercent > 0 {
+		threshold := int64(float64(node.Status.Capacity.Memory().Value()) * r.EvictionMemoryPercent / 100)
+		memory.Sub(*resource.NewQuantity(threshold, resource.BinarySI))
+	}
+
+	return cpu, memory
+}
+
+// ParseSystemOverhead parses a system overhead in the form cpu=500m,memory=1Gi.
+func ParseSystemOverhead(value string) (SystemOverhead, error) {
+	var o SystemOverhead
+
+	for _, pair := range strings.Split(value, ",") {
+		name, quantityValue, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			return o, fmt.Errorf("invalid system overhead %q, expected <resource>=<quantity>", pair)
+		}
+
+		quantity, err := resource.ParseQuantity(quantityValue)
+		if err != nil || quantity.Sign() < 0 {
+			return o, fmt.Errorf("invalid system overhead %q of %s, must be a positive quantity", quantityValue, name)
+		}
+
+		switch name {
+		case "cpu":
+			o.CPU = quantity
+		case "memory":
+			o.Memory = quantity
+		default:
+			return o, fmt.Errorf("unknown resource %q in system overhead, supported are cpu and memory", name)
+		}
+	}
+
+	return o, nil
+}
+
+// allocatable returns the allocatable cpu and memory of the nodes, minus the system overhead of each node.
+func (o SystemOverhead) allocatable(nodes []v1.Node) (resource.Quantity, resource.Quantity) {
+	var cpu, memory resource.Quantity
+
+	for _, node := range nodes {
+		nodeCPU, nodeMemory := o.nodeAllocatable(node)
+
+		cpu.Add(nodeCPU)
+		cpu.Sub(o.CPU)
+		memory.Add(nodeMemory)
+		memory.Sub(o.Memory)
+	}
+
+	return cpu, memory
+}
+
+// nodeAllocatable returns the allocatable cpu and memory of a node: derived from its capacity with the reservation,
+// if one is configured, otherwise as reported by the node. Nodes without allocatable resources fall back to their
+// capacity.
+func (o SystemOverhead) nodeAllocatable(node v1.Node) (resource.Quantity, resource.Quantity) {
+	if o.Reservation != nil {
+		return o.Reservation.allocatable(node)
+	}
+
+	allocatable := node.Status.Allocatable
+	if len(allocatable) == 0 {
+		allocatable = node.Status.Capacity
+	}
+
+	return *allocatable.Cpu(), *allocatable.Memory()
+}
+
+// Capacity compares the requests with the nodes of the cluster.
+type Capacity struct {
+	Nodes int
+	// CPU and Memory are the allocatable resources of the nodes, minus the system overhead of each node.
+	CPU    resource.Quantity
+	Memory resource.Quantity
+	// NodesNeeded is the number of nodes of the average size, which fit the requests.
+	NodesNeeded int
+}
+
+// NodeCapacity returns the capacity of the nodes for the requests, with the system overhead subtracted from each node.
+func NodeCapacity(requests Resources, nodes []v1.Node, overhead SystemOverhead) (Capacity, error) {
+	if len(nodes) == 0 {
+		return Capacity{}, ErrNoNodes
+	}
+
+	c := Capacity{Nodes: len(nodes)}
+	c.CPU, c.Memory = overhead.allocatable(nodes)
+
+	if c.CPU.Sign() <= 0 || c.Memory.Sign() <= 0 {
+		return Capacity{}, fmt.Errorf("the system overhead of cpu=%s,memory=%s exceeds the allocatable resources of the nodes",
+			overhead.CPU.String(), overhead.Memory.String())
+	}
+
+	// the requests divided by the resources of an average node, rounded up
+	nodesNeeded := func(requested, usable resource.Quantity) int {
+		perNode := usable.MilliValue() / int64(len(nodes))
+		if perNode == 0 {
+			return 0
+		}
+
+		return int((requested.MilliValue() + perNode - 1) / perNode)
+	}
+
+	c.NodesNeeded = max(nodesNeeded(requests.CPUMin, c.CPU), nodesNeeded(requests.MemoryMin, c.Memory))
+
+	return c, nil
+}
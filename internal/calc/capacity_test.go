@@ -0,0 +1,190 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseSystemOverhead(t *testing.T) {
+	var tests = []struct {
+		value    string
+		overhead SystemOverhead
+		err      bool
+	}{
+		{
+			value:    "cpu=500m,memory=1Gi",
+			overhead: SystemOverhead{CPU: resource.MustParse("500m"), Memory: resource.MustParse("1Gi")},
+		},
+		{value: "memory=512Mi", overhead: SystemOverhead{Memory: resource.MustParse("512Mi")}},
+		{value: "cpu", err: true},
+		{value: "cpu=-1", err: true},
+		{value: "cpu=lots", err: true},
+		{value: "storage=1Gi", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			r := require.New(t)
+
+			overhead, err := ParseSystemOverhead(test.value)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			AssertEqualQuantities(r, test.overhead.CPU, overhead.CPU, "cpu value")
+			AssertEqualQuantities(r, test.overhead.Memory, overhead.Memory, "memory value")
+		})
+	}
+}
+
+func TestNodeCapacity(t *testing.T) {
+	node := testNode("worker-1", nil)
+	node.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+	}
+
+	nodes := []v1.Node{node, node, node}
+	requests := Resources{CPUMin: resource.MustParse("7"), MemoryMin: resource.MustParse("20Gi")}
+
+	var tests = []struct {
+		name        string
+		nodes       []v1.Node
+		overhead    SystemOverhead
+		err         bool
+		cpu         resource.Quantity
+		memory      resource.Quantity
+		nodesNeeded int
+	}{
+		{
+			name:        "without overhead",
+			nodes:       nodes,
+			cpu:         resource.MustParse("12"),
+			memory:      resource.MustParse("48Gi"),
+			nodesNeeded: 2,
+		},
+		{
+			name:        "with overhead",
+			nodes:       nodes,
+			overhead:    SystemOverhead{CPU: resource.MustParse("1"), Memory: resource.MustParse("2Gi")},
+			cpu:         resource.MustParse("9"),
+			memory:      resource.MustParse("42Gi"),
+			nodesNeeded: 3,
+		},
+		{
+			name:     "overhead exceeds nodes",
+			nodes:    nodes,
+			overhead: SystemOverhead{CPU: resource.MustParse("4")},
+			err:      true,
+		},
+		{
+			name: "no nodes",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			capacity, err := NodeCapacity(requests, test.nodes, test.overhead)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(len(test.nodes), capacity.Nodes, "nodes")
+			AssertEqualQuantities(r, test.cpu, capacity.CPU, "cpu value")
+			AssertEqualQuantities(r, test.memory, capacity.Memory, "memory value")
+			r.Equal(test.nodesNeeded, capacity.NodesNeeded, "nodes needed")
+		})
+	}
+}
+
+func TestParseNodeReservation(t *testing.T) {
+	var tests = []struct {
+		name         string
+		system, kube string
+		eviction     string
+		reservation  NodeReservation
+		err          bool
+	}{
+		{
+			name:        "reserved resources add up",
+			system:      "cpu=500m,memory=1Gi",
+			kube:        "cpu=100m,memory=512Mi",
+			reservation: NodeReservation{CPU: resource.MustParse("600m"), Memory: resource.MustParse("1536Mi")},
+		},
+		{
+			name:        "eviction threshold",
+			eviction:    "memory.available<100Mi,nodefs.available<10%",
+			reservation: NodeReservation{EvictionMemory: resource.MustParse("100Mi")},
+		},
+		{
+			name:        "eviction threshold in percent",
+			eviction:    "memory.available<5%",
+			reservation: NodeReservation{EvictionMemoryPercent: 5},
+		},
+		{name: "invalid reserved resources", kube: "cpu", err: true},
+		{name: "invalid eviction threshold", eviction: "memory.available<lots", err: true},
+		{name: "invalid eviction percentage", eviction: "memory.available<120%", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			reservation, err := ParseNodeReservation(test.system, test.kube, test.eviction)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			AssertEqualQuantities(r, test.reservation.CPU, reservation.CPU, "cpu value")
+			AssertEqualQuantities(r, test.reservation.Memory, reservation.Memory, "memory value")
+			AssertEqualQuantities(r, test.reservation.EvictionMemory, reservation.EvictionMemory, "eviction memory value")
+			r.InDelta(test.reservation.EvictionMemoryPercent, reservation.EvictionMemoryPercent, 0.001)
+		})
+	}
+}
+
+func TestNodeCapacityWithReservation(t *testing.T) {
+	r := require.New(t)
+
+	node := testNode("worker-1", nil)
+	node.Status.Capacity = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+	}
+
+	requests := Resources{CPUMin: resource.MustParse("7"), MemoryMin: resource.MustParse("20Gi")}
+
+	// nodes without allocatable resources fall back to their capacity
+	capacity, err := NodeCapacity(requests, []v1.Node{node, node}, SystemOverhead{})
+	r.NoError(err)
+	AssertEqualQuantities(r, resource.MustParse("8"), capacity.CPU, "cpu value")
+
+	reservation, err := ParseNodeReservation("cpu=500m,memory=1Gi", "cpu=500m,memory=1Gi", "memory.available<10%")
+	r.NoError(err)
+
+	node.Status.Allocatable = node.Status.Capacity
+
+	capacity, err = NodeCapacity(requests, []v1.Node{node, node}, SystemOverhead{Reservation: reservation})
+	r.NoError(err)
+
+	// 4 - 1 cpu and 16Gi - 2Gi - 1638.4Mi memory per node
+	AssertEqualQuantities(r, resource.MustParse("6"), capacity.CPU, "cpu value")
+	AssertEqualQuantities(r, *resource.NewQuantity(2*(14*1024*1024*1024-int64(16*1024*1024*1024/10)), resource.BinarySI),
+		capacity.Memory, "memory value")
+	r.Equal(3, capacity.NodesNeeded)
+}
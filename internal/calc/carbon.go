@@ -0,0 +1,115 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// wattsPerCPU is the average power of a vCPU at 50% utilization, between 0.74W idle and 3.5W at full load.
+	wattsPerCPU = 2.12
+	// wattsPerGB is the power of a GB of memory.
+	wattsPerGB = 0.392
+	// hoursPerMonth is the average number of hours of a month.
+	hoursPerMonth = 730
+	// defaultPUE is the power usage effectiveness of a data center, if the provider is unknown.
+	defaultPUE = 1.2
+)
+
+// CarbonFootprint converts resources into the energy and the emissions of running them for a month, following the
+// methodology of Cloud Carbon Footprint: the power of the cpus and the memory, times the power usage effectiveness (PUE)
+// of the data center, times the carbon intensity of the grid.
+// https://www.cloudcarbonfootprint.org/docs/methodology
+type CarbonFootprint struct {
+	// GridIntensity is the carbon intensity of the grid in gCO2e per kWh.
+	GridIntensity float64
+	// PUE is the power usage effectiveness of the data center, the total energy per energy of the servers.
+	PUE float64
+}
+
+// CarbonEstimate is the energy and the emissions of running resources for a month.
+type CarbonEstimate struct {
+	KWhPerMonth    float64
+	KgCO2ePerMonth float64
+}
+
+// carbonRegions returns the approximate yearly average carbon intensity of the grid of common cloud regions, by
+// provider/region. The intensities vary by year and hour, give --carbon-grid-intensity for precise values.
+func carbonRegions() map[string]float64 {
+	return map[string]float64{
+		"aws/us-east-1":       379,
+		"aws/us-west-2":       136,
+		"aws/eu-central-1":    338,
+		"aws/eu-west-1":       316,
+		"aws/eu-north-1":      9,
+		"gcp/us-central1":     479,
+		"gcp/europe-west1":    167,
+		"gcp/europe-west4":    390,
+		"azure/westeurope":    390,
+		"azure/northeurope":   316,
+		"azure/eastus":        379,
+		"azure/swedencentral": 9,
+	}
+}
+
+// providerPUEs returns the power usage effectiveness published by the cloud providers.
+func providerPUEs() map[string]float64 {
+	return map[string]float64{
+		"aws":   1.135,
+		"gcp":   1.1,
+		"azure": 1.185,
+	}
+}
+
+// NewCarbonFootprint returns the carbon footprint of a cloud region in the form provider/region, e.g.
+// aws/eu-central-1, or of the given grid intensity in gCO2e per kWh. A positive grid intensity overrides the one of
+// the region, the provider of the region still selects the PUE.
+func NewCarbonFootprint(region string, gridIntensity float64) (CarbonFootprint, error) {
+	if gridIntensity < 0 {
+		return CarbonFootprint{}, fmt.Errorf("invalid carbon grid intensity %v, must be positive", gridIntensity)
+	}
+
+	c := CarbonFootprint{GridIntensity: gridIntensity, PUE: defaultPUE}
+
+	if region == "" {
+		return c, nil
+	}
+
+	provider, _, _ := strings.Cut(region, "/")
+	if pue, ok := providerPUEs()[provider]; ok {
+		c.PUE = pue
+	}
+
+	if gridIntensity > 0 {
+		return c, nil
+	}
+
+	intensity, ok := carbonRegions()[region]
+	if !ok {
+		regions := make([]string, 0, len(carbonRegions()))
+		for name := range carbonRegions() {
+			regions = append(regions, name)
+		}
+
+		slices.Sort(regions)
+
+		return CarbonFootprint{}, fmt.Errorf("unknown carbon region %q, give its grid intensity instead or use one of %s",
+			region, strings.Join(regions, ", "))
+	}
+
+	c.GridIntensity = intensity
+
+	return c, nil
+}
+
+// Estimate returns the energy and the emissions of running the requested cpus and memory for a month.
+func (c CarbonFootprint) Estimate(r Resources) CarbonEstimate {
+	watts := r.CPUMin.AsApproximateFloat64()*wattsPerCPU + r.MemoryMin.AsApproximateFloat64()/1e9*wattsPerGB
+	kWh := watts * hoursPerMonth / 1000 * c.PUE
+
+	return CarbonEstimate{
+		KWhPerMonth:    kWh,
+		KgCO2ePerMonth: kWh * c.GridIntensity / 1000,
+	}
+}
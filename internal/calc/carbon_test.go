@@ -0,0 +1,67 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCarbonFootprint(t *testing.T) {
+	resources := Resources{
+		CPUMin:    resource.MustParse("4"),
+		CPUMax:    resource.MustParse("8"),
+		MemoryMin: resource.MustParse("8G"),
+	}
+
+	var tests = []struct {
+		name          string
+		region        string
+		gridIntensity float64
+		kWh           float64
+		kgCO2e        float64
+	}{
+		{
+			name:   "region",
+			region: "aws/eu-central-1",
+			kWh:    9.6244,
+			kgCO2e: 3.2531,
+		},
+		{
+			name:          "grid intensity overrides the region",
+			region:        "gcp/europe-west1",
+			gridIntensity: 100,
+			kWh:           9.3276,
+			kgCO2e:        0.9328,
+		},
+		{
+			name:          "grid intensity without region",
+			gridIntensity: 100,
+			kWh:           10.1756,
+			kgCO2e:        1.0176,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			footprint, err := NewCarbonFootprint(test.region, test.gridIntensity)
+			r.NoError(err)
+
+			estimate := footprint.Estimate(resources)
+			r.InDelta(test.kWh, estimate.KWhPerMonth, 0.0001)
+			r.InDelta(test.kgCO2e, estimate.KgCO2ePerMonth, 0.0001)
+		})
+	}
+}
+
+func TestCarbonFootprintInvalid(t *testing.T) {
+	r := require.New(t)
+
+	_, err := NewCarbonFootprint("aws/mars-1", 0)
+	r.Error(err)
+
+	_, err = NewCarbonFootprint("", -1)
+	r.Error(err)
+}
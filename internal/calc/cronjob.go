@@ -1,23 +1,123 @@
 package calc
 
-import batchV1 "k8s.io/api/batch/v1"
+import (
+	"fmt"
+	"time"
 
-func cronjob(cronjob batchV1.CronJob) *ResourceUsage {
-	podResources := calcPodResources(&cronjob.Spec.JobTemplate.Spec.Template.Spec)
+	"github.com/robfig/cron/v3"
+	batchV1 "k8s.io/api/batch/v1"
+)
+
+const (
+	// maxScheduleRuns limits how many runs of a schedule are evaluated to estimate overlapping runs.
+	maxScheduleRuns = 50000
+	// scheduleHorizon is the minimal time span of a schedule, which is evaluated to estimate overlapping runs.
+	scheduleHorizon = 7 * 24 * time.Hour
+)
+
+// calculates the cpu/memory resources a single cronjob needs. Runs of a cronjob with the concurrencyPolicy Allow
+// overlap, if a job runs longer than the interval of its schedule. How long a job can run is only known if it has
+// an activeDeadlineSeconds, in addition it might start up to startingDeadlineSeconds late. Without a deadline a
+// single run is assumed. Suspended cronjobs don't need any resources.
+func cronjob(cronjob batchV1.CronJob, opts Options) (*ResourceUsage, error) {
+	var concurrentRuns int32 = 1
+
+	// a suspended cronjob doesn't start any jobs, like a deployment scaled to zero doesn't run any pods
+	if cronjob.Spec.Suspend != nil && *cronjob.Spec.Suspend {
+		resourceUsage := ResourceUsage{
+			Details: Details{
+				Version:           cronjob.APIVersion,
+				Kind:              cronjob.Kind,
+				Namespace:         cronjob.Namespace,
+				Name:              cronjob.Name,
+				PriorityClassName: cronjob.Spec.JobTemplate.Spec.Template.Spec.PriorityClassName,
+				Suspended:         true,
+			},
+		}
+
+		resourceUsage.explainf(opts, "suspended: no jobs are started")
+
+		return &resourceUsage, nil
+	}
+
+	jobSpec := cronjob.Spec.JobTemplate.Spec
+
+	policy := cronjob.Spec.ConcurrencyPolicy
+	if (policy == batchV1.AllowConcurrent || policy == "") && jobSpec.ActiveDeadlineSeconds != nil {
+		lifetime := time.Duration(*jobSpec.ActiveDeadlineSeconds) * time.Second
+		if cronjob.Spec.StartingDeadlineSeconds != nil {
+			lifetime += time.Duration(*cronjob.Spec.StartingDeadlineSeconds) * time.Second
+		}
+
+		runs, err := overlappingRuns(cronjob.Spec.Schedule, lifetime)
+		if err != nil {
+			return nil, fmt.Errorf("cronjob: %s: %w", cronjob.Name, err)
+		}
+
+		concurrentRuns = runs
+	}
+
+	podResources := annotatedPodResources(cronjob.ObjectMeta, jobSpec.Template.ObjectMeta, &jobSpec.Template.Spec, opts)
+	retryResources := opts.jobRetryResources(&jobSpec, podResources)
 
 	resourceUsage := ResourceUsage{
 		// TODO should jobs always be considered with their rollout resources?
-		NormalResources:  podResources.Containers,
-		RolloutResources: podResources.MaxResources,
+		NormalResources:  podResources.Containers.MulInt32(concurrentRuns),
+		RolloutResources: podResources.MaxResources.Add(retryResources).MulInt32(concurrentRuns),
+		Pod:              podResources,
 		Details: Details{
-			Version:     cronjob.APIVersion,
-			Kind:        cronjob.Kind,
-			Name:        cronjob.Name,
-			Strategy:    "",
-			Replicas:    0,
-			MaxReplicas: 0,
+			Version:           cronjob.APIVersion,
+			Kind:              cronjob.Kind,
+			Namespace:         cronjob.Namespace,
+			Name:              cronjob.Name,
+			PriorityClassName: cronjob.Spec.JobTemplate.Spec.Template.Spec.PriorityClassName,
+			Strategy:          "",
+			Replicas:          0,
+			MaxReplicas:       0,
 		},
 	}
 
-	return &resourceUsage
+	resourceUsage.explainf(opts, "concurrent runs: %d", concurrentRuns)
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "retry overlap: %s", retryResources)
+	resourceUsage.explainf(opts, "normal = containers * %d", concurrentRuns)
+	resourceUsage.explainf(opts, "rollout = (max + retry overlap) * %d", concurrentRuns)
+
+	if opts.Timeline {
+		resourceUsage.Timeline = staticTimeline(&resourceUsage, newPodTimings(&jobSpec.Template.Spec, 0, opts))
+	}
+
+	return &resourceUsage, nil
+}
+
+// overlappingRuns returns the maximum number of runs of the cron schedule, which can run at the same time if each
+// run lasts for the given lifetime.
+func overlappingRuns(schedule string, lifetime time.Duration) (int32, error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return 0, fmt.Errorf("parsing schedule %q: %w", schedule, err)
+	}
+
+	// the result doesn't depend on the start, a fixed one just keeps the calculation reproducible
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(scheduleHorizon + lifetime)
+
+	var runs []time.Time
+
+	for next := sched.Next(start); !next.IsZero() && next.Before(end) && len(runs) < maxScheduleRuns; next = sched.Next(next) {
+		runs = append(runs, next)
+	}
+
+	// sliding window over all runs, counting the runs that started less than lifetime ago
+	var maxRuns, first int
+
+	for i := range runs {
+		for first < i && runs[i].Sub(runs[first]) >= lifetime {
+			first++
+		}
+
+		maxRuns = max(maxRuns, i-first+1)
+	}
+
+	return int32(max(maxRuns, 1)), nil
 }
@@ -1,7 +1,9 @@
 package calc
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -27,6 +29,22 @@ func TestCronJob(t *testing.T) {
 			memoryMin: resource.MustParse("2Gi"),
 			memoryMax: resource.MustParse("4Gi"),
 		},
+		{
+			name:      "overlapping runs bounded by the deadlines",
+			cronjob:   frequentCronJob,
+			cpuMin:    resource.MustParse("1"),
+			cpuMax:    resource.MustParse("4"),
+			memoryMin: resource.MustParse("8Gi"),
+			memoryMax: resource.MustParse("16Gi"),
+		},
+		{
+			name:      "concurrent runs forbidden",
+			cronjob:   forbidConcurrentCronJob,
+			cpuMin:    resource.MustParse("250m"),
+			cpuMax:    resource.MustParse("1"),
+			memoryMin: resource.MustParse("2Gi"),
+			memoryMax: resource.MustParse("4Gi"),
+		},
 	}
 
 	for _, test := range tests {
@@ -34,7 +52,7 @@ func TestCronJob(t *testing.T) {
 			test.name, func(t *testing.T) {
 				r := require.New(t)
 
-				usage, err := ResourceQuotaFromYaml([]byte(test.cronjob))
+				usage, err := ResourceQuotaFromYaml([]byte(test.cronjob), Options{})
 				r.NoError(err)
 				r.NotEmpty(usage)
 
@@ -49,3 +67,54 @@ func TestCronJob(t *testing.T) {
 		)
 	}
 }
+
+func TestSuspendedCronJob(t *testing.T) {
+	r := require.New(t)
+
+	suspended := strings.Replace(normalCronJob, "spec:\n  schedule:", "spec:\n  suspend: true\n  schedule:", 1)
+
+	usage, err := ResourceQuotaFromYaml([]byte(suspended), Options{})
+	r.NoError(err)
+	r.True(usage.Details.Suspended)
+	r.True(usage.ScaledToZero())
+	r.True(usage.NormalResources.CPUMin.IsZero())
+	r.True(usage.RolloutResources.MemoryMin.IsZero())
+
+	usage, err = ResourceQuotaFromYaml([]byte(normalCronJob), Options{})
+	r.NoError(err)
+	r.False(usage.ScaledToZero())
+}
+
+func TestCronJobInvalidSchedule(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(invalidScheduleCronJob), Options{})
+	r.Error(err)
+	r.Nil(usage)
+	r.ErrorContains(err, "every five minutes")
+}
+
+func TestOverlappingRuns(t *testing.T) {
+	var tests = []struct {
+		name     string
+		schedule string
+		lifetime time.Duration
+		runs     int32
+	}{
+		{name: "every minute", schedule: "* * * * *", lifetime: 10 * time.Minute, runs: 10},
+		{name: "shorter than the interval", schedule: "@hourly", lifetime: 30 * time.Minute, runs: 1},
+		{name: "exactly the interval", schedule: "0 * * * *", lifetime: time.Hour, runs: 1},
+		{name: "irregular schedule", schedule: "0,1,2 * * * *", lifetime: 30 * time.Minute, runs: 3},
+		{name: "daily", schedule: "30 2 * * *", lifetime: 36 * time.Hour, runs: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			runs, err := overlappingRuns(test.schedule, test.lifetime)
+			r.NoError(err)
+			r.Equal(test.runs, runs)
+		})
+	}
+}
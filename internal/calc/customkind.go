@@ -0,0 +1,238 @@
+package calc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// CustomKinds describe where the replicas and the pod templates of kinds unknown to kuota-calc are, e.g. the custom
+// resources of an operator, so they are calculated without a calculator of their own.
+type CustomKinds struct {
+	Kinds []CustomKind `json:"kinds"`
+}
+
+// CustomKind describes a kind by the JSONPaths of its replicas and its pod templates, e.g. {.spec.replicas} and
+// {.spec.template}. A path may select several pod templates, e.g. {.spec.pools[*].template}, the replicas path then
+// selects the replicas of each, e.g. {.spec.pools[*].replicas}.
+type CustomKind struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Replicas is the path of the replicas. Without it, or if the object doesn't set them, the replicas are assumed.
+	Replicas string `json:"replicas,omitempty"`
+	// PodTemplate is the path of the pod templates. A pod spec, an object with containers, is accepted as well.
+	PodTemplate string `json:"podTemplate"`
+}
+
+// Validate checks the kinds and parses their paths.
+func (k *CustomKinds) Validate() error {
+	for _, kind := range k.Kinds {
+		if _, err := schema.ParseGroupVersion(kind.APIVersion); err != nil || kind.APIVersion == "" || kind.Kind == "" {
+			return fmt.Errorf("custom kind %s %s: apiVersion and kind are required", kind.APIVersion, kind.Kind)
+		}
+
+		if kind.PodTemplate == "" {
+			return fmt.Errorf("custom kind %s %s: podTemplate is required", kind.APIVersion, kind.Kind)
+		}
+
+		for _, path := range []string{kind.Replicas, kind.PodTemplate} {
+			if path == "" {
+				continue
+			}
+
+			if _, err := parseJSONPath(path); err != nil {
+				return fmt.Errorf("custom kind %s %s: invalid path %q: %w", kind.APIVersion, kind.Kind, path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// kind returns the description of a kind, if there is one.
+func (k *CustomKinds) kind(gvk schema.GroupVersionKind) (CustomKind, bool) {
+	if k == nil {
+		return CustomKind{}, false
+	}
+
+	for _, kind := range k.Kinds {
+		if kind.APIVersion == gvk.GroupVersion().String() && kind.Kind == gvk.Kind {
+			return kind, true
+		}
+	}
+
+	return CustomKind{}, false
+}
+
+// parseJSONPath parses a JSONPath, the braces may be left out, e.g. .spec.replicas.
+func parseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+
+	parser := jsonpath.New("custom kind").AllowMissingKeys(true)
+
+	return parser, parser.Parse(path)
+}
+
+// findJSONPath returns the values of the path in the object.
+func findJSONPath(object map[string]any, path string) ([]any, error) {
+	parser, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := parser.FindResults(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []any
+
+	for _, result := range results {
+		for _, value := range result {
+			if value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer {
+				value = value.Elem()
+			}
+
+			if value.IsValid() {
+				values = append(values, value.Interface())
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// customKindResource calculates a kind described by the custom kinds from its pod templates. As the rollout of the
+// kind is unknown, all pods are assumed to start at once, like for the heuristic.
+func customKindResource(object *runtime.Unknown, kind CustomKind, opts Options) (*ResourceUsage, error) {
+	// decoding the json keeps the integer replicas as int64
+	data, err := sigsyaml.YAMLToJSON(object.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", object.GroupVersionKind(), err)
+	}
+
+	content := unstructured.Unstructured{}
+	if err := content.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", object.GroupVersionKind(), err)
+	}
+
+	templates, err := findJSONPath(content.Object, kind.PodTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("finding the pod template %s: %w", kind.PodTemplate, err)
+	}
+
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("%w: no pod template found in %s", ErrResourceNotSupported, kind.PodTemplate)
+	}
+
+	replicas, err := customKindReplicas(content.Object, kind, len(templates))
+	if err != nil {
+		return nil, err
+	}
+
+	resourceUsage := ResourceUsage{
+		Details: Details{
+			Version:   content.GetAPIVersion(),
+			Kind:      content.GetKind(),
+			Namespace: content.GetNamespace(),
+			Name:      content.GetName(),
+		},
+	}
+
+	for i, template := range templates {
+		podSpec, err := customKindPodSpec(template)
+		if err != nil {
+			return nil, fmt.Errorf("decoding pod template %s: %w", kind.PodTemplate, err)
+		}
+
+		podReplicas, assumed := opts.replicas(replicas[i])
+		podResources := calcPodResources(podSpec, opts)
+
+		resourceUsage.NormalResources = resourceUsage.NormalResources.Add(podResources.Containers.MulInt32(podReplicas))
+		resourceUsage.RolloutResources = resourceUsage.RolloutResources.Add(podResources.MaxResources.MulInt32(podReplicas))
+		resourceUsage.Details.Replicas += podReplicas
+		resourceUsage.Details.ReplicasAssumed = resourceUsage.Details.ReplicasAssumed || assumed
+
+		if resourceUsage.Pod == nil {
+			resourceUsage.Pod = podResources
+			resourceUsage.Details.PriorityClassName = podSpec.PriorityClassName
+		}
+
+		resourceUsage.explainPod(opts, podResources)
+		resourceUsage.explainf(opts, "pod template %d: normal = containers * %d, rollout = max * %d, the rollout of the kind is unknown",
+			i+1, podReplicas, podReplicas)
+	}
+
+	resourceUsage.Details.MaxReplicas = resourceUsage.Details.Replicas
+	resourceUsage.Details.NormalReplicas = resourceUsage.Details.Replicas
+
+	return &resourceUsage, nil
+}
+
+// customKindReplicas returns the replicas of each pod template, nil for the ones which don't set them. A single
+// replicas value applies to all pod templates.
+func customKindReplicas(object map[string]any, kind CustomKind, templates int) ([]*int32, error) {
+	replicas := make([]*int32, templates)
+
+	if kind.Replicas == "" {
+		return replicas, nil
+	}
+
+	values, err := findJSONPath(object, kind.Replicas)
+	if err != nil {
+		return nil, fmt.Errorf("finding the replicas %s: %w", kind.Replicas, err)
+	}
+
+	if len(values) == 0 {
+		return replicas, nil
+	}
+
+	if len(values) != 1 && len(values) != templates {
+		return nil, fmt.Errorf("found %d replicas in %s for %d pod templates", len(values), kind.Replicas, templates)
+	}
+
+	for i := range replicas {
+		value := values[0]
+		if len(values) == templates {
+			value = values[i]
+		}
+
+		count, ok := value.(int64)
+		if !ok {
+			return nil, fmt.Errorf("replicas %s must be an integer, got %v", kind.Replicas, value)
+		}
+
+		replica := int32(count) //nolint:gosec // replicas are int32 in kubernetes
+		replicas[i] = &replica
+	}
+
+	return replicas, nil
+}
+
+// customKindPodSpec decodes the pod spec of a pod template or the pod spec itself.
+func customKindPodSpec(template any) (*v1.PodSpec, error) {
+	content, ok := template.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %v", template)
+	}
+
+	if spec, ok := content["spec"].(map[string]any); ok {
+		content = spec
+	}
+
+	var podSpec v1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &podSpec); err != nil {
+		return nil, err
+	}
+
+	return &podSpec, nil
+}
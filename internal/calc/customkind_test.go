@@ -0,0 +1,81 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var workerPool = `
+apiVersion: example.com/v1
+kind: WorkerPool
+metadata:
+  name: workers
+  namespace: jobs
+spec:
+  pools:
+  - size: 3
+    template:
+      spec:
+        containers:
+        - name: small
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+  - size: 2
+    template:
+      spec:
+        containers:
+        - name: large
+          resources:
+            requests:
+              cpu: "1"
+              memory: 1Gi`
+
+func TestCustomKinds(t *testing.T) {
+	r := require.New(t)
+
+	kinds := &CustomKinds{Kinds: []CustomKind{{
+		APIVersion:  "example.com/v1",
+		Kind:        "WorkerPool",
+		Replicas:    "{.spec.pools[*].size}",
+		PodTemplate: ".spec.pools[*].template",
+	}}}
+	r.NoError(kinds.Validate())
+
+	object, err := Decode([]byte(workerPool))
+	r.NoError(err)
+
+	// without a description the kind stays unsupported
+	_, err = ResourceQuotaFromObject(object, Options{})
+	r.ErrorIs(err, ErrResourceNotSupported)
+
+	usage, err := ResourceQuotaFromObject(object, Options{CustomKinds: kinds})
+	r.NoError(err)
+	r.Equal("WorkerPool", usage.Details.Kind)
+	r.Equal("jobs", usage.Details.Namespace)
+	r.Equal(int32(5), usage.Details.Replicas)
+	AssertEqualQuantities(r, resource.MustParse("2300m"), usage.NormalResources.CPUMin, "cpu request")
+	AssertEqualQuantities(r, resource.MustParse("2432Mi"), usage.RolloutResources.MemoryMin, "memory request")
+	AssertEqualQuantities(r, resource.MustParse("5"), usage.NormalResources.Pods, "pods")
+
+	// a single replicas value applies to every pod template
+	kinds.Kinds[0].Replicas = ".spec.pools[0].size"
+	usage, err = ResourceQuotaFromObject(object, Options{CustomKinds: kinds})
+	r.NoError(err)
+	r.Equal(int32(6), usage.Details.Replicas)
+}
+
+func TestCustomKindsValidate(t *testing.T) {
+	r := require.New(t)
+
+	for _, invalid := range []CustomKind{
+		{Kind: "Worker", PodTemplate: ".spec.template"},
+		{APIVersion: "example.com/v1", Kind: "Worker"},
+		{APIVersion: "example.com/v1", Kind: "Worker", PodTemplate: "{.spec.template"},
+	} {
+		r.Error((&CustomKinds{Kinds: []CustomKind{invalid}}).Validate(), invalid)
+	}
+}
@@ -4,21 +4,51 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 )
 
-func daemonSet(dSet appsv1.DaemonSet) *ResourceUsage {
-	podResources := calcPodResources(&dSet.Spec.Template.Spec)
+// calculates the cpu/memory resources a single daemonset needs. Without nodes, a single pod is assumed. Otherwise
+// there is a pod on every node the daemonset can be scheduled on, which are updated one at a time.
+func daemonSet(dSet appsv1.DaemonSet, opts Options) *ResourceUsage {
+	var replicas int32 = 1
+
+	if len(opts.Nodes) > 0 {
+		replicas = daemonSetNodes(&dSet.Spec.Template.Spec, opts.Nodes)
+	}
+
+	podResources := annotatedPodResources(dSet.ObjectMeta, dSet.Spec.Template.ObjectMeta, &dSet.Spec.Template.Spec, opts)
+
+	var rolloutResources Resources
+	if replicas > 0 {
+		rolloutResources = podResources.Containers.MulInt32(replicas - 1).Add(podResources.MaxResources)
+	}
 
 	resourceUsage := ResourceUsage{
-		NormalResources:  podResources.Containers,
-		RolloutResources: podResources.MaxResources,
+		NormalResources:  podResources.Containers.MulInt32(replicas),
+		RolloutResources: rolloutResources,
+		Pod:              podResources,
 		Details: Details{
-			Version:     dSet.APIVersion,
-			Kind:        dSet.Kind,
-			Name:        dSet.Name,
-			Strategy:    "",
-			Replicas:    1,
-			MaxReplicas: 1,
+			Version:           dSet.APIVersion,
+			Kind:              dSet.Kind,
+			Namespace:         dSet.Namespace,
+			Name:              dSet.Name,
+			PriorityClassName: dSet.Spec.Template.Spec.PriorityClassName,
+			Strategy:          "",
+			Replicas:          replicas,
+			MaxReplicas:       replicas,
 		},
 	}
 
+	if len(opts.Nodes) > 0 {
+		resourceUsage.explainf(opts, "pods: %d, one on each node the daemonset can be scheduled on", replicas)
+	} else {
+		resourceUsage.explainf(opts, "pods: 1, no nodes given")
+	}
+
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "normal = containers * %d", replicas)
+	resourceUsage.explainf(opts, "rollout = containers * (%d pods - 1 updated) + max", replicas)
+
+	if opts.Timeline {
+		resourceUsage.Timeline = staticTimeline(&resourceUsage, newPodTimings(&dSet.Spec.Template.Spec, dSet.Spec.MinReadySeconds, opts))
+	}
+
 	return &resourceUsage
 }
@@ -12,6 +12,7 @@ func TestDaemonSet(t *testing.T) {
 	var tests = []struct {
 		name        string
 		daemonset   string
+		opts        Options
 		cpuMin      resource.Quantity
 		cpuMax      resource.Quantity
 		memoryMin   resource.Quantity
@@ -30,6 +31,28 @@ func TestDaemonSet(t *testing.T) {
 			memoryMin:   resource.MustParse("200Mi"),
 			memoryMax:   resource.MustParse("2Gi"),
 		},
+		{
+			name:        "on all nodes without untolerated taints",
+			daemonset:   normalDaemonSet,
+			opts:        Options{Nodes: testNodes()},
+			replicas:    4,
+			maxReplicas: 4,
+			cpuMin:      resource.MustParse("2"),
+			cpuMax:      resource.MustParse("8"),
+			memoryMin:   resource.MustParse("800Mi"),
+			memoryMax:   resource.MustParse("8Gi"),
+		},
+		{
+			name:        "on selected and tolerated nodes",
+			daemonset:   selectiveDaemonSet,
+			opts:        Options{Nodes: testNodes()},
+			replicas:    4,
+			maxReplicas: 4,
+			cpuMin:      resource.MustParse("2"),
+			cpuMax:      resource.MustParse("8"),
+			memoryMin:   resource.MustParse("800Mi"),
+			memoryMax:   resource.MustParse("8Gi"),
+		},
 	}
 
 	for _, test := range tests {
@@ -37,7 +60,7 @@ func TestDaemonSet(t *testing.T) {
 			test.name, func(t *testing.T) {
 				r := require.New(t)
 
-				usage, err := ResourceQuotaFromYaml([]byte(test.daemonset))
+				usage, err := ResourceQuotaFromYaml([]byte(test.daemonset), test.opts)
 				r.NoError(err)
 				r.NotEmpty(usage)
 
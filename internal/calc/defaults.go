@@ -0,0 +1,102 @@
+package calc
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// PlatformKubernetes selects the strategy defaults of kubernetes.
+	PlatformKubernetes = "kubernetes"
+	// PlatformOpenShift selects the strategy defaults of openshift.
+	PlatformOpenShift = "openshift"
+)
+
+// StrategyDefaults are applied to the rollout strategies of resources, which don't specify all values themselves.
+type StrategyDefaults struct {
+	Deployment       RollingUpdateDefaults `json:"deployment"`
+	DeploymentConfig RollingUpdateDefaults `json:"deploymentConfig"`
+	// StatefulSet only uses MaxUnavailable, StatefulSets never surge.
+	StatefulSet RollingUpdateDefaults `json:"statefulSet"`
+}
+
+// RollingUpdateDefaults are the defaults of a rolling update strategy.
+type RollingUpdateDefaults struct {
+	MaxSurge       intstr.IntOrString `json:"maxSurge"`
+	MaxUnavailable intstr.IntOrString `json:"maxUnavailable"`
+	// Paired only defaults both values together. A value, which is missing while the other one is set to something
+	// other than 0, is 0 instead of its default.
+	Paired bool `json:"paired,omitempty"`
+}
+
+// BuiltinStrategyDefaults returns the strategy defaults the given platform applies. The defaults of the current api
+// versions didn't change between kubernetes versions, the ones of deprecated api versions like extensions/v1beta1 are
+// applied when they are converted.
+func BuiltinStrategyDefaults(platform string) (StrategyDefaults, error) {
+	switch platform {
+	case PlatformKubernetes, "":
+		// https://pkg.go.dev/k8s.io/api/apps/v1#RollingUpdateDeployment
+		// https://pkg.go.dev/k8s.io/api/apps/v1#RollingUpdateStatefulSetStrategy
+		// DeploymentConfigs don't exist in kubernetes, they are calculated like Deployments.
+		return StrategyDefaults{
+			Deployment:       RollingUpdateDefaults{MaxSurge: intstr.FromString("25%"), MaxUnavailable: intstr.FromString("25%")},
+			DeploymentConfig: RollingUpdateDefaults{MaxSurge: intstr.FromString("25%"), MaxUnavailable: intstr.FromString("25%")},
+			StatefulSet:      RollingUpdateDefaults{MaxUnavailable: intstr.FromInt32(1)},
+		}, nil
+	case PlatformOpenShift:
+		// https://pkg.go.dev/github.com/openshift/api/apps/v1#RollingDeploymentStrategyParams
+		// openshift only defaults maxSurge and maxUnavailable of a DeploymentConfig to 25%, if both are missing or the
+		// other one is 0. Otherwise the missing one is 0.
+		return StrategyDefaults{
+			Deployment: RollingUpdateDefaults{MaxSurge: intstr.FromString("25%"), MaxUnavailable: intstr.FromString("25%")},
+			DeploymentConfig: RollingUpdateDefaults{
+				MaxSurge: intstr.FromString("25%"), MaxUnavailable: intstr.FromString("25%"), Paired: true,
+			},
+			StatefulSet: RollingUpdateDefaults{MaxUnavailable: intstr.FromInt32(1)},
+		}, nil
+	default:
+		return StrategyDefaults{}, fmt.Errorf("unknown platform %q, supported are %s and %s", platform, PlatformKubernetes, PlatformOpenShift)
+	}
+}
+
+// strategyDefaults returns the configured strategy defaults or the ones of kubernetes, if none are configured.
+func (o Options) strategyDefaults() StrategyDefaults {
+	if o.StrategyDefaults != nil {
+		return *o.StrategyDefaults
+	}
+
+	defaults, _ := BuiltinStrategyDefaults(PlatformKubernetes)
+
+	return defaults
+}
+
+// values returns the maxSurge and maxUnavailable of a rolling update, whose missing values are defaulted.
+func (d RollingUpdateDefaults) values(maxSurge, maxUnavailable *intstr.IntOrString) (intstr.IntOrString, intstr.IntOrString) {
+	surge, unavailable := withDefault(maxSurge, d.MaxSurge), withDefault(maxUnavailable, d.MaxUnavailable)
+
+	if d.Paired {
+		switch {
+		case maxSurge == nil && maxUnavailable != nil && !isZero(*maxUnavailable):
+			surge = intstr.FromInt32(0)
+		case maxUnavailable == nil && maxSurge != nil && !isZero(*maxSurge):
+			unavailable = intstr.FromInt32(0)
+		}
+	}
+
+	return surge, unavailable
+}
+
+// isZero returns whether the value is 0 or 0%.
+func isZero(value intstr.IntOrString) bool {
+	return value == intstr.FromInt32(0) || value == intstr.FromString("0%")
+}
+
+// withDefault returns the value or the default if the value is not set.
+func withDefault(value *intstr.IntOrString, defaultValue intstr.IntOrString) intstr.IntOrString {
+	if value == nil {
+		return defaultValue
+	}
+
+	return *value
+}
@@ -0,0 +1,64 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestBuiltinStrategyDefaults(t *testing.T) {
+	r := require.New(t)
+
+	for _, platform := range []string{PlatformKubernetes, PlatformOpenShift} {
+		defaults, err := BuiltinStrategyDefaults(platform)
+		r.NoError(err)
+		r.Equal(intstr.FromString("25%"), defaults.Deployment.MaxSurge, platform)
+		r.Equal(intstr.FromString("25%"), defaults.DeploymentConfig.MaxUnavailable, platform)
+		r.Equal(intstr.FromInt32(1), defaults.StatefulSet.MaxUnavailable, platform)
+	}
+
+	kubernetes, err := BuiltinStrategyDefaults(PlatformKubernetes)
+	r.NoError(err)
+	openshift, err := BuiltinStrategyDefaults(PlatformOpenShift)
+	r.NoError(err)
+	r.NotEqual(kubernetes.DeploymentConfig, openshift.DeploymentConfig)
+	r.Equal(kubernetes.Deployment, openshift.Deployment)
+
+	_, err = BuiltinStrategyDefaults("nomad")
+	r.Error(err)
+}
+
+func TestRollingUpdateDefaultsValues(t *testing.T) {
+	var (
+		zero    = intstr.FromInt32(0)
+		half    = intstr.FromString("50%")
+		quarter = intstr.FromString("25%")
+	)
+
+	var tests = []struct {
+		name           string
+		paired         bool
+		maxSurge       *intstr.IntOrString
+		maxUnavailable *intstr.IntOrString
+		surge          intstr.IntOrString
+		unavailable    intstr.IntOrString
+	}{
+		{name: "both missing", paired: true, surge: quarter, unavailable: quarter},
+		{name: "maxUnavailable missing", maxSurge: &half, surge: half, unavailable: quarter},
+		{name: "paired maxUnavailable missing", paired: true, maxSurge: &half, surge: half, unavailable: zero},
+		{name: "paired maxSurge missing", paired: true, maxUnavailable: &half, surge: zero, unavailable: half},
+		{name: "paired maxSurge missing next to 0", paired: true, maxUnavailable: &zero, surge: quarter, unavailable: zero},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			defaults := RollingUpdateDefaults{MaxSurge: quarter, MaxUnavailable: quarter, Paired: test.paired}
+			surge, unavailable := defaults.values(test.maxSurge, test.maxUnavailable)
+			r.Equal(test.surge, surge, "maxSurge")
+			r.Equal(test.unavailable, unavailable, "maxUnavailable")
+		})
+	}
+}
@@ -1,125 +1,148 @@
 package calc
 
 import (
-	"errors"
 	"fmt"
-	"math"
 
 	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // calculates the cpu/memory resources a single deployment needs. Replicas and the deployment
 // strategy are taken into account.
-func deployment(deployment appsv1.Deployment) (*ResourceUsage, error) { //nolint:funlen // disable function length linting
+func deployment(deployment appsv1.Deployment, opts Options) (*ResourceUsage, error) { //nolint:funlen // disable function length linting
 	var (
 		maxUnavailable      int32 // max amount of unavailable pods during a deployment
 		maxSurge            int32 // max amount of pods that are allowed in addition to replicas during deployment
 		maxNonReadyPodCount int32 // max pods that are not ready during deployment,
 		//  so either running init containers or already running normal containers,
 		//  but probes haven't succeeded yet
+		terminatingPodCount int32 // max old pods that are already scaled down, but still terminating
+		strategyExplanation string
 	)
 
-	replicas := deployment.Spec.Replicas
+	replicas, replicasAssumed, replicasAnnotated := opts.workloadReplicas(deployment.ObjectMeta, deployment.Spec.Replicas)
 	strategy := deployment.Spec.Strategy
 
-	if *replicas == 0 {
-		return &ResourceUsage{
+	if replicas == 0 {
+		resourceUsage := ResourceUsage{
 			NormalResources:  Resources{},
 			RolloutResources: Resources{},
 			Details: Details{
-				Version:     deployment.APIVersion,
-				Kind:        deployment.Kind,
-				Name:        deployment.Name,
-				Replicas:    *replicas,
-				MaxReplicas: *replicas,
-				Strategy:    string(strategy.Type),
+				Version:           deployment.APIVersion,
+				Kind:              deployment.Kind,
+				Namespace:         deployment.Namespace,
+				Name:              deployment.Name,
+				PriorityClassName: deployment.Spec.Template.Spec.PriorityClassName,
+				Replicas:          replicas,
+				ReplicasAssumed:   replicasAssumed,
+				ReplicasAnnotated: replicasAnnotated,
+				MaxReplicas:       replicas,
+				Strategy:          string(strategy.Type),
 			},
-		}, nil
+		}
+
+		resourceUsage.explainf(opts, "replicas: 0, no pods are running")
+
+		return &resourceUsage, nil
 	}
 
+	// an autoscaler scales between its bounds, so the normal and the rollout resources might use different replicas
+	normalReplicas, replicas, autoscaler := opts.autoscaledReplicas("Deployment", deployment.ObjectMeta, replicas)
+
 	switch strategy.Type {
 	case appsv1.RecreateDeploymentStrategyType:
 		// kill all existing pods, then recreate new ones at once -> no overhead on recreate
-		maxNonReadyPodCount = *replicas
-		maxUnavailable = *replicas
+		maxNonReadyPodCount = replicas
+		maxUnavailable = replicas
 		maxSurge = 0
+		strategyExplanation = fmt.Sprintf("all %d pods are replaced at once", replicas)
 	case "":
-		// RollingUpdate is the default and can be an empty string. If so, set the defaults
-		// (https://pkg.go.dev/k8s.io/api/apps/v1?tab=doc#RollingUpdateDeployment) and continue calculation.
-		defaults := intstr.FromString("25%")
-		strategy = appsv1.DeploymentStrategy{
-			Type: appsv1.RollingUpdateDeploymentStrategyType,
-			RollingUpdate: &appsv1.RollingUpdateDeployment{
-				MaxUnavailable: &defaults,
-				MaxSurge:       &defaults,
-			},
-		}
+		// RollingUpdate is the default and can be an empty string. If so, continue the calculation with the defaults.
+		strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
 
 		fallthrough
 	case appsv1.RollingUpdateDeploymentStrategyType:
 		// Documentation: https://pkg.go.dev/k8s.io/api/apps/v1?tab=doc#RollingUpdateDeployment
-		// all default values are set as stated in the docs
-		var (
-			maxUnavailableValue intstr.IntOrString
-			maxSurgeValue       intstr.IntOrString
-		)
-
-		// can be nil, if so apply default value
-		if strategy.RollingUpdate == nil {
-			maxUnavailableValue = intstr.FromString("25%")
-			maxSurgeValue = intstr.FromString("25%")
-		} else {
-			maxUnavailableValue = *strategy.RollingUpdate.MaxUnavailable
-			maxSurgeValue = *strategy.RollingUpdate.MaxSurge
-		}
-
-		// docs say, that the absolute number is calculated by rounding down.
-		maxUnavailableInt, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailableValue, int(*replicas), false)
-		if err != nil {
-			return nil, err
-		}
+		// values which aren't set are taken from the configured strategy defaults
+		defaults := opts.strategyDefaults().Deployment
+		maxUnavailableValue := defaults.MaxUnavailable
+		maxSurgeValue := defaults.MaxSurge
 
-		if maxUnavailableInt < math.MinInt32 || maxUnavailableInt > math.MaxInt32 {
-			return nil, errors.New("maxUnavailableInt out of int32 boundaries")
+		if strategy.RollingUpdate != nil {
+			maxSurgeValue, maxUnavailableValue = defaults.values(strategy.RollingUpdate.MaxSurge, strategy.RollingUpdate.MaxUnavailable)
 		}
 
-		maxUnavailable = int32(maxUnavailableInt)
+		// docs say, that maxSurge is rounded up and maxUnavailable is rounded down.
+		var err error
 
-		// docs say, absolute number is calculated by rounding up.
-		maxSurgeInt, err := intstr.GetScaledValueFromIntOrPercent(&maxSurgeValue, int(*replicas), true)
+		maxSurge, maxUnavailable, err = rollingUpdateBounds(deployment.Name, maxSurgeValue, maxUnavailableValue, replicas)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("deployment: %s: %w", deployment.Name, err)
 		}
 
-		if maxSurgeInt < math.MinInt32 || maxSurgeInt > math.MaxInt32 {
-			return nil, errors.New("maxSurgeInt out of int32 boundaries")
-		}
-
-		maxSurge = int32(maxSurgeInt)
+		strategyExplanation = fmt.Sprintf("maxSurge %s -> %d, maxUnavailable %s -> %d",
+			maxSurgeValue.String(), maxSurge, maxUnavailableValue.String(), maxUnavailable)
 
 		// maxNonReadyPodCount is the max number of pods potentially in init phase during a deployment
 		maxNonReadyPodCount = maxSurge + maxUnavailable
+
+		// the scaled down old pods might still be terminating, while their replacements are already starting. Each
+		// step scales down up to maxSurge + maxUnavailable old pods, so there are terminating pods even without
+		// maxUnavailable. Recreate on the other hand waits for all old pods to be terminated, so there is no overlap.
+		terminatingPodCount = opts.terminatingPods(min(maxSurge+maxUnavailable, replicas),
+			deployment.Spec.Template.Spec.TerminationGracePeriodSeconds)
 	default:
 		return nil, fmt.Errorf("deployment: %s deployment strategy %q is unknown", deployment.Name, strategy.Type)
 	}
 
-	podResources := calcPodResources(&deployment.Spec.Template.Spec)
-	rolloutResources := podResources.Containers.MulInt32(*replicas - maxUnavailable).Add(podResources.MaxResources.MulInt32(maxNonReadyPodCount))
-	normalResources := podResources.Containers.MulInt32(*replicas)
+	podResources := annotatedPodResources(deployment.ObjectMeta, deployment.Spec.Template.ObjectMeta, &deployment.Spec.Template.Spec, opts)
+	rolloutResources := podResources.Containers.MulInt32(replicas - maxUnavailable + terminatingPodCount).
+		Add(podResources.MaxResources.MulInt32(maxNonReadyPodCount))
+	normalResources := podResources.Containers.MulInt32(normalReplicas)
 
 	resourceUsage := ResourceUsage{
 		NormalResources:  normalResources,
 		RolloutResources: rolloutResources,
+		Pod:              podResources,
 		Details: Details{
-			Version:     deployment.APIVersion,
-			Kind:        deployment.Kind,
-			Name:        deployment.Name,
-			Replicas:    *replicas,
-			Strategy:    string(strategy.Type),
-			MaxReplicas: *replicas + maxSurge,
+			Version:           deployment.APIVersion,
+			Kind:              deployment.Kind,
+			Namespace:         deployment.Namespace,
+			Name:              deployment.Name,
+			PriorityClassName: deployment.Spec.Template.Spec.PriorityClassName,
+			Replicas:          replicas,
+			ReplicasAssumed:   replicasAssumed,
+			ReplicasAnnotated: replicasAnnotated,
+			Strategy:          string(strategy.Type),
+			MaxReplicas:       replicas + maxSurge + terminatingPodCount,
+			Autoscaler:        autoscaler,
+			NormalReplicas:    normalReplicas,
 		},
 	}
 
+	if strategy.Type == appsv1.RollingUpdateDeploymentStrategyType {
+		resourceUsage.Details.RollingUpdate = &RollingUpdate{
+			MaxSurge:        maxSurge,
+			MaxUnavailable:  maxUnavailable,
+			TerminatingPods: terminatingPodCount,
+		}
+	}
+
+	resourceUsage.explainReplicas(opts)
+	resourceUsage.explainf(opts, "%s: %s", strategy.Type, strategyExplanation)
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "normal = containers * %d", normalReplicas)
+	resourceUsage.explainf(opts, "rollout = containers * (%d replicas - %d unavailable + %d terminating) + max * %d not ready",
+		replicas, maxUnavailable, terminatingPodCount, maxNonReadyPodCount)
+
+	if opts.Timeline {
+		timings := newPodTimings(&deployment.Spec.Template.Spec, deployment.Spec.MinReadySeconds, opts)
+
+		if strategy.Type == appsv1.RecreateDeploymentStrategyType {
+			resourceUsage.Timeline = batchTimeline(podResources, replicas, replicas, timings)
+		} else {
+			resourceUsage.Timeline = rollingUpdateTimeline(podResources, replicas, maxSurge, maxUnavailable, timings)
+		}
+	}
+
 	return &resourceUsage, nil
 }
@@ -1,126 +1,163 @@
 package calc
 
 import (
-	"errors"
 	"fmt"
-	"math"
 
 	openshiftAppsV1 "github.com/openshift/api/apps/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // calculates the cpu/memory resources a single deployment needs. Replicas and the deployment
 // strategy are taken into account.
-func deploymentConfig(deploymentConfig openshiftAppsV1.DeploymentConfig) (*ResourceUsage, error) { //nolint:funlen // disable function length linting
+func deploymentConfig(deploymentConfig openshiftAppsV1.DeploymentConfig, opts Options) (*ResourceUsage, error) { //nolint:funlen // disable function length linting
 	var (
 		maxUnavailable      int32 // max amount of unavailable pods during a deployment
 		maxSurge            int32 // max amount of pods that are allowed in addition to replicas during deployment
 		maxNonReadyPodCount int32 // max pods that are not ready during deployment,
 		//  so either running init containers or already running normal containers,
 		//  but probes haven't succeeded yet
+		terminatingPodCount int32 // max old pods that are already scaled down, but still terminating
+		strategyExplanation string
 	)
 
-	replicas := deploymentConfig.Spec.Replicas
+	// spec.replicas of a DeploymentConfig is always set, so the replicas are never assumed
+	replicas, _, replicasAnnotated := opts.workloadReplicas(deploymentConfig.ObjectMeta, &deploymentConfig.Spec.Replicas)
 	strategy := deploymentConfig.Spec.Strategy
 
 	if replicas == 0 {
-		return &ResourceUsage{
+		resourceUsage := ResourceUsage{
 			NormalResources:  Resources{},
 			RolloutResources: Resources{},
 			Details: Details{
-				Version:     deploymentConfig.APIVersion,
-				Kind:        deploymentConfig.Kind,
-				Name:        deploymentConfig.Name,
-				Replicas:    replicas,
-				MaxReplicas: replicas,
-				Strategy:    string(strategy.Type),
+				Version:           deploymentConfig.APIVersion,
+				Kind:              deploymentConfig.Kind,
+				Namespace:         deploymentConfig.Namespace,
+				Name:              deploymentConfig.Name,
+				PriorityClassName: deploymentConfig.Spec.Template.Spec.PriorityClassName,
+				Replicas:          replicas,
+				ReplicasAnnotated: replicasAnnotated,
+				MaxReplicas:       replicas,
+				Strategy:          string(strategy.Type),
 			},
-		}, nil
+		}
+
+		resourceUsage.explainf(opts, "replicas: 0, no pods are running")
+
+		return &resourceUsage, nil
 	}
-	// TODO lookup default values, these are copied from kubernetes Deployment
+
+	// an autoscaler scales between its bounds, so the normal and the rollout resources might use different replicas
+	normalReplicas, replicas, autoscaler := opts.autoscaledReplicas("DeploymentConfig", deploymentConfig.ObjectMeta, replicas)
+
 	switch strategy.Type {
 	case openshiftAppsV1.DeploymentStrategyTypeRecreate:
 		// kill all existing pods, then recreate new ones at once -> no overhead on recreate
 		maxNonReadyPodCount = replicas
 		maxUnavailable = replicas
 		maxSurge = 0
+		strategyExplanation = fmt.Sprintf("all %d pods are replaced at once", replicas)
 	case "":
-		// Rolling is the default and can be an empty string. If so, set the defaults
-		// (https://pkg.go.dev/k8s.io/api/apps/v1?tab=doc#RollingUpdateDeployment) and continue calculation.
-		defaults := intstr.FromString("25%")
-		strategy = openshiftAppsV1.DeploymentStrategy{
-			Type: openshiftAppsV1.DeploymentStrategyTypeRolling,
-			RollingParams: &openshiftAppsV1.RollingDeploymentStrategyParams{
-				MaxUnavailable: &defaults,
-				MaxSurge:       &defaults,
-			},
-		}
+		// Rolling is the default and can be an empty string. If so, continue the calculation with the defaults.
+		strategy.Type = openshiftAppsV1.DeploymentStrategyTypeRolling
 
 		fallthrough
 	case openshiftAppsV1.DeploymentStrategyTypeRolling:
-		// Documentation: https://pkg.go.dev/k8s.io/api/apps/v1?tab=doc#RollingUpdateDeployment
-		// all default values are set as stated in the docs
-		var (
-			maxUnavailableValue intstr.IntOrString
-			maxSurgeValue       intstr.IntOrString
-		)
-
-		// can be nil, if so apply default value
-		if strategy.RollingParams == nil {
-			maxUnavailableValue = intstr.FromString("25%")
-			maxSurgeValue = intstr.FromString("25%")
-		} else {
-			maxUnavailableValue = *strategy.RollingParams.MaxUnavailable
-			maxSurgeValue = *strategy.RollingParams.MaxSurge
+		// Documentation: https://pkg.go.dev/github.com/openshift/api/apps/v1#RollingDeploymentStrategyParams
+		// values which aren't set are taken from the configured strategy defaults
+		defaults := opts.strategyDefaults().DeploymentConfig
+		maxUnavailableValue := defaults.MaxUnavailable
+		maxSurgeValue := defaults.MaxSurge
+
+		if strategy.RollingParams != nil {
+			maxSurgeValue, maxUnavailableValue = defaults.values(strategy.RollingParams.MaxSurge, strategy.RollingParams.MaxUnavailable)
 		}
 
-		// docs say, that the absolute number is calculated by rounding down.
-		maxUnavailableInt, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailableValue, int(replicas), false)
-		if err != nil {
-			return nil, err
-		}
+		// docs say, that maxSurge is rounded up and maxUnavailable is rounded down.
+		var err error
 
-		if maxUnavailableInt < math.MinInt32 || maxUnavailableInt > math.MaxInt32 {
-			return nil, errors.New("maxUnavailableInt out of int32 boundaries")
-		}
-
-		maxUnavailable = int32(maxUnavailableInt)
-
-		// docs say, absolute number is calculated by rounding up.
-		maxSurgeInt, err := intstr.GetScaledValueFromIntOrPercent(&maxSurgeValue, int(replicas), true)
+		maxSurge, maxUnavailable, err = rollingUpdateBounds(deploymentConfig.Name, maxSurgeValue, maxUnavailableValue, replicas)
 		if err != nil {
-			return nil, err
-		}
-
-		if maxSurgeInt < math.MinInt32 || maxSurgeInt > math.MaxInt32 {
-			return nil, errors.New("maxSurgeInt out of int32 boundaries")
+			return nil, fmt.Errorf("deploymentConfig: %s: %w", deploymentConfig.Name, err)
 		}
 
-		maxSurge = int32(maxSurgeInt)
+		strategyExplanation = fmt.Sprintf("maxSurge %s -> %d, maxUnavailable %s -> %d",
+			maxSurgeValue.String(), maxSurge, maxUnavailableValue.String(), maxUnavailable)
 
 		// maxNonReadyPodCount is the max number of pods potentially in init phase during a deployment
 		maxNonReadyPodCount = maxSurge + maxUnavailable
+
+		// the scaled down old pods might still be terminating, while their replacements are already starting. Each
+		// step scales down up to maxSurge + maxUnavailable old pods, so there are terminating pods even without
+		// maxUnavailable. Recreate on the other hand waits for all old pods to be terminated, so there is no overlap.
+		terminatingPodCount = opts.terminatingPods(min(maxSurge+maxUnavailable, replicas),
+			deploymentConfig.Spec.Template.Spec.TerminationGracePeriodSeconds)
 	default:
 		return nil, fmt.Errorf("deploymentConfig: %s deploymentConfig strategy %q is unknown", deploymentConfig.Name, strategy.Type)
 	}
 
-	podResources := calcPodResources(&deploymentConfig.Spec.Template.Spec)
+	podResources := annotatedPodResources(deploymentConfig.ObjectMeta, deploymentConfig.Spec.Template.ObjectMeta,
+		&deploymentConfig.Spec.Template.Spec, opts)
 	strategyResources := ConvertToResources(&deploymentConfig.Spec.Strategy.Resources)
-	rolloutResources := podResources.Containers.MulInt32(replicas - maxUnavailable).Add(podResources.MaxResources.MulInt32(maxNonReadyPodCount)).Add(strategyResources)
-	normalResources := podResources.Containers.MulInt32(replicas)
+	rolloutResources := podResources.Containers.MulInt32(replicas - maxUnavailable + terminatingPodCount).
+		Add(podResources.MaxResources.MulInt32(maxNonReadyPodCount)).
+		Add(strategyResources)
+	normalResources := podResources.Containers.MulInt32(normalReplicas)
 
 	resourceUsage := ResourceUsage{
 		NormalResources:  normalResources,
 		RolloutResources: rolloutResources,
+		Pod:              podResources,
 		Details: Details{
-			Version:     deploymentConfig.APIVersion,
-			Kind:        deploymentConfig.Kind,
-			Name:        deploymentConfig.Name,
-			Replicas:    replicas,
-			Strategy:    string(strategy.Type),
-			MaxReplicas: replicas + maxSurge,
+			Version:           deploymentConfig.APIVersion,
+			Kind:              deploymentConfig.Kind,
+			Namespace:         deploymentConfig.Namespace,
+			Name:              deploymentConfig.Name,
+			PriorityClassName: deploymentConfig.Spec.Template.Spec.PriorityClassName,
+			Replicas:          replicas,
+			ReplicasAnnotated: replicasAnnotated,
+			Strategy:          string(strategy.Type),
+			MaxReplicas:       replicas + maxSurge + terminatingPodCount,
+			Autoscaler:        autoscaler,
+			NormalReplicas:    normalReplicas,
 		},
 	}
 
+	if strategy.Type == openshiftAppsV1.DeploymentStrategyTypeRolling {
+		resourceUsage.Details.RollingUpdate = &RollingUpdate{
+			MaxSurge:        maxSurge,
+			MaxUnavailable:  maxUnavailable,
+			TerminatingPods: terminatingPodCount,
+		}
+	}
+
+	resourceUsage.explainReplicas(opts)
+	resourceUsage.explainf(opts, "%s: %s", strategy.Type, strategyExplanation)
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "strategy resources: %s", strategyResources)
+
+	// test deployments scale back to zero replicas after the test, so their pods only exist during the rollout
+	if deploymentConfig.Spec.Test {
+		resourceUsage.NormalResources = Resources{}
+		resourceUsage.Details.NormalReplicas = 0
+		resourceUsage.explainf(opts, "normal = 0, test deployments are scaled down after the test")
+	} else {
+		resourceUsage.explainf(opts, "normal = containers * %d", normalReplicas)
+	}
+
+	resourceUsage.explainf(opts, "rollout = containers * (%d replicas - %d unavailable + %d terminating) + max * %d not ready + strategy resources",
+		replicas, maxUnavailable, terminatingPodCount, maxNonReadyPodCount)
+
+	if opts.Timeline {
+		timings := newPodTimings(&deploymentConfig.Spec.Template.Spec, deploymentConfig.Spec.MinReadySeconds, opts)
+
+		if strategy.Type == openshiftAppsV1.DeploymentStrategyTypeRecreate {
+			resourceUsage.Timeline = batchTimeline(podResources, replicas, replicas, timings)
+		} else {
+			resourceUsage.Timeline = rollingUpdateTimeline(podResources, replicas, maxSurge, maxUnavailable, timings)
+		}
+
+		// the deployer pod runs during the whole rollout
+		addToTimeline(resourceUsage.Timeline, strategyResources)
+	}
+
 	return &resourceUsage, nil
 }
@@ -1,6 +1,7 @@
 package calc
 
 import (
+	"strings"
 	"testing"
 
 	openshiftAppsV1 "github.com/openshift/api/apps/v1"
@@ -38,7 +39,7 @@ func TestDeploymentConfig(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			r := require.New(t)
 
-			usage, err := ResourceQuotaFromYaml([]byte(test.deploymentConfig))
+			usage, err := ResourceQuotaFromYaml([]byte(test.deploymentConfig), Options{})
 			r.NoError(err)
 			r.NotEmpty(usage)
 
@@ -52,3 +53,61 @@ func TestDeploymentConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestTestDeploymentConfig(t *testing.T) {
+	r := require.New(t)
+
+	testDeploymentConfig := strings.Replace(normalDeploymentConfig, "  replicas: 10\n", "  replicas: 10\n  test: true\n", 1)
+
+	usage, err := ResourceQuotaFromYaml([]byte(testDeploymentConfig), Options{})
+	r.NoError(err)
+
+	AssertEqualQuantities(r, resource.MustParse("3250m"), usage.RolloutResources.CPUMin, "cpu request value")
+	AssertEqualQuantities(r, resource.MustParse("26Gi"), usage.RolloutResources.MemoryMin, "memory request value")
+	r.True(usage.NormalResources.CPUMin.IsZero(), "normal cpu request value")
+	r.True(usage.NormalResources.MemoryMin.IsZero(), "normal memory request value")
+	r.Equal(int32(10), usage.Details.Replicas, "replicas")
+	r.Equal(int32(0), usage.Details.NormalReplicas, "normal replicas")
+}
+
+func TestDeploymentConfigPlatformDefaults(t *testing.T) {
+	var tests = []struct {
+		platform    string
+		cpuMin      resource.Quantity
+		maxSurge    int32
+		maxReplicas int32
+	}{
+		{
+			platform:    PlatformKubernetes,
+			cpuMin:      resource.MustParse("3250m"),
+			maxSurge:    3,
+			maxReplicas: 13,
+		},
+		{
+			// openshift doesn't default maxSurge next to a maxUnavailable other than 0
+			platform:    PlatformOpenShift,
+			cpuMin:      resource.MustParse("2500m"),
+			maxSurge:    0,
+			maxReplicas: 10,
+		},
+	}
+
+	withoutMaxSurge := strings.Replace(normalDeploymentConfig, "      maxSurge: 25%\n", "", 1)
+
+	for _, test := range tests {
+		t.Run(test.platform, func(t *testing.T) {
+			r := require.New(t)
+
+			defaults, err := BuiltinStrategyDefaults(test.platform)
+			r.NoError(err)
+
+			usage, err := ResourceQuotaFromYaml([]byte(withoutMaxSurge), Options{StrategyDefaults: &defaults})
+			r.NoError(err)
+
+			AssertEqualQuantities(r, test.cpuMin, usage.RolloutResources.CPUMin, "cpu request value")
+			r.Equal(test.maxSurge, usage.Details.RollingUpdate.MaxSurge, "maxSurge")
+			r.Equal(int32(2), usage.Details.RollingUpdate.MaxUnavailable, "maxUnavailable")
+			r.Equal(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
+		})
+	}
+}
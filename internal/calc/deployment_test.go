@@ -6,12 +6,15 @@ import (
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 )
 
 func TestDeployment(t *testing.T) {
 	var tests = []struct {
 		name        string
 		deployment  string
+		opts        Options
 		cpuMin      resource.Quantity
 		cpuMax      resource.Quantity
 		memoryMin   resource.Quantity
@@ -19,6 +22,7 @@ func TestDeployment(t *testing.T) {
 		replicas    int32
 		maxReplicas int32
 		strategy    appsv1.DeploymentStrategyType
+		assumed     bool
 	}{
 		{
 			name:        "normal deployment",
@@ -97,13 +101,113 @@ func TestDeployment(t *testing.T) {
 			maxReplicas: 4,
 			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
 		},
+		{
+			name:        "deployment with complete termination overlap",
+			deployment:  normalDeployment,
+			opts:        Options{TerminationOverlap: 1},
+			cpuMin:      resource.MustParse("4500m"),
+			cpuMax:      resource.MustParse("9"),
+			memoryMin:   resource.MustParse("36Gi"),
+			memoryMax:   resource.MustParse("72Gi"),
+			replicas:    10,
+			maxReplicas: 18,
+			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			name:        "deployment with partial termination overlap",
+			deployment:  normalDeployment,
+			opts:        Options{TerminationOverlap: 0.5},
+			cpuMin:      resource.MustParse("4000m"),
+			cpuMax:      resource.MustParse("8000m"),
+			memoryMin:   resource.MustParse("32Gi"),
+			memoryMax:   resource.MustParse("64Gi"),
+			replicas:    10,
+			maxReplicas: 16,
+			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			name:        "deployment with grace period based termination overlap",
+			deployment:  normalDeployment,
+			opts:        Options{TerminationOverlapByGracePeriod: true},
+			cpuMin:      resource.MustParse("4500m"),
+			cpuMax:      resource.MustParse("9"),
+			memoryMin:   resource.MustParse("36Gi"),
+			memoryMax:   resource.MustParse("72Gi"),
+			replicas:    10,
+			maxReplicas: 18,
+			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			// maxUnavailable 0 scales down an old pod for each new ready one, so there are terminating pods as well
+			name:        "deployment without max unavailable with termination overlap",
+			deployment:  deploymentWithAbsoluteValues,
+			opts:        Options{TerminationOverlap: 1},
+			cpuMin:      resource.MustParse("3500m"),
+			cpuMax:      resource.MustParse("14"),
+			memoryMin:   resource.MustParse("28Gi"),
+			memoryMax:   resource.MustParse("56Gi"),
+			replicas:    10,
+			maxReplicas: 14,
+			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			name:       "deployment without strategy with custom strategy defaults",
+			deployment: deploymentWithoutStrategy,
+			opts: Options{StrategyDefaults: &StrategyDefaults{
+				Deployment: RollingUpdateDefaults{MaxSurge: intstr.FromInt32(1), MaxUnavailable: intstr.FromInt32(0)},
+			}},
+			cpuMin:      resource.MustParse("2750m"),
+			cpuMax:      resource.MustParse("11"),
+			memoryMin:   resource.MustParse("22Gi"),
+			memoryMax:   resource.MustParse("44Gi"),
+			replicas:    10,
+			maxReplicas: 11,
+			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			name:        "deployment without replicas",
+			deployment:  deploymentWithoutReplicas,
+			cpuMin:      resource.MustParse("500m"),
+			cpuMax:      resource.MustParse("2"),
+			memoryMin:   resource.MustParse("4Gi"),
+			memoryMax:   resource.MustParse("8Gi"),
+			replicas:    1,
+			maxReplicas: 2,
+			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+			assumed:     true,
+		},
+		{
+			name:        "deployment without replicas with assumed replicas",
+			deployment:  deploymentWithoutReplicas,
+			opts:        Options{AssumedReplicas: ptr.To[int32](4)},
+			cpuMin:      resource.MustParse("1250m"),
+			cpuMax:      resource.MustParse("5"),
+			memoryMin:   resource.MustParse("10Gi"),
+			memoryMax:   resource.MustParse("20Gi"),
+			replicas:    4,
+			maxReplicas: 5,
+			strategy:    appsv1.RollingUpdateDeploymentStrategyType,
+			assumed:     true,
+		},
+		{
+			name:        "recreate deployment ignores termination overlap",
+			deployment:  recrateDeployment,
+			opts:        Options{TerminationOverlap: 1},
+			cpuMin:      resource.MustParse("2500m"),
+			cpuMax:      resource.MustParse("10"),
+			memoryMin:   resource.MustParse("20Gi"),
+			memoryMax:   resource.MustParse("40Gi"),
+			replicas:    10,
+			maxReplicas: 10,
+			strategy:    appsv1.RecreateDeploymentStrategyType,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			r := require.New(t)
 
-			usage, err := ResourceQuotaFromYaml([]byte(test.deployment))
+			usage, err := ResourceQuotaFromYaml([]byte(test.deployment), test.opts)
 			r.NoError(err)
 			r.NotEmpty(usage)
 
@@ -114,6 +218,7 @@ func TestDeployment(t *testing.T) {
 			r.Equal(test.replicas, usage.Details.Replicas, "replicas")
 			r.Equal(string(test.strategy), usage.Details.Strategy, "strategy")
 			r.Equal(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
+			r.Equal(test.assumed, usage.Details.ReplicasAssumed, "replicas assumed")
 		})
 	}
 }
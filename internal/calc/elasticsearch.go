@@ -0,0 +1,144 @@
+package calc
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// elasticsearchMemory is the memory request and limit ECK sets on elasticsearch containers without resources.
+	elasticsearchMemory = "2Gi"
+	// elasticsearchDataStorage is the storage of the data claim ECK creates for node sets without volumeClaimTemplates.
+	elasticsearchDataStorage = "1Gi"
+)
+
+// elasticsearchKind is the Elasticsearch cluster of Elastic Cloud on Kubernetes (ECK), whose node sets are run by
+// statefulsets the operator manages.
+// https://www.elastic.co/guide/en/cloud-on-k8s/current/k8s-elasticsearch-specification.html
+func elasticsearchKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: "elasticsearch.k8s.elastic.co", Version: "v1", Kind: "Elasticsearch"}
+}
+
+// elasticsearch is the part of an Elasticsearch, which kuota-calc calculates. The ECK api isn't a dependency of
+// kuota-calc, so the Elasticsearch is decoded from its unstructured content.
+type elasticsearch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              elasticsearchSpec `json:"spec"`
+}
+
+type elasticsearchSpec struct {
+	NodeSets       []elasticsearchNodeSet `json:"nodeSets"`
+	UpdateStrategy struct {
+		ChangeBudget struct {
+			MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+		} `json:"changeBudget"`
+	} `json:"updateStrategy"`
+}
+
+type elasticsearchNodeSet struct {
+	Name                 string                     `json:"name"`
+	Count                int32                      `json:"count"`
+	PodTemplate          v1.PodTemplateSpec         `json:"podTemplate"`
+	VolumeClaimTemplates []v1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+}
+
+// calculates the cpu/memory resources of the nodes of an elasticsearch cluster and the storage of their claims. ECK
+// restarts the nodes of a node set within the maxUnavailable of its change budget, which defaults to 1 and is assumed
+// for each node set on its own.
+func elasticsearchResource(object *unstructured.Unstructured, opts Options) (*ResourceUsage, error) {
+	var es elasticsearch
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &es); err != nil {
+		return nil, fmt.Errorf("elasticsearch: %s: decoding: %w", object.GetName(), err)
+	}
+
+	// a negative maxUnavailable removes the limit
+	var maxUnavailable int32 = 1
+	if budget := es.Spec.UpdateStrategy.ChangeBudget.MaxUnavailable; budget != nil {
+		maxUnavailable = *budget
+	}
+
+	resourceUsage := ResourceUsage{
+		Details: Details{
+			Version:   es.APIVersion,
+			Kind:      es.Kind,
+			Namespace: es.Namespace,
+			Name:      es.Name,
+			Strategy:  fmt.Sprintf("maxUnavailable %d", maxUnavailable),
+		},
+	}
+
+	for i := range es.Spec.NodeSets {
+		nodeSet := &es.Spec.NodeSets[i]
+		podSpec := elasticsearchPodSpec(&nodeSet.PodTemplate.Spec)
+		podResources := annotatedPodResources(es.ObjectMeta, nodeSet.PodTemplate.ObjectMeta, podSpec, opts)
+
+		unavailable := nodeSet.Count
+		if maxUnavailable >= 0 && maxUnavailable < unavailable {
+			unavailable = maxUnavailable
+		}
+
+		resourceUsage.NormalResources = resourceUsage.NormalResources.Add(podResources.Containers.MulInt32(nodeSet.Count))
+		resourceUsage.RolloutResources = resourceUsage.RolloutResources.Add(podResources.Containers.MulInt32(nodeSet.Count - unavailable).
+			Add(podResources.MaxResources.MulInt32(unavailable)))
+		resourceUsage.Details.Replicas += nodeSet.Count
+
+		if resourceUsage.Pod == nil {
+			resourceUsage.Pod = podResources
+			resourceUsage.Details.PriorityClassName = podSpec.PriorityClassName
+		}
+
+		resourceUsage.explainPod(opts, podResources)
+		resourceUsage.explainf(opts, "node set %s: normal = containers * %d, rollout = containers * (%d - %d unavailable) + max * %d unavailable",
+			nodeSet.Name, nodeSet.Count, nodeSet.Count, unavailable, unavailable)
+
+		// the claims are kept when the nodes are restarted, like the ones of a statefulset
+		claims := volumeClaimResources(elasticsearchVolumeClaims(nodeSet.VolumeClaimTemplates)).MulInt32(nodeSet.Count)
+		resourceUsage.NormalResources = resourceUsage.NormalResources.Add(claims)
+		resourceUsage.RolloutResources = resourceUsage.RolloutResources.Add(claims)
+
+		resourceUsage.explainf(opts, "node set %s: volume claims * %d, storage %s", nodeSet.Name, nodeSet.Count, claims.Storage.String())
+	}
+
+	resourceUsage.Details.MaxReplicas = resourceUsage.Details.Replicas
+	resourceUsage.Details.NormalReplicas = resourceUsage.Details.Replicas
+
+	return &resourceUsage, nil
+}
+
+// elasticsearchPodSpec returns the pod spec of a node set with the elasticsearch container ECK generates. Without
+// resources of its own, the container requests and is limited to the default memory.
+func elasticsearchPodSpec(spec *v1.PodSpec) *v1.PodSpec {
+	memory := v1.ResourceList{v1.ResourceMemory: resource.MustParse(elasticsearchMemory)}
+	generated := []v1.Container{{
+		Name:      "elasticsearch",
+		Resources: v1.ResourceRequirements{Requests: memory, Limits: memory},
+	}}
+
+	podSpec := spec.DeepCopy()
+	podSpec.Containers = mergeContainers(generated, spec.Containers)
+
+	return podSpec
+}
+
+// elasticsearchVolumeClaims returns the claims of a node set, ECK creates the default data claim, if it has none.
+func elasticsearchVolumeClaims(claims []v1.PersistentVolumeClaim) []v1.PersistentVolumeClaim {
+	if len(claims) > 0 {
+		return claims
+	}
+
+	return []v1.PersistentVolumeClaim{{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-data"},
+		Spec: v1.PersistentVolumeClaimSpec{
+			Resources: v1.VolumeResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(elasticsearchDataStorage)},
+			},
+		},
+	}}
+}
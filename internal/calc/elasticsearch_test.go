@@ -0,0 +1,59 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var elasticsearchCluster = `
+apiVersion: elasticsearch.k8s.elastic.co/v1
+kind: Elasticsearch
+metadata:
+  name: logs
+  namespace: logging
+spec:
+  version: 8.15.0
+  nodeSets:
+  - name: master
+    count: 3
+    config:
+      node.roles: ["master"]
+  - name: data
+    count: 2
+    podTemplate:
+      spec:
+        containers:
+        - name: elasticsearch
+          resources:
+            requests:
+              cpu: "2"
+              memory: 4Gi
+    volumeClaimTemplates:
+    - metadata:
+        name: elasticsearch-data
+      spec:
+        accessModes: ["ReadWriteOnce"]
+        resources:
+          requests:
+            storage: 100Gi`
+
+func TestElasticsearch(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(elasticsearchCluster), Options{})
+	r.NoError(err)
+	r.Equal("Elasticsearch", usage.Details.Kind)
+	r.Equal(int32(5), usage.Details.Replicas)
+
+	for _, resources := range []Resources{usage.NormalResources, usage.RolloutResources} {
+		AssertEqualQuantities(r, resource.MustParse("4"), resources.CPUMin, "cpu request value")
+		AssertEqualQuantities(r, resource.MustParse("14Gi"), resources.MemoryMin, "memory request value")
+		// the master nodes get the default memory limit
+		AssertEqualQuantities(r, resource.MustParse("6Gi"), resources.MemoryMax, "memory limit value")
+		AssertEqualQuantities(r, resource.MustParse("203Gi"), resources.Storage, "storage value")
+		AssertEqualQuantities(r, resource.MustParse("5"), resources.PersistentVolumeClaims, "claims")
+		AssertEqualQuantities(r, resource.MustParse("5"), resources.Pods, "pods")
+	}
+}
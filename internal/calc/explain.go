@@ -0,0 +1,55 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// String returns the requests and limits of the resources, followed by the extended resources.
+func (r Resources) String() string {
+	s := fmt.Sprintf("requests cpu=%s memory=%s, limits cpu=%s memory=%s",
+		r.CPUMin.String(), r.MemoryMin.String(), r.CPUMax.String(), r.MemoryMax.String())
+
+	if len(r.Extended) == 0 {
+		return s
+	}
+
+	extended := make([]string, 0, len(r.Extended))
+
+	for name, quantity := range r.Extended {
+		extended = append(extended, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+
+	slices.Sort(extended)
+
+	return s + ", extended " + strings.Join(extended, " ")
+}
+
+// explainf adds a line to the explanation of the calculation, if explanations are enabled.
+func (u *ResourceUsage) explainf(opts Options, format string, args ...any) {
+	if opts.Explain {
+		u.Explanation = append(u.Explanation, fmt.Sprintf(format, args...))
+	}
+}
+
+// explainReplicas explains where the replicas of a workload come from.
+func (u *ResourceUsage) explainReplicas(opts Options) {
+	switch {
+	case u.Details.Autoscaler != "":
+		u.explainf(opts, "replicas: %d normal, %d rollout (hpa %s)", u.Details.NormalReplicas, u.Details.Replicas, u.Details.Autoscaler)
+	case u.Details.ReplicasAnnotated:
+		u.explainf(opts, "replicas: %d (annotation %s)", u.Details.Replicas, AnnotationReplicas)
+	case u.Details.ReplicasAssumed:
+		u.explainf(opts, "replicas: %d (assumed)", u.Details.Replicas)
+	default:
+		u.explainf(opts, "replicas: %d", u.Details.Replicas)
+	}
+}
+
+// explainPod explains the resources of a single pod.
+func (u *ResourceUsage) explainPod(opts Options, podResources *PodResources) {
+	u.explainf(opts, "containers: %s", podResources.Containers)
+	u.explainf(opts, "init containers: %s", podResources.InitContainers)
+	u.explainf(opts, "max of containers and init containers: %s", podResources.MaxResources)
+}
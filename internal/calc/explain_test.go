@@ -0,0 +1,27 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(normalDeployment), Options{Explain: true})
+	r.NoError(err)
+	r.Equal([]string{
+		"replicas: 10",
+		"RollingUpdate: maxSurge 25% -> 3, maxUnavailable 25% -> 2",
+		"containers: requests cpu=250m memory=2Gi, limits cpu=500m memory=4Gi",
+		"init containers: requests cpu=0 memory=0, limits cpu=0 memory=0",
+		"max of containers and init containers: requests cpu=250m memory=2Gi, limits cpu=500m memory=4Gi",
+		"normal = containers * 10",
+		"rollout = containers * (10 replicas - 2 unavailable + 0 terminating) + max * 5 not ready",
+	}, usage.Explanation)
+
+	usage, err = ResourceQuotaFromYaml([]byte(normalDeployment), Options{})
+	r.NoError(err)
+	r.Empty(usage.Explanation)
+}
@@ -0,0 +1,154 @@
+package calc
+
+import (
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// isExtendedResource reports whether the resource is an extended resource advertised by a device plugin or the
+// cluster, e.g. nvidia.com/gpu. Their names have a domain other than kubernetes.io.
+// https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/#extended-resources
+func isExtendedResource(name v1.ResourceName) bool {
+	domain, _, found := strings.Cut(string(name), "/")
+
+	return found && domain != "kubernetes.io" && !strings.HasSuffix(domain, ".kubernetes.io") &&
+		!strings.HasPrefix(string(name), "requests.")
+}
+
+// isHugePages reports whether the resource is a size of hugepages, e.g. hugepages-2Mi.
+// https://kubernetes.io/docs/tasks/manage-hugepages/scheduling-hugepages/
+func isHugePages(name v1.ResourceName) bool {
+	return strings.HasPrefix(string(name), v1.ResourceHugePagesPrefix)
+}
+
+// isExtendedOrHugePages reports whether the resource is counted in the extended resources of Resources.
+func isExtendedOrHugePages(name v1.ResourceName) bool {
+	return isExtendedResource(name) || isHugePages(name)
+}
+
+// extendedResources returns the extended resources and hugepages of a container. They can't be overcommitted, so
+// their requests equal their limits, and a container setting only the limit requests the same amount.
+func extendedResources(req *v1.ResourceRequirements) v1.ResourceList {
+	var extended v1.ResourceList
+
+	for _, list := range []v1.ResourceList{req.Limits, req.Requests} {
+		for name, quantity := range list {
+			if !isExtendedOrHugePages(name) {
+				continue
+			}
+
+			if extended == nil {
+				extended = v1.ResourceList{}
+			}
+
+			extended[name] = quantity
+		}
+	}
+
+	return extended
+}
+
+// combineExtended returns a new list with the quantities of both lists combined by the function, nil if both are
+// empty. The lists themselves are left unchanged, so copies of Resources can share them.
+func combineExtended(l1, l2 v1.ResourceList, combine func(q1, q2 resource.Quantity) resource.Quantity) v1.ResourceList {
+	if len(l1) == 0 && len(l2) == 0 {
+		return nil
+	}
+
+	combined := v1.ResourceList{}
+
+	for name, quantity := range l1 {
+		combined[name] = combine(quantity, l2[name])
+	}
+
+	for name, quantity := range l2 {
+		if _, ok := l1[name]; !ok {
+			combined[name] = combine(resource.Quantity{}, quantity)
+		}
+	}
+
+	return combined
+}
+
+func addQuantities(q1, q2 resource.Quantity) resource.Quantity {
+	q1.Add(q2)
+
+	return q1
+}
+
+// mulExtended returns a new list with all quantities multiplied by the multiplier.
+func mulExtended(l v1.ResourceList, y float64) v1.ResourceList {
+	if len(l) == 0 {
+		return nil
+	}
+
+	multiplied := make(v1.ResourceList, len(l))
+
+	for name, quantity := range l {
+		quantity.SetMilli(int64(float64(quantity.MilliValue()) * y))
+		multiplied[name] = quantity
+	}
+
+	return multiplied
+}
+
+// totalExtended sums the extended resources of all usages like Total does.
+func totalExtended(maxRollout int, usage []*ResourceUsage) v1.ResourceList {
+	var total v1.ResourceList
+
+	if maxRollout <= -1 {
+		for _, u := range usage {
+			total = combineExtended(total, u.RolloutResources.Extended, addQuantities)
+		}
+
+		return total
+	}
+
+	diffs := map[v1.ResourceName][]resource.Quantity{}
+
+	for _, u := range usage {
+		total = combineExtended(total, u.NormalResources.Extended, addQuantities)
+
+		for name, quantity := range combineExtended(u.RolloutResources.Extended, u.NormalResources.Extended, subQuantities) {
+			diffs[name] = append(diffs[name], quantity)
+		}
+	}
+
+	for name, quantities := range diffs {
+		slices.SortFunc(quantities, func(a, b resource.Quantity) int {
+			return b.Cmp(a)
+		})
+
+		for i := 0; i < len(quantities) && i < maxRollout; i++ {
+			total = combineExtended(total, v1.ResourceList{name: quantities[i]}, addQuantities)
+		}
+	}
+
+	return total
+}
+
+func subQuantities(q1, q2 resource.Quantity) resource.Quantity {
+	return diffQuantities(&q1, &q2)
+}
+
+// ExtendedResourceNames returns the names of the extended resources and hugepages of the usages, sorted by name.
+func ExtendedResourceNames(usage []*ResourceUsage) []v1.ResourceName {
+	var names []v1.ResourceName
+
+	for _, u := range usage {
+		for _, list := range []v1.ResourceList{u.NormalResources.Extended, u.RolloutResources.Extended} {
+			for name := range list {
+				if !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
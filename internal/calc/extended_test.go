@@ -0,0 +1,148 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var gpuPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: training
+spec:
+  initContainers:
+  - name: warmup
+    image: warmup
+    resources:
+      limits:
+        nvidia.com/gpu: 4
+  containers:
+  - name: trainer
+    image: trainer
+    resources:
+      requests:
+        cpu: "1"
+        nvidia.com/gpu: 2
+        hugepages-2Mi: 100Mi
+      limits:
+        nvidia.com/gpu: 2
+        hugepages-2Mi: 100Mi
+  - name: exporter
+    image: exporter
+    resources:
+      limits:
+        nvidia.com/gpu: 1
+        example.com/foo: 3`
+
+func TestExtendedResources(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(gpuPod), Options{})
+	r.NoError(err)
+
+	// limits without requests request the same
+	AssertEqualQuantities(r, resource.MustParse("3"), usage.NormalResources.Extended["nvidia.com/gpu"], "normal gpus")
+	AssertEqualQuantities(r, resource.MustParse("3"), usage.NormalResources.Extended["example.com/foo"], "normal foos")
+	AssertEqualQuantities(r, resource.MustParse("100Mi"), usage.NormalResources.Extended["hugepages-2Mi"], "normal hugepages")
+	r.Len(usage.NormalResources.Extended, 3)
+
+	// the init container needs more gpus than the containers
+	AssertEqualQuantities(r, resource.MustParse("4"), usage.RolloutResources.Extended["nvidia.com/gpu"], "rollout gpus")
+	AssertEqualQuantities(r, resource.MustParse("3"), usage.RolloutResources.Extended["example.com/foo"], "rollout foos")
+
+	r.Equal([]v1.ResourceName{"example.com/foo", "hugepages-2Mi", "nvidia.com/gpu"}, ExtendedResourceNames([]*ResourceUsage{usage}))
+}
+
+func TestIsExtendedResource(t *testing.T) {
+	r := require.New(t)
+
+	r.True(isExtendedResource("nvidia.com/gpu"))
+	r.True(isExtendedResource("amd.com/gpu"))
+	r.False(isExtendedResource(v1.ResourceCPU))
+	r.False(isExtendedResource("hugepages-2Mi"))
+	r.False(isExtendedResource("kubernetes.io/foo"))
+	r.False(isExtendedResource("requests.nvidia.com/gpu"))
+	r.True(isHugePages("hugepages-1Gi"))
+	r.False(isHugePages(v1.ResourceMemory))
+}
+
+func TestExtendedArithmetic(t *testing.T) {
+	r := require.New(t)
+
+	gpus := Resources{Extended: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")}}
+	sum := gpus.Add(gpus).MulInt32(3)
+
+	AssertEqualQuantities(r, resource.MustParse("12"), sum.Extended["nvidia.com/gpu"], "gpus")
+	// copies share the list, so it is never modified in place
+	AssertEqualQuantities(r, resource.MustParse("2"), gpus.Extended["nvidia.com/gpu"], "original gpus")
+	r.Nil(Resources{}.Add(Resources{}).Extended)
+}
+
+func TestTotalExtended(t *testing.T) {
+	gpus := func(quantity string) Resources {
+		return Resources{Extended: v1.ResourceList{"nvidia.com/gpu": resource.MustParse(quantity)}}
+	}
+
+	usage := []*ResourceUsage{
+		{NormalResources: gpus("2"), RolloutResources: gpus("4")},
+		{NormalResources: gpus("1"), RolloutResources: gpus("2")},
+		{NormalResources: Resources{}, RolloutResources: Resources{}},
+	}
+
+	var tests = []struct {
+		name       string
+		maxRollout int
+		expected   resource.Quantity
+	}{
+		{name: "unlimited rollouts", maxRollout: -1, expected: resource.MustParse("6")},
+		{name: "no rollout", maxRollout: 0, expected: resource.MustParse("3")},
+		{name: "largest rollout", maxRollout: 1, expected: resource.MustParse("5")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			total := Total(test.maxRollout, usage)
+			AssertEqualQuantities(r, test.expected, total.Extended["nvidia.com/gpu"], "gpus")
+		})
+	}
+}
+
+func TestExtendedBudget(t *testing.T) {
+	r := require.New(t)
+
+	budget, err := ParseBudget("requests.nvidia.com/gpu=4")
+	r.NoError(err)
+	r.Equal(Budget{"requests.nvidia.com/gpu": resource.MustParse("4")}, budget)
+
+	// extended resources can't be overcommitted, so quotas only limit their requests
+	for _, invalid := range []string{"nvidia.com/gpu=4", "limits.nvidia.com/gpu=4"} {
+		_, err := ParseBudget(invalid)
+		r.Error(err, invalid)
+	}
+
+	r.Empty(budget.Exceeded(Resources{Extended: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("4")}}))
+	r.Equal([]v1.ResourceName{"requests.nvidia.com/gpu"},
+		budget.Exceeded(Resources{Extended: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("5")}}))
+
+	quota := ResourceQuota("ml", "compute-resources", Resources{Extended: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("5")}})
+	r.Equal(v1.ResourceList{"requests.nvidia.com/gpu": resource.MustParse("5")}, quota.Spec.Hard)
+}
+
+func TestHugePagesBudget(t *testing.T) {
+	r := require.New(t)
+
+	budget, err := ParseBudget("requests.hugepages-2Mi=1Gi")
+	r.NoError(err)
+
+	hugePages := Resources{Extended: v1.ResourceList{"hugepages-2Mi": resource.MustParse("2Gi")}}
+	r.Equal([]v1.ResourceName{"requests.hugepages-2Mi"}, budget.Exceeded(hugePages))
+
+	quota := ResourceQuota("db", "compute-resources", hugePages)
+	r.Equal(v1.ResourceList{"requests.hugepages-2Mi": resource.MustParse("2Gi")}, quota.Spec.Hard)
+}
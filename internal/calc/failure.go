@@ -0,0 +1,150 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ErrNoNodes is returned if a failure is simulated or the capacity is reported without the nodes of the cluster.
+var ErrNoNodes = errors.New("the nodes of the cluster are missing in the input")
+
+// FailureSimulation describes the part of the cluster, whose failure is simulated: a zone or a number of nodes.
+type FailureSimulation struct {
+	Zone  bool
+	Nodes int
+}
+
+// ParseFailureSimulation parses a failure like zone or nodes=2.
+func ParseFailureSimulation(value string) (FailureSimulation, error) {
+	if value == "zone" {
+		return FailureSimulation{Zone: true}, nil
+	}
+
+	if count, ok := strings.CutPrefix(value, "nodes="); ok {
+		nodes, err := strconv.Atoi(count)
+		if err == nil && nodes > 0 {
+			return FailureSimulation{Nodes: nodes}, nil
+		}
+	}
+
+	return FailureSimulation{}, fmt.Errorf("invalid failure %q: must be zone or nodes=<count>", value)
+}
+
+func (f FailureSimulation) String() string {
+	if f.Zone {
+		return "a zone"
+	}
+
+	return fmt.Sprintf("%d nodes", f.Nodes)
+}
+
+// FailureHeadroom is the result of a failure simulation.
+type FailureHeadroom struct {
+	// Resources are the additional resources needed, while the pods of the failed nodes are rescheduled.
+	Resources Resources
+	// RemainingCPU and RemainingMemory are the allocatable resources of the nodes, which didn't fail, minus the system
+	// overhead of each node.
+	RemainingCPU    resource.Quantity
+	RemainingMemory resource.Quantity
+}
+
+// SimulateFailure estimates the headroom needed, if a zone or a number of nodes fail. The pods are assumed to be spread
+// evenly across the nodes and zones. The pods of the failed nodes keep counting against the quota during their grace
+// period, while their replacements are already starting elsewhere. The largest zone or nodes are assumed to fail.
+func SimulateFailure(usage []*ResourceUsage, nodes []v1.Node, failure FailureSimulation, overhead SystemOverhead) (FailureHeadroom, error) {
+	if len(nodes) == 0 {
+		return FailureHeadroom{}, ErrNoNodes
+	}
+
+	remaining, failed, total, err := failedNodes(nodes, failure)
+	if err != nil {
+		return FailureHeadroom{}, err
+	}
+
+	var headroom FailureHeadroom
+
+	headroom.RemainingCPU, headroom.RemainingMemory = overhead.allocatable(remaining)
+
+	for _, u := range usage {
+		pods := reschedulablePods(u)
+		if pods == 0 || u.Pod == nil {
+			continue
+		}
+
+		// the failed part hosts its share of the evenly spread pods, rounded up
+		affected := min(pods, int32(failed)*((pods+int32(total)-1)/int32(total)))
+
+		headroom.Resources = headroom.Resources.Add(u.Pod.MaxResources.MulInt32(affected))
+	}
+
+	return headroom, nil
+}
+
+// failedNodes returns the nodes, which remain after the failure, how many nodes or zones fail and of how many in total.
+func failedNodes(nodes []v1.Node, failure FailureSimulation) ([]v1.Node, int, int, error) {
+	// the nodes with the most cpu fail first, as their failure hurts the most
+	nodes = slices.Clone(nodes)
+	slices.SortStableFunc(nodes, func(a, b v1.Node) int {
+		return b.Status.Allocatable.Cpu().Cmp(*a.Status.Allocatable.Cpu())
+	})
+
+	if !failure.Zone {
+		if failure.Nodes >= len(nodes) {
+			return nil, 0, 0, fmt.Errorf("can't simulate the failure of %d of %d nodes", failure.Nodes, len(nodes))
+		}
+
+		return nodes[failure.Nodes:], failure.Nodes, len(nodes), nil
+	}
+
+	zones := map[string]*resource.Quantity{}
+
+	for _, node := range nodes {
+		zone, ok := node.Labels[v1.LabelTopologyZone]
+		if !ok {
+			continue
+		}
+
+		if zones[zone] == nil {
+			zones[zone] = &resource.Quantity{}
+		}
+
+		zones[zone].Add(*node.Status.Allocatable.Cpu())
+	}
+
+	if len(zones) < 2 {
+		return nil, 0, 0, fmt.Errorf("can't simulate the failure of a zone with %d zones, see the %s label of the nodes",
+			len(zones), v1.LabelTopologyZone)
+	}
+
+	var largest string
+
+	for zone, cpu := range zones {
+		if largest == "" || cpu.Cmp(*zones[largest]) > 0 || (cpu.Cmp(*zones[largest]) == 0 && zone < largest) {
+			largest = zone
+		}
+	}
+
+	remaining := slices.DeleteFunc(nodes, func(node v1.Node) bool { return node.Labels[v1.LabelTopologyZone] == largest })
+
+	return remaining, 1, len(zones), nil
+}
+
+// reschedulablePods returns how many pods of a resource are replaced elsewhere, while the failed ones still count
+// against the quota. Argo Rollouts and bare ReplicaSets replace their pods like Deployments. StatefulSet pods are only
+// replaced once the failed ones are deleted, DaemonSet pods and bare pods aren't replaced at all.
+func reschedulablePods(u *ResourceUsage) int32 {
+	switch u.Details.Kind {
+	case "Deployment", "DeploymentConfig", "Rollout", "ReplicaSet":
+		return u.Details.NormalReplicas
+	case "Job", "CronJob":
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,174 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseFailureSimulation(t *testing.T) {
+	var tests = []struct {
+		value   string
+		failure FailureSimulation
+		err     bool
+	}{
+		{value: "zone", failure: FailureSimulation{Zone: true}},
+		{value: "nodes=2", failure: FailureSimulation{Nodes: 2}},
+		{value: "nodes=0", err: true},
+		{value: "nodes=two", err: true},
+		{value: "region", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			r := require.New(t)
+
+			failure, err := ParseFailureSimulation(test.value)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(test.failure, failure)
+		})
+	}
+}
+
+func TestSimulateFailure(t *testing.T) {
+	zonedNode := func(name, zone, cpu, memory string) v1.Node {
+		node := testNode(name, map[string]string{v1.LabelTopologyZone: zone})
+		node.Status.Allocatable = v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse(memory),
+		}
+
+		return node
+	}
+
+	nodes := []v1.Node{
+		zonedNode("a-1", "a", "4", "16Gi"),
+		zonedNode("a-2", "a", "4", "16Gi"),
+		zonedNode("b-1", "b", "2", "8Gi"),
+		zonedNode("c-1", "c", "2", "8Gi"),
+	}
+
+	pod := &PodResources{MaxResources: Resources{
+		CPUMin:    resource.MustParse("100m"),
+		CPUMax:    resource.MustParse("200m"),
+		MemoryMin: resource.MustParse("100Mi"),
+		MemoryMax: resource.MustParse("200Mi"),
+	}}
+
+	usage := []*ResourceUsage{
+		{Pod: pod, Details: Details{Kind: "Deployment", NormalReplicas: 6}},
+		{Pod: pod, Details: Details{Kind: "Job"}},
+		{Pod: pod, Details: Details{Kind: "StatefulSet", NormalReplicas: 3}},
+		{Pod: pod, Details: Details{Kind: "DaemonSet", NormalReplicas: 4}},
+		{Details: Details{Kind: "Deployment"}},
+	}
+
+	var tests = []struct {
+		name            string
+		nodes           []v1.Node
+		failure         FailureSimulation
+		overhead        SystemOverhead
+		err             bool
+		cpuMin          resource.Quantity
+		memoryMax       resource.Quantity
+		remainingCPU    resource.Quantity
+		remainingMemory resource.Quantity
+	}{
+		{
+			// 2 of 6 deployment pods and the job pod are in the largest zone
+			name:            "zone",
+			nodes:           nodes,
+			failure:         FailureSimulation{Zone: true},
+			cpuMin:          resource.MustParse("300m"),
+			memoryMax:       resource.MustParse("600Mi"),
+			remainingCPU:    resource.MustParse("4"),
+			remainingMemory: resource.MustParse("16Gi"),
+		},
+		{
+			// 2 of the deployment pods are spread across each of the 2 failed nodes
+			name:            "nodes",
+			nodes:           nodes,
+			failure:         FailureSimulation{Nodes: 2},
+			cpuMin:          resource.MustParse("500m"),
+			memoryMax:       resource.MustParse("1000Mi"),
+			remainingCPU:    resource.MustParse("4"),
+			remainingMemory: resource.MustParse("16Gi"),
+		},
+		{
+			name:            "nodes with overhead",
+			nodes:           nodes,
+			failure:         FailureSimulation{Nodes: 2},
+			overhead:        SystemOverhead{CPU: resource.MustParse("500m"), Memory: resource.MustParse("1Gi")},
+			cpuMin:          resource.MustParse("500m"),
+			memoryMax:       resource.MustParse("1000Mi"),
+			remainingCPU:    resource.MustParse("3"),
+			remainingMemory: resource.MustParse("14Gi"),
+		},
+		{
+			name:    "no nodes",
+			failure: FailureSimulation{Zone: true},
+			err:     true,
+		},
+		{
+			name:    "all nodes",
+			nodes:   nodes,
+			failure: FailureSimulation{Nodes: 4},
+			err:     true,
+		},
+		{
+			name:    "single zone",
+			nodes:   nodes[:2],
+			failure: FailureSimulation{Zone: true},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			headroom, err := SimulateFailure(usage, test.nodes, test.failure, test.overhead)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			AssertEqualQuantities(r, test.cpuMin, headroom.Resources.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.memoryMax, headroom.Resources.MemoryMax, "memory limit value")
+			AssertEqualQuantities(r, test.remainingCPU, headroom.RemainingCPU, "remaining cpu value")
+			AssertEqualQuantities(r, test.remainingMemory, headroom.RemainingMemory, "remaining memory value")
+		})
+	}
+}
+
+func TestReschedulablePods(t *testing.T) {
+	var tests = []struct {
+		kind string
+		pods int32
+	}{
+		{kind: "Deployment", pods: 3},
+		{kind: "DeploymentConfig", pods: 3},
+		{kind: "Rollout", pods: 3},
+		{kind: "ReplicaSet", pods: 3},
+		{kind: "Job", pods: 1},
+		{kind: "StatefulSet", pods: 0},
+		{kind: "DaemonSet", pods: 0},
+		{kind: "Pod", pods: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.kind, func(t *testing.T) {
+			require.Equal(t, test.pods, reschedulablePods(&ResourceUsage{Details: Details{Kind: test.kind, NormalReplicas: 3}}))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// GroupByNamespace groups resources by their namespace.
+	GroupByNamespace = "namespace"
+	// GroupByPriorityClass groups resources by the priorityClassName of their pods.
+	GroupByPriorityClass = "priorityClass"
+	// GroupByKind groups resources by their kind.
+	GroupByKind = "kind"
+	// GroupByLabelPrefix groups resources by the value of a label, e.g. label:team.
+	GroupByLabelPrefix = "label:"
+	// GroupByAnnotationPrefix groups resources by the value of an annotation, e.g. annotation:cost-center.
+	GroupByAnnotationPrefix = "annotation:"
+)
+
+// Group contains the usages of all resources with the same group key.
+type Group struct {
+	Key   string
+	Usage []*ResourceUsage
+	// Groups are the nested groups of the usages, if they are grouped by several keys.
+	Groups []Group
+}
+
+// GroupKey returns the function, which determines the group key of a resource for the given grouping.
+func GroupKey(groupBy string) (func(*ResourceUsage) string, error) {
+	switch {
+	case groupBy == GroupByNamespace:
+		return func(u *ResourceUsage) string {
+			return u.Details.Namespace
+		}, nil
+	case groupBy == GroupByPriorityClass:
+		return func(u *ResourceUsage) string {
+			return u.Details.PriorityClassName
+		}, nil
+	case groupBy == GroupByKind:
+		return func(u *ResourceUsage) string {
+			return u.Details.Kind
+		}, nil
+	case strings.HasPrefix(groupBy, GroupByLabelPrefix) && len(groupBy) > len(GroupByLabelPrefix):
+		return LabelGroupKey(strings.TrimPrefix(groupBy, GroupByLabelPrefix)), nil
+	case strings.HasPrefix(groupBy, GroupByAnnotationPrefix) && len(groupBy) > len(GroupByAnnotationPrefix):
+		key := strings.TrimPrefix(groupBy, GroupByAnnotationPrefix)
+
+		return func(u *ResourceUsage) string {
+			return u.Details.Annotations[key]
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown grouping %q, supported are %s, %s, %s, %s<key> and %s<key>",
+			groupBy, GroupByNamespace, GroupByPriorityClass, GroupByKind, GroupByLabelPrefix, GroupByAnnotationPrefix)
+	}
+}
+
+// GroupKeys returns the functions of a comma separated list of groupings, e.g. namespace,kind for nested groups.
+func GroupKeys(groupBy string) ([]func(*ResourceUsage) string, error) {
+	var keys []func(*ResourceUsage) string
+
+	for _, grouping := range strings.Split(groupBy, ",") {
+		key, err := GroupKey(strings.TrimSpace(grouping))
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// LabelGroupKey returns the function, which groups resources by the value of their label with the given key.
+func LabelGroupKey(key string) func(*ResourceUsage) string {
+	return func(u *ResourceUsage) string {
+		return u.Details.Labels[key]
+	}
+}
+
+// GroupBy groups the usages by their key. The groups are sorted by key.
+func GroupBy(usage []*ResourceUsage, key func(*ResourceUsage) string) []Group {
+	var groups []Group
+
+	for _, u := range usage {
+		k := key(u)
+
+		i, found := slices.BinarySearchFunc(groups, k, func(g Group, k string) int {
+			return strings.Compare(g.Key, k)
+		})
+		if !found {
+			groups = slices.Insert(groups, i, Group{Key: k})
+		}
+
+		groups[i].Usage = append(groups[i].Usage, u)
+	}
+
+	return groups
+}
+
+// GroupByNested groups the usages by the first key, and the usages of each group by the remaining keys.
+func GroupByNested(usage []*ResourceUsage, keys []func(*ResourceUsage) string) []Group {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	groups := GroupBy(usage, keys[0])
+
+	for i := range groups {
+		groups[i].Groups = GroupByNested(groups[i].Usage, keys[1:])
+	}
+
+	return groups
+}
@@ -0,0 +1,106 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupBy(t *testing.T) {
+	r := require.New(t)
+
+	usage := []*ResourceUsage{
+		{Details: Details{Name: "a", PriorityClassName: "low"}},
+		{Details: Details{Name: "b"}},
+		{Details: Details{Name: "c", PriorityClassName: "high"}},
+		{Details: Details{Name: "d", PriorityClassName: "low"}},
+	}
+
+	key, err := GroupKey(GroupByPriorityClass)
+	r.NoError(err)
+
+	groups := GroupBy(usage, key)
+	r.Len(groups, 3)
+	r.Equal("", groups[0].Key)
+	r.Equal("high", groups[1].Key)
+	r.Equal("low", groups[2].Key)
+	r.Equal([]*ResourceUsage{usage[0], usage[3]}, groups[2].Usage)
+
+	key, err = GroupKey(GroupByNamespace)
+	r.NoError(err)
+	r.Equal("team-a", key(&ResourceUsage{Details: Details{Namespace: "team-a"}}))
+
+	_, err = GroupKey("color")
+	r.Error(err)
+
+	labelKey := LabelGroupKey("team")
+	r.Equal("payments", labelKey(&ResourceUsage{Details: Details{Labels: map[string]string{"team": "payments"}}}))
+	r.Equal("", labelKey(&ResourceUsage{Details: Details{Labels: map[string]string{"app": "web"}}}))
+	r.Equal("", labelKey(&ResourceUsage{}))
+}
+
+func TestGroupKeys(t *testing.T) {
+	u := &ResourceUsage{Details: Details{
+		Kind:        "Deployment",
+		Labels:      map[string]string{"team": "payments"},
+		Annotations: map[string]string{"cost-center": "4711"},
+	}}
+
+	var tests = []struct {
+		groupBy string
+		key     string
+		err     bool
+	}{
+		{groupBy: GroupByKind, key: "Deployment"},
+		{groupBy: "label:team", key: "payments"},
+		{groupBy: "label:app", key: ""},
+		{groupBy: "annotation:cost-center", key: "4711"},
+		{groupBy: "label:", err: true},
+		{groupBy: "annotation", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.groupBy, func(t *testing.T) {
+			r := require.New(t)
+
+			key, err := GroupKey(test.groupBy)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(test.key, key(u))
+		})
+	}
+}
+
+func TestGroupByNested(t *testing.T) {
+	r := require.New(t)
+
+	usage := []*ResourceUsage{
+		{Details: Details{Namespace: "team-b", Kind: "Deployment", Name: "a"}},
+		{Details: Details{Namespace: "team-a", Kind: "StatefulSet", Name: "b"}},
+		{Details: Details{Namespace: "team-a", Kind: "Deployment", Name: "c"}},
+		{Details: Details{Namespace: "team-a", Kind: "Deployment", Name: "d"}},
+	}
+
+	keys, err := GroupKeys("namespace, kind")
+	r.NoError(err)
+
+	groups := GroupByNested(usage, keys)
+	r.Len(groups, 2)
+	r.Equal("team-a", groups[0].Key)
+	r.Len(groups[0].Usage, 3)
+	r.Len(groups[0].Groups, 2)
+	r.Equal("Deployment", groups[0].Groups[0].Key)
+	r.Equal([]*ResourceUsage{usage[2], usage[3]}, groups[0].Groups[0].Usage)
+	r.Empty(groups[0].Groups[0].Groups)
+	r.Equal("StatefulSet", groups[0].Groups[1].Key)
+	r.Equal("team-b", groups[1].Key)
+	r.Len(groups[1].Groups, 1)
+
+	_, err = GroupKeys("namespace,color")
+	r.Error(err)
+}
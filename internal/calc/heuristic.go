@@ -0,0 +1,121 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// HeuristicUsage estimates the resources of a kind without a calculator from the pod spec embedded in its spec, e.g. in
+// spec.template.spec or spec.podTemplate.spec. The replicas are taken from the nearest replicas field on the way to the
+// pod spec, e.g. spec.replicas or spec.workers.replicas, or are assumed. As the rollout of the kind is unknown, all
+// pods are assumed to start at once. The estimate is marked as Heuristic in the details. Objects without a pod spec
+// aren't supported.
+func HeuristicUsage(object *runtime.Unknown, opts Options) (*ResourceUsage, error) {
+	// decoding the json keeps the integers, which the replicas are searched for, as int64
+	data, err := sigsyaml.YAMLToJSON(object.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", object.GroupVersionKind(), err)
+	}
+
+	content := unstructured.Unstructured{}
+	if err := content.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", object.GroupVersionKind(), err)
+	}
+
+	spec, ok := content.Object["spec"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: no pod spec found", ErrResourceNotSupported)
+	}
+
+	path, podSpecContent, specReplicas := findPodSpec(spec, []string{"spec"}, nil)
+	if podSpecContent == nil {
+		return nil, fmt.Errorf("%w: no pod spec found", ErrResourceNotSupported)
+	}
+
+	var podSpec v1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podSpecContent, &podSpec); err != nil {
+		return nil, fmt.Errorf("decoding pod spec %s: %w", strings.Join(path, "."), err)
+	}
+
+	replicas, assumed := opts.replicas(specReplicas)
+	podResources := calcPodResources(&podSpec, opts)
+
+	resourceUsage := ResourceUsage{
+		NormalResources:  podResources.Containers.MulInt32(replicas),
+		RolloutResources: podResources.MaxResources.MulInt32(replicas),
+		Pod:              podResources,
+		Details: Details{
+			Version:           content.GetAPIVersion(),
+			Kind:              content.GetKind(),
+			Namespace:         content.GetNamespace(),
+			Name:              content.GetName(),
+			PriorityClassName: podSpec.PriorityClassName,
+			Replicas:          replicas,
+			ReplicasAssumed:   assumed,
+			MaxReplicas:       replicas,
+			NormalReplicas:    replicas,
+			Heuristic:         true,
+		},
+	}
+
+	resourceUsage.explainf(opts, "heuristic: pod spec found in %s", strings.Join(path, "."))
+	resourceUsage.explainReplicas(opts)
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "normal = containers * %d", replicas)
+	resourceUsage.explainf(opts, "rollout = max * %d, the rollout of the kind is unknown", replicas)
+
+	return &resourceUsage, nil
+}
+
+// findPodSpec searches the object for a pod spec, an object with a list of containers, breadth first and in the
+// order of the field names. It returns the path of the pod spec, the pod spec and the nearest replicas field on its
+// path.
+func findPodSpec(object map[string]any, path []string, replicas *int32) ([]string, map[string]any, *int32) {
+	type candidate struct {
+		path     []string
+		object   map[string]any
+		replicas *int32
+	}
+
+	queue := []candidate{{path: path, object: object, replicas: replicas}}
+
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		if value, ok := c.object["replicas"].(int64); ok {
+			r := int32(value)
+			c.replicas = &r
+		}
+
+		if _, ok := c.object["containers"].([]any); ok {
+			return c.path, c.object, c.replicas
+		}
+
+		keys := make([]string, 0, len(c.object))
+
+		for key, value := range c.object {
+			if _, ok := value.(map[string]any); ok {
+				keys = append(keys, key)
+			}
+		}
+
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			queue = append(queue, candidate{
+				path:     append(slices.Clip(c.path), key),
+				object:   c.object[key].(map[string]any),
+				replicas: c.replicas,
+			})
+		}
+	}
+
+	return nil, nil, nil
+}
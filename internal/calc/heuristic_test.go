@@ -0,0 +1,150 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var templateCustomResource = `
+apiVersion: example.com/v1
+kind: Worker
+metadata:
+  name: worker
+  namespace: batch
+spec:
+  replicas: 3
+  template:
+    spec:
+      initContainers:
+      - name: init
+        resources:
+          requests:
+            cpu: "1"
+      containers:
+      - name: worker
+        resources:
+          requests:
+            cpu: 250m
+            memory: 1Gi`
+
+var nestedCustomResource = `
+apiVersion: example.com/v1
+kind: Cluster
+metadata:
+  name: cluster
+spec:
+  replicas: 5
+  workers:
+    replicas: 2
+    podTemplate:
+      spec:
+        containers:
+        - name: worker
+          resources:
+            requests:
+              cpu: 500m
+              memory: 2Gi`
+
+var assumedReplicasCustomResource = `
+apiVersion: example.com/v1
+kind: Runner
+metadata:
+  name: runner
+spec:
+  podSpec:
+    containers:
+    - name: runner
+      resources:
+        requests:
+          cpu: 100m
+          memory: 128Mi`
+
+var configCustomResource = `
+apiVersion: example.com/v1
+kind: Config
+metadata:
+  name: config
+spec:
+  settings:
+    replicas: 3`
+
+func TestHeuristicUsage(t *testing.T) {
+	assumedReplicas := int32(4)
+
+	tests := []struct {
+		name          string
+		object        string
+		opts          Options
+		path          string
+		replicas      int32
+		assumed       bool
+		cpu           resource.Quantity
+		memory        resource.Quantity
+		rolloutCPU    resource.Quantity
+		rolloutMemory resource.Quantity
+	}{
+		{
+			name:          "spec.template",
+			object:        templateCustomResource,
+			path:          "spec.template.spec",
+			replicas:      3,
+			cpu:           resource.MustParse("750m"),
+			memory:        resource.MustParse("3Gi"),
+			rolloutCPU:    resource.MustParse("3"),
+			rolloutMemory: resource.MustParse("3Gi"),
+		},
+		{
+			name:          "nearest replicas",
+			object:        nestedCustomResource,
+			path:          "spec.workers.podTemplate.spec",
+			replicas:      2,
+			cpu:           resource.MustParse("1"),
+			memory:        resource.MustParse("4Gi"),
+			rolloutCPU:    resource.MustParse("1"),
+			rolloutMemory: resource.MustParse("4Gi"),
+		},
+		{
+			name:          "assumed replicas",
+			object:        assumedReplicasCustomResource,
+			opts:          Options{AssumedReplicas: &assumedReplicas},
+			path:          "spec.podSpec",
+			replicas:      4,
+			assumed:       true,
+			cpu:           resource.MustParse("400m"),
+			memory:        resource.MustParse("512Mi"),
+			rolloutCPU:    resource.MustParse("400m"),
+			rolloutMemory: resource.MustParse("512Mi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			test.opts.Explain = true
+
+			usage, err := HeuristicUsage(&runtime.Unknown{Raw: []byte(test.object)}, test.opts)
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.True(usage.Details.Heuristic)
+			r.Equal(test.replicas, usage.Details.Replicas)
+			r.Equal(test.assumed, usage.Details.ReplicasAssumed)
+			r.Contains(usage.Explanation, "heuristic: pod spec found in "+test.path)
+			AssertEqualQuantities(r, test.cpu, usage.NormalResources.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.memory, usage.NormalResources.MemoryMin, "memory request value")
+			AssertEqualQuantities(r, test.rolloutCPU, usage.RolloutResources.CPUMin, "rollout cpu request value")
+			AssertEqualQuantities(r, test.rolloutMemory, usage.RolloutResources.MemoryMin, "rollout memory request value")
+		})
+	}
+}
+
+func TestHeuristicUsageWithoutPodSpec(t *testing.T) {
+	r := require.New(t)
+
+	_, err := HeuristicUsage(&runtime.Unknown{Raw: []byte(configCustomResource)}, Options{})
+	r.ErrorIs(err, ErrResourceNotSupported)
+}
@@ -0,0 +1,106 @@
+package calc
+
+import (
+	"fmt"
+
+	autoscalingV1 "k8s.io/api/autoscaling/v1"
+	autoscalingV2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// HPAReplicas selects which replicas are used for a workload scaled by a HorizontalPodAutoscaler.
+type HPAReplicas string
+
+const (
+	// HPASpecReplicas uses the replicas of the workload spec, as if there was no HorizontalPodAutoscaler.
+	HPASpecReplicas HPAReplicas = "spec"
+	// HPAMinReplicas uses the minReplicas of the HorizontalPodAutoscaler.
+	HPAMinReplicas HPAReplicas = "min"
+	// HPAMaxReplicas uses the maxReplicas of the HorizontalPodAutoscaler.
+	HPAMaxReplicas HPAReplicas = "max"
+)
+
+// ParseHPAReplicas parses the name of a HPAReplicas policy.
+func ParseHPAReplicas(policy string) (HPAReplicas, error) {
+	switch HPAReplicas(policy) {
+	case HPASpecReplicas, HPAMinReplicas, HPAMaxReplicas:
+		return HPAReplicas(policy), nil
+	default:
+		return "", fmt.Errorf("unknown hpa replicas %q, supported are %s, %s and %s", policy, HPAMinReplicas, HPASpecReplicas, HPAMaxReplicas)
+	}
+}
+
+// Autoscaler contains the replica bounds of a HorizontalPodAutoscaler.
+type Autoscaler struct {
+	Name        string
+	MinReplicas int32
+	MaxReplicas int32
+}
+
+// scaleTarget identifies the workload a HorizontalPodAutoscaler scales.
+type scaleTarget struct {
+	namespace string
+	kind      string
+	name      string
+}
+
+// Autoscalers are HorizontalPodAutoscalers indexed by the workload they scale.
+type Autoscalers map[scaleTarget]Autoscaler
+
+// Add adds the object to the autoscalers if it is a HorizontalPodAutoscaler and reports whether it was one.
+func (a Autoscalers) Add(object runtime.Object) bool {
+	switch hpa := object.(type) {
+	case *autoscalingV1.HorizontalPodAutoscaler:
+		a.add(hpa.ObjectMeta, hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name, hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	case *autoscalingV2.HorizontalPodAutoscaler:
+		a.add(hpa.ObjectMeta, hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name, hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (a Autoscalers) add(meta metav1.ObjectMeta, kind, name string, minReplicas *int32, maxReplicas int32) {
+	autoscaler := Autoscaler{
+		Name: meta.Name,
+		// https://pkg.go.dev/k8s.io/api/autoscaling/v2#HorizontalPodAutoscalerSpec
+		MinReplicas: 1,
+		MaxReplicas: maxReplicas,
+	}
+
+	if minReplicas != nil {
+		autoscaler.MinReplicas = *minReplicas
+	}
+
+	a[scaleTarget{namespace: meta.Namespace, kind: kind, name: name}] = autoscaler
+}
+
+// replicas returns the replicas selected by the policy.
+func (a Autoscaler) replicas(policy HPAReplicas, specReplicas int32) int32 {
+	switch policy {
+	case HPAMinReplicas:
+		return a.MinReplicas
+	case HPAMaxReplicas:
+		return a.MaxReplicas
+	default:
+		return specReplicas
+	}
+}
+
+// autoscaledReplicas returns the replicas of a workload for its normal and its rollout resources, which only differ
+// if the workload is scaled by a HorizontalPodAutoscaler. The name of the autoscaler is returned as well.
+// The rollout replicas are never less than the normal ones.
+func (o Options) autoscaledReplicas(kind string, meta metav1.ObjectMeta, replicas int32) (normal, peak int32, autoscaler string) {
+	hpa, ok := o.Autoscalers[scaleTarget{namespace: meta.Namespace, kind: kind, name: meta.Name}]
+	// autoscaling is disabled for workloads scaled to zero
+	if !ok || replicas == 0 {
+		return replicas, replicas, ""
+	}
+
+	normal = hpa.replicas(o.HPANormalReplicas, replicas)
+	peak = max(normal, hpa.replicas(o.HPAPeakReplicas, replicas))
+
+	return normal, peak, hpa.Name
+}
@@ -0,0 +1,102 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestAutoscaledDeployment(t *testing.T) {
+	var tests = []struct {
+		name           string
+		normal         HPAReplicas
+		peak           HPAReplicas
+		normalCPUMin   resource.Quantity
+		rolloutCPUMin  resource.Quantity
+		replicas       int32
+		normalReplicas int32
+	}{
+		{
+			name:           "spec replicas by default",
+			normalCPUMin:   resource.MustParse("2500m"),
+			rolloutCPUMin:  resource.MustParse("3250m"),
+			replicas:       10,
+			normalReplicas: 10,
+		},
+		{
+			name:           "min replicas normal, max replicas peak",
+			normal:         HPAMinReplicas,
+			peak:           HPAMaxReplicas,
+			normalCPUMin:   resource.MustParse("500m"),
+			rolloutCPUMin:  resource.MustParse("6250m"),
+			replicas:       20,
+			normalReplicas: 2,
+		},
+		{
+			name:           "min replicas normal, spec replicas peak",
+			normal:         HPAMinReplicas,
+			peak:           HPASpecReplicas,
+			normalCPUMin:   resource.MustParse("500m"),
+			rolloutCPUMin:  resource.MustParse("3250m"),
+			replicas:       10,
+			normalReplicas: 2,
+		},
+		{
+			name:           "peak is never less than normal",
+			normal:         HPAMaxReplicas,
+			peak:           HPAMinReplicas,
+			normalCPUMin:   resource.MustParse("5"),
+			rolloutCPUMin:  resource.MustParse("6250m"),
+			replicas:       20,
+			normalReplicas: 20,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			hpa, err := Decode([]byte(normalDeploymentHPA))
+			r.NoError(err)
+
+			autoscalers := Autoscalers{}
+			r.True(autoscalers.Add(hpa))
+
+			usage, err := ResourceQuotaFromYaml([]byte(normalDeployment), Options{
+				Autoscalers:       autoscalers,
+				HPANormalReplicas: test.normal,
+				HPAPeakReplicas:   test.peak,
+			})
+			r.NoError(err)
+
+			AssertEqualQuantities(r, test.normalCPUMin, usage.NormalResources.CPUMin, "normal cpu request value")
+			AssertEqualQuantities(r, test.rolloutCPUMin, usage.RolloutResources.CPUMin, "rollout cpu request value")
+			r.Equal(test.replicas, usage.Details.Replicas)
+			r.Equal(test.normalReplicas, usage.Details.NormalReplicas)
+			r.Equal("normal-hpa", usage.Details.Autoscaler)
+		})
+	}
+}
+
+func TestAutoscalersAdd(t *testing.T) {
+	r := require.New(t)
+
+	deployment, err := Decode([]byte(normalDeployment))
+	r.NoError(err)
+
+	autoscalers := Autoscalers{}
+	r.False(autoscalers.Add(deployment))
+	r.Empty(autoscalers)
+}
+
+func TestParseHPAReplicas(t *testing.T) {
+	r := require.New(t)
+
+	policy, err := ParseHPAReplicas("max")
+	r.NoError(err)
+	r.Equal(HPAMaxReplicas, policy)
+
+	_, err = ParseHPAReplicas("avg")
+	r.Error(err)
+}
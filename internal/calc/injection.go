@@ -0,0 +1,127 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// InjectionRules describe the containers mutating webhooks inject into the pods at admission, e.g. vault agents or log
+// shippers. The injected containers aren't part of the manifests, but count against the quota like any other container.
+type InjectionRules struct {
+	Rules []InjectionRule `json:"rules"`
+	// namespaceLabels are the labels of the namespaces of the input, to evaluate the namespace selectors.
+	namespaceLabels map[string]map[string]string
+}
+
+// InjectionRule injects a container into the pods it matches. A pod has to match all of the given selectors.
+type InjectionRule struct {
+	Name string `json:"name"`
+	// Namespaces restricts the rule to the pods of these namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces by their labels, like the namespaceSelector of a webhook. It is evaluated
+	// against the namespaces of the input. Without namespaces in the input, every namespace matches.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// ObjectSelector selects the pods by their labels, like the objectSelector of a webhook.
+	ObjectSelector *metav1.LabelSelector `json:"objectSelector,omitempty"`
+	// Annotations selects the pods, which have all of these annotations, e.g. vault.hashicorp.com/agent-inject: "true".
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Container is the injected container. Only its resources and, for init containers, its restartPolicy are used.
+	Container v1.Container `json:"container"`
+	// InitContainer injects the container as an init container.
+	InitContainer bool `json:"initContainer,omitempty"`
+}
+
+// Validate checks the selectors of the rules.
+func (r *InjectionRules) Validate() error {
+	for _, rule := range r.Rules {
+		for _, selector := range []*metav1.LabelSelector{rule.NamespaceSelector, rule.ObjectSelector} {
+			if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+				return fmt.Errorf("injection rule %s: %w", rule.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// AddNamespace records the labels of a namespace of the input, which the namespace selectors are evaluated against.
+func (r *InjectionRules) AddNamespace(namespace *v1.Namespace) {
+	if r.namespaceLabels == nil {
+		r.namespaceLabels = map[string]map[string]string{}
+	}
+
+	r.namespaceLabels[namespace.Name] = namespace.Labels
+}
+
+// inject returns a copy of the pod spec with the containers of the matching rules appended, or the pod spec itself if
+// no rule matches.
+func (r *InjectionRules) inject(namespace string, pod metav1.ObjectMeta, podSpec *v1.PodSpec) *v1.PodSpec {
+	if r == nil {
+		return podSpec
+	}
+
+	injected := podSpec
+
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if !rule.matches(namespace, pod, r.namespaceLabels) {
+			continue
+		}
+
+		if injected == podSpec {
+			injected = podSpec.DeepCopy()
+		}
+
+		container := *rule.Container.DeepCopy()
+		if container.Name == "" {
+			container.Name = rule.Name
+		}
+
+		if rule.InitContainer {
+			injected.InitContainers = append(injected.InitContainers, container)
+		} else {
+			injected.Containers = append(injected.Containers, container)
+		}
+	}
+
+	return injected
+}
+
+// matches returns whether the rule matches a pod of the namespace.
+func (rule *InjectionRule) matches(namespace string, pod metav1.ObjectMeta, namespaceLabels map[string]map[string]string) bool {
+	if len(rule.Namespaces) > 0 && !slices.Contains(rule.Namespaces, namespace) {
+		return false
+	}
+
+	if rule.NamespaceSelector != nil && namespaceLabels != nil {
+		if !selects(rule.NamespaceSelector, namespaceLabels[namespace]) {
+			return false
+		}
+	}
+
+	if rule.ObjectSelector != nil && !selects(rule.ObjectSelector, pod.Labels) {
+		return false
+	}
+
+	for key, value := range rule.Annotations {
+		if pod.Annotations[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selects returns whether the selector matches the labels. Invalid selectors, which Validate rejects, match nothing.
+func selects(selector *metav1.LabelSelector, values map[string]string) bool {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+
+	return s.Matches(labels.Set(values))
+}
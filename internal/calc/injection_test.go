@@ -0,0 +1,132 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+var injectionRules = `
+rules:
+- name: vault-agent
+  annotations:
+    vault.hashicorp.com/agent-inject: "true"
+  container:
+    resources:
+      requests:
+        cpu: 50m
+        memory: 64Mi
+- name: vault-agent-init
+  annotations:
+    vault.hashicorp.com/agent-inject: "true"
+  initContainer: true
+  container:
+    resources:
+      requests:
+        cpu: "2"
+- name: log-shipper
+  namespaceSelector:
+    matchLabels:
+      logging: enabled
+  objectSelector:
+    matchExpressions:
+    - key: app
+      operator: Exists
+  container:
+    resources:
+      requests:
+        cpu: 100m`
+
+func TestInjectionRules(t *testing.T) {
+	loggingNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "logged", Labels: map[string]string{"logging": "enabled"}}}
+	plainNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+
+	tests := []struct {
+		name       string
+		namespaces []*v1.Namespace
+		namespace  string
+		pod        metav1.ObjectMeta
+		cpu        resource.Quantity
+		max        resource.Quantity
+	}{
+		{
+			name:      "no rule matches",
+			namespace: "default",
+			cpu:       resource.MustParse("250m"),
+			max:       resource.MustParse("250m"),
+		},
+		{
+			name:      "annotated pod gets the container and the init container",
+			namespace: "default",
+			pod:       metav1.ObjectMeta{Annotations: map[string]string{"vault.hashicorp.com/agent-inject": "true"}},
+			cpu:       resource.MustParse("300m"),
+			max:       resource.MustParse("2"),
+		},
+		{
+			name:      "namespace selector matches every namespace without namespaces in the input",
+			namespace: "default",
+			pod:       metav1.ObjectMeta{Labels: map[string]string{"app": "normal"}},
+			cpu:       resource.MustParse("350m"),
+			max:       resource.MustParse("350m"),
+		},
+		{
+			name:       "namespace selector doesn't match",
+			namespaces: []*v1.Namespace{loggingNamespace, plainNamespace},
+			namespace:  "default",
+			pod:        metav1.ObjectMeta{Labels: map[string]string{"app": "normal"}},
+			cpu:        resource.MustParse("250m"),
+			max:        resource.MustParse("250m"),
+		},
+		{
+			name:       "namespace selector matches",
+			namespaces: []*v1.Namespace{loggingNamespace, plainNamespace},
+			namespace:  "logged",
+			pod:        metav1.ObjectMeta{Labels: map[string]string{"app": "normal"}},
+			cpu:        resource.MustParse("350m"),
+			max:        resource.MustParse("350m"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			var rules InjectionRules
+			r.NoError(sigsyaml.UnmarshalStrict([]byte(injectionRules), &rules))
+			r.NoError(rules.Validate())
+
+			for _, namespace := range test.namespaces {
+				rules.AddNamespace(namespace)
+			}
+
+			podSpec := &v1.PodSpec{Containers: []v1.Container{{
+				Name:      "app",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m")}},
+			}}}
+
+			test.pod.Namespace = test.namespace
+			podResources := annotatedPodResources(test.pod, test.pod, podSpec, Options{Injections: &rules})
+
+			AssertEqualQuantities(r, test.cpu, podResources.Containers.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.max, podResources.MaxResources.CPUMin, "max cpu request value")
+			r.Len(podSpec.Containers, 1, "the pod spec of the manifest is unchanged")
+		})
+	}
+}
+
+func TestInjectionRulesValidate(t *testing.T) {
+	r := require.New(t)
+
+	rules := InjectionRules{Rules: []InjectionRule{{
+		Name: "invalid",
+		ObjectSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "app", Operator: "Near"},
+		}},
+	}}}
+
+	r.Error(rules.Validate())
+}
@@ -2,21 +2,63 @@ package calc
 
 import batchV1 "k8s.io/api/batch/v1"
 
-func job(job batchV1.Job) *ResourceUsage {
-	podResources := calcPodResources(&job.Spec.Template.Spec)
+// defaultBackoffLimit is the backoffLimit the api server sets on jobs which don't specify one.
+const defaultBackoffLimit = 6
+
+func job(job batchV1.Job, opts Options) *ResourceUsage {
+	podResources := annotatedPodResources(job.ObjectMeta, job.Spec.Template.ObjectMeta, &job.Spec.Template.Spec, opts)
+
+	retryResources := opts.jobRetryResources(&job.Spec, podResources)
 
 	resourceUsage := ResourceUsage{
 		NormalResources:  podResources.Containers,
-		RolloutResources: podResources.MaxResources,
+		RolloutResources: podResources.MaxResources.Add(retryResources),
+		Pod:              podResources,
 		Details: Details{
-			Version:     job.APIVersion,
-			Kind:        job.Kind,
-			Name:        job.Name,
-			Strategy:    "",
-			Replicas:    0,
-			MaxReplicas: 0,
+			Version:           job.APIVersion,
+			Kind:              job.Kind,
+			Namespace:         job.Namespace,
+			Name:              job.Name,
+			PriorityClassName: job.Spec.Template.Spec.PriorityClassName,
+			Strategy:          "",
+			Replicas:          0,
+			MaxReplicas:       0,
 		},
 	}
 
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "retry overlap: %s", retryResources)
+	resourceUsage.explainf(opts, "normal = containers")
+	resourceUsage.explainf(opts, "rollout = max + retry overlap")
+
+	if opts.Timeline {
+		resourceUsage.Timeline = staticTimeline(&resourceUsage, newPodTimings(&job.Spec.Template.Spec, 0, opts))
+	}
+
 	return &resourceUsage
 }
+
+// jobRetryResources returns the resources of a failing pod, which is still terminating while its retry pod is
+// already starting. A job only retries if its backoffLimit allows it, and it only creates the retry pod early if its
+// podReplacementPolicy doesn't wait for failed pods to be terminated. Without JobRetryOverlap no overlap is assumed.
+func (o Options) jobRetryResources(spec *batchV1.JobSpec, podResources *PodResources) Resources {
+	if !o.JobRetryOverlap {
+		return Resources{}
+	}
+
+	backoffLimit := int32(defaultBackoffLimit)
+	if spec.BackoffLimit != nil {
+		backoffLimit = *spec.BackoffLimit
+	}
+
+	if backoffLimit == 0 {
+		return Resources{}
+	}
+
+	// https://kubernetes.io/docs/concepts/workloads/controllers/job/#pod-replacement-policy
+	if spec.PodReplacementPolicy != nil && *spec.PodReplacementPolicy == batchV1.Failed {
+		return Resources{}
+	}
+
+	return podResources.Containers
+}
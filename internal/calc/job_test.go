@@ -11,6 +11,7 @@ func TestJob(t *testing.T) {
 	var tests = []struct {
 		name        string
 		job         string
+		opts        Options
 		cpuMin      resource.Quantity
 		cpuMax      resource.Quantity
 		memoryMin   resource.Quantity
@@ -27,6 +28,24 @@ func TestJob(t *testing.T) {
 			memoryMin: resource.MustParse("2Gi"),
 			memoryMax: resource.MustParse("4Gi"),
 		},
+		{
+			name:      "retry overlap",
+			job:       normalJob,
+			opts:      Options{JobRetryOverlap: true},
+			cpuMin:    resource.MustParse("500m"),
+			cpuMax:    resource.MustParse("2"),
+			memoryMin: resource.MustParse("4Gi"),
+			memoryMax: resource.MustParse("8Gi"),
+		},
+		{
+			name:      "retry overlap without early replacement",
+			job:       failedReplacementJob,
+			opts:      Options{JobRetryOverlap: true},
+			cpuMin:    resource.MustParse("250m"),
+			cpuMax:    resource.MustParse("1"),
+			memoryMin: resource.MustParse("2Gi"),
+			memoryMax: resource.MustParse("4Gi"),
+		},
 	}
 
 	for _, test := range tests {
@@ -34,7 +53,7 @@ func TestJob(t *testing.T) {
 			test.name, func(t *testing.T) {
 				r := require.New(t)
 
-				usage, err := ResourceQuotaFromYaml([]byte(test.job))
+				usage, err := ResourceQuotaFromYaml([]byte(test.job), test.opts)
 				r.NoError(err)
 				r.NotEmpty(usage)
 
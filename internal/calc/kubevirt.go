@@ -0,0 +1,254 @@
+package calc
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// virtLauncherOverhead approximates the fixed memory overhead of the virt-launcher pod: the launcher and its
+	// monitor, libvirt, qemu and the video device.
+	virtLauncherOverhead = "200Mi"
+	// vCPUOverhead is the memory overhead of each vCPU.
+	vCPUOverhead = "8Mi"
+	// pageTableRatio is the share of the guest memory needed for its page tables.
+	pageTableRatio = 512
+	// vCPURequest is the cpu KubeVirt requests per vCPU with its default cpuAllocationRatio of 10.
+	vCPURequest = "100m"
+)
+
+// kubevirtKind is a kind of KubeVirt, whose virtual machines run in virt-launcher pods.
+// https://kubevirt.io/api-reference/main/definitions.html
+func kubevirtKind(kind string) schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: kind}
+}
+
+// virtualMachine is the part of a VirtualMachine, which kuota-calc calculates. The KubeVirt api isn't a dependency of
+// kuota-calc, so the VirtualMachine is decoded from its unstructured content.
+type virtualMachine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		Running     *bool  `json:"running,omitempty"`
+		RunStrategy string `json:"runStrategy,omitempty"`
+		Template    struct {
+			metav1.ObjectMeta `json:"metadata,omitempty"`
+			Spec              virtualMachineInstanceSpec `json:"spec"`
+		} `json:"template"`
+		DataVolumeTemplates []struct {
+			Spec struct {
+				PVC     *v1.PersistentVolumeClaimSpec `json:"pvc,omitempty"`
+				Storage *v1.PersistentVolumeClaimSpec `json:"storage,omitempty"`
+			} `json:"spec"`
+		} `json:"dataVolumeTemplates,omitempty"`
+	} `json:"spec"`
+}
+
+// virtualMachineInstance is the part of a VirtualMachineInstance, which kuota-calc calculates.
+type virtualMachineInstance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              virtualMachineInstanceSpec `json:"spec"`
+	Status            struct {
+		Phase string `json:"phase,omitempty"`
+	} `json:"status"`
+}
+
+type virtualMachineInstanceSpec struct {
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	EvictionStrategy  string `json:"evictionStrategy,omitempty"`
+	Domain            struct {
+		Resources struct {
+			Requests v1.ResourceList `json:"requests,omitempty"`
+			Limits   v1.ResourceList `json:"limits,omitempty"`
+		} `json:"resources"`
+		Memory *struct {
+			Guest *resource.Quantity `json:"guest,omitempty"`
+		} `json:"memory,omitempty"`
+		CPU *struct {
+			Cores                 int32 `json:"cores,omitempty"`
+			Sockets               int32 `json:"sockets,omitempty"`
+			Threads               int32 `json:"threads,omitempty"`
+			DedicatedCPUPlacement bool  `json:"dedicatedCpuPlacement,omitempty"`
+		} `json:"cpu,omitempty"`
+	} `json:"domain"`
+}
+
+// calculates the cpu/memory resources of the virt-launcher pod of a virtual machine and the storage of its data
+// volumes. Stopped virtual machines don't run a pod. Virtual machines, which are evicted by a live migration, run a
+// second pod on the target node during the migration.
+func virtualMachineResource(object *unstructured.Unstructured, opts Options) (*ResourceUsage, error) {
+	var vm virtualMachine
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &vm); err != nil {
+		return nil, fmt.Errorf("virtualmachine: %s: decoding: %w", object.GetName(), err)
+	}
+
+	spec := &vm.Spec.Template.Spec
+	podResources := annotatedPodResources(vm.ObjectMeta, vm.Spec.Template.ObjectMeta, virtLauncherPodSpec(spec), opts)
+
+	resourceUsage := ResourceUsage{
+		Pod: podResources,
+		Details: Details{
+			Version:           vm.APIVersion,
+			Kind:              vm.Kind,
+			Namespace:         vm.Namespace,
+			Name:              vm.Name,
+			PriorityClassName: spec.PriorityClassName,
+			Strategy:          vm.Spec.RunStrategy,
+		},
+	}
+
+	var claims []v1.PersistentVolumeClaim
+
+	for _, template := range vm.Spec.DataVolumeTemplates {
+		if claim := template.Spec.Storage; claim != nil {
+			claims = append(claims, v1.PersistentVolumeClaim{Spec: *claim})
+		} else if claim := template.Spec.PVC; claim != nil {
+			claims = append(claims, v1.PersistentVolumeClaim{Spec: *claim})
+		}
+	}
+
+	// the data volumes exist, whether the virtual machine runs or not
+	storage := volumeClaimResources(claims)
+
+	if stopped := vm.Spec.RunStrategy == "Halted" || (vm.Spec.RunStrategy == "" && (vm.Spec.Running == nil || !*vm.Spec.Running)); stopped {
+		resourceUsage.NormalResources = storage
+		resourceUsage.RolloutResources = storage
+		resourceUsage.explainf(opts, "stopped: the virtual machine doesn't run a pod, data volumes: storage %s", storage.Storage.String())
+
+		return &resourceUsage, nil
+	}
+
+	resourceUsage.Details.Replicas = 1
+	resourceUsage.Details.MaxReplicas = 1
+	resourceUsage.Details.NormalReplicas = 1
+
+	virtLauncherUsage(&resourceUsage, spec, podResources, opts)
+
+	resourceUsage.NormalResources = resourceUsage.NormalResources.Add(storage)
+	resourceUsage.RolloutResources = resourceUsage.RolloutResources.Add(storage)
+	resourceUsage.explainf(opts, "data volumes: storage %s", storage.Storage.String())
+
+	return &resourceUsage, nil
+}
+
+// calculates the cpu/memory resources of the virt-launcher pod of a virtual machine instance. Finished instances
+// don't run a pod anymore.
+func virtualMachineInstanceResource(object *unstructured.Unstructured, opts Options) (*ResourceUsage, error) {
+	var vmi virtualMachineInstance
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &vmi); err != nil {
+		return nil, fmt.Errorf("virtualmachineinstance: %s: decoding: %w", object.GetName(), err)
+	}
+
+	podResources := annotatedPodResources(vmi.ObjectMeta, vmi.ObjectMeta, virtLauncherPodSpec(&vmi.Spec), opts)
+
+	resourceUsage := ResourceUsage{
+		Pod: podResources,
+		Details: Details{
+			Version:           vmi.APIVersion,
+			Kind:              vmi.Kind,
+			Namespace:         vmi.Namespace,
+			Name:              vmi.Name,
+			PriorityClassName: vmi.Spec.PriorityClassName,
+		},
+	}
+
+	if vmi.Status.Phase == "Succeeded" || vmi.Status.Phase == "Failed" {
+		resourceUsage.explainf(opts, "phase %s: the virtual machine instance finished, its pod doesn't run anymore", vmi.Status.Phase)
+
+		return &resourceUsage, nil
+	}
+
+	resourceUsage.Details.Replicas = 1
+	resourceUsage.Details.MaxReplicas = 1
+	resourceUsage.Details.NormalReplicas = 1
+
+	virtLauncherUsage(&resourceUsage, &vmi.Spec, podResources, opts)
+
+	return &resourceUsage, nil
+}
+
+// virtLauncherUsage sets the resources of a running virt-launcher pod. A live migration runs the pod on the source and
+// on the target node.
+func virtLauncherUsage(usage *ResourceUsage, spec *virtualMachineInstanceSpec, podResources *PodResources, opts Options) {
+	migrations := int32(0)
+	if spec.EvictionStrategy == "LiveMigrate" || spec.EvictionStrategy == "LiveMigrateIfPossible" {
+		migrations = 1
+	}
+
+	usage.NormalResources = podResources.Containers
+	usage.RolloutResources = podResources.Containers.Add(podResources.MaxResources.MulInt32(migrations))
+
+	usage.explainPod(opts, podResources)
+	usage.explainf(opts, "normal = containers")
+	usage.explainf(opts, "rollout = containers + max * %d migration target, evictionStrategy %q", migrations, spec.EvictionStrategy)
+}
+
+// virtLauncherPodSpec returns the pod spec of the virt-launcher pod of a virtual machine instance. Its compute
+// container requests the memory of the guest and the overhead of KubeVirt, and the cpu of the vCPUs.
+func virtLauncherPodSpec(spec *virtualMachineInstanceSpec) *v1.PodSpec {
+	domain := &spec.Domain
+
+	var vCPUs int64 = 1
+	if cpu := domain.CPU; cpu != nil {
+		vCPUs = int64(max(cpu.Cores, 1)) * int64(max(cpu.Sockets, 1)) * int64(max(cpu.Threads, 1))
+	}
+
+	requests := domain.Resources.Requests.DeepCopy()
+	if requests == nil {
+		requests = v1.ResourceList{}
+	}
+
+	limits := domain.Resources.Limits.DeepCopy()
+	if limits == nil {
+		limits = v1.ResourceList{}
+	}
+
+	if _, ok := requests[v1.ResourceMemory]; !ok && domain.Memory != nil && domain.Memory.Guest != nil {
+		requests[v1.ResourceMemory] = *domain.Memory.Guest
+	}
+
+	if _, ok := requests[v1.ResourceCPU]; !ok {
+		if domain.CPU != nil && domain.CPU.DedicatedCPUPlacement {
+			requests[v1.ResourceCPU] = *resource.NewQuantity(vCPUs, resource.DecimalSI)
+			limits[v1.ResourceCPU] = requests[v1.ResourceCPU]
+		} else {
+			cpu := resource.MustParse(vCPURequest)
+			requests[v1.ResourceCPU] = *resource.NewMilliQuantity(cpu.MilliValue()*vCPUs, resource.DecimalSI)
+		}
+	}
+
+	overhead := virtLauncherMemoryOverhead(requests[v1.ResourceMemory], vCPUs)
+
+	for _, list := range []v1.ResourceList{requests, limits} {
+		if memory, ok := list[v1.ResourceMemory]; ok {
+			memory.Add(overhead)
+			list[v1.ResourceMemory] = memory
+		}
+	}
+
+	return &v1.PodSpec{
+		Containers: []v1.Container{{
+			Name:      "compute",
+			Resources: v1.ResourceRequirements{Requests: requests, Limits: limits},
+		}},
+		PriorityClassName: spec.PriorityClassName,
+	}
+}
+
+// virtLauncherMemoryOverhead approximates the memory KubeVirt adds to the guest memory of a virt-launcher pod.
+func virtLauncherMemoryOverhead(guest resource.Quantity, vCPUs int64) resource.Quantity {
+	overhead := resource.MustParse(virtLauncherOverhead)
+
+	perVCPU := resource.MustParse(vCPUOverhead)
+	overhead.Add(*resource.NewQuantity(perVCPU.Value()*vCPUs, resource.BinarySI))
+	overhead.Add(*resource.NewQuantity(guest.Value()/pageTableRatio, resource.BinarySI))
+
+	return overhead
+}
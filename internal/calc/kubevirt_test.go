@@ -0,0 +1,141 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var migratableVirtualMachine = `
+apiVersion: kubevirt.io/v1
+kind: VirtualMachine
+metadata:
+  name: db
+  namespace: vms
+spec:
+  running: true
+  template:
+    spec:
+      evictionStrategy: LiveMigrate
+      domain:
+        cpu:
+          cores: 2
+        memory:
+          guest: 4Gi
+        devices: {}
+  dataVolumeTemplates:
+  - metadata:
+      name: db-root
+    spec:
+      storage:
+        resources:
+          requests:
+            storage: 30Gi`
+
+var haltedVirtualMachine = `
+apiVersion: kubevirt.io/v1
+kind: VirtualMachine
+metadata:
+  name: db
+  namespace: vms
+spec:
+  runStrategy: Halted
+  template:
+    spec:
+      domain:
+        memory:
+          guest: 4Gi
+  dataVolumeTemplates:
+  - metadata:
+      name: db-root
+    spec:
+      pvc:
+        resources:
+          requests:
+            storage: 30Gi`
+
+var runningVirtualMachineInstance = `
+apiVersion: kubevirt.io/v1
+kind: VirtualMachineInstance
+metadata:
+  name: worker
+  namespace: vms
+spec:
+  domain:
+    resources:
+      requests:
+        cpu: "1"
+        memory: 1Gi
+status:
+  phase: Running`
+
+var succeededVirtualMachineInstance = `
+apiVersion: kubevirt.io/v1
+kind: VirtualMachineInstance
+metadata:
+  name: worker
+  namespace: vms
+spec:
+  domain:
+    resources:
+      requests:
+        cpu: "1"
+        memory: 1Gi
+status:
+  phase: Succeeded`
+
+func TestKubeVirt(t *testing.T) {
+	var tests = []struct {
+		name             string
+		object           string
+		normalCPUMin     resource.Quantity
+		normalMemoryMin  resource.Quantity
+		rolloutCPUMin    resource.Quantity
+		rolloutMemoryMin resource.Quantity
+		storage          resource.Quantity
+	}{
+		{
+			name:             "live migrated virtual machine",
+			object:           migratableVirtualMachine,
+			normalCPUMin:     resource.MustParse("200m"),
+			normalMemoryMin:  resource.MustParse("4320Mi"),
+			rolloutCPUMin:    resource.MustParse("400m"),
+			rolloutMemoryMin: resource.MustParse("8640Mi"),
+			storage:          resource.MustParse("30Gi"),
+		},
+		{
+			name:    "halted virtual machine",
+			object:  haltedVirtualMachine,
+			storage: resource.MustParse("30Gi"),
+		},
+		{
+			name:             "running virtual machine instance",
+			object:           runningVirtualMachineInstance,
+			normalCPUMin:     resource.MustParse("1"),
+			normalMemoryMin:  resource.MustParse("1234Mi"),
+			rolloutCPUMin:    resource.MustParse("1"),
+			rolloutMemoryMin: resource.MustParse("1234Mi"),
+		},
+		{
+			name:   "succeeded virtual machine instance",
+			object: succeededVirtualMachineInstance,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.object), Options{})
+			r.NoError(err)
+			r.Equal("vms", usage.Details.Namespace)
+
+			AssertEqualQuantities(r, test.normalCPUMin, usage.NormalResources.CPUMin, "normal cpu request value")
+			AssertEqualQuantities(r, test.normalMemoryMin, usage.NormalResources.MemoryMin, "normal memory request value")
+			AssertEqualQuantities(r, test.rolloutCPUMin, usage.RolloutResources.CPUMin, "rollout cpu request value")
+			AssertEqualQuantities(r, test.rolloutMemoryMin, usage.RolloutResources.MemoryMin, "rollout memory request value")
+			AssertEqualQuantities(r, test.storage, usage.NormalResources.Storage, "storage value")
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package calc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batchV1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// legacyKinds returns the deprecated versions of the built-in kinds, which old manifests still use. They are removed
+// from the api server, but the bundled kubernetes api still decodes them.
+func legacyKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{
+		{Group: "extensions", Version: "v1beta1", Kind: "Deployment"},
+		{Group: "apps", Version: "v1beta1", Kind: "Deployment"},
+		{Group: "apps", Version: "v1beta2", Kind: "Deployment"},
+		{Group: "batch", Version: "v1beta1", Kind: "CronJob"},
+	}
+}
+
+// legacyResource calculates a deprecated version of a built-in kind like its current version.
+func legacyResource(object runtime.Object, opts Options) (*ResourceUsage, error) {
+	converted, err := convertLegacy(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage *ResourceUsage
+
+	switch obj := converted.(type) {
+	case *appsv1.Deployment:
+		usage, err = deployment(*obj, opts)
+	case *batchV1.CronJob:
+		usage, err = cronjob(*obj, opts)
+	default:
+		return nil, ErrResourceNotSupported
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	usage.explainf(opts, "converted from the deprecated %s", object.GetObjectKind().GroupVersionKind().GroupVersion())
+
+	return usage, nil
+}
+
+// convertLegacy converts a deprecated version of a built-in kind into the current one. The apiVersion of the object is kept, so the report shows the version of the manifest. Other objects are returned
+// unchanged.
+func convertLegacy(object runtime.Object) (runtime.Object, error) {
+	switch obj := object.(type) {
+	case *extensionsv1beta1.Deployment:
+		deployment := &appsv1.Deployment{}
+		if err := convertFields(obj, deployment); err != nil {
+			return nil, err
+		}
+
+		// extensions/v1beta1 defaulted the rolling update to absolute values instead of 25%
+		// https://github.com/kubernetes/kubernetes/blob/release-1.15/pkg/apis/extensions/v1beta1/defaults.go
+		if deployment.Spec.Strategy.Type == "" || deployment.Spec.Strategy.Type == appsv1.RollingUpdateDeploymentStrategyType {
+			if deployment.Spec.Strategy.RollingUpdate == nil {
+				deployment.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{}
+			}
+
+			rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+			one := intstr.FromInt32(1)
+
+			if rollingUpdate.MaxSurge == nil {
+				rollingUpdate.MaxSurge = &one
+			}
+
+			if rollingUpdate.MaxUnavailable == nil {
+				rollingUpdate.MaxUnavailable = &one
+			}
+		}
+
+		return deployment, nil
+	case *appsv1beta1.Deployment, *appsv1beta2.Deployment:
+		deployment := &appsv1.Deployment{}
+
+		return deployment, convertFields(obj, deployment)
+	case *batchv1beta1.CronJob:
+		cronJob := &batchV1.CronJob{}
+
+		return cronJob, convertFields(obj, cronJob)
+	default:
+		return object, nil
+	}
+}
+
+// convertFields copies the fields of a legacy object into the current version of its kind by their json names. The
+// fields calculated by kuota-calc kept their names across the versions, removed fields are dropped.
+func convertFields(legacy, current runtime.Object) error {
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		return fmt.Errorf("converting %s: %w", legacy.GetObjectKind().GroupVersionKind(), err)
+	}
+
+	if err := json.Unmarshal(data, current); err != nil {
+		return fmt.Errorf("converting %s: %w", legacy.GetObjectKind().GroupVersionKind(), err)
+	}
+
+	return nil
+}
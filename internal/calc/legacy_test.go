@@ -0,0 +1,66 @@
+package calc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestLegacyVersions(t *testing.T) {
+	var tests = []struct {
+		name       string
+		object     string
+		apiVersion string
+		cpuMin     resource.Quantity
+		memoryMin  resource.Quantity
+	}{
+		{
+			name:       "extensions/v1beta1 deployment defaults to absolute values",
+			object:     deploymentWithoutStrategy,
+			apiVersion: "extensions/v1beta1",
+			cpuMin:     resource.MustParse("2750m"),
+			memoryMin:  resource.MustParse("22Gi"),
+		},
+		{
+			name:       "apps/v1beta1 deployment",
+			object:     deploymentWithoutStrategy,
+			apiVersion: "apps/v1beta1",
+			cpuMin:     resource.MustParse("3250m"),
+			memoryMin:  resource.MustParse("26Gi"),
+		},
+		{
+			name:       "apps/v1beta2 deployment",
+			object:     deploymentWithoutStrategy,
+			apiVersion: "apps/v1beta2",
+			cpuMin:     resource.MustParse("3250m"),
+			memoryMin:  resource.MustParse("26Gi"),
+		},
+		{
+			name:       "batch/v1beta1 cronjob",
+			object:     normalCronJob,
+			apiVersion: "batch/v1beta1",
+			cpuMin:     resource.MustParse("250m"),
+			memoryMin:  resource.MustParse("2Gi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			current := strings.SplitN(strings.SplitN(test.object, "apiVersion: ", 2)[1], "\n", 2)[0]
+			legacy := strings.Replace(test.object, "apiVersion: "+current, "apiVersion: "+test.apiVersion, 1)
+
+			usage, err := ResourceQuotaFromYaml([]byte(legacy), Options{Explain: true})
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			r.Equal(test.apiVersion, usage.Details.Version)
+			r.Contains(usage.Explanation, "converted from the deprecated "+test.apiVersion)
+			AssertEqualQuantities(r, test.cpuMin, usage.RolloutResources.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.memoryMin, usage.RolloutResources.MemoryMin, "memory request value")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package calc
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// observedRange is the smallest and largest request and limit of a resource, observed in the containers.
+type observedRange struct {
+	minRequest, maxRequest *resource.Quantity
+	minLimit, maxLimit     *resource.Quantity
+}
+
+// observe adds the request and limit of a container, zero quantities aren't set by the container. Like the api server
+// does, a container which only sets the limit requests the same.
+func (o *observedRange) observe(request, limit resource.Quantity) {
+	if request.IsZero() {
+		request = limit
+	}
+
+	if !request.IsZero() {
+		o.minRequest = minObserved(o.minRequest, request)
+		o.maxRequest = maxObserved(o.maxRequest, request)
+	}
+
+	if !limit.IsZero() {
+		o.minLimit = minObserved(o.minLimit, limit)
+		o.maxLimit = maxObserved(o.maxLimit, limit)
+	}
+}
+
+func minObserved(observed *resource.Quantity, q resource.Quantity) *resource.Quantity {
+	if observed == nil || q.Cmp(*observed) < 0 {
+		return &q
+	}
+
+	return observed
+}
+
+func maxObserved(observed *resource.Quantity, q resource.Quantity) *resource.Quantity {
+	if observed == nil || q.Cmp(*observed) > 0 {
+		return &q
+	}
+
+	return observed
+}
+
+// LimitRange returns a LimitRange for the containers of the given resources, which all of them satisfy. The smallest
+// request is the min and the defaultRequest, the largest limit or request the max and the smallest limit the default
+// limit. Resources no container sets are left out.
+func LimitRange(namespace, name string, usage []*ResourceUsage) v1.LimitRange {
+	observed := map[v1.ResourceName]*observedRange{
+		v1.ResourceCPU:    {},
+		v1.ResourceMemory: {},
+	}
+
+	for _, u := range usage {
+		// e.g. persistent volume claims have no pod
+		if u.Pod == nil {
+			continue
+		}
+
+		for _, container := range u.Pod.ContainerResources {
+			observed[v1.ResourceCPU].observe(container.CPUMin, container.CPUMax)
+			observed[v1.ResourceMemory].observe(container.MemoryMin, container.MemoryMax)
+		}
+	}
+
+	item := v1.LimitRangeItem{
+		Type:           v1.LimitTypeContainer,
+		Min:            v1.ResourceList{},
+		Max:            v1.ResourceList{},
+		Default:        v1.ResourceList{},
+		DefaultRequest: v1.ResourceList{},
+	}
+
+	for resourceName, o := range observed {
+		if o.minRequest != nil {
+			item.Min[resourceName] = *o.minRequest
+			item.DefaultRequest[resourceName] = *o.minRequest
+		}
+
+		// a request without a limit might be larger than all limits
+		switch {
+		case o.maxLimit != nil:
+			item.Max[resourceName] = *maxObserved(o.maxRequest, *o.maxLimit)
+		case o.maxRequest != nil:
+			item.Max[resourceName] = *o.maxRequest
+		}
+
+		// each limit is at least its request, so the default limit is never below the default request
+		if o.minLimit != nil {
+			item.Default[resourceName] = *o.minLimit
+		}
+	}
+
+	return v1.LimitRange{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "LimitRange",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1.LimitRangeSpec{
+			Limits: []v1.LimitRangeItem{item},
+		},
+	}
+}
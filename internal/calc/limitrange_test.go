@@ -0,0 +1,68 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var limitRangePod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: shop
+spec:
+  initContainers:
+  - name: migrate
+    image: migrate
+    resources:
+      limits:
+        cpu: 50m
+  containers:
+  - name: web
+    image: web
+    resources:
+      requests:
+        cpu: 250m
+        memory: 256Mi
+      limits:
+        cpu: "1"
+        memory: 512Mi
+  - name: cache
+    image: cache
+    resources:
+      requests:
+        cpu: 100m
+        memory: 2Gi`
+
+func TestLimitRange(t *testing.T) {
+	r := require.New(t)
+
+	pod, err := ResourceQuotaFromYaml([]byte(limitRangePod), Options{})
+	r.NoError(err)
+
+	claim, err := ResourceQuotaFromYaml([]byte(persistentVolumeClaimManifest), Options{})
+	r.NoError(err)
+
+	limitRange := LimitRange("shop", "container-limits", []*ResourceUsage{pod, claim})
+	r.Equal("LimitRange", limitRange.Kind)
+	r.Equal("shop", limitRange.Namespace)
+	r.Len(limitRange.Spec.Limits, 1)
+
+	item := limitRange.Spec.Limits[0]
+	r.Equal(v1.LimitTypeContainer, item.Type)
+
+	// the init container only sets the limit, so it requests the same
+	AssertEqualQuantities(r, resource.MustParse("50m"), item.Min[v1.ResourceCPU], "min cpu")
+	AssertEqualQuantities(r, resource.MustParse("50m"), item.DefaultRequest[v1.ResourceCPU], "default cpu request")
+	AssertEqualQuantities(r, resource.MustParse("50m"), item.Default[v1.ResourceCPU], "default cpu limit")
+	AssertEqualQuantities(r, resource.MustParse("1"), item.Max[v1.ResourceCPU], "max cpu")
+
+	// the request of the cache exceeds the limit of the web container
+	AssertEqualQuantities(r, resource.MustParse("256Mi"), item.Min[v1.ResourceMemory], "min memory")
+	AssertEqualQuantities(r, resource.MustParse("512Mi"), item.Default[v1.ResourceMemory], "default memory limit")
+	AssertEqualQuantities(r, resource.MustParse("2Gi"), item.Max[v1.ResourceMemory], "max memory")
+}
@@ -0,0 +1,176 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MeshIstio selects the istio service mesh.
+	MeshIstio = "istio"
+	// MeshLinkerd selects the linkerd service mesh.
+	MeshLinkerd = "linkerd"
+)
+
+// Mesh is a service mesh, which injects a proxy container into the pods at admission. The proxy isn't part of the
+// manifests, but counts against the quota like any other container.
+type Mesh struct {
+	Name string
+	// Proxy are the resources of the injected proxy container.
+	Proxy Resources
+	// InjectedNamespaces records for each namespace of the input, whether it enables the injection. If the input
+	// doesn't contain namespaces, the pods of all namespaces are injected.
+	InjectedNamespaces map[string]bool
+}
+
+// meshConfig are the labels and annotations a mesh is configured with and the default resources of its proxy.
+type meshConfig struct {
+	// inject is the label or annotation of a pod, which enables or disables the injection.
+	inject string
+	// enabled and disabled are the values of inject.
+	enabled, disabled []string
+	// proxyAnnotations are the annotations of a pod, which override the resources of the proxy, by their name in a
+	// ResourceQuota.
+	proxyAnnotations map[v1.ResourceName]string
+	proxy            Resources
+}
+
+// meshConfigs returns the configuration of each supported mesh.
+func meshConfigs() map[string]meshConfig {
+	return map[string]meshConfig{
+		// https://istio.io/latest/docs/reference/config/annotations/
+		MeshIstio: {
+			inject:   "sidecar.istio.io/inject",
+			enabled:  []string{"true"},
+			disabled: []string{"false"},
+			proxyAnnotations: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:    "sidecar.istio.io/proxyCPU",
+				v1.ResourceLimitsCPU:      "sidecar.istio.io/proxyCPULimit",
+				v1.ResourceRequestsMemory: "sidecar.istio.io/proxyMemory",
+				v1.ResourceLimitsMemory:   "sidecar.istio.io/proxyMemoryLimit",
+			},
+			proxy: Resources{
+				CPUMin:    resource.MustParse("100m"),
+				CPUMax:    resource.MustParse("2"),
+				MemoryMin: resource.MustParse("128Mi"),
+				MemoryMax: resource.MustParse("1Gi"),
+			},
+		},
+		// https://linkerd.io/2/reference/proxy-configuration/
+		MeshLinkerd: {
+			inject:   "linkerd.io/inject",
+			enabled:  []string{"enabled", "ingress"},
+			disabled: []string{"disabled"},
+			proxyAnnotations: map[v1.ResourceName]string{
+				v1.ResourceRequestsCPU:    "config.linkerd.io/proxy-cpu-request",
+				v1.ResourceLimitsCPU:      "config.linkerd.io/proxy-cpu-limit",
+				v1.ResourceRequestsMemory: "config.linkerd.io/proxy-memory-request",
+				v1.ResourceLimitsMemory:   "config.linkerd.io/proxy-memory-limit",
+			},
+			proxy: Resources{
+				CPUMin:    resource.MustParse("100m"),
+				MemoryMin: resource.MustParse("20Mi"),
+				MemoryMax: resource.MustParse("250Mi"),
+			},
+		},
+	}
+}
+
+// NewMesh returns the mesh of the given name with the default resources of its proxy. proxyResources overrides them
+// in the form requests.cpu=100m,limits.memory=1Gi, if not empty.
+func NewMesh(name, proxyResources string) (*Mesh, error) {
+	config, ok := meshConfigs()[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown mesh %q, supported are %s and %s", name, MeshIstio, MeshLinkerd)
+	}
+
+	m := &Mesh{Name: name, Proxy: config.proxy}
+
+	if proxyResources != "" {
+		overrides, err := parseQuotaResources(proxyResources, "mesh proxy resources")
+		if err != nil {
+			return nil, err
+		}
+
+		for resourceName, quantity := range overrides {
+			m.Proxy.setQuotaResource(resourceName, quantity)
+		}
+	}
+
+	return m, nil
+}
+
+// AddNamespace records whether the namespace enables the injection: istio injects the pods of namespaces labeled
+// istio-injection=enabled or with an istio.io/rev label, linkerd the ones of namespaces annotated linkerd.io/inject.
+func (m *Mesh) AddNamespace(namespace *v1.Namespace) {
+	if m.InjectedNamespaces == nil {
+		m.InjectedNamespaces = map[string]bool{}
+	}
+
+	var injected bool
+
+	switch m.Name {
+	case MeshIstio:
+		_, revision := namespace.Labels["istio.io/rev"]
+		injected = namespace.Labels["istio-injection"] == "enabled" || revision
+	case MeshLinkerd:
+		injected = namespace.Annotations["linkerd.io/inject"] == "enabled"
+	}
+
+	m.InjectedNamespaces[namespace.Name] = injected
+}
+
+// proxyResources returns the resources of the proxy injected into a pod of the namespace, or no resources if the pod
+// isn't injected. The labels and annotations of the pod enable or disable the injection and override the resources
+// of the proxy. Invalid annotations are ignored with a warning.
+func (m *Mesh) proxyResources(namespace string, pod metav1.ObjectMeta) Resources {
+	if m == nil {
+		return Resources{}
+	}
+
+	config := meshConfigs()[m.Name]
+	injected := m.InjectedNamespaces == nil || m.InjectedNamespaces[namespace]
+
+	for _, values := range []map[string]string{pod.Labels, pod.Annotations} {
+		value, ok := values[config.inject]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case slices.Contains(config.enabled, value):
+			injected = true
+		case slices.Contains(config.disabled, value):
+			injected = false
+		}
+	}
+
+	if !injected {
+		return Resources{}
+	}
+
+	proxy := m.Proxy
+
+	for resourceName, annotation := range config.proxyAnnotations {
+		value, ok := pod.Annotations[annotation]
+		if !ok {
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil || quantity.Sign() < 0 {
+			log.Warn().Msgf("ignoring invalid annotation %s: %q", annotation, value)
+
+			continue
+		}
+
+		proxy.setQuotaResource(resourceName, quantity)
+	}
+
+	return proxy
+}
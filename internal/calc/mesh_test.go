@@ -0,0 +1,111 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewMesh(t *testing.T) {
+	r := require.New(t)
+
+	mesh, err := NewMesh(MeshIstio, "")
+	r.NoError(err)
+	AssertEqualQuantities(r, resource.MustParse("100m"), mesh.Proxy.CPUMin, "cpu request value")
+	AssertEqualQuantities(r, resource.MustParse("1Gi"), mesh.Proxy.MemoryMax, "memory limit value")
+
+	mesh, err = NewMesh(MeshLinkerd, "cpu=50m,limits.cpu=1")
+	r.NoError(err)
+	AssertEqualQuantities(r, resource.MustParse("50m"), mesh.Proxy.CPUMin, "cpu request value")
+	AssertEqualQuantities(r, resource.MustParse("1"), mesh.Proxy.CPUMax, "cpu limit value")
+	AssertEqualQuantities(r, resource.MustParse("20Mi"), mesh.Proxy.MemoryMin, "memory request value")
+
+	_, err = NewMesh("consul", "")
+	r.Error(err)
+
+	_, err = NewMesh(MeshIstio, "gpu=1")
+	r.Error(err)
+}
+
+func TestMeshProxyResources(t *testing.T) {
+	namespace := func(name string, labels, annotations map[string]string) *v1.Namespace {
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations}}
+	}
+
+	tests := []struct {
+		name       string
+		mesh       string
+		namespaces []*v1.Namespace
+		pod        metav1.ObjectMeta
+		cpu        resource.Quantity
+	}{
+		{
+			name: "all namespaces without namespaces in the input",
+			mesh: MeshIstio,
+			cpu:  resource.MustParse("100m"),
+		},
+		{
+			name: "pod opts out",
+			mesh: MeshIstio,
+			pod:  metav1.ObjectMeta{Labels: map[string]string{"sidecar.istio.io/inject": "false"}},
+		},
+		{
+			name:       "namespace without injection",
+			mesh:       MeshIstio,
+			namespaces: []*v1.Namespace{namespace("default", nil, nil)},
+		},
+		{
+			name:       "namespace with revision",
+			mesh:       MeshIstio,
+			namespaces: []*v1.Namespace{namespace("default", map[string]string{"istio.io/rev": "canary"}, nil)},
+			cpu:        resource.MustParse("100m"),
+		},
+		{
+			name:       "pod opts in",
+			mesh:       MeshLinkerd,
+			namespaces: []*v1.Namespace{namespace("default", nil, nil)},
+			pod:        metav1.ObjectMeta{Annotations: map[string]string{"linkerd.io/inject": "enabled"}},
+			cpu:        resource.MustParse("100m"),
+		},
+		{
+			name:       "pod overrides the proxy resources",
+			mesh:       MeshLinkerd,
+			namespaces: []*v1.Namespace{namespace("default", nil, map[string]string{"linkerd.io/inject": "enabled"})},
+			pod:        metav1.ObjectMeta{Annotations: map[string]string{"config.linkerd.io/proxy-cpu-request": "300m"}},
+			cpu:        resource.MustParse("300m"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			mesh, err := NewMesh(test.mesh, "")
+			r.NoError(err)
+
+			for _, ns := range test.namespaces {
+				mesh.AddNamespace(ns)
+			}
+
+			AssertEqualQuantities(r, test.cpu, mesh.proxyResources("default", test.pod).CPUMin, "cpu request value")
+		})
+	}
+}
+
+func TestMeshDeployment(t *testing.T) {
+	r := require.New(t)
+
+	mesh, err := NewMesh(MeshIstio, "")
+	r.NoError(err)
+
+	usage, err := ResourceQuotaFromYaml([]byte(normalDeployment), Options{Mesh: mesh})
+	r.NoError(err)
+
+	// 10 replicas with 250m + 100m of the proxy
+	AssertEqualQuantities(r, resource.MustParse("3500m"), usage.NormalResources.CPUMin, "cpu request value")
+	// 13 pods with 2Gi + 128Mi of the proxy during the rollout
+	AssertEqualQuantities(r, resource.MustParse("28288Mi"), usage.RolloutResources.MemoryMin, "memory request value")
+}
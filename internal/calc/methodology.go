@@ -0,0 +1,358 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Methodology describes how the resources of a kind are calculated with the given options. The formulas use the
+// same terms as the explanations of the single workloads.
+type Methodology struct {
+	Kind        string
+	Formulas    []string
+	Assumptions []string
+}
+
+// methodologies returns the methodology of each built-in kind, in the order of SupportedKinds.
+func methodologies(opts Options) []Methodology {
+	defaults := opts.strategyDefaults()
+
+	return []Methodology{
+		{
+			Kind: "DeploymentConfig",
+			Formulas: []string{
+				"normal = containers * replicas",
+				"Rolling: rollout = containers * (replicas - maxUnavailable + terminating) + max * (maxSurge + maxUnavailable)" +
+					" + strategy resources",
+				"Recreate: rollout = max * replicas + strategy resources",
+			},
+			Assumptions: append([]string{
+				rollingUpdateAssumption(defaults.DeploymentConfig),
+				"the strategy resources are needed by the deployer pod during the whole rollout",
+				"normal = 0 for test deployments (spec.test), as they are scaled down after the test",
+			}, opts.workloadAssumptions()...),
+		},
+		{
+			Kind: "Deployment",
+			Formulas: []string{
+				"normal = containers * replicas",
+				"RollingUpdate: rollout = containers * (replicas - maxUnavailable + terminating) + max * (maxSurge + maxUnavailable)",
+				"Recreate: rollout = max * replicas",
+			},
+			Assumptions: append([]string{rollingUpdateAssumption(defaults.Deployment)}, opts.workloadAssumptions()...),
+		},
+		{
+			Kind: "StatefulSet",
+			Formulas: []string{
+				"normal = containers * replicas",
+				"RollingUpdate: rollout = containers * (replicas - maxUnavailable) + max * maxUnavailable",
+				"OnDelete: rollout = max * replicas",
+				"storage = storage of the volumeClaimTemplates * replicas, for normal and rollout",
+			},
+			Assumptions: append([]string{
+				opts.statefulSetMaxUnavailableAssumption(defaults.StatefulSet),
+				"OnDelete assumes all pods are deleted at once",
+				"the claims of the volumeClaimTemplates are kept, when the statefulset is scaled down or rolled out",
+			}, opts.replicaAssumptions()...),
+		},
+		{
+			Kind: "DaemonSet",
+			Formulas: []string{
+				"normal = containers * pods",
+				"rollout = containers * (pods - 1) + max",
+			},
+			Assumptions: []string{
+				"pods = the nodes the daemonset can be scheduled on, if the nodes are part of the input, otherwise 1",
+				"one pod is updated at a time",
+			},
+		},
+		{
+			Kind: "CronJob",
+			Formulas: []string{
+				"normal = containers * concurrent runs",
+				"rollout = (max + retry overlap) * concurrent runs",
+			},
+			Assumptions: []string{
+				"concurrent runs are the overlapping runs of the schedule within activeDeadlineSeconds + startingDeadlineSeconds, " +
+					"if the concurrencyPolicy is Allow and activeDeadlineSeconds is set, otherwise 1",
+				"suspended cronjobs start no jobs, normal = rollout = 0",
+				opts.jobRetryAssumption(),
+			},
+		},
+		{
+			Kind: "Job",
+			Formulas: []string{
+				"normal = containers",
+				"rollout = max + retry overlap",
+			},
+			Assumptions: []string{opts.jobRetryAssumption()},
+		},
+		{
+			Kind: "Pod",
+			Formulas: []string{
+				"normal = containers",
+				"rollout = max",
+			},
+			Assumptions: []string{"bare pods aren't rolled out, their init containers run once"},
+		},
+		{
+			Kind: "ReplicaSet",
+			Formulas: []string{
+				"normal = containers * replicas",
+				"rollout = containers * replicas",
+			},
+			Assumptions: append([]string{
+				"bare replicasets aren't rolled out, replicasets of a deployment in the input are calculated by the deployment",
+			}, opts.replicaAssumptions()...),
+		},
+		{
+			Kind: "Rollout",
+			Formulas: []string{
+				"normal = containers * replicas",
+				"Canary: rollout = containers * (replicas - maxUnavailable) + max * (maxSurge + maxUnavailable)",
+				"Canary with traffic routing: rollout = containers * stable + max * canary, at the step with the most pods",
+				"BlueGreen: rollout = containers * replicas + max * replicas",
+			},
+			Assumptions: append([]string{
+				"maxSurge and maxUnavailable of a canary default to 25%",
+				"with traffic routing, the stable pods keep running until the canary is promoted to all replicas, " +
+					"unless dynamicStableScale scales them down to the remaining weight",
+				"the blue-green preview is scaled up to all replicas before the active pods are scaled down",
+				"rollouts with a workloadRef aren't supported",
+			}, opts.replicaAssumptions()...),
+		},
+		{
+			Kind: "PersistentVolumeClaim",
+			Formulas: []string{
+				"normal = rollout = requested storage",
+			},
+			Assumptions: []string{
+				"claims created from the volumeClaimTemplates of a statefulset in the input are calculated by the statefulset",
+			},
+		},
+		{
+			Kind: "BuildConfig",
+			Formulas: []string{
+				"normal = containers",
+				"rollout = max",
+			},
+			Assumptions: []string{
+				"containers = the resources of the build, its pod runs the build containers one after another",
+				"a single build runs at a time, with the runPolicy Parallel as well",
+			},
+		},
+		{
+			Kind: "Build",
+			Formulas: []string{
+				"normal = containers",
+				"rollout = max",
+			},
+			Assumptions: []string{
+				"containers = the resources of the build, its pod runs the build containers one after another",
+				"finished builds (Complete, Failed, Error, Cancelled) need no resources, " +
+					"builds of a buildconfig in the input are calculated by the buildconfig",
+			},
+		},
+		prometheusOperatorMethodology("Prometheus", "pods = replicas * shards, each shard is rolled out on its own",
+			"the thanos sidecar is added with spec.thanos"),
+		prometheusOperatorMethodology("Alertmanager", "pods = replicas",
+			fmt.Sprintf("the alertmanager container requests %s memory, if it doesn't request memory itself", alertmanagerMemoryRequest)),
+		prometheusOperatorMethodology("ThanosRuler", "pods = replicas"),
+		{
+			Kind: "Elasticsearch",
+			Formulas: []string{
+				"normal = sum of containers * count of each node set",
+				"rollout = sum of containers * (count - maxUnavailable) + max * maxUnavailable of each node set",
+				"storage = storage of the volumeClaimTemplates * count of each node set, for normal and rollout",
+			},
+			Assumptions: []string{
+				"maxUnavailable of the change budget defaults to 1 and is assumed for each node set, a negative one restarts all nodes at once",
+				fmt.Sprintf("the elasticsearch container requests and is limited to %s memory, if it sets no resources", elasticsearchMemory),
+				fmt.Sprintf("node sets without volumeClaimTemplates claim %s of data storage", elasticsearchDataStorage),
+			},
+		},
+		{
+			Kind: "VirtualMachine",
+			Formulas: []string{
+				"normal = containers + storage of the dataVolumeTemplates",
+				"rollout = containers + max * migration target + storage of the dataVolumeTemplates",
+			},
+			Assumptions: append([]string{
+				"stopped virtual machines (runStrategy Halted or running false) only need the storage of their data volumes",
+			}, virtLauncherAssumptions()...),
+		},
+		{
+			Kind: "VirtualMachineInstance",
+			Formulas: []string{
+				"normal = containers",
+				"rollout = containers + max * migration target",
+			},
+			Assumptions: append([]string{
+				"finished instances (Succeeded, Failed) need no resources, " +
+					"instances of a virtual machine in the input are calculated by the virtual machine",
+			}, virtLauncherAssumptions()...),
+		},
+	}
+}
+
+// prometheusOperatorMethodology describes a kind of the prometheus operator, whose pods are run by statefulsets.
+func prometheusOperatorMethodology(kind string, assumptions ...string) Methodology {
+	return Methodology{
+		Kind: kind,
+		Formulas: []string{
+			"normal = containers * pods",
+			"rollout = containers * (pods - shards) + max * shards",
+			"storage = storage of the volumeClaimTemplate * pods, for normal and rollout",
+		},
+		Assumptions: append([]string{
+			"replicas and shards default to 1, one pod of each shard is updated at a time",
+			fmt.Sprintf("the config-reloader sidecar requests and is limited to %s cpu and %s memory", configReloaderCPU, configReloaderMemory),
+			"spec.containers replace the resources of the generated containers of the same name, other containers are added",
+		}, assumptions...),
+	}
+}
+
+// virtLauncherAssumptions describes how the virt-launcher pod of a virtual machine is calculated.
+func virtLauncherAssumptions() []string {
+	return []string{
+		"containers = the compute container requesting the resources of the domain, or the guest memory without a memory request",
+		fmt.Sprintf("the memory overhead of kubevirt is approximated by %s + %s per vCPU + guest memory / %d for the page tables",
+			virtLauncherOverhead, vCPUOverhead, pageTableRatio),
+		fmt.Sprintf("without a cpu request, each vCPU requests %s, or a whole cpu with dedicatedCpuPlacement", vCPURequest),
+		"migration target = 1 with the evictionStrategy LiveMigrate or LiveMigrateIfPossible, otherwise 0",
+	}
+}
+
+// KindMethodology returns the methodology of a built-in kind with the given options. The kind is case-insensitive.
+func KindMethodology(kind string, opts Options) (Methodology, error) {
+	all := Methodologies(opts)
+
+	for _, m := range all {
+		if strings.EqualFold(m.Kind, kind) {
+			return m, nil
+		}
+	}
+
+	kinds := make([]string, 0, len(all))
+
+	for _, m := range all {
+		kinds = append(kinds, m.Kind)
+	}
+
+	return Methodology{}, fmt.Errorf("unknown kind %q, supported are %s: %w", kind, strings.Join(kinds, ", "), ErrResourceNotSupported)
+}
+
+// Methodologies returns the methodologies of all built-in kinds with the given options. The pod formulas, which all
+// kinds build upon, are part of the assumptions of each kind.
+func Methodologies(opts Options) []Methodology {
+	all := methodologies(opts)
+
+	for i := range all {
+		all[i].Assumptions = slices.Concat(opts.podAssumptions(), all[i].Assumptions)
+	}
+
+	return all
+}
+
+// podAssumptions describes how the resources of a single pod are calculated.
+func (o Options) podAssumptions() []string {
+	assumptions := []string{
+		"containers = sum of the containers of a pod",
+		"max = max(containers, sum of the init containers), as a pod starting its init containers needs the larger of both",
+		fmt.Sprintf("the annotations %s and %s add extra resources to each pod of a workload", AnnotationExtraCPU, AnnotationExtraMemory),
+	}
+
+	if o.KubernetesVersion.nativeSidecars() {
+		assumptions = append(assumptions,
+			"init containers with the restartPolicy Always are native sidecars, which count towards the containers and the init containers")
+	} else {
+		assumptions = append(assumptions,
+			fmt.Sprintf("kubernetes %s has no native sidecars, all init containers are calculated as regular ones", o.KubernetesVersion))
+	}
+
+	if o.Mesh != nil {
+		assumptions = append(assumptions, fmt.Sprintf("the %s proxy adds %s to each injected pod", o.Mesh.Name, o.Mesh.Proxy))
+	}
+
+	if o.Injections != nil {
+		assumptions = append(assumptions,
+			fmt.Sprintf("the containers of the %d injection rules are added to the pods they match", len(o.Injections.Rules)))
+	}
+
+	if o.EmptyDirEphemeralStorage {
+		assumptions = append(assumptions, "the sizeLimit of emptyDir volumes counts towards the ephemeral storage of each pod")
+	}
+
+	return assumptions
+}
+
+// workloadAssumptions describes the replicas and the terminating pods of the workloads with a rolling update.
+func (o Options) workloadAssumptions() []string {
+	terminating := fmt.Sprintf("terminating = ceil((maxSurge + maxUnavailable) * %s), the pods scaled down by a step still terminating",
+		strconv.FormatFloat(o.TerminationOverlap, 'f', -1, 64))
+	if o.TerminationOverlapByGracePeriod {
+		terminating = "terminating = maxSurge + maxUnavailable, if the pods have a terminationGracePeriodSeconds, otherwise 0"
+	}
+
+	return append([]string{terminating}, o.replicaAssumptions()...)
+}
+
+// replicaAssumptions describes where the replicas of the workloads come from.
+func (o Options) replicaAssumptions() []string {
+	assumed, _ := o.replicas(nil)
+
+	return []string{
+		fmt.Sprintf("workloads without spec.replicas are assumed to run %d replicas, the annotation %s overrides the replicas",
+			assumed, AnnotationReplicas),
+		fmt.Sprintf("autoscaled workloads use the %s replicas of their hpa for normal and the %s replicas for rollout",
+			hpaReplicasOrSpec(o.HPANormalReplicas), hpaReplicasOrSpec(o.HPAPeakReplicas)),
+	}
+}
+
+func (o Options) statefulSetMaxUnavailableAssumption(defaults RollingUpdateDefaults) string {
+	if !o.KubernetesVersion.statefulSetMaxUnavailable() {
+		return fmt.Sprintf("kubernetes %s updates one pod at a time, maxUnavailable is ignored, statefulsets never surge",
+			o.KubernetesVersion)
+	}
+
+	return fmt.Sprintf("maxUnavailable defaults to %s and is rounded up, statefulsets never surge", defaults.MaxUnavailable.String())
+}
+
+func (o Options) jobRetryAssumption() string {
+	if o.JobRetryOverlap {
+		return "retry overlap = containers, if the backoffLimit allows retries and the podReplacementPolicy isn't Failed"
+	}
+
+	return "retry overlap = 0, failed pods are assumed to be terminated before their retry starts"
+}
+
+// rollingUpdateAssumption describes the defaults of a rolling update and how they are rounded.
+func rollingUpdateAssumption(defaults RollingUpdateDefaults) string {
+	assumption := fmt.Sprintf("maxSurge defaults to %s and is rounded up, maxUnavailable defaults to %s and is rounded down",
+		intOrStringOrZero(defaults.MaxSurge), intOrStringOrZero(defaults.MaxUnavailable))
+	if defaults.Paired {
+		assumption += ", a missing one defaults to 0 if the other one is set to more than 0"
+	}
+
+	return assumption
+}
+
+func intOrStringOrZero(value intstr.IntOrString) string {
+	if value.Type == intstr.String && value.StrVal == "" {
+		return "0"
+	}
+
+	return value.String()
+}
+
+func hpaReplicasOrSpec(replicas HPAReplicas) HPAReplicas {
+	if replicas == "" {
+		return HPASpecReplicas
+	}
+
+	return replicas
+}
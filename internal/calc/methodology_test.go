@@ -0,0 +1,92 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestKindMethodology(t *testing.T) {
+	var assumedReplicas int32 = 2
+
+	tests := []struct {
+		name        string
+		kind        string
+		opts        Options
+		assumptions []string
+		err         bool
+	}{
+		{
+			name: "deployment with defaults",
+			kind: "deployment",
+			assumptions: []string{
+				"maxSurge defaults to 25% and is rounded up, maxUnavailable defaults to 25% and is rounded down",
+				"terminating = ceil((maxSurge + maxUnavailable) * 0), the pods scaled down by a step still terminating",
+				"workloads without spec.replicas are assumed to run 1 replicas, the annotation kuota-calc.io/replicas overrides the replicas",
+				"autoscaled workloads use the spec replicas of their hpa for normal and the spec replicas for rollout",
+			},
+		},
+		{
+			name: "deployment with options",
+			kind: "Deployment",
+			opts: Options{
+				TerminationOverlap: 0.5,
+				AssumedReplicas:    &assumedReplicas,
+				StrategyDefaults: &StrategyDefaults{
+					Deployment: RollingUpdateDefaults{MaxSurge: intstr.FromInt32(1)},
+				},
+				HPAPeakReplicas: HPAMaxReplicas,
+			},
+			assumptions: []string{
+				"maxSurge defaults to 1 and is rounded up, maxUnavailable defaults to 0 and is rounded down",
+				"terminating = ceil((maxSurge + maxUnavailable) * 0.5), the pods scaled down by a step still terminating",
+				"workloads without spec.replicas are assumed to run 2 replicas, the annotation kuota-calc.io/replicas overrides the replicas",
+				"autoscaled workloads use the spec replicas of their hpa for normal and the max replicas for rollout",
+			},
+		},
+		{
+			name:        "job with retries",
+			kind:        "job",
+			opts:        Options{JobRetryOverlap: true},
+			assumptions: []string{"retry overlap = containers, if the backoffLimit allows retries and the podReplacementPolicy isn't Failed"},
+		},
+		{
+			name: "unknown kind",
+			kind: "ReplicationController",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			methodology, err := KindMethodology(test.kind, test.opts)
+			if test.err {
+				r.ErrorIs(err, ErrResourceNotSupported)
+
+				return
+			}
+
+			r.NoError(err)
+			r.NotEmpty(methodology.Formulas)
+			r.Equal(test.assumptions, methodology.Assumptions[len(methodology.Assumptions)-len(test.assumptions):])
+		})
+	}
+}
+
+func TestMethodologiesCoverBuiltinKinds(t *testing.T) {
+	r := require.New(t)
+
+	var kinds []string
+
+	for _, m := range Methodologies(Options{}) {
+		kinds = append(kinds, m.Kind)
+		r.Contains(m.Assumptions, "containers = sum of the containers of a pod")
+	}
+
+	r.Equal([]string{"DeploymentConfig", "Deployment", "StatefulSet", "DaemonSet", "CronJob", "Job", "Pod", "ReplicaSet", "Rollout", "PersistentVolumeClaim",
+		"BuildConfig", "Build", "Prometheus", "Alertmanager", "ThanosRuler", "Elasticsearch",
+		"VirtualMachine", "VirtualMachineInstance"}, kinds)
+}
@@ -0,0 +1,129 @@
+package calc
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// daemonSetNodes returns the number of nodes the pods of a daemonset can be scheduled on. Pods have to match the
+// nodeSelector and the required node affinity, and have to tolerate the taints of a node.
+func daemonSetNodes(podSpec *v1.PodSpec, nodes []v1.Node) int32 {
+	var count int32
+
+	tolerations := append(daemonSetTolerations(), podSpec.Tolerations...)
+
+	for i := range nodes {
+		node := &nodes[i]
+
+		if labels.SelectorFromSet(podSpec.NodeSelector).Matches(labels.Set(node.Labels)) &&
+			matchesNodeAffinity(podSpec.Affinity, node) &&
+			toleratesTaints(tolerations, node.Spec.Taints) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// daemonSetTolerations returns the tolerations the daemonset controller adds to every daemonset pod.
+// https://kubernetes.io/docs/concepts/workloads/controllers/daemonset/#taints-and-tolerations
+func daemonSetTolerations() []v1.Toleration {
+	var tolerations []v1.Toleration
+
+	for _, key := range []string{
+		v1.TaintNodeNotReady,
+		v1.TaintNodeUnreachable,
+		v1.TaintNodeDiskPressure,
+		v1.TaintNodeMemoryPressure,
+		v1.TaintNodePIDPressure,
+		v1.TaintNodeUnschedulable,
+	} {
+		tolerations = append(tolerations, v1.Toleration{Key: key, Operator: v1.TolerationOpExists})
+	}
+
+	return tolerations
+}
+
+// toleratesTaints reports whether the tolerations tolerate all taints, which prevent scheduling.
+func toleratesTaints(tolerations []v1.Toleration, taints []v1.Taint) bool {
+	for i := range taints {
+		taint := &taints[i]
+
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+
+				break
+			}
+		}
+
+		if !tolerated {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesNodeAffinity reports whether the node matches the required node affinity. The terms are ORed, the
+// requirements of a single term are ANDed.
+func matchesNodeAffinity(affinity *v1.Affinity, node *v1.Node) bool {
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		// an empty term matches no node, the only supported field is metadata.name
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+
+		if matchesNodeSelectorRequirements(term.MatchExpressions, labels.Set(node.Labels)) &&
+			matchesNodeSelectorRequirements(term.MatchFields, labels.Set{"metadata.name": node.Name}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesNodeSelectorRequirements(requirements []v1.NodeSelectorRequirement, set labels.Set) bool {
+	selector := labels.NewSelector()
+
+	for _, req := range requirements {
+		var op selection.Operator
+
+		switch req.Operator {
+		case v1.NodeSelectorOpIn:
+			op = selection.In
+		case v1.NodeSelectorOpNotIn:
+			op = selection.NotIn
+		case v1.NodeSelectorOpExists:
+			op = selection.Exists
+		case v1.NodeSelectorOpDoesNotExist:
+			op = selection.DoesNotExist
+		case v1.NodeSelectorOpGt:
+			op = selection.GreaterThan
+		case v1.NodeSelectorOpLt:
+			op = selection.LessThan
+		default:
+			return false
+		}
+
+		requirement, err := labels.NewRequirement(req.Key, op, req.Values)
+		if err != nil {
+			return false
+		}
+
+		selector = selector.Add(*requirement)
+	}
+
+	return selector.Matches(set)
+}
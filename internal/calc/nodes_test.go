@@ -0,0 +1,88 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testNode(name string, labels map[string]string, taints ...v1.Taint) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       v1.NodeSpec{Taints: taints},
+	}
+}
+
+func testNodes() []v1.Node {
+	workers := map[string]string{"pool": "workers"}
+
+	return []v1.Node{
+		testNode("worker-1", workers),
+		testNode("worker-2", workers, v1.Taint{Key: "dedicated", Value: "logging", Effect: v1.TaintEffectNoSchedule}),
+		testNode("worker-3", workers, v1.Taint{Key: "gpu", Value: "true", Effect: v1.TaintEffectNoSchedule}),
+		testNode("worker-4", workers, v1.Taint{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule}),
+		testNode("worker-5", workers, v1.Taint{Key: "spot", Value: "true", Effect: v1.TaintEffectPreferNoSchedule}),
+		testNode("infra-1", map[string]string{"pool": "infra"}),
+	}
+}
+
+func TestMatchesNodeAffinity(t *testing.T) {
+	node := testNode("worker-1", map[string]string{"pool": "workers", "cores": "16"})
+
+	affinity := func(terms ...v1.NodeSelectorTerm) *v1.Affinity {
+		return &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: terms},
+		}}
+	}
+
+	var tests = []struct {
+		name     string
+		affinity *v1.Affinity
+		matches  bool
+	}{
+		{
+			name:    "no affinity",
+			matches: true,
+		},
+		{
+			name: "in",
+			affinity: affinity(v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: "pool", Operator: v1.NodeSelectorOpIn, Values: []string{"workers", "infra"}},
+			}}),
+			matches: true,
+		},
+		{
+			name: "requirements of a term are ANDed",
+			affinity: affinity(v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: "pool", Operator: v1.NodeSelectorOpIn, Values: []string{"workers"}},
+				{Key: "cores", Operator: v1.NodeSelectorOpGt, Values: []string{"32"}},
+			}}),
+			matches: false,
+		},
+		{
+			name: "terms are ORed",
+			affinity: affinity(
+				v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{
+					{Key: "gpu", Operator: v1.NodeSelectorOpExists},
+				}},
+				v1.NodeSelectorTerm{MatchFields: []v1.NodeSelectorRequirement{
+					{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{"worker-1"}},
+				}},
+			),
+			matches: true,
+		},
+		{
+			name:     "empty term",
+			affinity: affinity(v1.NodeSelectorTerm{}),
+			matches:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.matches, matchesNodeAffinity(test.affinity, &node))
+		})
+	}
+}
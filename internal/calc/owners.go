@@ -0,0 +1,145 @@
+package calc
+
+import (
+	"slices"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// owner is an object of the input, which might control other objects of the input.
+type owner struct {
+	kind string
+	// controller is the uid of the controller of the owner itself, if it has one
+	controller types.UID
+}
+
+// Owners are the objects of the input indexed by their uid, to recognize objects whose controller is part of the input
+// as well, e.g. in cluster dumps like kubectl get all -o yaml.
+type Owners map[types.UID]owner
+
+// NewOwners indexes the objects of the input, which have a uid.
+func NewOwners(objects []runtime.Object) Owners {
+	owners := Owners{}
+
+	for _, object := range objects {
+		accessor, err := ownerAccessor(object)
+		if err != nil || accessor.GetUID() == "" {
+			continue
+		}
+
+		o := owner{kind: object.GetObjectKind().GroupVersionKind().Kind}
+		if controller := metav1.GetControllerOfNoCopy(accessor); controller != nil {
+			o.controller = controller.UID
+		}
+
+		owners[accessor.GetUID()] = o
+	}
+
+	return owners
+}
+
+// ownerAccessor returns the metadata of an object. Kinds unknown to the bundled api, like Argo Rollouts, are decoded
+// from their raw content.
+func ownerAccessor(object runtime.Object) (metav1.Object, error) {
+	unknown, ok := object.(*runtime.Unknown)
+	if !ok {
+		return meta.Accessor(object)
+	}
+
+	content := unstructured.Unstructured{}
+	if err := sigsyaml.Unmarshal(unknown.Raw, &content.Object); err != nil {
+		return nil, err
+	}
+
+	return &content, nil
+}
+
+// Controller returns the kind of the controller of the object, if the resources of the object are already part of the
+// calculation of its controller in the input. That's the case for Jobs spawned by a CronJob, for ReplicaSets of a
+// Deployment or Argo Rollout, for ReplicationControllers of a DeploymentConfig and for Pods of a workload. Pods of a
+// ReplicaSet are covered by its Deployment or Rollout, or by the ReplicaSet itself if it is a bare one. Pods of a
+// ReplicationController are only covered, if its DeploymentConfig is part of the input too, as kuota-calc doesn't
+// calculate ReplicationControllers themselves. Builds are covered by their BuildConfig and build pods by their Build, the StatefulSets of the prometheus operator by its Prometheus, Alertmanager or ThanosRuler
+// and the ones of ECK by their Elasticsearch. VirtualMachineInstances are covered by their VirtualMachine and
+// virt-launcher pods by their VirtualMachineInstance.
+func (o Owners) Controller(object runtime.Object) (string, bool) {
+	switch object := object.(type) {
+	case *batchV1.Job:
+		return o.controller(object, "CronJob")
+	case *appsv1.ReplicaSet:
+		return o.controller(object, "Deployment", "Rollout")
+	case *v1.ReplicationController:
+		return o.controller(object, "DeploymentConfig")
+	case *appsv1.StatefulSet:
+		return o.controller(object, "Prometheus", "Alertmanager", "ThanosRuler", "Elasticsearch")
+	case *buildv1.Build:
+		return o.controller(object, "BuildConfig")
+	case *runtime.Unknown:
+		if object.GroupVersionKind() == kubevirtKind("VirtualMachineInstance") {
+			return o.unknownController(object, "VirtualMachine")
+		}
+	case *v1.Pod:
+		if kind, ok := o.controller(object, "StatefulSet", "DaemonSet", "Job", "Build", "VirtualMachineInstance"); ok {
+			return kind, true
+		}
+
+		reference := metav1.GetControllerOfNoCopy(object)
+		if reference == nil {
+			return "", false
+		}
+
+		switch owner := o[reference.UID]; owner.kind {
+		case "ReplicaSet":
+			if kind, ok := o.kindOf(owner.controller, "Deployment", "Rollout"); ok {
+				return kind, true
+			}
+
+			// a replicaset, whose deployment isn't part of the input, is calculated itself
+			return owner.kind, true
+		case "ReplicationController":
+			return o.kindOf(owner.controller, "DeploymentConfig")
+		}
+	}
+
+	return "", false
+}
+
+// controller returns the kind of the controller of the object, if it is part of the input and one of the given kinds.
+func (o Owners) controller(object metav1.Object, kinds ...string) (string, bool) {
+	owner := metav1.GetControllerOfNoCopy(object)
+	if owner == nil {
+		return "", false
+	}
+
+	return o.kindOf(owner.UID, kinds...)
+}
+
+// unknownController returns the kind of the controller of an object of a kind unknown to the bundled api, if it is
+// part of the input and one of the given kinds.
+func (o Owners) unknownController(object *runtime.Unknown, kinds ...string) (string, bool) {
+	accessor, err := ownerAccessor(object)
+	if err != nil {
+		return "", false
+	}
+
+	return o.controller(accessor, kinds...)
+}
+
+// kindOf returns the kind of the object of the input with the uid, if it is one of the given kinds.
+func (o Owners) kindOf(uid types.UID, kinds ...string) (string, bool) {
+	owner, ok := o[uid]
+	if !ok || uid == "" || !slices.Contains(kinds, owner.kind) {
+		return "", false
+	}
+
+	return owner.kind, true
+}
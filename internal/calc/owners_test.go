@@ -0,0 +1,187 @@
+package calc
+
+import (
+	"testing"
+
+	openshiftAppsV1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestOwnersController(t *testing.T) {
+	controller := true
+
+	ownedBy := func(kind string, uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: "owner", UID: uid, Controller: &controller}}
+	}
+
+	cronJob := &batchV1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+		ObjectMeta: metav1.ObjectMeta{Name: "report", UID: "cronjob-uid"},
+	}
+
+	job := func(owners []metav1.OwnerReference) *batchV1.Job {
+		return &batchV1.Job{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+			ObjectMeta: metav1.ObjectMeta{Name: "report-28000000", UID: "job-uid", OwnerReferences: owners},
+		}
+	}
+
+	pod := func(owners []metav1.OwnerReference) *v1.Pod {
+		return &v1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "pod", OwnerReferences: owners},
+		}
+	}
+
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", UID: "deployment-uid"},
+	}
+
+	replicaSet := func(name string, uid types.UID, owners []metav1.OwnerReference) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: uid, OwnerReferences: owners},
+		}
+	}
+
+	deploymentConfig := &openshiftAppsV1.DeploymentConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps.openshift.io/v1", Kind: "DeploymentConfig"},
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", UID: "deploymentconfig-uid"},
+	}
+
+	replicationController := func(uid types.UID, owners []metav1.OwnerReference) *v1.ReplicationController {
+		return &v1.ReplicationController{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ReplicationController"},
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy-1", UID: uid, OwnerReferences: owners},
+		}
+	}
+
+	statefulSet := &appsv1.StatefulSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "db", UID: "statefulset-uid"},
+	}
+
+	prometheus := &runtime.Unknown{
+		TypeMeta: runtime.TypeMeta{APIVersion: "monitoring.coreos.com/v1", Kind: "Prometheus"},
+		Raw:      []byte("apiVersion: monitoring.coreos.com/v1\nkind: Prometheus\nmetadata:\n  name: k8s\n  uid: prometheus-uid\n"),
+	}
+
+	virtualMachine := &runtime.Unknown{
+		TypeMeta: runtime.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachine"},
+		Raw:      []byte("apiVersion: kubevirt.io/v1\nkind: VirtualMachine\nmetadata:\n  name: worker\n  uid: vm-uid\n"),
+	}
+
+	// the instance is controlled by the virtual machine with the uid, if one is given
+	virtualMachineInstance := func(uid, vmUID string) *runtime.Unknown {
+		raw := "apiVersion: kubevirt.io/v1\nkind: VirtualMachineInstance\nmetadata:\n  name: worker\n  uid: " + uid + "\n"
+		if vmUID != "" {
+			raw += "  ownerReferences:\n  - kind: VirtualMachine\n    name: worker\n    uid: " + vmUID + "\n    controller: true\n"
+		}
+
+		return &runtime.Unknown{
+			TypeMeta: runtime.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineInstance"},
+			Raw:      []byte(raw),
+		}
+	}
+
+	buildConfig := &buildv1.BuildConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "build.openshift.io/v1", Kind: "BuildConfig"},
+		ObjectMeta: metav1.ObjectMeta{Name: "app", UID: "buildconfig-uid"},
+	}
+
+	build := func(owners []metav1.OwnerReference) *buildv1.Build {
+		return &buildv1.Build{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "build.openshift.io/v1", Kind: "Build"},
+			ObjectMeta: metav1.ObjectMeta{Name: "app-1", UID: "build-uid", OwnerReferences: owners},
+		}
+	}
+
+	// kinds unknown to the bundled api are decoded as such
+	rollout := &runtime.Unknown{
+		TypeMeta: runtime.TypeMeta{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout"},
+		Raw:      []byte("apiVersion: argoproj.io/v1alpha1\nkind: Rollout\nmetadata:\n  name: canary\n  uid: rollout-uid\n"),
+	}
+
+	owners := NewOwners([]runtime.Object{
+		rollout,
+		replicaSet("canary-abc", "rollout-replicaset-uid", ownedBy("Rollout", "rollout-uid")),
+		cronJob,
+		job(nil),
+		deployment,
+		replicaSet("web-abc", "replicaset-uid", ownedBy("Deployment", "deployment-uid")),
+		replicaSet("standalone", "standalone-uid", nil),
+		deploymentConfig,
+		replicationController("replicationcontroller-uid", ownedBy("DeploymentConfig", "deploymentconfig-uid")),
+		replicationController("standalone-replicationcontroller-uid", nil),
+		statefulSet,
+		buildConfig,
+		build(ownedBy("BuildConfig", "buildconfig-uid")),
+		prometheus,
+		virtualMachine,
+		virtualMachineInstance("worker-uid", "vm-uid"),
+	})
+
+	var tests = []struct {
+		name   string
+		object runtime.Object
+		kind   string
+		owned  bool
+	}{
+		{name: "job of cronjob in input", object: job(ownedBy("CronJob", "cronjob-uid")), kind: "CronJob", owned: true},
+		{name: "job of cronjob not in input", object: job(ownedBy("CronJob", "other-uid"))},
+		{name: "job without owner", object: job(nil)},
+		{name: "cronjob", object: cronJob},
+		{name: "pod of statefulset", object: pod(ownedBy("StatefulSet", "statefulset-uid")), kind: "StatefulSet", owned: true},
+		{name: "pod of deployment", object: pod(ownedBy("ReplicaSet", "replicaset-uid")), kind: "Deployment", owned: true},
+		{name: "pod of standalone replicaset", object: pod(ownedBy("ReplicaSet", "standalone-uid")), kind: "ReplicaSet", owned: true},
+		{name: "pod of replicaset not in input", object: pod(ownedBy("ReplicaSet", "other-uid"))},
+		{name: "replicaset of deployment", object: replicaSet("web-abc", "replicaset-uid", ownedBy("Deployment", "deployment-uid")),
+			kind: "Deployment", owned: true},
+		{name: "replicaset of deployment not in input", object: replicaSet("web-def", "orphan-uid", ownedBy("Deployment", "other-uid"))},
+		{name: "standalone replicaset", object: replicaSet("standalone", "standalone-uid", nil)},
+		{name: "replicaset of rollout", object: replicaSet("canary-abc", "rollout-replicaset-uid", ownedBy("Rollout", "rollout-uid")),
+			kind: "Rollout", owned: true},
+		{name: "pod of rollout", object: pod(ownedBy("ReplicaSet", "rollout-replicaset-uid")), kind: "Rollout", owned: true},
+		{name: "replicationcontroller of deploymentconfig", object: replicationController("replicationcontroller-uid",
+			ownedBy("DeploymentConfig", "deploymentconfig-uid")), kind: "DeploymentConfig", owned: true},
+		{name: "replicationcontroller of deploymentconfig not in input", object: replicationController("orphan-uid",
+			ownedBy("DeploymentConfig", "other-uid"))},
+		{name: "pod of deploymentconfig", object: pod(ownedBy("ReplicationController", "replicationcontroller-uid")),
+			kind: "DeploymentConfig", owned: true},
+		{name: "pod of standalone replicationcontroller", object: pod(ownedBy("ReplicationController",
+			"standalone-replicationcontroller-uid"))},
+		{name: "pod of job", object: pod(ownedBy("Job", "job-uid")), kind: "Job", owned: true},
+		{name: "statefulset of prometheus", object: &appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			ObjectMeta: metav1.ObjectMeta{Name: "prometheus-k8s", OwnerReferences: ownedBy("Prometheus", "prometheus-uid")},
+		}, kind: "Prometheus", owned: true},
+		{name: "statefulset", object: statefulSet},
+		{name: "instance of virtual machine", object: virtualMachineInstance("worker-uid", "vm-uid"),
+			kind: "VirtualMachine", owned: true},
+		{name: "instance without virtual machine", object: virtualMachineInstance("standalone-vmi-uid", "")},
+		{name: "virt-launcher pod", object: pod(ownedBy("VirtualMachineInstance", "worker-uid")), kind: "VirtualMachineInstance", owned: true},
+		{name: "build of buildconfig", object: build(ownedBy("BuildConfig", "buildconfig-uid")), kind: "BuildConfig", owned: true},
+		{name: "build of buildconfig not in input", object: build(ownedBy("BuildConfig", "other-uid"))},
+		{name: "pod of build", object: pod(ownedBy("Build", "build-uid")), kind: "Build", owned: true},
+		{name: "pod of unknown owner", object: pod(ownedBy("StatefulSet", "other-uid"))},
+		{name: "bare pod", object: pod(nil)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			kind, owned := owners.Controller(test.object)
+			r.Equal(test.owned, owned)
+			r.Equal(test.kind, kind)
+		})
+	}
+}
@@ -2,21 +2,32 @@ package calc
 
 import v1 "k8s.io/api/core/v1"
 
-func pod(pod v1.Pod) *ResourceUsage {
-	podResources := calcPodResources(&pod.Spec)
+func pod(pod v1.Pod, opts Options) *ResourceUsage {
+	podResources := annotatedPodResources(pod.ObjectMeta, pod.ObjectMeta, &pod.Spec, opts)
 
 	resourceUsage := ResourceUsage{
 		NormalResources:  podResources.Containers,
 		RolloutResources: podResources.MaxResources,
+		Pod:              podResources,
 		Details: Details{
-			Version:     pod.APIVersion,
-			Kind:        pod.Kind,
-			Name:        pod.Name,
-			Strategy:    "",
-			Replicas:    0,
-			MaxReplicas: 0,
+			Version:           pod.APIVersion,
+			Kind:              pod.Kind,
+			Namespace:         pod.Namespace,
+			Name:              pod.Name,
+			PriorityClassName: pod.Spec.PriorityClassName,
+			Strategy:          "",
+			Replicas:          0,
+			MaxReplicas:       0,
 		},
 	}
 
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "normal = containers")
+	resourceUsage.explainf(opts, "rollout = max")
+
+	if opts.Timeline {
+		resourceUsage.Timeline = staticTimeline(&resourceUsage, newPodTimings(&pod.Spec, 0, opts))
+	}
+
 	return &resourceUsage
 }
@@ -68,7 +68,7 @@ func TestPod(t *testing.T) {
 			test.name, func(t *testing.T) {
 				r := require.New(t)
 
-				usage, err := ResourceQuotaFromYaml([]byte(test.pod))
+				usage, err := ResourceQuotaFromYaml([]byte(test.pod), Options{})
 				r.NoError(err)
 				r.NotEmpty(usage)
 
@@ -83,3 +83,39 @@ func TestPod(t *testing.T) {
 		)
 	}
 }
+
+func TestPodEphemeralStorage(t *testing.T) {
+	var tests = []struct {
+		name         string
+		opts         Options
+		ephemeralMin resource.Quantity
+		ephemeralMax resource.Quantity
+	}{
+		{
+			name:         "containers only",
+			ephemeralMin: resource.MustParse("1Gi"),
+			ephemeralMax: resource.MustParse("2Gi"),
+		},
+		{
+			name:         "with emptyDir size limits",
+			opts:         Options{EmptyDirEphemeralStorage: true},
+			ephemeralMin: resource.MustParse("6Gi"),
+			ephemeralMax: resource.MustParse("7Gi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(emptyDirPod), test.opts)
+			r.NoError(err)
+
+			AssertEqualQuantities(r, test.ephemeralMin, usage.NormalResources.EphemeralStorageMin, "ephemeral storage request value")
+			AssertEqualQuantities(r, test.ephemeralMax, usage.RolloutResources.EphemeralStorageMax, "ephemeral storage limit value")
+
+			total := Total(-1, []*ResourceUsage{usage, usage})
+			AssertEqualQuantities(r, usage.RolloutResources.MulInt32(2).EphemeralStorageMax, total.EphemeralStorageMax, "total ephemeral storage limit value")
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// DefaultPodPhases are the phases of pods, which count against a quota. Pods in a terminal phase don't.
+const DefaultPodPhases = "Pending,Running,Unknown"
+
+// PodFilter selects the pods of the input, which are calculated. Pods of manifests don't have a phase, they always
+// match the phases.
+type PodFilter struct {
+	Phases        []v1.PodPhase
+	FieldSelector fields.Selector
+}
+
+// ParsePodFilter parses a comma separated list of pod phases and a field selector like the one of kubectl get
+// --field-selector. An empty field selector matches all pods.
+func ParsePodFilter(phases, fieldSelector string) (PodFilter, error) {
+	var filter PodFilter
+
+	for _, phase := range strings.Split(phases, ",") {
+		switch p := v1.PodPhase(strings.TrimSpace(phase)); p {
+		case v1.PodPending, v1.PodRunning, v1.PodSucceeded, v1.PodFailed, v1.PodUnknown:
+			filter.Phases = append(filter.Phases, p)
+		default:
+			return filter, fmt.Errorf("unknown pod phase %q, supported are %s, %s, %s, %s and %s",
+				phase, v1.PodPending, v1.PodRunning, v1.PodSucceeded, v1.PodFailed, v1.PodUnknown)
+		}
+	}
+
+	selector, err := fields.ParseSelector(fieldSelector)
+	if err != nil {
+		return filter, fmt.Errorf("invalid field selector %q: %w", fieldSelector, err)
+	}
+
+	filter.FieldSelector = selector
+
+	return filter, nil
+}
+
+// Excludes returns why the pod is excluded by the filter, or an empty string if it isn't.
+func (f PodFilter) Excludes(pod *v1.Pod) string {
+	if pod.Status.Phase != "" && !slices.Contains(f.Phases, pod.Status.Phase) {
+		return "phase " + string(pod.Status.Phase)
+	}
+
+	if f.FieldSelector != nil && !f.FieldSelector.Matches(podFields(pod)) {
+		return "field selector"
+	}
+
+	return ""
+}
+
+// podFields are the fields of a pod, which are selectable by the api server.
+func podFields(pod *v1.Pod) fields.Set {
+	return fields.Set{
+		"metadata.name":            pod.Name,
+		"metadata.namespace":       pod.Namespace,
+		"spec.nodeName":            pod.Spec.NodeName,
+		"spec.restartPolicy":       string(pod.Spec.RestartPolicy),
+		"spec.schedulerName":       pod.Spec.SchedulerName,
+		"spec.serviceAccountName":  pod.Spec.ServiceAccountName,
+		"spec.hostNetwork":         strconv.FormatBool(pod.Spec.HostNetwork),
+		"status.phase":             string(pod.Status.Phase),
+		"status.podIP":             pod.Status.PodIP,
+		"status.nominatedNodeName": pod.Status.NominatedNodeName,
+	}
+}
@@ -0,0 +1,61 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodFilter(t *testing.T) {
+	pod := func(phase v1.PodPhase, node string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "team-a"},
+			Spec:       v1.PodSpec{NodeName: node},
+			Status:     v1.PodStatus{Phase: phase},
+		}
+	}
+
+	var tests = []struct {
+		name          string
+		phases        string
+		fieldSelector string
+		pod           *v1.Pod
+		excluded      string
+	}{
+		{name: "running", phases: DefaultPodPhases, pod: pod(v1.PodRunning, "")},
+		{name: "manifest without phase", phases: "Running", pod: pod("", "")},
+		{name: "succeeded", phases: DefaultPodPhases, pod: pod(v1.PodSucceeded, ""), excluded: "phase Succeeded"},
+		{name: "failed", phases: DefaultPodPhases, pod: pod(v1.PodFailed, ""), excluded: "phase Failed"},
+		{name: "pending excluded", phases: "Running", pod: pod(v1.PodPending, ""), excluded: "phase Pending"},
+		{name: "field selector matches", phases: DefaultPodPhases, fieldSelector: "spec.nodeName=worker-1", pod: pod(v1.PodRunning, "worker-1")},
+		{
+			name:          "field selector doesn't match",
+			phases:        DefaultPodPhases,
+			fieldSelector: "spec.nodeName=worker-1,metadata.namespace!=team-b",
+			pod:           pod(v1.PodRunning, "worker-2"),
+			excluded:      "field selector",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			filter, err := ParsePodFilter(test.phases, test.fieldSelector)
+			r.NoError(err)
+			r.Equal(test.excluded, filter.Excludes(test.pod))
+		})
+	}
+}
+
+func TestParsePodFilterInvalid(t *testing.T) {
+	r := require.New(t)
+
+	_, err := ParsePodFilter("Running,Completed", "")
+	r.Error(err)
+
+	_, err = ParsePodFilter(DefaultPodPhases, "spec.nodeName")
+	r.Error(err)
+}
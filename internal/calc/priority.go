@@ -0,0 +1,91 @@
+package calc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// PriorityClasses are the values of the PriorityClasses of the input by their name. The empty name holds the value of
+// the global default class, which pods without a priorityClassName get.
+type PriorityClasses map[string]int32
+
+// systemPriorityClasses returns the values of the priority classes every cluster has.
+// https://kubernetes.io/docs/concepts/scheduling-eviction/pod-priority-preemption/#priorityclass
+func systemPriorityClasses() PriorityClasses {
+	return PriorityClasses{
+		"system-cluster-critical": 2000000000,
+		"system-node-critical":    2000001000,
+	}
+}
+
+// Add adds the object to the priority classes if it is a PriorityClass and reports whether it was one.
+func (p PriorityClasses) Add(object runtime.Object) bool {
+	class, ok := object.(*schedulingv1.PriorityClass)
+	if !ok {
+		return false
+	}
+
+	p[class.Name] = class.Value
+
+	if class.GlobalDefault {
+		p[""] = class.Value
+	}
+
+	return true
+}
+
+// Value returns the priority of the pods of a priority class. Pods without a priority class have the priority of the
+// global default class, or 0 without one. It reports false for classes, which are neither part of the input nor
+// system classes.
+func (p PriorityClasses) Value(name string) (int32, bool) {
+	if value, ok := p[name]; ok {
+		return value, true
+	}
+
+	if name == "" {
+		return 0, true
+	}
+
+	value, ok := systemPriorityClasses()[name]
+
+	return value, ok
+}
+
+// Threshold returns the priority of the given priority class or the priority value itself, e.g. 1000.
+func (p PriorityClasses) Threshold(classOrValue string) (int32, error) {
+	if value, ok := p.Value(classOrValue); ok && classOrValue != "" {
+		return value, nil
+	}
+
+	value, err := strconv.ParseInt(classOrValue, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unknown priority class %q, it has to be part of the input or a priority value", classOrValue)
+	}
+
+	return int32(value), nil
+}
+
+// SplitByPriority splits the usages into the guaranteed ones and the preemptible ones, whose pods have a priority below
+// the threshold. Preemptible pods may be evicted instead of being guaranteed their quota. Workloads of unknown priority
+// classes are kept with the guaranteed ones with a warning.
+func (p PriorityClasses) SplitByPriority(usage []*ResourceUsage, threshold int32) (guaranteed, preemptible []*ResourceUsage) {
+	for _, u := range usage {
+		value, ok := p.Value(u.Details.PriorityClassName)
+		if !ok {
+			log.Warn().Msgf("%s: priority class %s is not part of the input, keeping the workload in the total",
+				u.Details.Name, u.Details.PriorityClassName)
+		}
+
+		if ok && value < threshold {
+			preemptible = append(preemptible, u)
+		} else {
+			guaranteed = append(guaranteed, u)
+		}
+	}
+
+	return guaranteed, preemptible
+}
@@ -0,0 +1,71 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSplitByPriority(t *testing.T) {
+	r := require.New(t)
+
+	classes := PriorityClasses{}
+	r.True(classes.Add(&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "scavenger"}, Value: -10}))
+	r.True(classes.Add(&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, Value: 1000, GlobalDefault: true}))
+	r.True(classes.Add(&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "business"}, Value: 100000}))
+	r.False(classes.Add(&schedulingv1.PriorityClassList{}))
+
+	usage := []*ResourceUsage{
+		{Details: Details{Name: "batch", PriorityClassName: "scavenger"}},
+		{Details: Details{Name: "web"}},
+		{Details: Details{Name: "payments", PriorityClassName: "business"}},
+		{Details: Details{Name: "dns", PriorityClassName: "system-cluster-critical"}},
+		{Details: Details{Name: "unknown", PriorityClassName: "missing"}},
+	}
+
+	var tests = []struct {
+		name        string
+		threshold   string
+		preemptible []string
+	}{
+		{
+			name:        "class",
+			threshold:   "standard",
+			preemptible: []string{"batch"},
+		},
+		{
+			name:        "value",
+			threshold:   "100000",
+			preemptible: []string{"batch", "web"},
+		},
+		{
+			name:        "system class",
+			threshold:   "system-node-critical",
+			preemptible: []string{"batch", "web", "payments", "dns"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			threshold, err := classes.Threshold(test.threshold)
+			r.NoError(err)
+
+			guaranteed, preemptible := classes.SplitByPriority(usage, threshold)
+			r.Len(guaranteed, len(usage)-len(test.preemptible))
+
+			names := make([]string, 0, len(preemptible))
+			for _, u := range preemptible {
+				names = append(names, u.Details.Name)
+			}
+
+			r.Equal(test.preemptible, names)
+		})
+	}
+
+	_, err := classes.Threshold("missing")
+	r.Error(err)
+}
@@ -0,0 +1,199 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// configReloaderCPU and configReloaderMemory are the requests and limits the prometheus operator sets on the
+	// config-reloader sidecar by default.
+	configReloaderCPU    = "10m"
+	configReloaderMemory = "50Mi"
+	// alertmanagerMemoryRequest is the memory request the prometheus operator sets on alertmanager containers, which
+	// don't request memory themselves.
+	alertmanagerMemoryRequest = "200Mi"
+)
+
+// prometheusOperatorKind is a kind of the prometheus operator, whose pods are run by statefulsets the operator manages.
+// https://prometheus-operator.dev/docs/api-reference/api/
+func prometheusOperatorKind(kind string) schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: kind}
+}
+
+// prometheusOperatorObject is the part of a Prometheus, Alertmanager or ThanosRuler, which kuota-calc calculates.
+// The prometheus operator api isn't a dependency of kuota-calc, so the objects are decoded from their unstructured
+// content.
+type prometheusOperatorObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              prometheusOperatorSpec `json:"spec"`
+}
+
+type prometheusOperatorSpec struct {
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Shards is only known to Prometheus, each shard is a statefulset of its own.
+	Shards            *int32                  `json:"shards,omitempty"`
+	Resources         v1.ResourceRequirements `json:"resources,omitempty"`
+	PodMetadata       metav1.ObjectMeta       `json:"podMetadata,omitempty"`
+	PriorityClassName string                  `json:"priorityClassName,omitempty"`
+	// Containers and InitContainers are merged into the generated ones by their name, other ones are added.
+	Containers     []v1.Container `json:"containers,omitempty"`
+	InitContainers []v1.Container `json:"initContainers,omitempty"`
+	// Thanos adds the thanos sidecar to a Prometheus.
+	Thanos *struct {
+		Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	} `json:"thanos,omitempty"`
+	Storage *struct {
+		VolumeClaimTemplate *v1.PersistentVolumeClaim `json:"volumeClaimTemplate,omitempty"`
+	} `json:"storage,omitempty"`
+}
+
+// prometheusResource calculates a Prometheus, its prometheus container and the config-reloader and thanos sidecars.
+func prometheusResource(object *unstructured.Unstructured, opts Options) (*ResourceUsage, error) {
+	return prometheusOperatorResource(object, "prometheus", opts)
+}
+
+// alertmanagerResource calculates an Alertmanager, its alertmanager container and the config-reloader sidecar.
+func alertmanagerResource(object *unstructured.Unstructured, opts Options) (*ResourceUsage, error) {
+	return prometheusOperatorResource(object, "alertmanager", opts)
+}
+
+// thanosRulerResource calculates a ThanosRuler, its thanos-ruler container and the config-reloader sidecar.
+func thanosRulerResource(object *unstructured.Unstructured, opts Options) (*ResourceUsage, error) {
+	return prometheusOperatorResource(object, "thanos-ruler", opts)
+}
+
+// calculates the cpu/memory resources of the pods the prometheus operator runs for an object. The operator runs the
+// replicas of each shard by a statefulset with the default rolling update, which updates one pod at a time.
+func prometheusOperatorResource(object *unstructured.Unstructured, container string, opts Options) (*ResourceUsage, error) {
+	var o prometheusOperatorObject
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+		return nil, fmt.Errorf("%s: %s: decoding: %w", object.GetKind(), object.GetName(), err)
+	}
+
+	// the operator runs a single replica and shard, if the object doesn't set them
+	var replicas, shards int32 = 1, 1
+
+	if o.Spec.Replicas != nil {
+		replicas = *o.Spec.Replicas
+	}
+
+	replicas, _, replicasAnnotated := opts.workloadReplicas(o.ObjectMeta, &replicas)
+
+	if o.Spec.Shards != nil && *o.Spec.Shards > 0 {
+		shards = *o.Spec.Shards
+	}
+
+	pods := replicas * shards
+	podSpec := prometheusOperatorPodSpec(container, &o.Spec)
+	podResources := annotatedPodResources(o.ObjectMeta, o.Spec.PodMetadata, podSpec, opts)
+
+	resourceUsage := ResourceUsage{
+		Pod: podResources,
+		Details: Details{
+			Version:           o.APIVersion,
+			Kind:              o.Kind,
+			Namespace:         o.Namespace,
+			Name:              o.Name,
+			PriorityClassName: o.Spec.PriorityClassName,
+			Strategy:          "RollingUpdate",
+			Replicas:          pods,
+			ReplicasAnnotated: replicasAnnotated,
+			MaxReplicas:       pods,
+			NormalReplicas:    pods,
+		},
+	}
+
+	if pods == 0 {
+		resourceUsage.explainf(opts, "replicas: 0, no pods are running")
+
+		return &resourceUsage, nil
+	}
+
+	resourceUsage.NormalResources = podResources.Containers.MulInt32(pods)
+	resourceUsage.RolloutResources = podResources.Containers.MulInt32(pods - shards).Add(podResources.MaxResources.MulInt32(shards))
+
+	resourceUsage.explainf(opts, "pods: %d replicas * %d shards", replicas, shards)
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "normal = containers * %d", pods)
+	resourceUsage.explainf(opts, "rollout = containers * (%d pods - %d shards) + max * %d shards, one pod of each shard is updated at a time",
+		pods, shards, shards)
+
+	// like the ones of a statefulset, the claims are kept when the pods are rolled out
+	if o.Spec.Storage != nil && o.Spec.Storage.VolumeClaimTemplate != nil {
+		claims := volumeClaimResources([]v1.PersistentVolumeClaim{*o.Spec.Storage.VolumeClaimTemplate}).MulInt32(pods)
+		resourceUsage.NormalResources = resourceUsage.NormalResources.Add(claims)
+		resourceUsage.RolloutResources = resourceUsage.RolloutResources.Add(claims)
+
+		resourceUsage.explainf(opts, "volume claims: %d pods, storage %s", pods, claims.Storage.String())
+	}
+
+	if opts.Timeline {
+		resourceUsage.Timeline = batchTimeline(podResources, pods, shards, newPodTimings(podSpec, 0, opts))
+	}
+
+	return &resourceUsage, nil
+}
+
+// prometheusOperatorPodSpec returns the pod spec the operator generates: the main container with the resources of
+// the object, the config-reloader sidecar and, if configured, the thanos sidecar.
+func prometheusOperatorPodSpec(container string, spec *prometheusOperatorSpec) *v1.PodSpec {
+	main := v1.Container{Name: container, Resources: *spec.Resources.DeepCopy()}
+
+	if _, ok := main.Resources.Requests[v1.ResourceMemory]; container == "alertmanager" && !ok {
+		if main.Resources.Requests == nil {
+			main.Resources.Requests = v1.ResourceList{}
+		}
+
+		main.Resources.Requests[v1.ResourceMemory] = resource.MustParse(alertmanagerMemoryRequest)
+	}
+
+	reloader := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(configReloaderCPU),
+		v1.ResourceMemory: resource.MustParse(configReloaderMemory),
+	}
+
+	containers := []v1.Container{
+		main,
+		{Name: "config-reloader", Resources: v1.ResourceRequirements{Requests: reloader, Limits: reloader}},
+	}
+
+	if spec.Thanos != nil {
+		containers = append(containers, v1.Container{Name: "thanos-sidecar", Resources: spec.Thanos.Resources})
+	}
+
+	return &v1.PodSpec{
+		Containers:        mergeContainers(containers, spec.Containers),
+		InitContainers:    mergeContainers(nil, spec.InitContainers),
+		PriorityClassName: spec.PriorityClassName,
+	}
+}
+
+// mergeContainers merges the containers of an object into the generated ones like the operator does. A container
+// named like a generated one replaces its resources, if it sets any, other containers are added.
+func mergeContainers(generated, containers []v1.Container) []v1.Container {
+	merged := append([]v1.Container{}, generated...)
+
+	for _, container := range containers {
+		i := slices.IndexFunc(merged, func(c v1.Container) bool { return c.Name == container.Name })
+		if i < 0 {
+			merged = append(merged, container)
+
+			continue
+		}
+
+		if len(container.Resources.Requests) > 0 || len(container.Resources.Limits) > 0 {
+			merged[i].Resources = container.Resources
+		}
+	}
+
+	return merged
+}
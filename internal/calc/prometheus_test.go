@@ -0,0 +1,122 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var shardedPrometheus = `
+apiVersion: monitoring.coreos.com/v1
+kind: Prometheus
+metadata:
+  name: k8s
+  namespace: monitoring
+spec:
+  replicas: 2
+  shards: 2
+  resources:
+    requests:
+      cpu: 500m
+      memory: 2Gi
+  thanos:
+    resources:
+      requests:
+        cpu: 100m
+        memory: 128Mi
+  storage:
+    volumeClaimTemplate:
+      spec:
+        resources:
+          requests:
+            storage: 10Gi`
+
+var defaultAlertmanager = `
+apiVersion: monitoring.coreos.com/v1
+kind: Alertmanager
+metadata:
+  name: main
+  namespace: monitoring
+spec:
+  replicas: 3`
+
+var patchedThanosRuler = `
+apiVersion: monitoring.coreos.com/v1
+kind: ThanosRuler
+metadata:
+  name: rules
+  namespace: monitoring
+spec:
+  resources:
+    requests:
+      cpu: 100m
+      memory: 256Mi
+  containers:
+  - name: config-reloader
+    resources:
+      requests:
+        cpu: 20m
+        memory: 64Mi
+  - name: oauth-proxy
+    resources:
+      requests:
+        cpu: 50m
+        memory: 64Mi`
+
+func TestPrometheusOperator(t *testing.T) {
+	var tests = []struct {
+		name      string
+		object    string
+		kind      string
+		cpuMin    resource.Quantity
+		memoryMin resource.Quantity
+		storage   resource.Quantity
+		replicas  int32
+	}{
+		{
+			name:      "prometheus with shards and thanos sidecar",
+			object:    shardedPrometheus,
+			kind:      "Prometheus",
+			cpuMin:    resource.MustParse("2440m"),
+			memoryMin: resource.MustParse("8904Mi"),
+			storage:   resource.MustParse("40Gi"),
+			replicas:  4,
+		},
+		{
+			name:      "alertmanager with default resources",
+			object:    defaultAlertmanager,
+			kind:      "Alertmanager",
+			cpuMin:    resource.MustParse("30m"),
+			memoryMin: resource.MustParse("750Mi"),
+			replicas:  3,
+		},
+		{
+			name:      "thanos ruler with patched containers",
+			object:    patchedThanosRuler,
+			kind:      "ThanosRuler",
+			cpuMin:    resource.MustParse("170m"),
+			memoryMin: resource.MustParse("384Mi"),
+			replicas:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.object), Options{})
+			r.NoError(err)
+			r.NotEmpty(usage)
+			r.Equal(test.kind, usage.Details.Kind)
+			r.Equal(test.replicas, usage.Details.Replicas)
+
+			for _, resources := range []Resources{usage.NormalResources, usage.RolloutResources} {
+				AssertEqualQuantities(r, test.cpuMin, resources.CPUMin, "cpu request value")
+				AssertEqualQuantities(r, test.memoryMin, resources.MemoryMin, "memory request value")
+				AssertEqualQuantities(r, test.storage, resources.Storage, "storage value")
+				AssertEqualQuantities(r, *resource.NewQuantity(int64(test.replicas), resource.DecimalSI), resources.Pods, "pods")
+			}
+		})
+	}
+}
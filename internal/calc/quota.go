@@ -0,0 +1,302 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/inf.v0"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceQuota returns a ResourceQuota, which allows exactly the given resources in the namespace.
+// Zero quantities are left out, a quota of zero would forbid any pod that sets them.
+func ResourceQuota(namespace, name string, r Resources) v1.ResourceQuota {
+	hard := v1.ResourceList{}
+
+	for resourceName, quantity := range r.quotaResources() {
+		if !quantity.IsZero() {
+			hard[resourceName] = quantity
+		}
+	}
+
+	return v1.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ResourceQuota",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}
+}
+
+// QuotaComparison compares a resource of the total with the hard limit of an existing ResourceQuota.
+type QuotaComparison struct {
+	Name      v1.ResourceName
+	Requested resource.Quantity
+	Allowed   resource.Quantity
+}
+
+// Remaining returns the headroom left by the total, it is negative if the quota is exceeded.
+func (c QuotaComparison) Remaining() resource.Quantity {
+	remaining := c.Allowed.DeepCopy()
+	remaining.Sub(c.Requested)
+
+	return remaining
+}
+
+// Exceeded reports whether the total exceeds the hard limit.
+func (c QuotaComparison) Exceeded() bool {
+	return c.Requested.Cmp(c.Allowed) > 0
+}
+
+// CompareQuota compares the total with the hard limits of an existing ResourceQuota, sorted by name. cpu, memory and
+// ephemeral-storage are short for their requests, if both are given the lower limit applies. Limits of resources which
+// aren't calculated, like services or count/*, are left out.
+func CompareQuota(hard v1.ResourceList, total Resources) []QuotaComparison {
+	quantities := total.quotaResources()
+	allowed := v1.ResourceList{}
+
+	for name, limit := range hard {
+		switch name {
+		case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage:
+			name = v1.ResourceName("requests." + string(name))
+		}
+
+		if _, ok := quantities[name]; !ok && !isExtendedQuotaResource(name) {
+			continue
+		}
+
+		if current, ok := allowed[name]; !ok || limit.Cmp(current) < 0 {
+			allowed[name] = limit
+		}
+	}
+
+	comparisons := make([]QuotaComparison, 0, len(allowed))
+	for name, limit := range allowed {
+		comparisons = append(comparisons, QuotaComparison{Name: name, Requested: quantities[name], Allowed: limit})
+	}
+
+	slices.SortFunc(comparisons, func(a, b QuotaComparison) int {
+		return strings.Compare(string(a.Name), string(b.Name))
+	})
+
+	return comparisons
+}
+
+// QuotaRounding are the increments, to which the values of the generated quotas are rounded up, by their name in a
+// ResourceQuota. Resources missing in the rounding keep their exact values.
+type QuotaRounding v1.ResourceList
+
+// ParseQuotaRounding parses increments in the form cpu=500m,memory=1Gi. Unlike in a budget, cpu, memory and
+// ephemeral-storage round both their requests and limits, requests.cpu=500m only rounds the requests.
+func ParseQuotaRounding(value string) (QuotaRounding, error) {
+	var pairs []string
+
+	for _, pair := range strings.Split(value, ",") {
+		name, quantity, _ := strings.Cut(strings.TrimSpace(pair), "=")
+
+		switch v1.ResourceName(name) {
+		case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage:
+			pairs = append(pairs, "requests."+name+"="+quantity, "limits."+name+"="+quantity)
+		default:
+			pairs = append(pairs, pair)
+		}
+	}
+
+	rounding, err := parseQuotaResources(strings.Join(pairs, ","), "quota rounding")
+	if err != nil {
+		return nil, err
+	}
+
+	for name, increment := range rounding {
+		if increment.IsZero() {
+			return nil, fmt.Errorf("invalid quota rounding of %s, the increment must not be zero", name)
+		}
+	}
+
+	return QuotaRounding(rounding), nil
+}
+
+// RoundUp returns the resources with each value rounded up to the next multiple of its increment, e.g. 3250m to 3500m
+// with an increment of 500m.
+func (q QuotaRounding) RoundUp(r Resources) Resources {
+	rounded := r
+
+	for name, quantity := range r.quotaResources() {
+		increment, ok := q[name]
+		if !ok {
+			continue
+		}
+
+		rounded.setQuotaResource(name, roundUp(quantity, increment))
+	}
+
+	return rounded
+}
+
+// WithHeadroom returns the resources with the percentage added on top of each value, e.g. 3 cpus with a headroom of 20
+// percent result in 3600m. Cpu is rounded up to whole millicores, all other values to whole units, so counts like the
+// pods stay whole numbers.
+func (r Resources) WithHeadroom(percent float64) Resources {
+	// decimals avoid floating point errors, which would round e.g. 10 pods with 10 percent up to 12
+	factor, _ := new(inf.Dec).SetString(strconv.FormatFloat(percent, 'f', -1, 64))
+	factor.SetScale(factor.Scale() + 2)
+	factor.Add(factor, inf.NewDec(1, 0))
+
+	withHeadroom := r
+
+	for name, quantity := range r.quotaResources() {
+		if quantity.IsZero() {
+			continue
+		}
+
+		var scale inf.Scale
+		if name == v1.ResourceRequestsCPU || name == v1.ResourceLimitsCPU {
+			scale = 3
+		}
+
+		value := new(inf.Dec).Round(new(inf.Dec).Mul(quantity.AsDec(), factor), scale, inf.RoundCeil)
+		withHeadroom.setQuotaResource(name, *resource.NewDecimalQuantity(*value, quantity.Format))
+	}
+
+	return withHeadroom
+}
+
+// roundUp rounds the quantity up to the next multiple of the increment, in the format of the increment.
+func roundUp(quantity, increment resource.Quantity) resource.Quantity {
+	step := increment.AsDec()
+
+	multiples := new(inf.Dec).QuoRound(quantity.AsDec(), step, 0, inf.RoundCeil)
+
+	return *resource.NewDecimalQuantity(*new(inf.Dec).Mul(multiples, step), increment.Format)
+}
+
+const (
+	// QuotaScopeTerminating splits the quotas into one with the scope Terminating for batch workloads (Jobs and
+	// CronJobs) and one with the scope NotTerminating for the other workloads.
+	QuotaScopeTerminating = "terminating"
+	// QuotaScopePriorityClass splits the quotas into one per priorityClassName of the pods.
+	QuotaScopePriorityClass = "priority-class"
+)
+
+// QuotaScopes selects, by which scopes the quota of a namespace is split.
+type QuotaScopes struct {
+	Terminating   bool
+	PriorityClass bool
+}
+
+// ParseQuotaScopes parses a comma separated list of quota scopes, e.g. terminating,priority-class.
+func ParseQuotaScopes(value string) (QuotaScopes, error) {
+	var s QuotaScopes
+
+	for _, scope := range strings.Split(value, ",") {
+		switch strings.TrimSpace(scope) {
+		case QuotaScopeTerminating:
+			s.Terminating = true
+		case QuotaScopePriorityClass:
+			s.PriorityClass = true
+		default:
+			return s, fmt.Errorf("unknown quota scope %q, supported are %s and %s", scope, QuotaScopeTerminating, QuotaScopePriorityClass)
+		}
+	}
+
+	return s, nil
+}
+
+// QuotaBucket contains the usages of the resources, which are counted by the same scoped quota.
+type QuotaBucket struct {
+	// Suffix is appended to the name of the quota, it is empty if the quota isn't split.
+	Suffix        string
+	ScopeSelector *v1.ScopeSelector
+	Usage         []*ResourceUsage
+}
+
+// Buckets splits the usages by the scopes. The buckets are sorted by their suffix.
+func (s QuotaScopes) Buckets(usage []*ResourceUsage) []QuotaBucket {
+	groups := GroupBy(usage, func(u *ResourceUsage) string {
+		var suffix []string
+
+		if s.Terminating {
+			if terminating(u) {
+				suffix = append(suffix, "terminating")
+			} else {
+				suffix = append(suffix, "not-terminating")
+			}
+		}
+
+		if s.PriorityClass {
+			if u.Details.PriorityClassName == "" {
+				suffix = append(suffix, "no-priority-class")
+			} else {
+				suffix = append(suffix, u.Details.PriorityClassName)
+			}
+		}
+
+		return strings.Join(suffix, "-")
+	})
+
+	buckets := make([]QuotaBucket, 0, len(groups))
+
+	for _, group := range groups {
+		buckets = append(buckets, QuotaBucket{
+			Suffix:        group.Key,
+			ScopeSelector: s.scopeSelector(group.Usage[0]),
+			Usage:         group.Usage,
+		})
+	}
+
+	return buckets
+}
+
+// scopeSelector returns the scope selector of the quota, which counts the resource.
+func (s QuotaScopes) scopeSelector(u *ResourceUsage) *v1.ScopeSelector {
+	var expressions []v1.ScopedResourceSelectorRequirement
+
+	if s.Terminating {
+		scope := v1.ResourceQuotaScopeNotTerminating
+		if terminating(u) {
+			scope = v1.ResourceQuotaScopeTerminating
+		}
+
+		expressions = append(expressions, v1.ScopedResourceSelectorRequirement{
+			ScopeName: scope,
+			Operator:  v1.ScopeSelectorOpExists,
+		})
+	}
+
+	if s.PriorityClass {
+		if u.Details.PriorityClassName == "" {
+			expressions = append(expressions, v1.ScopedResourceSelectorRequirement{
+				ScopeName: v1.ResourceQuotaScopePriorityClass,
+				Operator:  v1.ScopeSelectorOpDoesNotExist,
+			})
+		} else {
+			expressions = append(expressions, v1.ScopedResourceSelectorRequirement{
+				ScopeName: v1.ResourceQuotaScopePriorityClass,
+				Operator:  v1.ScopeSelectorOpIn,
+				Values:    []string{u.Details.PriorityClassName},
+			})
+		}
+	}
+
+	if len(expressions) == 0 {
+		return nil
+	}
+
+	return &v1.ScopeSelector{MatchExpressions: expressions}
+}
+
+// terminating reports whether the pods of a resource are batch pods, which are counted by the Terminating scope.
+// Only pods setting activeDeadlineSeconds are, so batch workloads are expected to set it.
+func terminating(u *ResourceUsage) bool {
+	return u.Details.Kind == "Job" || u.Details.Kind == "CronJob"
+}
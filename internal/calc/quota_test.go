@@ -0,0 +1,208 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestResourceQuota(t *testing.T) {
+	r := require.New(t)
+
+	quota := ResourceQuota("team-a", "compute", Resources{
+		CPUMin:    resource.MustParse("3"),
+		MemoryMin: resource.MustParse("8Gi"),
+		MemoryMax: resource.MustParse("16Gi"),
+	})
+
+	r.Equal("ResourceQuota", quota.Kind)
+	r.Equal("team-a", quota.Namespace)
+	r.Equal("compute", quota.Name)
+	r.Equal(v1.ResourceList{
+		v1.ResourceRequestsCPU:    resource.MustParse("3"),
+		v1.ResourceRequestsMemory: resource.MustParse("8Gi"),
+		v1.ResourceLimitsMemory:   resource.MustParse("16Gi"),
+	}, quota.Spec.Hard)
+}
+
+func TestCompareQuota(t *testing.T) {
+	r := require.New(t)
+
+	comparisons := CompareQuota(v1.ResourceList{
+		v1.ResourceCPU:            resource.MustParse("4"),
+		v1.ResourceRequestsCPU:    resource.MustParse("3"),
+		v1.ResourceLimitsMemory:   resource.MustParse("16Gi"),
+		"requests.nvidia.com/gpu": resource.MustParse("2"),
+		v1.ResourceServices:       resource.MustParse("10"),
+	}, Resources{
+		CPUMin:    resource.MustParse("3500m"),
+		MemoryMax: resource.MustParse("12Gi"),
+	})
+
+	// the lower limit of cpu applies, services aren't calculated
+	r.Len(comparisons, 3)
+	r.Equal(v1.ResourceLimitsMemory, comparisons[0].Name)
+	r.False(comparisons[0].Exceeded())
+	AssertEqualQuantities(r, resource.MustParse("4Gi"), comparisons[0].Remaining(), "remaining memory")
+
+	r.Equal(v1.ResourceRequestsCPU, comparisons[1].Name)
+	r.True(comparisons[1].Exceeded())
+	AssertEqualQuantities(r, resource.MustParse("-500m"), comparisons[1].Remaining(), "remaining cpu")
+
+	// extended resources, which aren't requested, request nothing
+	r.Equal(v1.ResourceName("requests.nvidia.com/gpu"), comparisons[2].Name)
+	r.True(comparisons[2].Requested.IsZero())
+}
+
+func TestWithHeadroom(t *testing.T) {
+	r := require.New(t)
+
+	withHeadroom := Resources{
+		CPUMin:    resource.MustParse("3"),
+		CPUMax:    resource.MustParse("250m"),
+		MemoryMin: resource.MustParse("10Gi"),
+		Pods:      resource.MustParse("13"),
+	}.WithHeadroom(20)
+
+	AssertEqualQuantities(r, resource.MustParse("3600m"), withHeadroom.CPUMin, "cpu request")
+	AssertEqualQuantities(r, resource.MustParse("300m"), withHeadroom.CPUMax, "cpu limit")
+	AssertEqualQuantities(r, resource.MustParse("12Gi"), withHeadroom.MemoryMin, "memory request")
+	AssertEqualQuantities(r, resource.MustParse("16"), withHeadroom.Pods, "pods rounded up")
+	r.True(withHeadroom.MemoryMax.IsZero())
+
+	AssertEqualQuantities(r, resource.MustParse("11"), Resources{Pods: resource.MustParse("10")}.WithHeadroom(10).Pods, "exact pods")
+}
+
+func TestQuotaBuckets(t *testing.T) {
+	usage := []*ResourceUsage{
+		{Details: Details{Kind: "Deployment", Name: "web", PriorityClassName: "high"}},
+		{Details: Details{Kind: "Deployment", Name: "worker"}},
+		{Details: Details{Kind: "CronJob", Name: "report"}},
+	}
+
+	var tests = []struct {
+		name     string
+		scopes   string
+		suffixes []string
+		sizes    []int
+	}{
+		{
+			name:     "terminating",
+			scopes:   "terminating",
+			suffixes: []string{"not-terminating", "terminating"},
+			sizes:    []int{2, 1},
+		},
+		{
+			name:     "priority class",
+			scopes:   "priority-class",
+			suffixes: []string{"high", "no-priority-class"},
+			sizes:    []int{1, 2},
+		},
+		{
+			name:     "both",
+			scopes:   "terminating,priority-class",
+			suffixes: []string{"not-terminating-high", "not-terminating-no-priority-class", "terminating-no-priority-class"},
+			sizes:    []int{1, 1, 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			scopes, err := ParseQuotaScopes(test.scopes)
+			r.NoError(err)
+
+			buckets := scopes.Buckets(usage)
+			r.Len(buckets, len(test.suffixes))
+
+			for i, bucket := range buckets {
+				r.Equal(test.suffixes[i], bucket.Suffix)
+				r.Len(bucket.Usage, test.sizes[i])
+				r.NotNil(bucket.ScopeSelector)
+			}
+		})
+	}
+}
+
+func TestQuotaScopeSelector(t *testing.T) {
+	r := require.New(t)
+
+	scopes := QuotaScopes{Terminating: true, PriorityClass: true}
+
+	r.Equal(&v1.ScopeSelector{MatchExpressions: []v1.ScopedResourceSelectorRequirement{
+		{ScopeName: v1.ResourceQuotaScopeTerminating, Operator: v1.ScopeSelectorOpExists},
+		{ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpIn, Values: []string{"batch"}},
+	}}, scopes.scopeSelector(&ResourceUsage{Details: Details{Kind: "Job", PriorityClassName: "batch"}}))
+
+	r.Equal(&v1.ScopeSelector{MatchExpressions: []v1.ScopedResourceSelectorRequirement{
+		{ScopeName: v1.ResourceQuotaScopeNotTerminating, Operator: v1.ScopeSelectorOpExists},
+		{ScopeName: v1.ResourceQuotaScopePriorityClass, Operator: v1.ScopeSelectorOpDoesNotExist},
+	}}, scopes.scopeSelector(&ResourceUsage{Details: Details{Kind: "StatefulSet"}}))
+
+	r.Nil(QuotaScopes{}.scopeSelector(&ResourceUsage{}))
+
+	_, err := ParseQuotaScopes("best-effort")
+	r.Error(err)
+}
+
+func TestQuotaRounding(t *testing.T) {
+	resources := Resources{
+		CPUMin:    resource.MustParse("3250m"),
+		CPUMax:    resource.MustParse("18700m"),
+		MemoryMin: resource.MustParse("34493Mi"),
+		MemoryMax: resource.MustParse("48Gi"),
+	}
+
+	var tests = []struct {
+		name      string
+		rounding  string
+		cpuMin    resource.Quantity
+		cpuMax    resource.Quantity
+		memoryMin resource.Quantity
+		memoryMax resource.Quantity
+	}{
+		{
+			name:      "requests and limits",
+			rounding:  "cpu=500m,memory=1Gi",
+			cpuMin:    resource.MustParse("3500m"),
+			cpuMax:    resource.MustParse("19"),
+			memoryMin: resource.MustParse("34Gi"),
+			memoryMax: resource.MustParse("48Gi"),
+		},
+		{
+			name:      "requests only",
+			rounding:  "requests.cpu=1",
+			cpuMin:    resource.MustParse("4"),
+			cpuMax:    resource.MustParse("18700m"),
+			memoryMin: resource.MustParse("34493Mi"),
+			memoryMax: resource.MustParse("48Gi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			rounding, err := ParseQuotaRounding(test.rounding)
+			r.NoError(err)
+
+			rounded := rounding.RoundUp(resources)
+			AssertEqualQuantities(r, test.cpuMin, rounded.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.cpuMax, rounded.CPUMax, "cpu limit value")
+			AssertEqualQuantities(r, test.memoryMin, rounded.MemoryMin, "memory request value")
+			AssertEqualQuantities(r, test.memoryMax, rounded.MemoryMax, "memory limit value")
+		})
+	}
+}
+
+func TestParseQuotaRoundingInvalid(t *testing.T) {
+	r := require.New(t)
+
+	for _, value := range []string{"cpu=0", "cpu", "gpu=1", "memory=-1Gi"} {
+		_, err := ParseQuotaRounding(value)
+		r.Error(err, value)
+	}
+}
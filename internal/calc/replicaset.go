@@ -0,0 +1,51 @@
+package calc
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// calculates the cpu/memory resources a single bare replicaset needs. Replicas are taken into account. A replicaset
+// doesn't roll out on its own, it only replaces failed pods, so there is no rollout overhead.
+func replicaSet(rs appsv1.ReplicaSet, opts Options) *ResourceUsage {
+	replicas, replicasAssumed, replicasAnnotated := opts.workloadReplicas(rs.ObjectMeta, rs.Spec.Replicas)
+	normalReplicas, replicas, autoscaler := opts.autoscaledReplicas("ReplicaSet", rs.ObjectMeta, replicas)
+
+	podResources := annotatedPodResources(rs.ObjectMeta, rs.Spec.Template.ObjectMeta, &rs.Spec.Template.Spec, opts)
+
+	resourceUsage := ResourceUsage{
+		NormalResources:  podResources.Containers.MulInt32(normalReplicas),
+		RolloutResources: podResources.Containers.MulInt32(replicas),
+		Pod:              podResources,
+		Details: Details{
+			Version:           rs.APIVersion,
+			Kind:              rs.Kind,
+			Namespace:         rs.Namespace,
+			Name:              rs.Name,
+			PriorityClassName: rs.Spec.Template.Spec.PriorityClassName,
+			Strategy:          "",
+			Replicas:          replicas,
+			ReplicasAssumed:   replicasAssumed,
+			ReplicasAnnotated: replicasAnnotated,
+			MaxReplicas:       replicas,
+			Autoscaler:        autoscaler,
+			NormalReplicas:    normalReplicas,
+		},
+	}
+
+	if replicas == 0 {
+		resourceUsage.explainf(opts, "replicas: 0, no pods are running")
+
+		return &resourceUsage
+	}
+
+	resourceUsage.explainReplicas(opts)
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "normal = containers * %d", normalReplicas)
+	resourceUsage.explainf(opts, "rollout = containers * %d, replicasets aren't rolled out", replicas)
+
+	if opts.Timeline {
+		resourceUsage.Timeline = staticTimeline(&resourceUsage, newPodTimings(&rs.Spec.Template.Spec, rs.Spec.MinReadySeconds, opts))
+	}
+
+	return &resourceUsage
+}
@@ -0,0 +1,67 @@
+package calc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestReplicaSet(t *testing.T) {
+	var tests = []struct {
+		name        string
+		replicaset  string
+		cpuMin      resource.Quantity
+		cpuMax      resource.Quantity
+		memoryMin   resource.Quantity
+		memoryMax   resource.Quantity
+		replicas    int32
+		maxReplicas int32
+	}{
+		{
+			name:        "ok",
+			replicaset:  normalReplicaSet,
+			replicas:    3,
+			maxReplicas: 3,
+			cpuMin:      resource.MustParse("1500m"),
+			cpuMax:      resource.MustParse("3"),
+			memoryMin:   resource.MustParse("1536Mi"),
+			memoryMax:   resource.MustParse("3Gi"),
+		},
+		{
+			name:        "without replicas",
+			replicaset:  strings.Replace(normalReplicaSet, "  replicas: 3\n", "", 1),
+			replicas:    1,
+			maxReplicas: 1,
+			cpuMin:      resource.MustParse("500m"),
+			cpuMax:      resource.MustParse("1"),
+			memoryMin:   resource.MustParse("512Mi"),
+			memoryMax:   resource.MustParse("1Gi"),
+		},
+		{
+			name:       "scaled to zero",
+			replicaset: strings.Replace(normalReplicaSet, "replicas: 3", "replicas: 0", 1),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.replicaset), Options{})
+			r.NoError(err)
+			r.NotEmpty(usage)
+
+			// replicasets don't roll out, so the init containers never add to the normal resources
+			r.Equal(usage.NormalResources, usage.RolloutResources)
+			AssertEqualQuantities(r, test.cpuMin, usage.RolloutResources.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.cpuMax, usage.RolloutResources.CPUMax, "cpu limit value")
+			AssertEqualQuantities(r, test.memoryMin, usage.RolloutResources.MemoryMin, "memory request value")
+			AssertEqualQuantities(r, test.memoryMax, usage.RolloutResources.MemoryMax, "memory limit value")
+			r.Equalf(test.replicas, usage.Details.Replicas, "replicas")
+			r.Equalf(test.maxReplicas, usage.Details.MaxReplicas, "maxReplicas")
+			r.Equal("ReplicaSet", usage.Details.Kind)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package calc
+
+import (
+	openshiftAppsV1 "github.com/openshift/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Scale returns a copy of a workload with the given replicas. It reports false, if the object has no replicas.
+func Scale(object runtime.Object, replicas int32) (runtime.Object, bool) {
+	scaled := object.DeepCopyObject()
+
+	switch obj := scaled.(type) {
+	case *openshiftAppsV1.DeploymentConfig:
+		obj.Spec.Replicas = replicas
+	case *appsv1.Deployment:
+		obj.Spec.Replicas = &replicas
+	case *appsv1.StatefulSet:
+		obj.Spec.Replicas = &replicas
+	default:
+		return object, false
+	}
+
+	return scaled, true
+}
@@ -0,0 +1,32 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScale(t *testing.T) {
+	r := require.New(t)
+
+	deployment, err := Decode([]byte(normalDeployment))
+	r.NoError(err)
+
+	scaled, ok := Scale(deployment, 2)
+	r.True(ok)
+
+	usage, err := ResourceQuotaFromObject(scaled, Options{})
+	r.NoError(err)
+	r.Equal(int32(2), usage.Details.Replicas)
+
+	// the original is left as it is
+	usage, err = ResourceQuotaFromObject(deployment, Options{})
+	r.NoError(err)
+	r.Equal(int32(10), usage.Details.Replicas)
+
+	pod, err := Decode([]byte(normalPod))
+	r.NoError(err)
+
+	_, ok = Scale(pod, 2)
+	r.False(ok)
+}
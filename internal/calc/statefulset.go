@@ -1,28 +1,23 @@
 package calc
 
 import (
-	"errors"
-	"math"
+	"fmt"
 
+	"github.com/rs/zerolog/log"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // calculates the cpu/memory resources a single statefulset needs. Replicas are taken into account.
-func statefulSet(s appsv1.StatefulSet) (*ResourceUsage, error) {
+func statefulSet(s appsv1.StatefulSet, opts Options) (*ResourceUsage, error) {
 	var (
-		replicas       int32
-		maxUnavailable int32
+		maxUnavailable      int32
+		strategyExplanation string
 	)
 
 	strategy := s.Spec.UpdateStrategy
-
-	// https://github.com/kubernetes/api/blob/v0.18.4/apps/v1/types.go#L117
-	if s.Spec.Replicas != nil {
-		replicas = *s.Spec.Replicas
-	} else {
-		replicas = 1
-	}
+	replicas, replicasAssumed, replicasAnnotated := opts.workloadReplicas(s.ObjectMeta, s.Spec.Replicas)
+	normalReplicas, replicas, autoscaler := opts.autoscaledReplicas("StatefulSet", s.ObjectMeta, replicas)
 
 	// https://kubernetes.io/docs/concepts/workloads/controllers/statefulset/#update-strategies
 	switch strategy.Type {
@@ -30,57 +25,85 @@ func statefulSet(s appsv1.StatefulSet) (*ResourceUsage, error) {
 		// OnDelete doesn't do anything until you kill pods, which it then replaces with the newer ones.
 		// The most expensive case would be killing all pods at once, with the init containers being more expensive than the normal container.
 		maxUnavailable = replicas
+		strategyExplanation = fmt.Sprintf("all %d pods might be deleted at once", replicas)
 	case "":
-		// RollingUpdate is the default and can be an empty string. If so, set the defaults and continue calculation.
-		defaultMaxUnavailable := intstr.FromInt32(1)
-		strategy = appsv1.StatefulSetUpdateStrategy{
-			Type: appsv1.RollingUpdateStatefulSetStrategyType,
-			RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
-				MaxUnavailable: &defaultMaxUnavailable,
-			},
-		}
+		// RollingUpdate is the default and can be an empty string. If so, continue the calculation with the defaults.
+		strategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
 
 		fallthrough
 	case appsv1.RollingUpdateStatefulSetStrategyType:
 		// RollingUpdate updates each Pod one at a time. It waits until an updated Pod is Running and Ready before continuing with the next pod.
 		// There is an alpha feature to support rollout of multiple pods at once with `.spec.updateStrategy.rollingUpdate.maxUnavailable`
-		var maxUnavailableValue intstr.IntOrString
+		maxUnavailableValue := opts.strategyDefaults().StatefulSet.MaxUnavailable
+
+		if strategy.RollingUpdate != nil && strategy.RollingUpdate.MaxUnavailable != nil {
+			if opts.KubernetesVersion.statefulSetMaxUnavailable() {
+				maxUnavailableValue = *strategy.RollingUpdate.MaxUnavailable
+			} else {
+				log.Warn().Str("name", s.Name).Msgf("kubernetes %s ignores the maxUnavailable of statefulsets, "+
+					"pods are updated one at a time", opts.KubernetesVersion)
 
-		if strategy.RollingUpdate == nil {
-			maxUnavailableValue = intstr.FromInt32(1)
-		} else {
-			maxUnavailableValue = *strategy.RollingUpdate.MaxUnavailable
+				maxUnavailableValue = intstr.FromInt32(1)
+			}
 		}
 
 		// docs say, that the absolute number is calculated by rounding up.
-		maxUnavailableInt, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailableValue, int(replicas), true)
-		if err != nil {
-			return nil, err
-		}
+		var err error
 
-		if maxUnavailableInt < math.MinInt32 || maxUnavailableInt > math.MaxInt32 {
-			return nil, errors.New("maxUnavailableInt out of int32 boundaries")
+		maxUnavailable, err = statefulSetMaxUnavailable(s.Name, maxUnavailableValue, replicas)
+		if err != nil {
+			return nil, fmt.Errorf("statefulset: %s: %w", s.Name, err)
 		}
 
-		maxUnavailable = int32(maxUnavailableInt)
+		strategyExplanation = fmt.Sprintf("maxUnavailable %s -> %d", maxUnavailableValue.String(), maxUnavailable)
 	}
 
-	podResources := calcPodResources(&s.Spec.Template.Spec)
+	podResources := annotatedPodResources(s.ObjectMeta, s.Spec.Template.ObjectMeta, &s.Spec.Template.Spec, opts)
 	rolloutResources := podResources.Containers.MulInt32(replicas - maxUnavailable).Add(podResources.MaxResources.MulInt32(maxUnavailable))
-	normalResources := podResources.Containers.MulInt32(replicas)
+	normalResources := podResources.Containers.MulInt32(normalReplicas)
 
 	resourceUsage := ResourceUsage{
 		NormalResources:  normalResources,
 		RolloutResources: rolloutResources,
+		Pod:              podResources,
 		Details: Details{
-			Version:     s.APIVersion,
-			Kind:        s.Kind,
-			Name:        s.Name,
-			Replicas:    replicas,
-			Strategy:    string(strategy.Type),
-			MaxReplicas: replicas,
+			Version:           s.APIVersion,
+			Kind:              s.Kind,
+			Namespace:         s.Namespace,
+			Name:              s.Name,
+			PriorityClassName: s.Spec.Template.Spec.PriorityClassName,
+			Replicas:          replicas,
+			ReplicasAssumed:   replicasAssumed,
+			ReplicasAnnotated: replicasAnnotated,
+			Strategy:          string(strategy.Type),
+			MaxReplicas:       replicas,
+			Autoscaler:        autoscaler,
+			NormalReplicas:    normalReplicas,
 		},
 	}
 
+	resourceUsage.explainReplicas(opts)
+	resourceUsage.explainf(opts, "%s: %s", strategy.Type, strategyExplanation)
+	resourceUsage.explainPod(opts, podResources)
+	resourceUsage.explainf(opts, "normal = containers * %d", normalReplicas)
+	resourceUsage.explainf(opts, "rollout = containers * (%d replicas - %d unavailable) + max * %d unavailable",
+		replicas, maxUnavailable, maxUnavailable)
+
+	// the claims of the volumeClaimTemplates are kept, when the statefulset is scaled down or rolled out, so normal and
+	// rollout both need the claims of the peak replicas
+	if len(s.Spec.VolumeClaimTemplates) > 0 {
+		claims := volumeClaimResources(s.Spec.VolumeClaimTemplates).MulInt32(replicas)
+		resourceUsage.NormalResources = resourceUsage.NormalResources.Add(claims)
+		resourceUsage.RolloutResources = resourceUsage.RolloutResources.Add(claims)
+
+		resourceUsage.explainf(opts, "volume claims: %d templates * %d replicas, storage %s",
+			len(s.Spec.VolumeClaimTemplates), replicas, claims.Storage.String())
+	}
+
+	if opts.Timeline {
+		timings := newPodTimings(&s.Spec.Template.Spec, s.Spec.MinReadySeconds, opts)
+		resourceUsage.Timeline = batchTimeline(podResources, replicas, maxUnavailable, timings)
+	}
+
 	return &resourceUsage, nil
 }
@@ -48,7 +48,7 @@ func TestStatefulSet(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			r := require.New(t)
 
-			usage, err := ResourceQuotaFromYaml([]byte(test.statefulset))
+			usage, err := ResourceQuotaFromYaml([]byte(test.statefulset), Options{})
 			r.NoError(err)
 			r.NotEmpty(usage)
 
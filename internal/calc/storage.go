@@ -0,0 +1,73 @@
+package calc
+
+import (
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// volumeClaimResources returns the storage requested by the claims and their number.
+func volumeClaimResources(claims []v1.PersistentVolumeClaim) Resources {
+	var r Resources
+
+	for i := range claims {
+		r.Storage.Add(*claims[i].Spec.Resources.Requests.Storage())
+	}
+
+	r.PersistentVolumeClaims = *resource.NewQuantity(int64(len(claims)), resource.DecimalSI)
+
+	return r
+}
+
+// calculates the storage a single standalone persistent volume claim requests. Claims don't roll out, so normal and
+// rollout are the same.
+func persistentVolumeClaim(claim v1.PersistentVolumeClaim, opts Options) *ResourceUsage {
+	claims := volumeClaimResources([]v1.PersistentVolumeClaim{claim})
+
+	resourceUsage := ResourceUsage{
+		NormalResources:  claims,
+		RolloutResources: claims,
+		Details: Details{
+			Version:   claim.APIVersion,
+			Kind:      claim.Kind,
+			Namespace: claim.Namespace,
+			Name:      claim.Name,
+		},
+	}
+
+	resourceUsage.explainf(opts, "storage: %s", claims.Storage.String())
+
+	return &resourceUsage
+}
+
+// totalQuantity sums a quantity of all usages like Total does.
+func totalQuantity(maxRollout int, usage []*ResourceUsage, quantity func(r Resources) resource.Quantity) resource.Quantity {
+	var total resource.Quantity
+
+	if maxRollout <= -1 {
+		for _, u := range usage {
+			total.Add(quantity(u.RolloutResources))
+		}
+
+		return total
+	}
+
+	diffs := make([]resource.Quantity, 0, len(usage))
+
+	for _, u := range usage {
+		normal, rollout := quantity(u.NormalResources), quantity(u.RolloutResources)
+		total.Add(normal)
+		diffs = append(diffs, diffQuantities(&rollout, &normal))
+	}
+
+	slices.SortFunc(diffs, func(a, b resource.Quantity) int {
+		return b.Cmp(a)
+	})
+
+	for i := 0; i < len(diffs) && i < maxRollout; i++ {
+		total.Add(diffs[i])
+	}
+
+	return total
+}
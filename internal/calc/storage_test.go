@@ -0,0 +1,105 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var volumeClaimStatefulSet = `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: database
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: database
+  serviceName: database
+  template:
+    metadata:
+      labels:
+        app: database
+    spec:
+      containers:
+      - name: database
+        image: database
+        resources:
+          requests:
+            cpu: 500m
+            memory: 1Gi
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      accessModes: ["ReadWriteOnce"]
+      resources:
+        requests:
+          storage: 10Gi
+  - metadata:
+      name: wal
+    spec:
+      accessModes: ["ReadWriteOnce"]
+      resources:
+        requests:
+          storage: 2Gi`
+
+var persistentVolumeClaimManifest = `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: uploads
+  namespace: shop
+spec:
+  accessModes: ["ReadWriteMany"]
+  resources:
+    requests:
+      storage: 50Gi`
+
+func TestStatefulSetVolumeClaims(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(volumeClaimStatefulSet), Options{})
+	r.NoError(err)
+
+	// the claims are kept during rollouts, both need the claims of all replicas
+	for _, resources := range []Resources{usage.NormalResources, usage.RolloutResources} {
+		AssertEqualQuantities(r, resource.MustParse("36Gi"), resources.Storage, "storage")
+		AssertEqualQuantities(r, resource.MustParse("6"), resources.PersistentVolumeClaims, "claims")
+	}
+
+	AssertEqualQuantities(r, resource.MustParse("1500m"), usage.NormalResources.CPUMin, "cpu request")
+}
+
+func TestPersistentVolumeClaim(t *testing.T) {
+	r := require.New(t)
+
+	usage, err := ResourceQuotaFromYaml([]byte(persistentVolumeClaimManifest), Options{})
+	r.NoError(err)
+
+	r.Equal("PersistentVolumeClaim", usage.Details.Kind)
+	r.Equal("shop", usage.Details.Namespace)
+	AssertEqualQuantities(r, resource.MustParse("50Gi"), usage.NormalResources.Storage, "storage")
+	AssertEqualQuantities(r, resource.MustParse("1"), usage.RolloutResources.PersistentVolumeClaims, "claims")
+	r.True(usage.NormalResources.CPUMin.IsZero())
+}
+
+func TestTotalStorage(t *testing.T) {
+	r := require.New(t)
+
+	statefulSet, err := ResourceQuotaFromYaml([]byte(volumeClaimStatefulSet), Options{})
+	r.NoError(err)
+
+	claim, err := ResourceQuotaFromYaml([]byte(persistentVolumeClaimManifest), Options{})
+	r.NoError(err)
+
+	total := Total(0, []*ResourceUsage{statefulSet, claim})
+	AssertEqualQuantities(r, resource.MustParse("86Gi"), total.Storage, "storage")
+	AssertEqualQuantities(r, resource.MustParse("7"), total.PersistentVolumeClaims, "claims")
+
+	quota := ResourceQuota("shop", "compute-resources", total)
+	AssertEqualQuantities(r, resource.MustParse("86Gi"), quota.Spec.Hard["requests.storage"], "quota storage")
+	AssertEqualQuantities(r, resource.MustParse("7"), quota.Spec.Hard["persistentvolumeclaims"], "quota claims")
+}
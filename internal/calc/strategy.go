@@ -0,0 +1,133 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// rollingUpdateBounds resolves maxSurge and maxUnavailable of a rolling update to absolute numbers of pods, the way
+// the deployment controller does. maxSurge is rounded up, maxUnavailable is rounded down.
+// Values the api server would reject are corrected with a warning, see normalizeIntOrPercent.
+func rollingUpdateBounds(name string, maxSurgeValue, maxUnavailableValue intstr.IntOrString, replicas int32) (maxSurge, maxUnavailable int32, err error) {
+	maxSurgeValue = normalizeIntOrPercent(name, "maxSurge", maxSurgeValue, false)
+	maxUnavailableValue = normalizeIntOrPercent(name, "maxUnavailable", maxUnavailableValue, true)
+
+	// the api server rejects this, such a rollout could never replace a single pod
+	if isZero(maxSurgeValue) && isZero(maxUnavailableValue) {
+		return 0, 0, fmt.Errorf("%w: maxUnavailable may not be 0 when maxSurge is 0", ErrInvalidStrategy)
+	}
+
+	maxSurge, err = scaledValue(maxSurgeValue, replicas, true)
+	if err != nil {
+		return 0, 0, fmt.Errorf("maxSurge: %w", err)
+	}
+
+	maxUnavailable, err = scaledValue(maxUnavailableValue, replicas, false)
+	if err != nil {
+		return 0, 0, fmt.Errorf("maxUnavailable: %w", err)
+	}
+
+	// percentages might both be rounded down to zero, the controller then allows one unavailable pod to make progress
+	if maxSurge == 0 && maxUnavailable == 0 {
+		maxUnavailable = 1
+	}
+
+	// there can't be more unavailable pods than replicas
+	maxUnavailable = min(maxUnavailable, replicas)
+
+	return maxSurge, maxUnavailable, nil
+}
+
+// statefulSetMaxUnavailable resolves maxUnavailable of a statefulset rolling update to an absolute number of pods,
+// rounded up. Values the api server would reject are corrected with a warning, see normalizeIntOrPercent.
+func statefulSetMaxUnavailable(name string, maxUnavailableValue intstr.IntOrString, replicas int32) (int32, error) {
+	maxUnavailableValue = normalizeIntOrPercent(name, "maxUnavailable", maxUnavailableValue, true)
+
+	if isZero(maxUnavailableValue) {
+		return 0, fmt.Errorf("%w: maxUnavailable must be greater than 0", ErrInvalidStrategy)
+	}
+
+	maxUnavailable, err := scaledValue(maxUnavailableValue, replicas, true)
+	if err != nil {
+		return 0, fmt.Errorf("maxUnavailable: %w", err)
+	}
+
+	return min(maxUnavailable, replicas), nil
+}
+
+// normalizeIntOrPercent corrects values the api server would reject and warns about them: integers given as strings
+// are read as integers, negative values as 0 and, if limitPercent is set, percentages above 100% as 100%.
+// Other invalid strings are left for the scaling to fail on.
+func normalizeIntOrPercent(name, field string, value intstr.IntOrString, limitPercent bool) intstr.IntOrString {
+	warn := func(format string, args ...any) {
+		log.Warn().Str("name", name).Str("field", field).Msgf(format, args...)
+	}
+
+	if value.Type == intstr.String && !strings.HasSuffix(value.StrVal, "%") {
+		number, err := strconv.Atoi(value.StrVal)
+		if err != nil {
+			return value
+		}
+
+		warn("%q is not a percentage, using it as the integer %d", value.StrVal, number)
+
+		value = intstr.FromInt(number)
+	}
+
+	if value.Type == intstr.Int {
+		if value.IntVal < 0 {
+			warn("negative value %d, using 0", value.IntVal)
+
+			return intstr.FromInt32(0)
+		}
+
+		return value
+	}
+
+	percent, err := strconv.Atoi(strings.TrimSuffix(value.StrVal, "%"))
+	if err != nil {
+		return value
+	}
+
+	switch {
+	case percent < 0:
+		warn("negative percentage %s, using 0%%", value.StrVal)
+
+		return intstr.FromString("0%")
+	case limitPercent && percent > 100:
+		warn("percentage %s above 100%%, using 100%%", value.StrVal)
+
+		return intstr.FromString("100%")
+	default:
+		return value
+	}
+}
+
+// scaledValue returns the absolute value of an int or a percentage of replicas.
+func scaledValue(value intstr.IntOrString, replicas int32, roundUp bool) (int32, error) {
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(&value, int(replicas), roundUp)
+	if err != nil {
+		return 0, err
+	}
+
+	if scaled < math.MinInt32 || scaled > math.MaxInt32 {
+		return 0, errors.New("value out of int32 boundaries")
+	}
+
+	return int32(scaled), nil
+}
+
+// isZero reports whether the value is 0 or 0%, regardless of any replicas.
+func isZero(value intstr.IntOrString) bool {
+	// scaling a percentage of 100 returns the percentage itself
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(&value, 100, true)
+
+	return err == nil && scaled == 0
+}
@@ -0,0 +1,126 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestRollingUpdateBounds(t *testing.T) {
+	var tests = []struct {
+		name           string
+		maxSurge       intstr.IntOrString
+		maxUnavailable intstr.IntOrString
+		replicas       int32
+		surge          int32
+		unavailable    int32
+		invalid        bool
+	}{
+		{
+			name:           "percentages",
+			maxSurge:       intstr.FromString("25%"),
+			maxUnavailable: intstr.FromString("25%"),
+			replicas:       10,
+			surge:          3,
+			unavailable:    2,
+		},
+		{
+			name:           "absolute values",
+			maxSurge:       intstr.FromInt32(2),
+			maxUnavailable: intstr.FromInt32(0),
+			replicas:       10,
+			surge:          2,
+			unavailable:    0,
+		},
+		{
+			name:           "both rounded down to zero",
+			maxSurge:       intstr.FromString("0%"),
+			maxUnavailable: intstr.FromString("10%"),
+			replicas:       5,
+			surge:          0,
+			unavailable:    1,
+		},
+		{
+			name:           "percentages above 100%",
+			maxSurge:       intstr.FromString("150%"),
+			maxUnavailable: intstr.FromString("150%"),
+			replicas:       4,
+			surge:          6,
+			unavailable:    4,
+		},
+		{
+			name:           "negative values",
+			maxSurge:       intstr.FromInt32(-1),
+			maxUnavailable: intstr.FromString("-10%"),
+			replicas:       4,
+			invalid:        true,
+		},
+		{
+			name:           "string integers",
+			maxSurge:       intstr.FromString("2"),
+			maxUnavailable: intstr.FromString("1"),
+			replicas:       10,
+			surge:          2,
+			unavailable:    1,
+		},
+		{
+			name:           "more unavailable than replicas",
+			maxSurge:       intstr.FromInt32(1),
+			maxUnavailable: intstr.FromInt32(5),
+			replicas:       3,
+			surge:          1,
+			unavailable:    3,
+		},
+		{
+			name:           "both zero",
+			maxSurge:       intstr.FromInt32(0),
+			maxUnavailable: intstr.FromInt32(0),
+			replicas:       10,
+			invalid:        true,
+		},
+		{
+			name:           "both zero percent",
+			maxSurge:       intstr.FromString("0%"),
+			maxUnavailable: intstr.FromInt32(0),
+			replicas:       10,
+			invalid:        true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			surge, unavailable, err := rollingUpdateBounds("test", test.maxSurge, test.maxUnavailable, test.replicas)
+			if test.invalid {
+				r.ErrorIs(err, ErrInvalidStrategy)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(test.surge, surge, "max surge")
+			r.Equal(test.unavailable, unavailable, "max unavailable")
+		})
+	}
+}
+
+func TestStatefulSetMaxUnavailable(t *testing.T) {
+	r := require.New(t)
+
+	maxUnavailable, err := statefulSetMaxUnavailable("test", intstr.FromString("10%"), 5)
+	r.NoError(err)
+	r.Equal(int32(1), maxUnavailable)
+
+	_, err = statefulSetMaxUnavailable("test", intstr.FromInt32(0), 5)
+	r.ErrorIs(err, ErrInvalidStrategy)
+}
+
+func TestInvalidStrategy(t *testing.T) {
+	r := require.New(t)
+
+	_, err := ResourceQuotaFromYaml([]byte(noProgressDeployment), Options{})
+	r.ErrorIs(err, ErrInvalidStrategy)
+	r.ErrorContains(err, "stuck")
+}
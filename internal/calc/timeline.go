@@ -0,0 +1,241 @@
+package calc
+
+import (
+	"math"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// maxTimelineEvents limits the simulation of a rollout, in case the rollout never finishes.
+	maxTimelineEvents = 100000
+	// defaultTerminationGracePeriodSeconds is applied by the api server, if a pod doesn't specify a grace period.
+	defaultTerminationGracePeriodSeconds = 30
+)
+
+// TimelinePoint is the resource usage of a k8s resource from Second onwards during a simulated rollout.
+type TimelinePoint struct {
+	Second    int64
+	Resources Resources
+}
+
+// podTimings contains the durations, which determine how a rollout of a pod template proceeds over time.
+type podTimings struct {
+	readySeconds       int64 // seconds until a new pod is ready
+	terminatingSeconds int64 // seconds an old pod still occupies resources after it was scaled down
+}
+
+// newPodTimings estimates the timings of a pod template. A new pod is ready after the initial delays of its
+// startup and readiness probes plus minReadySeconds. It needs at least one second, which also guarantees
+// the progress of the simulation. Old pods only keep terminating if a termination overlap is configured.
+func newPodTimings(podSpec *v1.PodSpec, minReadySeconds int32, opts Options) podTimings {
+	var probeDelay int32
+
+	for i := range podSpec.Containers {
+		var delay int32
+
+		container := podSpec.Containers[i]
+
+		// readiness probes only start after the startup probe succeeded
+		if container.StartupProbe != nil {
+			delay += container.StartupProbe.InitialDelaySeconds
+		}
+
+		if container.ReadinessProbe != nil {
+			delay += container.ReadinessProbe.InitialDelaySeconds
+		}
+
+		probeDelay = max(probeDelay, delay)
+	}
+
+	gracePeriod := int64(defaultTerminationGracePeriodSeconds)
+	if podSpec.TerminationGracePeriodSeconds != nil {
+		gracePeriod = *podSpec.TerminationGracePeriodSeconds
+	}
+
+	terminatingSeconds := int64(math.Ceil(float64(gracePeriod) * opts.TerminationOverlap))
+	if opts.TerminationOverlapByGracePeriod {
+		terminatingSeconds = gracePeriod
+	}
+
+	return podTimings{
+		readySeconds:       max(1, int64(probeDelay)+int64(minReadySeconds)),
+		terminatingSeconds: terminatingSeconds,
+	}
+}
+
+// rollingUpdateTimeline simulates a rolling update the way the deployment controller performs it: old pods are
+// scaled down as long as maxUnavailable allows it and new pods are created as long as maxSurge allows it.
+// Starting pods are accounted with their init-vs-main maximum, running and terminating pods with their containers.
+func rollingUpdateTimeline(pod *PodResources, replicas, maxSurge, maxUnavailable int32, timings podTimings) []TimelinePoint {
+	var (
+		now             int64
+		oldPods         = replicas
+		readyPods       int32
+		startingPods    []int64 // seconds at which the starting pods are ready
+		terminatingPods []int64 // seconds at which the terminating pods are gone
+		timeline        []TimelinePoint
+	)
+
+	minAvailable := replicas - maxUnavailable
+
+	for range maxTimelineEvents {
+		startingPods = slices.DeleteFunc(startingPods, func(ready int64) bool {
+			if ready <= now {
+				readyPods++
+
+				return true
+			}
+
+			return false
+		})
+		terminatingPods = slices.DeleteFunc(terminatingPods, func(gone int64) bool { return gone <= now })
+
+		if scaleDown := min(oldPods, oldPods+readyPods-minAvailable); scaleDown > 0 {
+			oldPods -= scaleDown
+
+			if timings.terminatingSeconds > 0 {
+				for range scaleDown {
+					terminatingPods = append(terminatingPods, now+timings.terminatingSeconds)
+				}
+			}
+		}
+
+		newPods := readyPods + int32(len(startingPods))
+		for range min(replicas-newPods, replicas+maxSurge-oldPods-newPods) {
+			startingPods = append(startingPods, now+timings.readySeconds)
+		}
+
+		timeline = append(timeline, TimelinePoint{
+			Second: now,
+			Resources: pod.Containers.MulInt32(oldPods + readyPods + int32(len(terminatingPods))).
+				Add(pod.MaxResources.MulInt32(int32(len(startingPods)))),
+		})
+
+		next := slices.Concat(startingPods, terminatingPods)
+		if len(next) == 0 {
+			// either the rollout is done or it can't make any progress
+			break
+		}
+
+		now = slices.Min(next)
+	}
+
+	return timeline
+}
+
+// batchTimeline simulates a rollout which replaces the pods in batches: the pods of a batch are terminated
+// first and replaced afterwards. This is how StatefulSets roll out, and with a single batch of all replicas
+// how a Recreate rollout works.
+func batchTimeline(pod *PodResources, replicas, batchSize int32, timings podTimings) []TimelinePoint {
+	var (
+		now      int64
+		timeline []TimelinePoint
+	)
+
+	// a rollout always replaces at least one pod at a time
+	batchSize = max(batchSize, 1)
+
+	for updated := int32(0); updated < replicas; updated += batchSize {
+		batch := min(batchSize, replicas-updated)
+
+		if timings.terminatingSeconds > 0 {
+			timeline = append(timeline, TimelinePoint{Second: now, Resources: pod.Containers.MulInt32(replicas)})
+			now += timings.terminatingSeconds
+		}
+
+		timeline = append(timeline, TimelinePoint{
+			Second:    now,
+			Resources: pod.Containers.MulInt32(replicas - batch).Add(pod.MaxResources.MulInt32(batch)),
+		})
+		now += timings.readySeconds
+	}
+
+	return append(timeline, TimelinePoint{Second: now, Resources: pod.Containers.MulInt32(replicas)})
+}
+
+// staticTimeline is used for resources without a dedicated rollout simulation. They need their rollout resources
+// until their pods are ready and their normal resources afterwards.
+func staticTimeline(usage *ResourceUsage, timings podTimings) []TimelinePoint {
+	return []TimelinePoint{
+		{Second: 0, Resources: usage.RolloutResources},
+		{Second: timings.readySeconds, Resources: usage.NormalResources},
+	}
+}
+
+// addToTimeline adds resources to every point of a timeline except the last one, which is the state after the rollout.
+func addToTimeline(timeline []TimelinePoint, resources Resources) {
+	for i := 0; i < len(timeline)-1; i++ {
+		timeline[i].Resources = timeline[i].Resources.Add(resources)
+	}
+}
+
+// RolloutSeconds returns how long the simulated rollout takes. It is zero if there is no timeline.
+func (u *ResourceUsage) RolloutSeconds() int64 {
+	if len(u.Timeline) == 0 {
+		return 0
+	}
+
+	return u.Timeline[len(u.Timeline)-1].Second
+}
+
+// TimelinePeak merges the simulated rollout timelines of all usages, assuming that all rollouts start at the same time,
+// and returns the highest usage of each resource quantity over the whole timeline. Usages without a timeline
+// contribute their normal resources all the time.
+func TimelinePeak(usage []*ResourceUsage) Resources {
+	var seconds []int64
+
+	for _, u := range usage {
+		for _, point := range u.Timeline {
+			seconds = append(seconds, point.Second)
+		}
+	}
+
+	slices.Sort(seconds)
+	seconds = slices.Compact(seconds)
+
+	if len(seconds) == 0 {
+		seconds = []int64{0}
+	}
+
+	var peak Resources
+
+	// the current timeline point of each usage
+	positions := make([]int, len(usage))
+
+	for _, second := range seconds {
+		var sum Resources
+
+		for i, u := range usage {
+			for positions[i] < len(u.Timeline)-1 && u.Timeline[positions[i]+1].Second <= second {
+				positions[i]++
+			}
+
+			if len(u.Timeline) == 0 || u.Timeline[positions[i]].Second > second {
+				sum = sum.Add(u.NormalResources)
+			} else {
+				sum = sum.Add(u.Timeline[positions[i]].Resources)
+			}
+		}
+
+		peak = maxResources(peak, sum)
+	}
+
+	return peak
+}
+
+func maxResources(r1, r2 Resources) Resources {
+	return Resources{
+		CPUMin:                 maxQuantity(r1.CPUMin, r2.CPUMin),
+		CPUMax:                 maxQuantity(r1.CPUMax, r2.CPUMax),
+		MemoryMin:              maxQuantity(r1.MemoryMin, r2.MemoryMin),
+		MemoryMax:              maxQuantity(r1.MemoryMax, r2.MemoryMax),
+		EphemeralStorageMin:    maxQuantity(r1.EphemeralStorageMin, r2.EphemeralStorageMin),
+		EphemeralStorageMax:    maxQuantity(r1.EphemeralStorageMax, r2.EphemeralStorageMax),
+		Extended:               combineExtended(r1.Extended, r2.Extended, maxQuantity),
+		Storage:                maxQuantity(r1.Storage, r2.Storage),
+		PersistentVolumeClaims: maxQuantity(r1.PersistentVolumeClaims, r2.PersistentVolumeClaims),
+		Pods:                   maxQuantity(r1.Pods, r2.Pods),
+	}
+}
@@ -0,0 +1,156 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var slowDeployment = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: slow
+  name: slow
+spec:
+  minReadySeconds: 5
+  replicas: 4
+  selector:
+    matchLabels:
+      app: slow
+  strategy:
+    rollingUpdate:
+      maxSurge: 1
+      maxUnavailable: 0
+    type: RollingUpdate
+  template:
+    metadata:
+      labels:
+        app: slow
+    spec:
+      containers:
+        - image: myapp:v1.0.7
+          name: slow
+          readinessProbe:
+            initialDelaySeconds: 10
+            httpGet:
+              path: /ready
+              port: 8080
+          resources:
+            limits:
+              cpu: '500m'
+              memory: 1Gi
+            requests:
+              cpu: '250m'
+              memory: 512Mi
+      terminationGracePeriodSeconds: 30`
+
+func TestTimeline(t *testing.T) {
+	var tests = []struct {
+		name           string
+		resource       string
+		opts           Options
+		cpuMin         resource.Quantity
+		memoryMax      resource.Quantity
+		rolloutSeconds int64
+	}{
+		{
+			name:           "rolling update without termination overlap peaks like the static calculation",
+			resource:       normalDeployment,
+			opts:           Options{Timeline: true},
+			cpuMin:         resource.MustParse("3250m"),
+			memoryMax:      resource.MustParse("52Gi"),
+			rolloutSeconds: 2,
+		},
+		{
+			name:           "rolling update with terminating pods piling up",
+			resource:       normalDeployment,
+			opts:           Options{Timeline: true, TerminationOverlapByGracePeriod: true},
+			cpuMin:         resource.MustParse("5"),
+			memoryMax:      resource.MustParse("80Gi"),
+			rolloutSeconds: 32,
+		},
+		{
+			name:           "slow rolling update with probes and minReadySeconds",
+			resource:       slowDeployment,
+			opts:           Options{Timeline: true, TerminationOverlapByGracePeriod: true},
+			cpuMin:         resource.MustParse("1750m"),
+			memoryMax:      resource.MustParse("7Gi"),
+			rolloutSeconds: 90,
+		},
+		{
+			name:           "recreate",
+			resource:       recrateDeployment,
+			opts:           Options{Timeline: true, TerminationOverlap: 0.5},
+			cpuMin:         resource.MustParse("2500m"),
+			memoryMax:      resource.MustParse("40Gi"),
+			rolloutSeconds: 16,
+		},
+		{
+			name:           "statefulset",
+			resource:       normalStatefulSet,
+			opts:           Options{Timeline: true},
+			cpuMin:         resource.MustParse("500m"),
+			memoryMax:      resource.MustParse("8Gi"),
+			rolloutSeconds: 2,
+		},
+		{
+			name:           "pod",
+			resource:       initContainerPod,
+			opts:           Options{Timeline: true},
+			cpuMin:         resource.MustParse("250m"),
+			memoryMax:      resource.MustParse("4Gi"),
+			rolloutSeconds: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(test.resource), test.opts)
+			r.NoError(err)
+			r.NotEmpty(usage.Timeline)
+
+			peak := TimelinePeak([]*ResourceUsage{usage})
+			AssertEqualQuantities(r, test.cpuMin, peak.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.memoryMax, peak.MemoryMax, "memory limit value")
+			r.Equal(test.rolloutSeconds, usage.RolloutSeconds(), "rollout seconds")
+		})
+	}
+}
+
+func TestTimelinePeak(t *testing.T) {
+	r := require.New(t)
+
+	cpu := func(value string) Resources {
+		return Resources{CPUMin: resource.MustParse(value)}
+	}
+
+	usage := []*ResourceUsage{
+		{
+			NormalResources: cpu("1"),
+			Timeline: []TimelinePoint{
+				{Second: 0, Resources: cpu("2")},
+				{Second: 10, Resources: cpu("1")},
+			},
+		},
+		{
+			NormalResources: cpu("1"),
+			Timeline: []TimelinePoint{
+				{Second: 0, Resources: cpu("1")},
+				{Second: 10, Resources: cpu("3")},
+				{Second: 20, Resources: cpu("1")},
+			},
+		},
+		{
+			// no timeline, e.g. a deployment with zero replicas
+			NormalResources: cpu("500m"),
+		},
+	}
+
+	peak := TimelinePeak(usage)
+	AssertEqualQuantities(r, resource.MustParse("4500m"), peak.CPUMin, "cpu request value")
+}
@@ -0,0 +1,83 @@
+package calc
+
+import (
+	"math"
+	"time"
+)
+
+// TrendPoint is a value recorded at a point in time.
+type TrendPoint struct {
+	Time  time.Time
+	Value int64
+}
+
+// Trend is a linear trend of recorded values, fitted by least squares.
+type Trend struct {
+	// Start is the time of the first recorded value, Intercept the value of the trend at that time.
+	Start     time.Time
+	Intercept float64
+	// Slope is the change of the value per second.
+	Slope float64
+}
+
+// FitTrend fits a linear trend through the points. Fitting a trend needs at least two points at different times.
+func FitTrend(points []TrendPoint) (Trend, bool) {
+	if len(points) < 2 {
+		return Trend{}, false
+	}
+
+	start := points[0].Time
+	for _, point := range points {
+		if point.Time.Before(start) {
+			start = point.Time
+		}
+	}
+
+	var sumX, sumY, sumXX, sumXY float64
+
+	for _, point := range points {
+		x, y := point.Time.Sub(start).Seconds(), float64(point.Value)
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumXY += x * y
+	}
+
+	n := float64(len(points))
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		// all points were recorded at the same time
+		return Trend{}, false
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+
+	return Trend{
+		Start:     start,
+		Intercept: (sumY - slope*sumX) / n,
+		Slope:     slope,
+	}, true
+}
+
+// At returns the value of the trend at the given time.
+func (t Trend) At(at time.Time) float64 {
+	return t.Intercept + t.Slope*at.Sub(t.Start).Seconds()
+}
+
+// Per returns the change of the value over the given duration.
+func (t Trend) Per(d time.Duration) int64 {
+	return int64(math.Round(t.Slope * d.Seconds()))
+}
+
+// Reaches returns the time at which the trend reaches the value. A trend which doesn't grow never reaches a value
+// above it.
+func (t Trend) Reaches(value int64) (time.Time, bool) {
+	if t.Slope <= 0 {
+		return time.Time{}, false
+	}
+
+	seconds := (float64(value) - t.Intercept) / t.Slope
+
+	return t.Start.Add(time.Duration(seconds * float64(time.Second))), true
+}
@@ -0,0 +1,89 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitTrend(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	var tests = []struct {
+		name      string
+		points    []TrendPoint
+		ok        bool
+		perDay    int64
+		reachesOk bool
+		reaches   time.Time
+	}{
+		{
+			name: "growing",
+			points: []TrendPoint{
+				{Time: start, Value: 1000},
+				{Time: start.Add(day), Value: 1100},
+				{Time: start.Add(2 * day), Value: 1200},
+			},
+			ok:        true,
+			perDay:    100,
+			reachesOk: true,
+			reaches:   start.Add(10 * day),
+		},
+		{
+			name: "unordered and noisy",
+			points: []TrendPoint{
+				{Time: start.Add(2 * day), Value: 1250},
+				{Time: start, Value: 1000},
+				{Time: start.Add(day), Value: 1050},
+			},
+			ok:        true,
+			perDay:    125,
+			reachesOk: true,
+			reaches:   start.Add(8*day + 4*time.Hour + 48*time.Minute),
+		},
+		{
+			name: "shrinking",
+			points: []TrendPoint{
+				{Time: start, Value: 2000},
+				{Time: start.Add(day), Value: 1000},
+			},
+			ok:     true,
+			perDay: -1000,
+		},
+		{
+			name:   "single point",
+			points: []TrendPoint{{Time: start, Value: 1000}},
+		},
+		{
+			name: "same time",
+			points: []TrendPoint{
+				{Time: start, Value: 1000},
+				{Time: start, Value: 2000},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			trend, ok := FitTrend(test.points)
+			r.Equal(test.ok, ok)
+
+			if !ok {
+				return
+			}
+
+			r.Equal(test.perDay, trend.Per(day))
+
+			reaches, ok := trend.Reaches(2000)
+			r.Equal(test.reachesOk, ok)
+
+			if ok {
+				r.WithinDuration(test.reaches, reaches, time.Second)
+			}
+		})
+	}
+}
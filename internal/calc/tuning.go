@@ -0,0 +1,140 @@
+package calc
+
+import (
+	"cmp"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// RolloutSuggestion is a rolling update of a workload, whose rollout needs less resources than the configured one.
+type RolloutSuggestion struct {
+	Usage *ResourceUsage
+	// MaxSurge and MaxUnavailable are the suggested values, the configured ones are in Usage.Details.RollingUpdate.
+	MaxSurge       int32
+	MaxUnavailable int32
+}
+
+// SuggestRollingUpdates suggests lower maxSurge and maxUnavailable values for the workloads with the costliest
+// rollouts, one step at a time, until the total fits into the budget. maxSurge is lowered first, as a surging pod
+// costs a whole pod, while an unavailable pod only costs its init containers. It returns the suggestions and whether
+// the total, at the target utilization, fits into the budget with them.
+func SuggestRollingUpdates(
+	usage []*ResourceUsage, maxRollouts int, utilization TargetUtilization, budget Budget,
+) ([]RolloutSuggestion, bool) {
+	tuned := make([]*ResourceUsage, len(usage))
+	copy(tuned, usage)
+
+	fits := false
+
+	for {
+		exceeded := budget.Exceeded(Total(maxRollouts, tuned).AtUtilization(utilization))
+		if len(exceeded) == 0 {
+			fits = true
+
+			break
+		}
+
+		// lower the rolling update of the workload, whose rollout costs the most of the first exceeded resource
+		best := -1
+
+		var bestCost resource.Quantity
+
+		for i, u := range tuned {
+			if _, _, ok := rollingUpdateStep(u); !ok {
+				continue
+			}
+
+			cost := u.RolloutCost().quotaResources()[exceeded[0]]
+			if best == -1 || cost.Cmp(bestCost) > 0 {
+				best, bestCost = i, cost
+			}
+		}
+
+		if best == -1 || bestCost.Sign() <= 0 {
+			break
+		}
+
+		surge, unavailable, _ := rollingUpdateStep(tuned[best])
+		tuned[best] = tuned[best].withRollingUpdate(surge, unavailable)
+	}
+
+	var suggestions []RolloutSuggestion
+
+	for i, u := range tuned {
+		if u != usage[i] {
+			suggestions = append(suggestions, RolloutSuggestion{
+				Usage:          usage[i],
+				MaxSurge:       u.Details.RollingUpdate.MaxSurge,
+				MaxUnavailable: u.Details.RollingUpdate.MaxUnavailable,
+			})
+		}
+	}
+
+	return suggestions, fits
+}
+
+// RolloutCost returns the resources a rollout of the resource needs in addition to its normal resources.
+func (u *ResourceUsage) RolloutCost() Resources {
+	return u.RolloutResources.Add(u.NormalResources.MulInt32(-1))
+}
+
+// rollingUpdateStep returns the next lower maxSurge and maxUnavailable of a rolling update. Both can't be zero.
+func rollingUpdateStep(u *ResourceUsage) (int32, int32, bool) {
+	rollingUpdate := u.Details.RollingUpdate
+	if rollingUpdate == nil || u.Pod == nil {
+		return 0, 0, false
+	}
+
+	surge, unavailable := rollingUpdate.MaxSurge, rollingUpdate.MaxUnavailable
+
+	switch {
+	case surge > 1 || (surge == 1 && unavailable > 0):
+		return surge - 1, unavailable, true
+	case unavailable > 1:
+		return surge, unavailable - 1, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// withRollingUpdate returns a copy of the resource rolled out with the given maxSurge and maxUnavailable. The
+// terminating pods are assumed to shrink proportionally to the pods scaled down by a step, maxSurge + maxUnavailable.
+func (u *ResourceUsage) withRollingUpdate(surge, unavailable int32) *ResourceUsage {
+	current := u.Details.RollingUpdate
+
+	var terminating int32
+	if step := current.MaxSurge + current.MaxUnavailable; step > 0 {
+		terminating = (current.TerminatingPods*(surge+unavailable) + step - 1) / step
+	}
+
+	tuned := *u
+	tuned.Details.RollingUpdate = &RollingUpdate{MaxSurge: surge, MaxUnavailable: unavailable, TerminatingPods: terminating}
+	tuned.Details.MaxReplicas = u.Details.Replicas + surge + terminating
+
+	// rollout = containers * (replicas - unavailable + terminating) + max * (surge + unavailable) + strategy resources
+	tuned.RolloutResources = u.RolloutResources.
+		Add(u.Pod.Containers.MulInt32(current.MaxUnavailable - unavailable + terminating - current.TerminatingPods)).
+		Add(u.Pod.MaxResources.MulInt32(surge + unavailable - current.MaxSurge - current.MaxUnavailable))
+
+	return &tuned
+}
+
+// RankByRolloutCost returns the resources sorted by the cost of their rollout, the costliest first. The cpu requests
+// are compared first, then the memory requests, the cpu limits and the memory limits.
+func RankByRolloutCost(usage []*ResourceUsage) []*ResourceUsage {
+	ranked := slices.Clone(usage)
+
+	slices.SortStableFunc(ranked, func(a, b *ResourceUsage) int {
+		costA, costB := a.RolloutCost(), b.RolloutCost()
+
+		return cmp.Or(
+			costB.CPUMin.Cmp(costA.CPUMin),
+			costB.MemoryMin.Cmp(costA.MemoryMin),
+			costB.CPUMax.Cmp(costA.CPUMax),
+			costB.MemoryMax.Cmp(costA.MemoryMax),
+		)
+	})
+
+	return ranked
+}
@@ -0,0 +1,106 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestSuggestRollingUpdates(t *testing.T) {
+	// 10 replicas with maxSurge 25% -> 3 and maxUnavailable 25% -> 2, so the rollout needs 13 * 250m cpu
+	usage, err := ResourceQuotaFromYaml([]byte(normalDeployment), Options{})
+	require.NoError(t, err)
+
+	recreate, err := ResourceQuotaFromYaml([]byte(recrateDeployment), Options{})
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name           string
+		cpu            string
+		fits           bool
+		suggested      bool
+		maxSurge       int32
+		maxUnavailable int32
+		maxReplicas    int32
+	}{
+		{name: "fits already", cpu: "3250m", fits: true},
+		{name: "lower surge", cpu: "3", fits: true, suggested: true, maxSurge: 2, maxUnavailable: 2, maxReplicas: 12},
+		{name: "no surge", cpu: "2500m", fits: true, suggested: true, maxSurge: 0, maxUnavailable: 2, maxReplicas: 10},
+		// without init containers, unavailable pods don't cost anything, so lowering them doesn't help
+		{name: "impossible", cpu: "2", suggested: true, maxSurge: 0, maxUnavailable: 2, maxReplicas: 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			budget := Budget{v1.ResourceRequestsCPU: resource.MustParse(test.cpu)}
+
+			suggestions, fits := SuggestRollingUpdates([]*ResourceUsage{usage}, -1, TargetUtilization{}, budget)
+			r.Equal(test.fits, fits)
+
+			if !test.suggested {
+				r.Empty(suggestions)
+
+				return
+			}
+
+			r.Len(suggestions, 1)
+			r.Same(usage, suggestions[0].Usage)
+			r.Equal(test.maxSurge, suggestions[0].MaxSurge)
+			r.Equal(test.maxUnavailable, suggestions[0].MaxUnavailable)
+
+			tuned := usage.withRollingUpdate(test.maxSurge, test.maxUnavailable)
+			r.Equal(test.maxReplicas, tuned.Details.MaxReplicas)
+		})
+	}
+
+	// recreate deployments have no rolling update to tune
+	budget := Budget{v1.ResourceRequestsCPU: resource.MustParse("1")}
+	_, fits := SuggestRollingUpdates([]*ResourceUsage{recreate}, -1, TargetUtilization{}, budget)
+	require.False(t, fits)
+}
+
+func TestWithRollingUpdate(t *testing.T) {
+	r := require.New(t)
+
+	// the tuned rollout matches the calculation of the deployment with the tuned values
+	usage, err := ResourceQuotaFromYaml([]byte(normalDeployment), Options{TerminationOverlap: 1})
+	r.NoError(err)
+	r.Equal(&RollingUpdate{MaxSurge: 3, MaxUnavailable: 2, TerminatingPods: 5}, usage.Details.RollingUpdate)
+
+	tuned := usage.withRollingUpdate(3, 1)
+
+	// 10 replicas: containers * (10 - 1 unavailable + 4 terminating) + max * (3 + 1)
+	AssertEqualQuantities(r, resource.MustParse("4250m"), tuned.RolloutResources.CPUMin, "cpu request value")
+	AssertEqualQuantities(r, resource.MustParse("34Gi"), tuned.RolloutResources.MemoryMin, "memory request value")
+	r.Equal(&RollingUpdate{MaxSurge: 3, MaxUnavailable: 1, TerminatingPods: 4}, tuned.Details.RollingUpdate)
+	r.Equal(int32(17), tuned.Details.MaxReplicas)
+
+	// the original isn't changed
+	r.Equal(int32(2), usage.Details.RollingUpdate.MaxUnavailable)
+}
+
+func TestRankByRolloutCost(t *testing.T) {
+	r := require.New(t)
+
+	usage := func(name, normal, rollout, memory string) *ResourceUsage {
+		return &ResourceUsage{
+			NormalResources:  Resources{CPUMin: resource.MustParse(normal)},
+			RolloutResources: Resources{CPUMin: resource.MustParse(rollout), MemoryMin: resource.MustParse(memory)},
+			Details:          Details{Name: name},
+		}
+	}
+
+	cheap := usage("cheap", "4", "4", "0")
+	costly := usage("costly", "1", "3", "0")
+	tied := usage("tied", "2", "4", "1Gi")
+
+	ranked := RankByRolloutCost([]*ResourceUsage{cheap, costly, tied})
+	r.Equal([]*ResourceUsage{tied, costly, cheap}, ranked)
+
+	AssertEqualQuantities(r, resource.MustParse("2"), costly.RolloutCost().CPUMin, "cpu request value")
+	AssertEqualQuantities(r, resource.MustParse("0"), cheap.RolloutCost().CPUMin, "cpu request value")
+}
@@ -0,0 +1,58 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TargetUtilization is the share of the quota the requests should use in the steady state, e.g. 0.6 for 60%.
+// A zero value leaves the requests of that resource as they are.
+type TargetUtilization struct {
+	CPU    float64
+	Memory float64
+}
+
+// ParseTargetUtilization parses target utilizations in the form cpu=0.6,memory=0.8. Each ratio has to be
+// greater than 0 and at most 1.
+func ParseTargetUtilization(value string) (TargetUtilization, error) {
+	var t TargetUtilization
+
+	for _, pair := range strings.Split(value, ",") {
+		name, ratioValue, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			return t, fmt.Errorf("invalid target utilization %q, expected <resource>=<ratio>", pair)
+		}
+
+		ratio, err := strconv.ParseFloat(ratioValue, 64)
+		if err != nil || ratio <= 0 || ratio > 1 {
+			return t, fmt.Errorf("invalid target utilization ratio %q of %s, must be greater than 0 and at most 1", ratioValue, name)
+		}
+
+		switch name {
+		case "cpu":
+			t.CPU = ratio
+		case "memory":
+			t.Memory = ratio
+		default:
+			return t, fmt.Errorf("unknown resource %q in target utilization, supported are cpu and memory", name)
+		}
+	}
+
+	return t, nil
+}
+
+// AtUtilization returns the resources with their requests inflated, so the original requests use the target
+// utilization of them. Limits aren't changed.
+func (r Resources) AtUtilization(t TargetUtilization) Resources {
+	if t.CPU > 0 {
+		r.CPUMin.SetMilli(int64(math.Ceil(float64(r.CPUMin.MilliValue()) / t.CPU)))
+	}
+
+	if t.Memory > 0 {
+		r.MemoryMin.Set(int64(math.Ceil(float64(r.MemoryMin.Value()) / t.Memory)))
+	}
+
+	return r
+}
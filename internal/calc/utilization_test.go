@@ -0,0 +1,45 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseTargetUtilization(t *testing.T) {
+	r := require.New(t)
+
+	utilization, err := ParseTargetUtilization("cpu=0.6, memory=0.8")
+	r.NoError(err)
+	r.Equal(TargetUtilization{CPU: 0.6, Memory: 0.8}, utilization)
+
+	utilization, err = ParseTargetUtilization("memory=1")
+	r.NoError(err)
+	r.Equal(TargetUtilization{Memory: 1}, utilization)
+
+	for _, invalid := range []string{"cpu", "cpu=0", "cpu=1.5", "cpu=much", "gpu=0.5"} {
+		_, err = ParseTargetUtilization(invalid)
+		r.Error(err, invalid)
+	}
+}
+
+func TestAtUtilization(t *testing.T) {
+	r := require.New(t)
+
+	resources := Resources{
+		CPUMin:    resource.MustParse("3"),
+		CPUMax:    resource.MustParse("6"),
+		MemoryMin: resource.MustParse("8Gi"),
+		MemoryMax: resource.MustParse("16Gi"),
+	}
+
+	inflated := resources.AtUtilization(TargetUtilization{CPU: 0.6, Memory: 0.8})
+	AssertEqualQuantities(r, resource.MustParse("5"), inflated.CPUMin, "cpu request value")
+	AssertEqualQuantities(r, resource.MustParse("6"), inflated.CPUMax, "cpu limit value")
+	AssertEqualQuantities(r, resource.MustParse("10Gi"), inflated.MemoryMin, "memory request value")
+	AssertEqualQuantities(r, resource.MustParse("16Gi"), inflated.MemoryMax, "memory limit value")
+
+	// without a target the requests stay as they are
+	AssertEqualQuantities(r, resource.MustParse("3"), resources.AtUtilization(TargetUtilization{}).CPUMin, "cpu request value")
+}
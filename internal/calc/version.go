@@ -0,0 +1,83 @@
+package calc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+)
+
+// KubernetesVersion is the version of the target cluster. Features the version doesn't have are calculated like the
+// cluster would treat them. The zero value stands for the latest version, which has all features.
+type KubernetesVersion struct {
+	Major int
+	Minor int
+}
+
+// ParseKubernetesVersion parses a version like 1.27, v1.27 or 1.27.3. The patch version is ignored.
+func ParseKubernetesVersion(value string) (KubernetesVersion, error) {
+	parts := strings.SplitN(strings.TrimPrefix(value, "v"), ".", 3)
+	if len(parts) < 2 {
+		return KubernetesVersion{}, fmt.Errorf("invalid kubernetes version %q, expected <major>.<minor>", value)
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil || major < 1 {
+		return KubernetesVersion{}, fmt.Errorf("invalid major version in kubernetes version %q", value)
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 0 {
+		return KubernetesVersion{}, fmt.Errorf("invalid minor version in kubernetes version %q", value)
+	}
+
+	return KubernetesVersion{Major: major, Minor: minor}, nil
+}
+
+func (v KubernetesVersion) String() string {
+	if v == (KubernetesVersion{}) {
+		return "latest"
+	}
+
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// atLeast returns whether the version is the given one or newer.
+func (v KubernetesVersion) atLeast(major, minor int) bool {
+	if v == (KubernetesVersion{}) {
+		return true
+	}
+
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+// nativeSidecars returns whether init containers with the restartPolicy Always keep running next to the containers.
+// The SidecarContainers feature is enabled by default since kubernetes 1.29.
+func (v KubernetesVersion) nativeSidecars() bool {
+	return v.atLeast(1, 29)
+}
+
+// statefulSetMaxUnavailable returns whether the maxUnavailable of a StatefulSet rolling update exists, it was added
+// with kubernetes 1.24.
+func (v KubernetesVersion) statefulSetMaxUnavailable() bool {
+	return v.atLeast(1, 24)
+}
+
+// isSidecar returns whether an init container is a native sidecar, which keeps running next to the containers.
+// Older versions ignore the restartPolicy of init containers, which is warned about.
+func (o Options) isSidecar(container *v1.Container) bool {
+	if container.RestartPolicy == nil || *container.RestartPolicy != v1.ContainerRestartPolicyAlways {
+		return false
+	}
+
+	if !o.KubernetesVersion.nativeSidecars() {
+		log.Warn().Str("container", container.Name).Msgf("kubernetes %s ignores the restartPolicy of init containers, "+
+			"it is calculated as a regular init container", o.KubernetesVersion)
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,165 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var sidecarPod = `
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: mypod
+spec:
+  initContainers:
+  - name: proxy
+    image: proxy
+    restartPolicy: Always
+    resources:
+      requests:
+        cpu: 100m
+        memory: 128Mi
+  - name: migrate
+    image: migrate
+    resources:
+      requests:
+        cpu: "1"
+        memory: 1Gi
+  containers:
+  - name: myapp
+    image: myapp
+    resources:
+      requests:
+        cpu: 250m
+        memory: 2Gi`
+
+var maxUnavailableStatefulSet = `
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: myapp
+spec:
+  replicas: 4
+  updateStrategy:
+    type: RollingUpdate
+    rollingUpdate:
+      maxUnavailable: 2
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: migrate
+        resources:
+          requests:
+            cpu: "1"
+            memory: 1Gi
+      containers:
+      - name: myapp
+        image: myapp
+        resources:
+          requests:
+            cpu: 250m
+            memory: 2Gi`
+
+func TestParseKubernetesVersion(t *testing.T) {
+	tests := []struct {
+		value   string
+		version KubernetesVersion
+		err     bool
+	}{
+		{value: "1.27", version: KubernetesVersion{Major: 1, Minor: 27}},
+		{value: "v1.29.3", version: KubernetesVersion{Major: 1, Minor: 29}},
+		{value: "1", err: true},
+		{value: "1.x", err: true},
+		{value: "0.27", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			r := require.New(t)
+
+			version, err := ParseKubernetesVersion(test.value)
+			if test.err {
+				r.Error(err)
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(test.version, version)
+		})
+	}
+}
+
+func TestKubernetesVersionSidecars(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      KubernetesVersion
+		containerCPU resource.Quantity
+		initCPU      resource.Quantity
+	}{
+		{
+			name:         "latest",
+			containerCPU: resource.MustParse("350m"),
+			initCPU:      resource.MustParse("1100m"),
+		},
+		{
+			name:         "1.29 has native sidecars",
+			version:      KubernetesVersion{Major: 1, Minor: 29},
+			containerCPU: resource.MustParse("350m"),
+			initCPU:      resource.MustParse("1100m"),
+		},
+		{
+			name:         "1.28 runs sidecars as init containers",
+			version:      KubernetesVersion{Major: 1, Minor: 28},
+			containerCPU: resource.MustParse("250m"),
+			initCPU:      resource.MustParse("1100m"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(sidecarPod), Options{KubernetesVersion: test.version})
+			r.NoError(err)
+
+			AssertEqualQuantities(r, test.containerCPU, usage.NormalResources.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.initCPU, usage.Pod.InitContainers.CPUMin, "init cpu request value")
+			AssertEqualQuantities(r, test.initCPU, usage.RolloutResources.CPUMin, "rollout cpu request value")
+		})
+	}
+}
+
+func TestKubernetesVersionStatefulSetMaxUnavailable(t *testing.T) {
+	tests := []struct {
+		name    string
+		version KubernetesVersion
+		cpu     resource.Quantity
+	}{
+		{
+			name: "latest",
+			cpu:  resource.MustParse("2500m"),
+		},
+		{
+			name:    "1.23 ignores maxUnavailable",
+			version: KubernetesVersion{Major: 1, Minor: 23},
+			cpu:     resource.MustParse("1750m"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			usage, err := ResourceQuotaFromYaml([]byte(maxUnavailableStatefulSet), Options{KubernetesVersion: test.version})
+			r.NoError(err)
+
+			AssertEqualQuantities(r, test.cpu, usage.RolloutResources.CPUMin, "cpu request value")
+		})
+	}
+}
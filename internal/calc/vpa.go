@@ -0,0 +1,240 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+
+	openshiftAppsV1 "github.com/openshift/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// VPARecommendation selects which recommendation of a VerticalPodAutoscaler replaces the requests of the pods.
+type VPARecommendation string
+
+const (
+	// VPATarget uses the target recommendation, the requests the autoscaler sets on new pods.
+	VPATarget VPARecommendation = "target"
+	// VPAUpperBound uses the upper bound recommendation, the most the autoscaler considers to set.
+	VPAUpperBound VPARecommendation = "upperBound"
+	// VPAOff ignores the VerticalPodAutoscalers and keeps the requests of the pods.
+	VPAOff VPARecommendation = "off"
+)
+
+// ParseVPARecommendation parses the name of a VPARecommendation.
+func ParseVPARecommendation(recommendation string) (VPARecommendation, error) {
+	switch VPARecommendation(recommendation) {
+	case VPATarget, VPAUpperBound, VPAOff:
+		return VPARecommendation(recommendation), nil
+	default:
+		return "", fmt.Errorf("unknown vpa recommendation %q, supported are %s, %s and %s", recommendation, VPATarget, VPAUpperBound, VPAOff)
+	}
+}
+
+// verticalPodAutoscalerKind is the VerticalPodAutoscaler of the kubernetes autoscaler, which isn't part of the bundled
+// api.
+func verticalPodAutoscalerKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: "VerticalPodAutoscaler"}
+}
+
+// verticalAutoscaler is the part of a VerticalPodAutoscaler, which kuota-calc uses. The api of the kubernetes
+// autoscaler isn't a dependency of kuota-calc, so the VerticalPodAutoscaler is decoded from its raw manifest.
+// https://github.com/kubernetes/autoscaler/tree/master/vertical-pod-autoscaler
+type verticalAutoscaler struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		TargetRef struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"targetRef"`
+		UpdatePolicy *struct {
+			UpdateMode string `json:"updateMode,omitempty"`
+		} `json:"updatePolicy,omitempty"`
+		ResourcePolicy *struct {
+			ContainerPolicies []vpaContainerPolicy `json:"containerPolicies,omitempty"`
+		} `json:"resourcePolicy,omitempty"`
+	} `json:"spec"`
+	Status struct {
+		Recommendation *struct {
+			ContainerRecommendations []vpaContainerRecommendation `json:"containerRecommendations,omitempty"`
+		} `json:"recommendation,omitempty"`
+	} `json:"status"`
+}
+
+type vpaContainerPolicy struct {
+	ContainerName       string            `json:"containerName"`
+	Mode                string            `json:"mode,omitempty"`
+	ControlledResources []v1.ResourceName `json:"controlledResources,omitempty"`
+	ControlledValues    string            `json:"controlledValues,omitempty"`
+}
+
+type vpaContainerRecommendation struct {
+	ContainerName string          `json:"containerName"`
+	Target        v1.ResourceList `json:"target"`
+	UpperBound    v1.ResourceList `json:"upperBound,omitempty"`
+}
+
+// VerticalAutoscalers are VerticalPodAutoscalers indexed by the workload they scale.
+type VerticalAutoscalers map[scaleTarget]verticalAutoscaler
+
+// Add adds the object to the autoscalers if it is a VerticalPodAutoscaler and reports whether it was one.
+// VerticalPodAutoscalers, which can't be decoded, are ignored.
+func (a VerticalAutoscalers) Add(object runtime.Object) bool {
+	unknown, ok := object.(*runtime.Unknown)
+	if !ok || unknown.GroupVersionKind() != verticalPodAutoscalerKind() {
+		return false
+	}
+
+	var vpa verticalAutoscaler
+	if err := sigsyaml.Unmarshal(unknown.Raw, &vpa); err != nil {
+		return true
+	}
+
+	a[scaleTarget{namespace: vpa.Metadata.Namespace, kind: vpa.Spec.TargetRef.Kind, name: vpa.Spec.TargetRef.Name}] = vpa
+
+	return true
+}
+
+// verticallyScaled returns a copy of the object, whose pod template requests the recommendations of the
+// VerticalPodAutoscaler of the object, and the name of the autoscaler. The object is returned unchanged, if it has no
+// autoscaler with a recommendation, or the autoscaler only recommends (updateMode Off).
+func (o Options) verticallyScaled(object runtime.Object) (runtime.Object, string) {
+	if o.VPARecommendation == VPAOff || len(o.VerticalAutoscalers) == 0 {
+		return object, ""
+	}
+
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return object, ""
+	}
+
+	vpa, ok := o.VerticalAutoscalers[scaleTarget{
+		namespace: accessor.GetNamespace(),
+		kind:      objectKind(object).Kind,
+		name:      accessor.GetName(),
+	}]
+	if !ok || vpa.Status.Recommendation == nil || (vpa.Spec.UpdatePolicy != nil && vpa.Spec.UpdatePolicy.UpdateMode == "Off") {
+		return object, ""
+	}
+
+	recommended := object.DeepCopyObject()
+
+	podSpec := templatePodSpec(recommended)
+	if podSpec == nil {
+		return object, ""
+	}
+
+	for i := range podSpec.Containers {
+		vpa.recommend(&podSpec.Containers[i], o.VPARecommendation)
+	}
+
+	return recommended, vpa.Metadata.Name
+}
+
+// recommend replaces the requests of the container by its recommendation. Limits are scaled proportionally, unless
+// the autoscaler only controls the requests.
+func (a verticalAutoscaler) recommend(container *v1.Container, recommendation VPARecommendation) {
+	policy := a.containerPolicy(container.Name)
+	if policy.Mode == "Off" {
+		return
+	}
+
+	controlled := policy.ControlledResources
+	if len(controlled) == 0 {
+		controlled = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
+	}
+
+	for _, rec := range a.Status.Recommendation.ContainerRecommendations {
+		if rec.ContainerName != container.Name {
+			continue
+		}
+
+		values := rec.Target
+		if recommendation == VPAUpperBound && rec.UpperBound != nil {
+			values = rec.UpperBound
+		}
+
+		for _, name := range controlled {
+			value, ok := values[name]
+			if !ok {
+				continue
+			}
+
+			request, requested := container.Resources.Requests[name]
+			limit, limited := container.Resources.Limits[name]
+
+			if limited && requested && !request.IsZero() && policy.ControlledValues != "RequestsOnly" {
+				container.Resources.Limits[name] = scaledQuantity(name, limit, value.AsApproximateFloat64()/request.AsApproximateFloat64())
+			}
+
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = v1.ResourceList{}
+			}
+
+			container.Resources.Requests[name] = value
+		}
+	}
+}
+
+// containerPolicy returns the policy of the container, or the one of all containers (*).
+func (a verticalAutoscaler) containerPolicy(container string) vpaContainerPolicy {
+	var policy vpaContainerPolicy
+
+	if a.Spec.ResourcePolicy == nil {
+		return policy
+	}
+
+	for _, p := range a.Spec.ResourcePolicy.ContainerPolicies {
+		if p.ContainerName == container {
+			return p
+		}
+
+		if p.ContainerName == "*" {
+			policy = p
+		}
+	}
+
+	return policy
+}
+
+// scaledQuantity returns the quantity multiplied by the factor, rounded up to millicores for cpu and to bytes otherwise.
+func scaledQuantity(name v1.ResourceName, quantity resource.Quantity, factor float64) resource.Quantity {
+	if name == v1.ResourceCPU {
+		return *resource.NewMilliQuantity(int64(math.Ceil(float64(quantity.MilliValue())*factor)), quantity.Format)
+	}
+
+	return *resource.NewQuantity(int64(math.Ceil(quantity.AsApproximateFloat64()*factor)), quantity.Format)
+}
+
+// templatePodSpec returns the pod spec of the pod template of a workload a VerticalPodAutoscaler can target.
+func templatePodSpec(object runtime.Object) *v1.PodSpec {
+	switch o := object.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Template.Spec
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template.Spec
+	case *appsv1.DaemonSet:
+		return &o.Spec.Template.Spec
+	case *appsv1.ReplicaSet:
+		return &o.Spec.Template.Spec
+	case *batchV1.Job:
+		return &o.Spec.Template.Spec
+	case *batchV1.CronJob:
+		return &o.Spec.JobTemplate.Spec.Template.Spec
+	case *openshiftAppsV1.DeploymentConfig:
+		if o.Spec.Template != nil {
+			return &o.Spec.Template.Spec
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package calc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var vpaDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: team-a
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi
+          limits:
+            cpu: 200m
+            memory: 256Mi
+      - name: proxy
+        resources:
+          requests:
+            cpu: 50m
+            memory: 64Mi`
+
+var verticalPodAutoscaler = `
+apiVersion: autoscaling.k8s.io/v1
+kind: VerticalPodAutoscaler
+metadata:
+  name: api
+  namespace: team-a
+spec:
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: api
+  updatePolicy:
+    updateMode: UPDATE_MODE
+  resourcePolicy:
+    containerPolicies:
+    - containerName: proxy
+      mode: "Off"
+status:
+  recommendation:
+    containerRecommendations:
+    - containerName: app
+      target:
+        cpu: 300m
+        memory: 512Mi
+      upperBound:
+        cpu: 500m
+        memory: 1Gi
+    - containerName: proxy
+      target:
+        cpu: 10m
+        memory: 16Mi`
+
+func TestVerticalAutoscalers(t *testing.T) {
+	var tests = []struct {
+		name           string
+		updateMode     string
+		recommendation VPARecommendation
+		autoscaler     string
+		cpuMin         resource.Quantity
+		cpuMax         resource.Quantity
+		memoryMin      resource.Quantity
+		memoryMax      resource.Quantity
+	}{
+		{
+			name:       "target",
+			updateMode: "Auto",
+			autoscaler: "api",
+			cpuMin:     resource.MustParse("700m"),
+			cpuMax:     resource.MustParse("1200m"),
+			memoryMin:  resource.MustParse("1152Mi"),
+			memoryMax:  resource.MustParse("2Gi"),
+		},
+		{
+			name:           "upper bound",
+			updateMode:     "Auto",
+			recommendation: VPAUpperBound,
+			autoscaler:     "api",
+			cpuMin:         resource.MustParse("1100m"),
+			cpuMax:         resource.MustParse("2"),
+			memoryMin:      resource.MustParse("2176Mi"),
+			memoryMax:      resource.MustParse("4Gi"),
+		},
+		{
+			name:           "off",
+			updateMode:     "Auto",
+			recommendation: VPAOff,
+			cpuMin:         resource.MustParse("300m"),
+			cpuMax:         resource.MustParse("400m"),
+			memoryMin:      resource.MustParse("384Mi"),
+			memoryMax:      resource.MustParse("512Mi"),
+		},
+		{
+			name:       "recommendation only",
+			updateMode: "Off",
+			cpuMin:     resource.MustParse("300m"),
+			cpuMax:     resource.MustParse("400m"),
+			memoryMin:  resource.MustParse("384Mi"),
+			memoryMax:  resource.MustParse("512Mi"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := require.New(t)
+
+			vpa, err := Decode([]byte(strings.ReplaceAll(verticalPodAutoscaler, "UPDATE_MODE", test.updateMode)))
+			r.NoError(err)
+
+			autoscalers := VerticalAutoscalers{}
+			r.True(autoscalers.Add(vpa))
+
+			deployment, err := Decode([]byte(vpaDeployment))
+			r.NoError(err)
+			r.False(autoscalers.Add(deployment))
+
+			usage, err := ResourceQuotaFromObject(deployment, Options{VerticalAutoscalers: autoscalers, VPARecommendation: test.recommendation})
+			r.NoError(err)
+			r.Equal(test.autoscaler, usage.Details.VerticalAutoscaler)
+
+			AssertEqualQuantities(r, test.cpuMin, usage.NormalResources.CPUMin, "cpu request value")
+			AssertEqualQuantities(r, test.cpuMax, usage.NormalResources.CPUMax, "cpu limit value")
+			AssertEqualQuantities(r, test.memoryMin, usage.NormalResources.MemoryMin, "memory request value")
+			AssertEqualQuantities(r, test.memoryMax, usage.NormalResources.MemoryMax, "memory limit value")
+		})
+	}
+}